@@ -4,31 +4,40 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/guard"
+	"github.com/anomalyco/oho/internal/hook"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/resolve"
+	"github.com/anomalyco/oho/internal/schema"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+	"github.com/anomalyco/oho/internal/worktree"
 )
 
 // Flag variables for add command
 var (
-	addTitle      string
-	addParent     string
-	addAgent      = "" // 默认空字符串，与 prompt-async 保持一致
-	addModel      string
-	addNoReply    bool
-	addSystem     string
-	addTools      []string
-	addFiles      []string
-	addDirectory  string
-	addJSONOutput bool
-	addTimeout    int
+	addTitle     string
+	addParent    string
+	addAgent     = "" // 默认空字符串，与 prompt-async 保持一致
+	addModel     string
+	addNoReply   bool
+	addSystem    string
+	addTools     []string
+	addFiles     []string
+	addDirectory string
+	addTimeout   int
+	addWorktree  bool
+	addLimits    guard.Limits
+	addHooks     hook.Hooks
 )
 
 // Cmd add 命令 - 创建会话并发送消息
@@ -55,6 +64,7 @@ func init() {
 	Cmd.Flags().StringVar(&addTitle, "title", "", "Session title (auto-generated if not provided)")
 	Cmd.Flags().StringVar(&addParent, "parent", "", "Parent session ID (for creating sub-session)")
 	Cmd.Flags().StringVar(&addDirectory, "directory", "", "Working directory for the session (default: current directory)")
+	Cmd.Flags().BoolVar(&addWorktree, "worktree", false, "Run the task in a new git worktree on its own branch")
 
 	// Message-related flags
 	Cmd.Flags().StringVar(&addAgent, "agent", "", "Agent ID for message")
@@ -67,18 +77,36 @@ func init() {
 	// Timeout flag
 	Cmd.Flags().IntVar(&addTimeout, "timeout", 0, "Request timeout in seconds (0 uses default 300s)")
 
-	// Output format
-	Cmd.Flags().BoolVarP(&addJSONOutput, "json", "j", false, "Output in JSON format")
+	// Budget limits, enforced while waiting for the reply
+	addLimits.AddFlags(Cmd.Flags())
+
+	// Completion hooks, run when the reply finishes
+	addHooks.AddFlags(Cmd.Flags())
+
+	schema.Register("add", types.SubmitResult{})
 }
 
 func runAdd(cmd *cobra.Command, args []string) error {
+	if err := addLimits.RequireReply(addNoReply); err != nil {
+		return err
+	}
+	if err := addHooks.RequireReply(addNoReply); err != nil {
+		return err
+	}
+
 	// Apply timeout if specified
 	if addTimeout > 0 {
 		os.Setenv("OPENCODE_CLIENT_TIMEOUT", strconv.Itoa(addTimeout))
 	}
 
 	c := client.NewClient()
-	ctx := context.Background()
+	ctx := cmd.Context()
+
+	// The parent accepts the same references as -s (ID prefix, title, @last, aliases)
+	parent, err := resolve.Session(ctx, c, addParent)
+	if err != nil {
+		return err
+	}
 
 	// Step 1: Get current working directory
 	sessionDir := addDirectory
@@ -86,64 +114,96 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		var err error
 		sessionDir, err = os.Getwd()
 		if err != nil {
-			return fmt.Errorf("failed to get current directory: %w", err)
+			return i18n.Errorf("failed to get current directory: %w", err)
 		}
 	}
 
 	// Step 2: Generate title if not provided
 	sessionTitle := addTitle
 	if sessionTitle == "" {
-		sessionTitle = fmt.Sprintf("New session - %s", time.Now().Format("2006-01-02T15:04:05"))
+		sessionTitle = i18n.Sprintf("New session - %s", time.Now().Format("2006-01-02T15:04:05"))
 	}
 
-	// Step 3: Create session
-	sessionID, err := createSession(c, ctx, sessionTitle, addParent, sessionDir)
-	if err != nil {
-		return fmt.Errorf("failed to create session: %w", err)
+	// Step 3: Create session, optionally isolated in its own worktree
+	var (
+		wt        worktree.Entry
+		sessionID string
+	)
+	if addWorktree {
+		task := addTitle
+		if task == "" {
+			task = args[0]
+		}
+		wt, err = worktree.Setup(sessionDir, task, func(dir string) (string, error) {
+			return createSession(c, ctx, sessionTitle, parent, dir)
+		})
+		if err != nil {
+			return err
+		}
+		sessionID, sessionDir = wt.SessionID, wt.Path
+	} else {
+		sessionID, err = createSession(c, ctx, sessionTitle, parent, sessionDir)
+		if err != nil {
+			return i18n.Errorf("failed to create session: %w", err)
+		}
 	}
 
 	// Step 4: Send message
+	watcher, err := hook.Watch(ctx, c, types.Session{ID: sessionID, Title: sessionTitle, Directory: sessionDir}, addHooks)
+	if err != nil {
+		return err
+	}
 	message := args[0]
-	messageID, err := sendMessage(c, ctx, sessionID, message, addAgent, addModel, addNoReply, addSystem, addTools, addFiles)
+	var messageID string
+	err = guard.Send(ctx, c, sessionID, addLimits, func() error {
+		var err error
+		messageID, err = sendMessage(c, ctx, sessionID, message, addAgent, addModel, addNoReply, addSystem, addTools, addFiles)
+		return err
+	})
+	hook.Report(watcher.Finish(messageID, err))
+	var abortErr *guard.AbortError
+	if errors.As(err, &abortErr) {
+		return err
+	}
 	if err != nil {
-		// Message send failed, but session was created
-		if addJSONOutput {
-			output := map[string]interface{}{
-				"sessionId": sessionID,
-				"status":    "partial",
-				"error":     fmt.Sprintf("failed to send message: %v", err),
-			}
-			data, _ := json.MarshalIndent(output, "", "  ")
-			fmt.Println(string(data))
-		} else {
-			fmt.Printf("Session created: %s\n", sessionID)
-			fmt.Printf("Warning: Message send failed: %v\n", err)
+		// Message send failed, but session was created: report it as a failure
+		// and keep the session in the error details so scripts can clean up or retry
+		partial := types.SubmitResult{
+			SessionID: sessionID,
+			Directory: sessionDir,
+			Title:     sessionTitle,
+			Branch:    wt.Branch,
+			Status:    "partial",
+			Error:     err.Error(),
 		}
-		return nil
+		return util.WithDetails(i18n.Errorf("session %s was created, but sending the message failed: %w", sessionID, err), partial)
 	}
 
 	// Step 5: Output result
-	if addJSONOutput {
-		output := map[string]interface{}{
-			"sessionId": sessionID,
-			"messageId": messageID,
-			"directory": sessionDir,
-			"title":     sessionTitle,
-			"status":    "success",
-		}
-		data, _ := json.MarshalIndent(output, "", "  ")
-		fmt.Println(string(data))
-	} else {
-		fmt.Printf("Session created: %s\n", sessionID)
-		if messageID != "" {
-			if addNoReply {
-				fmt.Printf("Message sent (async): %s\n", messageID)
-			} else {
-				fmt.Printf("Message sent: %s\n", messageID)
-			}
+	result := types.SubmitResult{
+		SessionID: sessionID,
+		MessageID: messageID,
+		Directory: sessionDir,
+		Title:     sessionTitle,
+		Branch:    wt.Branch,
+		Status:    "success",
+	}
+	if ok, err := util.Render(ctx, result); ok || err != nil {
+		return err
+	}
+
+	i18n.Printf("Session created: %s\n", sessionID)
+	if addWorktree {
+		i18n.Printf("Worktree: %s (branch %s)\n", wt.Path, wt.Branch)
+	}
+	if messageID != "" {
+		if addNoReply {
+			i18n.Printf("Message sent (async): %s\n", messageID)
 		} else {
-			fmt.Println("Message sent successfully")
+			i18n.Printf("Message sent: %s\n", messageID)
 		}
+	} else {
+		fmt.Println(i18n.T("Message sent successfully"))
 	}
 
 	return nil
@@ -164,38 +224,17 @@ func createSession(c client.ClientInterface, ctx context.Context, title, parentI
 	queryParams := map[string]string{"directory": directory}
 	resp, err := c.PostWithQuery(ctx, "/session", queryParams, req)
 	if err != nil {
-		return "", fmt.Errorf("API request failed: %w", err)
+		return "", i18n.Errorf("API request failed: %w", err)
 	}
 
 	var session types.Session
 	if err := json.Unmarshal(resp, &session); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return "", i18n.Errorf("failed to parse response: %w", err)
 	}
 
 	return session.ID, nil
 }
 
-// convertModel converts a model string to the appropriate format (string or object)
-func convertModel(model string) interface{} {
-	if model == "" {
-		return nil
-	}
-
-	// Check if the model string contains a colon, which indicates provider:model format
-	if strings.Contains(model, ":") {
-		parts := strings.SplitN(model, ":", 2)
-		if len(parts) == 2 {
-			return types.Model{
-				ProviderID: parts[0],
-				ModelID:    parts[1],
-			}
-		}
-	}
-
-	// If no colon, treat as simple string model (backward compatibility)
-	return model
-}
-
 // sendMessage sends a message to the session and returns the message ID
 // For no-reply mode, it uses the dedicated /prompt_async endpoint
 func sendMessage(c client.ClientInterface, ctx context.Context, sessionID, message, agent, model string, noReply bool, system string, tools, files []string) (string, error) {
@@ -213,17 +252,17 @@ func sendMessage(c client.ClientInterface, ctx context.Context, sessionID, messa
 	for _, filePath := range files {
 		// Check if file exists
 		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			return "", fmt.Errorf("file not found: %s", filePath)
+			return "", i18n.Errorf("file not found: %s", filePath)
 		}
 
 		// Read file content
 		fileData, err := os.ReadFile(filePath)
 		if err != nil {
-			return "", fmt.Errorf("failed to read file %s: %w", filePath, err)
+			return "", i18n.Errorf("failed to read file %s: %w", filePath, err)
 		}
 
 		// Detect MIME type
-		mimeType := detectMimeType(filePath)
+		mimeType := util.DetectMimeType(filePath)
 
 		// Encode to base64 data URL
 		base64Data := base64.StdEncoding.EncodeToString(fileData)
@@ -238,7 +277,7 @@ func sendMessage(c client.ClientInterface, ctx context.Context, sessionID, messa
 
 	// Build message request
 	msgReq := types.MessageRequest{
-		Model:   convertModel(model),
+		Model:   util.ConvertModel(model),
 		Agent:   agent,
 		NoReply: noReply,
 		System:  system,
@@ -258,7 +297,7 @@ func sendMessage(c client.ClientInterface, ctx context.Context, sessionID, messa
 
 	resp, err := c.Post(ctx, endpoint, msgReq)
 	if err != nil {
-		return "", fmt.Errorf("API request failed: %w", err)
+		return "", i18n.Errorf("API request failed: %w", err)
 	}
 
 	// Handle empty response (no-reply mode)
@@ -272,74 +311,8 @@ func sendMessage(c client.ClientInterface, ctx context.Context, sessionID, messa
 
 	var result types.MessageWithParts
 	if err := json.Unmarshal(resp, &result); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return "", i18n.Errorf("failed to parse response: %w", err)
 	}
 
 	return result.Info.ID, nil
 }
-
-// detectMimeType detects MIME type based on file extension
-func detectMimeType(filePath string) string {
-	dotIndex := strings.LastIndex(filePath, ".")
-	if dotIndex == -1 {
-		return "application/octet-stream"
-	}
-	ext := strings.ToLower(filePath[dotIndex:])
-
-	mimeTypes := map[string]string{
-		// Images
-		".jpg":  "image/jpeg",
-		".jpeg": "image/jpeg",
-		".png":  "image/png",
-		".gif":  "image/gif",
-		".webp": "image/webp",
-		".bmp":  "image/bmp",
-		".svg":  "image/svg+xml",
-
-		// Documents
-		".pdf":  "application/pdf",
-		".doc":  "application/msword",
-		".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
-		".xls":  "application/vnd.ms-excel",
-		".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
-		".ppt":  "application/vnd.ms-powerpoint",
-		".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
-
-		// Text
-		".txt":  "text/plain",
-		".md":   "text/markdown",
-		".html": "text/html",
-		".css":  "text/css",
-		".js":   "application/javascript",
-		".json": "application/json",
-		".xml":  "application/xml",
-		".yaml": "application/x-yaml",
-		".yml":  "application/x-yaml",
-
-		// Code
-		".py":   "text/x-python",
-		".go":   "text/x-go",
-		".java": "text/x-java",
-		".c":    "text/x-c",
-		".cpp":  "text/x-c++",
-		".h":    "text/x-c",
-		".rs":   "text/x-rust",
-		".ts":   "text/x-typescript",
-		".tsx":  "text/x-typescript",
-
-		// Other
-		".zip": "application/zip",
-		".tar": "application/x-tar",
-		".gz":  "application/gzip",
-		".mp3": "audio/mpeg",
-		".mp4": "video/mp4",
-		".wav": "audio/wav",
-	}
-
-	if mimeType, ok := mimeTypes[ext]; ok {
-		return mimeType
-	}
-
-	// Default to octet-stream
-	return "application/octet-stream"
-}
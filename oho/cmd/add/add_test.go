@@ -8,8 +8,8 @@ import (
 
 	"github.com/anomalyco/oho/internal/client"
 	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
 	"github.com/anomalyco/oho/internal/testutil"
-	"github.com/anomalyco/oho/internal/types"
 )
 
 func TestMain(m *testing.M) {
@@ -18,113 +18,12 @@ func TestMain(m *testing.M) {
 	os.Setenv("OPENCODE_SERVER_USERNAME", "opencode")
 	os.Setenv("OPENCODE_SERVER_PASSWORD", "test")
 	_ = config.Init()
+	// add 命令的原文是英文，断言按英文编写
+	i18n.SetLanguage(i18n.English)
 
 	m.Run()
 }
 
-func TestConvertModel(t *testing.T) {
-	tests := []struct {
-		name     string
-		model    string
-		wantType string
-		wantStr  string
-		wantObj  types.Model
-	}{
-		{
-			name:     "empty model returns nil",
-			model:    "",
-			wantType: "nil",
-		},
-		{
-			name:     "simple model returns string",
-			model:    "gpt-4",
-			wantType: "string",
-			wantStr:  "gpt-4",
-		},
-		{
-			name:     "provider:model format returns Model object",
-			model:    "openai:gpt-4",
-			wantType: "Model",
-			wantObj:  types.Model{ProviderID: "openai", ModelID: "gpt-4"},
-		},
-		{
-			name:     "provider with colon in model name",
-			model:    "anthropic:claude-3-opus",
-			wantType: "Model",
-			wantObj:  types.Model{ProviderID: "anthropic", ModelID: "claude-3-opus"},
-		},
-		{
-			name:     "model without provider stays string",
-			model:    "claude-3",
-			wantType: "string",
-			wantStr:  "claude-3",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := convertModel(tt.model)
-
-			switch tt.wantType {
-			case "nil":
-				if result != nil {
-					t.Errorf("convertModel(%q) = %v, want nil", tt.model, result)
-				}
-			case "string":
-				str, ok := result.(string)
-				if !ok {
-					t.Errorf("convertModel(%q) returned %T, want string", tt.model, result)
-				} else if str != tt.wantStr {
-					t.Errorf("convertModel(%q) = %q, want %q", tt.model, str, tt.wantStr)
-				}
-			case "Model":
-				obj, ok := result.(types.Model)
-				if !ok {
-					t.Errorf("convertModel(%q) returned %T, want types.Model", tt.model, result)
-				} else if obj.ProviderID != tt.wantObj.ProviderID || obj.ModelID != tt.wantObj.ModelID {
-					t.Errorf("convertModel(%q) = %+v, want %+v", tt.model, obj, tt.wantObj)
-				}
-			}
-		})
-	}
-}
-
-func TestDetectMimeType(t *testing.T) {
-	tests := []struct {
-		name     string
-		filePath string
-		want     string
-	}{
-		{"text file", "test.txt", "text/plain"},
-		{"markdown file", "README.md", "text/markdown"},
-		{"Go source", "main.go", "text/x-go"},
-		{"Python source", "script.py", "text/x-python"},
-		{"JavaScript", "app.js", "application/javascript"},
-		{"TypeScript", "index.ts", "text/x-typescript"},
-		{"TSX file", "component.tsx", "text/x-typescript"},
-		{"JSON", "config.json", "application/json"},
-		{"YAML", "config.yaml", "application/x-yaml"},
-		{"YML", "config.yml", "application/x-yaml"},
-		{"PNG image", "image.png", "image/png"},
-		{"JPEG image", "photo.jpg", "image/jpeg"},
-		{"GIF image", "anim.gif", "image/gif"},
-		{"PDF document", "doc.pdf", "application/pdf"},
-		{"ZIP archive", "archive.zip", "application/zip"},
-		{"unknown extension", "file.xyz", "application/octet-stream"},
-		{"no extension", "Makefile", "application/octet-stream"},
-		{"uppercase extension", "IMAGE.PNG", "image/png"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := detectMimeType(tt.filePath)
-			if got != tt.want {
-				t.Errorf("detectMimeType(%q) = %q, want %q", tt.filePath, got, tt.want)
-			}
-		})
-	}
-}
-
 func TestCreateSession(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -492,7 +391,7 @@ func TestRunAddSuccess(t *testing.T) {
 			addSystem = tt.system
 			addTools = tt.tools
 			addFiles = tt.files
-			addJSONOutput = tt.jsonOutput
+			config.Get().JSON = tt.jsonOutput
 
 			mock := &client.MockClient{
 				PostWithQueryFunc: func(ctx context.Context, path string, queryParams map[string]string, body interface{}) ([]byte, error) {
@@ -756,7 +655,7 @@ func TestJSONOutputFormat(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			addJSONOutput = tt.jsonOutput
+			config.Get().JSON = tt.jsonOutput
 
 			mock := &client.MockClient{
 				PostWithQueryFunc: func(ctx context.Context, path string, queryParams map[string]string, body interface{}) ([]byte, error) {
@@ -1,15 +1,16 @@
 package agent
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
-	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
+ "github.com/anomalyco/oho/internal/schema"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 // Cmd 代理命令
@@ -22,9 +23,10 @@ var Cmd = &cobra.Command{
 var listCmd = &cobra.Command{
  Use:   "list",
  Short: "列出所有代理",
+ Annotations: map[string]string{"mcp": "readonly"},
  RunE: func(cmd *cobra.Command, args []string) error {
   c := client.NewClient()
-  ctx := context.Background()
+  ctx := cmd.Context()
 
   resp, err := c.Get(ctx, "/agent")
   if err != nil {
@@ -36,24 +38,22 @@ var listCmd = &cobra.Command{
    return err
   }
 
-  if config.Get().JSON {
-   data, _ := json.MarshalIndent(agents, "", "  ")
-   fmt.Println(string(data))
-   return nil
+  if ok, err := util.Render(ctx, agents); ok || err != nil {
+   return err
   }
 
   if len(agents) == 0 {
-   fmt.Println("没有可用代理")
+   fmt.Println(i18n.T("没有可用代理"))
    return nil
   }
 
-  fmt.Printf("共 %d 个代理:\n\n", len(agents))
+  i18n.Printf("共 %d 个代理:\n\n", len(agents))
   for _, a := range agents {
    fmt.Printf("🤖 %s\n", a.Name)
    fmt.Printf("   ID: %s\n", a.ID)
-   fmt.Printf("   描述：%s\n", a.Description)
+   i18n.Printf("   描述：%s\n", a.Description)
    if len(a.Tools) > 0 {
-    fmt.Printf("   工具：%s\n", a.Tools)
+    i18n.Printf("   工具：%s\n", a.Tools)
    }
    fmt.Println()
   }
@@ -64,4 +64,7 @@ var listCmd = &cobra.Command{
 
 func init() {
  Cmd.AddCommand(listCmd)
+
+ // JSON 输出 Schema
+ schema.Register("agent list", []types.Agent{})
 }
@@ -1,13 +1,16 @@
 package auth
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 // Cmd 认证命令
@@ -26,7 +29,7 @@ var (
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			// 解析凭据
 			credsMap := make(map[string]string)
@@ -39,7 +42,7 @@ var (
 			}
 
 			if len(credsMap) == 0 {
-				return fmt.Errorf("请提供至少一个凭据 (--credentials key=value)")
+				return i18n.Errorf("请提供至少一个凭据 (--credentials key=value)")
 			}
 
 			req := map[string]interface{}{
@@ -57,8 +60,11 @@ var (
 				return err
 			}
 
+			if ok, err := util.Render(ctx, types.ActionResult{ID: args[0], Success: success}); ok || err != nil {
+				return err
+			}
 			if success {
-				fmt.Printf("提供商 %s 的认证凭据已设置\n", args[0])
+				i18n.Printf("提供商 %s 的认证凭据已设置\n", args[0])
 			}
 			return nil
 		},
@@ -70,6 +76,9 @@ func init() {
 
 	setCmd.Flags().StringArrayVar(&credentials, "credentials", nil, "认证凭据 (key=value 格式)")
 	_ = setCmd.MarkFlagRequired("credentials")
+
+	// JSON 输出 Schema
+	schema.Register("auth set", types.ActionResult{})
 }
 
 func indexOf(s string, substr string) int {
@@ -0,0 +1,238 @@
+package chat
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/resolve"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// 输入提示符，以 \ 结尾的行续行时使用 continuePrompt
+const (
+	inputPrompt    = "> "
+	continuePrompt = ". "
+)
+
+var (
+	chatSession   string
+	chatTitle     string
+	chatDirectory string
+	chatModel     string
+	chatAgent     string
+)
+
+// Cmd 交互式对话命令
+var Cmd = &cobra.Command{
+	Use:   "chat",
+	Short: "与会话进行交互式对话",
+	Long: `打开已有会话或创建新会话，逐行读取输入并流式输出回复。
+
+支持行编辑和历史记录（上下方向键），以 \ 结尾的行与下一行合并发送。
+等待回复时按 Ctrl+C 中止会话，在输入提示符处按 Ctrl+D 或输入 /exit 退出。
+
+对话中可以使用以下命令:
+  /model [provider:model]  查看或切换模型
+  /agent [name]            查看或切换代理
+  /attach <file>           附加文件，随下一条消息发送
+  /abort                   中止正在运行的会话
+  /diff                    显示会话的文件变更
+  /fork                    分叉会话并切换到新会话
+  /undo                    回退最后一条消息
+  /<command> [参数]        执行服务器定义的斜杠命令（见 oho command list）`,
+	Example: `  oho chat
+  oho chat -s ses_xxx
+  oho chat --title refactor --model openai:gpt-4o`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{"mcp": "skip"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := client.NewClient()
+		ctx := cmd.Context()
+
+		session, err := openSession(ctx, c)
+		if err != nil {
+			return err
+		}
+
+		input, err := newReader()
+		if err != nil {
+			return err
+		}
+
+		r := &repl{
+			c:         c,
+			out:       os.Stdout,
+			color:     util.ColorEnabled(),
+			sessionID: session.ID,
+			model:     chatModel,
+			agent:     chatAgent,
+		}
+		if input.interactive() {
+			i18n.Printf("会话 %s：%s\n", session.ID, session.Title)
+			i18n.Printf("输入 /help 查看命令，Ctrl+D 退出\n")
+		}
+
+		for {
+			line, err := readInput(input)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			quit, err := r.handle(ctx, line)
+			if err != nil {
+				i18n.Fprintf(os.Stderr, "错误：%v\n", err)
+			}
+			if quit {
+				return nil
+			}
+		}
+	},
+}
+
+func init() {
+	Cmd.Flags().StringVarP(&chatSession, "session", "s", "", "要继续的会话 ID（默认创建新会话）")
+	Cmd.Flags().StringVar(&chatTitle, "title", "", "新会话的标题")
+	Cmd.Flags().StringVar(&chatDirectory, "directory", "", "新会话的工作目录（默认当前目录）")
+	Cmd.Flags().StringVar(&chatModel, "model", "", "消息使用的模型 (provider:model)")
+	Cmd.Flags().StringVar(&chatAgent, "agent", "", "消息使用的代理")
+}
+
+// openSession 打开 -s 指定的会话，未指定时在工作目录创建新会话
+func openSession(ctx context.Context, c client.ClientInterface) (types.Session, error) {
+	var session types.Session
+
+	if chatSession != "" {
+		id, err := resolve.Session(ctx, c, chatSession)
+		if err != nil {
+			return session, err
+		}
+		chatSession = id
+		resp, err := c.Get(ctx, fmt.Sprintf("/session/%s", chatSession))
+		if err != nil {
+			return session, err
+		}
+		if err := json.Unmarshal(resp, &session); err != nil {
+			return session, i18n.Errorf("解析会话失败：%w", err)
+		}
+		if session.ID == "" {
+			session.ID = chatSession
+		}
+		return session, nil
+	}
+
+	dir := chatDirectory
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return session, i18n.Errorf("获取当前目录失败：%w", err)
+		}
+		dir = wd
+	}
+	req := map[string]interface{}{}
+	if chatTitle != "" {
+		req["title"] = chatTitle
+	}
+	resp, err := c.PostWithQuery(ctx, "/session", map[string]string{"directory": dir}, req)
+	if err != nil {
+		return session, i18n.Errorf("创建会话失败：%w", err)
+	}
+	if err := json.Unmarshal(resp, &session); err != nil {
+		return session, i18n.Errorf("创建会话失败：%w", err)
+	}
+	return session, nil
+}
+
+// reader 逐行读取输入
+type reader interface {
+	readLine(prompt string) (string, error)
+	interactive() bool
+}
+
+// readInput 读取一条输入，以 \ 结尾的行与下一行合并
+func readInput(in reader) (string, error) {
+	var lines []string
+	prompt := inputPrompt
+	for {
+		line, err := in.readLine(prompt)
+		if err != nil {
+			if err == io.EOF && len(lines) > 0 {
+				return strings.Join(lines, "\n"), nil
+			}
+			return "", err
+		}
+		if !strings.HasSuffix(line, `\`) {
+			return strings.Join(append(lines, line), "\n"), nil
+		}
+		lines = append(lines, strings.TrimSuffix(line, `\`))
+		prompt = continuePrompt
+	}
+}
+
+// newReader 标准输入是终端时使用支持行编辑和历史记录的终端，否则按行读取
+func newReader() (reader, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return &lineReader{scanner: bufio.NewScanner(os.Stdin)}, nil
+	}
+	rw := struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}
+	return &terminalReader{fd: fd, term: term.NewTerminal(rw, inputPrompt)}, nil
+}
+
+// terminalReader 读取每一行时将终端切换到原始模式，输出回复时恢复
+type terminalReader struct {
+	fd   int
+	term *term.Terminal
+}
+
+func (t *terminalReader) readLine(prompt string) (string, error) {
+	state, err := term.MakeRaw(t.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(t.fd, state)
+
+	if width, height, err := term.GetSize(t.fd); err == nil && width > 0 {
+		_ = t.term.SetSize(width, height)
+	}
+	t.term.SetPrompt(prompt)
+	line, err := t.term.ReadLine()
+	if err == term.ErrPasteIndicator {
+		err = nil
+	}
+	return line, err
+}
+
+func (t *terminalReader) interactive() bool { return true }
+
+// lineReader 从管道或文件按行读取，不显示提示符
+type lineReader struct {
+	scanner *bufio.Scanner
+}
+
+func (l *lineReader) readLine(string) (string, error) {
+	if l.scanner.Scan() {
+		return l.scanner.Text(), nil
+	}
+	if err := l.scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", io.EOF
+}
+
+func (l *lineReader) interactive() bool { return false }
@@ -0,0 +1,294 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// sse 将事件编码为 SSE 数据块
+func sse(t *testing.T, typ string, props interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{"type": typ, "properties": props})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte("data: " + string(data) + "\n\n")
+}
+
+func TestPrinter(t *testing.T) {
+	var out bytes.Buffer
+	p := newPrinter(&out, "ses_1", false)
+
+	part := func(id, msg, typ, text string) types.Event {
+		props, _ := json.Marshal(map[string]interface{}{
+			"part": map[string]interface{}{"id": id, "sessionID": "ses_1", "messageID": msg, "type": typ, "text": text},
+		})
+		return types.Event{Type: "message.part.updated", Properties: props}
+	}
+	message := func(id, role string) types.Event {
+		props, _ := json.Marshal(map[string]interface{}{"info": map[string]interface{}{"id": id, "sessionID": "ses_1", "role": role}})
+		return types.Event{Type: "message.updated", Properties: props}
+	}
+
+	events := []types.Event{
+		message("msg_user", "user"),
+		part("prt_0", "msg_user", "text", "question"),
+		// 消息角色确定前到达的部分先暂存
+		part("prt_1", "msg_a", "text", "Hel"),
+		message("msg_a", "assistant"),
+		part("prt_1", "msg_a", "text", "Hello"),
+		part("prt_1", "msg_a", "text", "Hello world"),
+		{Type: "message.part.updated", Properties: json.RawMessage(`{"part":{"id":"prt_2","sessionID":"ses_1","messageID":"msg_a","type":"tool","tool":"bash","state":{"status":"running"}}}`)},
+		{Type: "message.part.updated", Properties: json.RawMessage(`{"part":{"id":"prt_2","sessionID":"ses_1","messageID":"msg_a","type":"tool","tool":"bash","state":{"status":"completed","title":"ls"}}}`)},
+		{Type: "message.part.updated", Properties: json.RawMessage(`{"part":{"id":"prt_9","sessionID":"ses_2","messageID":"msg_a","type":"text","text":"other session"}}`)},
+	}
+	for _, e := range events {
+		if done, _ := p.handle(e); done {
+			t.Fatalf("Unexpected done on %s", e.Type)
+		}
+	}
+
+	done, err := p.handle(types.Event{Type: "session.idle", Properties: json.RawMessage(`{"sessionID":"ses_1"}`)})
+	if !done || err != nil {
+		t.Fatalf("handle(session.idle) = %v, %v", done, err)
+	}
+	if want := "Hello world\n⚙ bash: ls\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestPrinterStatus(t *testing.T) {
+	p := newPrinter(io.Discard, "ses_1", false)
+	idle := types.Event{Type: "session.status", Properties: json.RawMessage(`{"sessionID":"ses_1","status":{"type":"idle"}}`)}
+
+	// 会话开始处理请求之前的 idle 状态不表示回复结束
+	if done, _ := p.handle(idle); done {
+		t.Error("Expected idle before busy to be ignored")
+	}
+	p.handle(types.Event{Type: "session.status", Properties: json.RawMessage(`{"sessionID":"ses_1","status":{"type":"busy"}}`)})
+	if done, _ := p.handle(idle); !done {
+		t.Error("Expected idle after busy to finish")
+	}
+
+	done, err := p.handle(types.Event{Type: "session.error", Properties: json.RawMessage(`{"sessionID":"ses_1","error":{"name":"APIError","data":{"message":"rate limited"}}}`)})
+	if !done || err == nil || err.Error() != "rate limited" {
+		t.Errorf("handle(session.error) = %v, %v", done, err)
+	}
+}
+
+func TestUndoTarget(t *testing.T) {
+	messages := []types.MessageWithParts{
+		{Info: types.Message{ID: "m1", Role: "user"}},
+		{Info: types.Message{ID: "m2", Role: "assistant"}},
+		{Info: types.Message{ID: "m3", Role: "user"}},
+		{Info: types.Message{ID: "m4", Role: "assistant"}},
+	}
+	tests := []struct {
+		name   string
+		revert *revertInfo
+		want   string
+	}{
+		{"no revert", nil, "m3"},
+		{"already reverted", &revertInfo{MessageID: "m3"}, "m1"},
+		{"nothing left", &revertInfo{MessageID: "m1"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := undoTarget(messages, tt.revert); got != tt.want {
+				t.Errorf("undoTarget() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandle(t *testing.T) {
+	var posts []string
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			switch path {
+			case "/command":
+				return []byte(`[{"name":"review","description":"Review changes"}]`), nil
+			case "/session/ses_1/diff":
+				return []byte(`[{"path":"a.txt","before":"a\n","after":"b\n"}]`), nil
+			}
+			return nil, errors.New("unexpected path " + path)
+		},
+		PostFunc: func(ctx context.Context, path string, body interface{}) ([]byte, error) {
+			posts = append(posts, path)
+			if path == "/session/ses_1/fork" {
+				return []byte(`{"id":"ses_2"}`), nil
+			}
+			return []byte(`true`), nil
+		},
+	}
+	var out bytes.Buffer
+	r := &repl{c: mock, out: &out, sessionID: "ses_1"}
+	ctx := context.Background()
+
+	if _, err := r.handle(ctx, "/model openai:gpt-4o"); err != nil || r.model != "openai:gpt-4o" {
+		t.Errorf("/model: model = %q, err = %v", r.model, err)
+	}
+	if _, err := r.handle(ctx, "/agent build"); err != nil || r.agent != "build" {
+		t.Errorf("/agent: agent = %q, err = %v", r.agent, err)
+	}
+
+	file := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(file, []byte("# notes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.handle(ctx, "/attach "+file); err != nil || len(r.files) != 1 {
+		t.Errorf("/attach: files = %v, err = %v", r.files, err)
+	}
+	if _, err := r.handle(ctx, "/attach /no/such/file"); err == nil {
+		t.Error("/attach of missing file should fail")
+	}
+
+	out.Reset()
+	if _, err := r.handle(ctx, "/diff"); err != nil {
+		t.Fatalf("/diff: %v", err)
+	}
+	if !strings.Contains(out.String(), "-a\n+b\n") {
+		t.Errorf("/diff output = %q", out.String())
+	}
+
+	if _, err := r.handle(ctx, "/nope"); err == nil {
+		t.Error("Unknown command should fail")
+	}
+
+	if _, err := r.handle(ctx, "/fork"); err != nil || r.sessionID != "ses_2" {
+		t.Errorf("/fork: session = %q, err = %v", r.sessionID, err)
+	}
+	if _, err := r.handle(ctx, "/abort"); err != nil {
+		t.Errorf("/abort: %v", err)
+	}
+	if want := []string{"/session/ses_1/fork", "/session/ses_2/abort"}; strings.Join(posts, ",") != strings.Join(want, ",") {
+		t.Errorf("posts = %v, want %v", posts, want)
+	}
+
+	if quit, _ := r.handle(ctx, "/exit"); !quit {
+		t.Error("/exit should quit")
+	}
+}
+
+func TestPromptStream(t *testing.T) {
+	sent := make(chan struct{})
+	var request types.MessageRequest
+	mock := &client.MockClient{
+		SSEStreamFunc: func(ctx context.Context, path string) (<-chan []byte, <-chan error, error) {
+			chunks := make(chan []byte)
+			errs := make(chan error)
+			go func() {
+				defer close(chunks)
+				defer close(errs)
+				<-sent
+				for _, c := range [][]byte{
+					sse(t, "session.status", map[string]interface{}{"sessionID": "ses_1", "status": map[string]string{"type": "busy"}}),
+					sse(t, "message.updated", map[string]interface{}{"info": map[string]string{"id": "msg_a", "sessionID": "ses_1", "role": "assistant"}}),
+					sse(t, "message.part.updated", map[string]interface{}{"part": map[string]string{"id": "prt_1", "sessionID": "ses_1", "messageID": "msg_a", "type": "text", "text": "Hi"}}),
+					sse(t, "session.idle", map[string]string{"sessionID": "ses_1"}),
+				} {
+					select {
+					case chunks <- c:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+			return chunks, errs, nil
+		},
+		PostFunc: func(ctx context.Context, path string, body interface{}) ([]byte, error) {
+			if path != "/session/ses_1/prompt_async" {
+				t.Errorf("Unexpected path: %s", path)
+			}
+			request = body.(types.MessageRequest)
+			close(sent)
+			return nil, nil
+		},
+	}
+
+	file := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(file, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	r := &repl{c: mock, out: &out, sessionID: "ses_1", agent: "build", files: []string{file}}
+	if err := r.prompt(context.Background(), "hello"); err != nil {
+		t.Fatalf("prompt() error: %v", err)
+	}
+	if out.String() != "Hi\n" {
+		t.Errorf("output = %q", out.String())
+	}
+	if request.Agent != "build" || len(request.Parts) != 2 || request.Parts[1].Type != "file" {
+		t.Errorf("request = %+v", request)
+	}
+	if len(r.files) != 0 {
+		t.Error("Expected attachments to be cleared after sending")
+	}
+}
+
+func TestPromptWithoutEvents(t *testing.T) {
+	mock := &client.MockClient{
+		SSEStreamFunc: func(ctx context.Context, path string) (<-chan []byte, <-chan error, error) {
+			return nil, nil, errors.New("not supported")
+		},
+		PostFunc: func(ctx context.Context, path string, body interface{}) ([]byte, error) {
+			if path != "/session/ses_1/message" {
+				t.Errorf("Unexpected path: %s", path)
+			}
+			return []byte(`{"info":{"id":"msg_a","role":"assistant"},"parts":[{"type":"text","text":"Done"}]}`), nil
+		},
+	}
+	var out bytes.Buffer
+	r := &repl{c: mock, out: &out, sessionID: "ses_1"}
+	if err := r.prompt(context.Background(), "hello"); err != nil {
+		t.Fatalf("prompt() error: %v", err)
+	}
+	if !strings.Contains(out.String(), "Done") {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+// fakeReader 依次返回预设的行
+type fakeReader struct {
+	lines   []string
+	prompts []string
+}
+
+func (f *fakeReader) readLine(prompt string) (string, error) {
+	f.prompts = append(f.prompts, prompt)
+	if len(f.lines) == 0 {
+		return "", io.EOF
+	}
+	line := f.lines[0]
+	f.lines = f.lines[1:]
+	return line, nil
+}
+
+func (f *fakeReader) interactive() bool { return false }
+
+func TestReadInput(t *testing.T) {
+	in := &fakeReader{lines: []string{`first \`, `second`, `third`}}
+	line, err := readInput(in)
+	if err != nil || line != "first \nsecond" {
+		t.Errorf("readInput() = %q, %v", line, err)
+	}
+	if strings.Join(in.prompts, "|") != inputPrompt+"|"+continuePrompt {
+		t.Errorf("prompts = %q", in.prompts)
+	}
+	if line, _ := readInput(in); line != "third" {
+		t.Errorf("readInput() = %q", line)
+	}
+	if _, err := readInput(in); err != io.EOF {
+		t.Errorf("readInput() at end = %v, want EOF", err)
+	}
+}
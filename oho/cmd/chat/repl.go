@@ -0,0 +1,404 @@
+package chat
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/diff"
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// builtin REPL 内置命令及说明
+var builtin = []struct {
+	usage string
+	help  string
+}{
+	{"/model [provider:model]", "查看或切换后续消息使用的模型"},
+	{"/agent [name]", "查看或切换后续消息使用的代理"},
+	{"/attach <file>", "附加文件，随下一条消息发送"},
+	{"/abort", "中止正在运行的会话"},
+	{"/diff", "显示会话的文件变更"},
+	{"/fork", "分叉当前会话并切换到新会话"},
+	{"/undo", "回退最后一条用户消息及其回复"},
+	{"/help", "显示帮助"},
+	{"/exit", "退出"},
+}
+
+// repl 交互式对话的状态
+type repl struct {
+	c         client.ClientInterface
+	out       io.Writer
+	color     bool
+	sessionID string
+	model     string
+	agent     string
+	files     []string        // /attach 的文件，随下一条消息发送
+	commands  []types.Command // 服务器的斜杠命令，首次使用时加载
+	loaded    bool
+}
+
+// handle 处理一行输入，输入 /exit 时返回 true
+func (r *repl) handle(ctx context.Context, line string) (bool, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return false, nil
+	}
+	if !strings.HasPrefix(line, "/") {
+		return false, r.prompt(ctx, line)
+	}
+
+	name, args, _ := strings.Cut(strings.TrimPrefix(line, "/"), " ")
+	args = strings.TrimSpace(args)
+	switch name {
+	case "exit", "quit":
+		return true, nil
+	case "help":
+		return false, r.help(ctx)
+	case "model":
+		if args != "" {
+			r.model = args
+		}
+		i18n.Fprintf(r.out, "模型：%s\n", orDefault(r.model))
+	case "agent":
+		if args != "" {
+			r.agent = args
+		}
+		i18n.Fprintf(r.out, "代理：%s\n", orDefault(r.agent))
+	case "attach":
+		return false, r.attach(args)
+	case "abort":
+		return false, r.abort(ctx)
+	case "diff":
+		return false, r.diff(ctx)
+	case "fork":
+		return false, r.fork(ctx)
+	case "undo":
+		return false, r.undo(ctx)
+	default:
+		return false, r.command(ctx, name, args)
+	}
+	return false, nil
+}
+
+func orDefault(s string) string {
+	if s == "" {
+		return i18n.T("默认")
+	}
+	return s
+}
+
+// prompt 发送消息并输出回复，附加的文件随消息一起发送
+func (r *repl) prompt(ctx context.Context, text string) error {
+	parts := []types.Part{{Type: "text", Text: &text}}
+	for _, file := range r.files {
+		part, err := filePart(file)
+		if err != nil {
+			return err
+		}
+		parts = append(parts, part)
+	}
+
+	req := types.MessageRequest{
+		Model: util.ConvertModel(r.model),
+		Agent: r.agent,
+		Parts: parts,
+	}
+	err := r.exchange(ctx,
+		fmt.Sprintf("/session/%s/prompt_async", r.sessionID),
+		fmt.Sprintf("/session/%s/message", r.sessionID),
+		req)
+	if err == nil {
+		r.files = nil
+	}
+	return err
+}
+
+// exchange 发送请求并输出回复
+// 事件流可用时先订阅再请求 streamPath，边生成边输出；否则同步请求 syncPath，完成后输出完整回复。
+// 等待回复时按 Ctrl+C 中止会话
+func (r *repl) exchange(ctx context.Context, streamPath, syncPath string, body interface{}) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events, _, err := event.Subscribe(ctx, r.c, event.DefaultPath)
+	if err != nil {
+		resp, err := r.c.Post(ctx, syncPath, body)
+		if err != nil {
+			return err
+		}
+		return r.printReply(resp)
+	}
+
+	sent := make(chan error, 1)
+	go func() {
+		_, err := r.c.Post(ctx, streamPath, body)
+		sent <- err
+	}()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	p := newPrinter(r.out, r.sessionID, r.color)
+	for {
+		select {
+		case err := <-sent:
+			if err != nil {
+				p.finish()
+				return err
+			}
+			sent = nil
+		case e, ok := <-events:
+			if !ok {
+				p.finish()
+				return i18n.Errorf("事件流已断开")
+			}
+			if done, err := p.handle(e); done {
+				return err
+			}
+		case <-interrupt:
+			p.finish()
+			return r.abort(context.Background())
+		}
+	}
+}
+
+// printReply 输出同步请求返回的完整回复
+func (r *repl) printReply(resp []byte) error {
+	if len(resp) == 0 {
+		return nil
+	}
+	var result types.MessageWithParts
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return i18n.Errorf("解析响应失败：%w", err)
+	}
+	for _, part := range result.Parts {
+		if part.Type == "text" && part.Text != nil {
+			fmt.Fprintln(r.out, util.FormatMarkdown(*part.Text))
+		}
+	}
+	return nil
+}
+
+// attach 记录要附加的文件，不带参数时列出已附加的文件
+func (r *repl) attach(file string) error {
+	if file == "" {
+		if len(r.files) == 0 {
+			i18n.Fprintf(r.out, "没有附加文件\n")
+		}
+		for _, f := range r.files {
+			fmt.Fprintf(r.out, "  %s\n", f)
+		}
+		return nil
+	}
+	if _, err := os.Stat(file); err != nil {
+		return i18n.Errorf("文件不存在：%s", file)
+	}
+	r.files = append(r.files, file)
+	i18n.Fprintf(r.out, "已附加 %s，将随下一条消息发送\n", file)
+	return nil
+}
+
+func (r *repl) abort(ctx context.Context) error {
+	if _, err := r.c.Post(ctx, fmt.Sprintf("/session/%s/abort", r.sessionID), nil); err != nil {
+		return err
+	}
+	i18n.Fprintf(r.out, "会话已中止\n")
+	return nil
+}
+
+// diff 以统一格式显示会话的文件变更
+func (r *repl) diff(ctx context.Context) error {
+	resp, err := r.c.Get(ctx, fmt.Sprintf("/session/%s/diff", r.sessionID))
+	if err != nil {
+		return err
+	}
+	var diffs []types.FileDiff
+	if err := json.Unmarshal(resp, &diffs); err != nil {
+		return i18n.Errorf("解析差异失败：%w", err)
+	}
+
+	files, added, deleted := 0, 0, 0
+	for _, d := range diffs {
+		patch := diff.Unified(d, diff.DefaultContext)
+		if patch == "" {
+			continue
+		}
+		a, del := diff.Stat(diff.FileHunks(d, diff.DefaultContext))
+		files++
+		added += a
+		deleted += del
+		if r.color {
+			patch = diff.Colorize(patch)
+		}
+		fmt.Fprint(r.out, patch)
+	}
+	if files == 0 {
+		i18n.Fprintf(r.out, "没有文件变更\n")
+		return nil
+	}
+	i18n.Fprintf(r.out, "\n%d 个文件变更，+%d -%d\n", files, added, deleted)
+	return nil
+}
+
+// fork 分叉当前会话，之后的消息发送到新会话
+func (r *repl) fork(ctx context.Context) error {
+	resp, err := r.c.Post(ctx, fmt.Sprintf("/session/%s/fork", r.sessionID), map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	var session types.Session
+	if err := json.Unmarshal(resp, &session); err != nil {
+		return i18n.Errorf("解析会话失败：%w", err)
+	}
+	r.sessionID = session.ID
+	i18n.Fprintf(r.out, "已切换到分叉的会话 %s\n", session.ID)
+	return nil
+}
+
+// undo 回退最后一条用户消息；已有回退时继续回退更早的一条
+func (r *repl) undo(ctx context.Context) error {
+	resp, err := r.c.Get(ctx, fmt.Sprintf("/session/%s/message", r.sessionID))
+	if err != nil {
+		return err
+	}
+	var messages []types.MessageWithParts
+	if err := json.Unmarshal(resp, &messages); err != nil {
+		return i18n.Errorf("解析消息列表失败：%w", err)
+	}
+
+	var session struct {
+		Revert *revertInfo `json:"revert"`
+	}
+	if resp, err := r.c.Get(ctx, fmt.Sprintf("/session/%s", r.sessionID)); err == nil {
+		_ = json.Unmarshal(resp, &session)
+	}
+
+	target := undoTarget(messages, session.Revert)
+	if target == "" {
+		return i18n.Errorf("没有可以回退的消息")
+	}
+	if _, err := r.c.Post(ctx, fmt.Sprintf("/session/%s/revert", r.sessionID), map[string]interface{}{"messageID": target}); err != nil {
+		return err
+	}
+	i18n.Fprintf(r.out, "已回退消息 %s 及之后的回复\n", target)
+	return nil
+}
+
+// revertInfo 会话当前的回退位置
+type revertInfo struct {
+	MessageID string `json:"messageID"`
+}
+
+// undoTarget 返回要回退的用户消息：已回退位置之前的最后一条用户消息
+func undoTarget(messages []types.MessageWithParts, revert *revertInfo) string {
+	end := len(messages)
+	if revert != nil && revert.MessageID != "" {
+		for i, msg := range messages {
+			if msg.Info.ID == revert.MessageID {
+				end = i
+				break
+			}
+		}
+	}
+	for i := end - 1; i >= 0; i-- {
+		if messages[i].Info.Role == "user" {
+			return messages[i].Info.ID
+		}
+	}
+	return ""
+}
+
+// command 执行服务器定义的斜杠命令
+func (r *repl) command(ctx context.Context, name, args string) error {
+	commands, err := r.serverCommands(ctx)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return i18n.Errorf("未知命令：/%s，输入 /help 查看可用命令", name)
+	}
+
+	req := map[string]interface{}{
+		"command":   name,
+		"arguments": args,
+	}
+	if r.agent != "" {
+		req["agent"] = r.agent
+	}
+	if model := util.ConvertModel(r.model); model != nil {
+		req["model"] = model
+	}
+	path := fmt.Sprintf("/session/%s/command", r.sessionID)
+	return r.exchange(ctx, path, path, req)
+}
+
+// serverCommands 加载服务器的斜杠命令，结果在本次对话中缓存
+func (r *repl) serverCommands(ctx context.Context) ([]types.Command, error) {
+	if r.loaded {
+		return r.commands, nil
+	}
+	resp, err := r.c.Get(ctx, "/command")
+	if err != nil {
+		return nil, err
+	}
+	var commands []types.Command
+	if err := json.Unmarshal(resp, &commands); err != nil {
+		return nil, i18n.Errorf("解析命令列表失败：%w", err)
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	r.commands, r.loaded = commands, true
+	return commands, nil
+}
+
+// help 列出内置命令和服务器命令
+func (r *repl) help(ctx context.Context) error {
+	i18n.Fprintf(r.out, "命令:\n")
+	for _, b := range builtin {
+		fmt.Fprintf(r.out, "  %-26s %s\n", b.usage, i18n.T(b.help))
+	}
+
+	commands, err := r.serverCommands(ctx)
+	if err != nil || len(commands) == 0 {
+		return nil
+	}
+	i18n.Fprintf(r.out, "\n服务器命令:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(r.out, "  %-26s %s\n", "/"+cmd.Name, cmd.Description)
+	}
+	return nil
+}
+
+// filePart 将文件编码为 base64 data URL 形式的消息部分
+func filePart(file string) (types.Part, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return types.Part{}, i18n.Errorf("读取文件失败：%s: %w", file, err)
+	}
+	mimeType := util.DetectMimeType(file)
+	return types.Part{
+		Type: "file",
+		URL:  fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data)),
+		Mime: mimeType,
+	}, nil
+}
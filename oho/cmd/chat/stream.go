@@ -0,0 +1,222 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// ANSI 颜色，用于工具调用等辅助输出
+const (
+	ansiDim   = "\x1b[2m"
+	ansiRed   = "\x1b[31m"
+	ansiReset = "\x1b[0m"
+)
+
+// messageEvent message.updated 事件字段
+type messageEvent struct {
+	Info struct {
+		ID        string `json:"id"`
+		SessionID string `json:"sessionID"`
+		Role      string `json:"role"`
+	} `json:"info"`
+}
+
+// streamPart message.part.updated 事件中的消息部分
+type streamPart struct {
+	ID        string `json:"id"`
+	SessionID string `json:"sessionID"`
+	MessageID string `json:"messageID"`
+	Type      string `json:"type"`
+	Text      string `json:"text"`
+	Tool      string `json:"tool"`
+	State     struct {
+		Status string `json:"status"`
+		Title  string `json:"title"`
+		Error  string `json:"error"`
+	} `json:"state"`
+}
+
+// statusEvent session.status 事件字段
+type statusEvent struct {
+	SessionID string              `json:"sessionID"`
+	Status    types.SessionStatus `json:"status"`
+}
+
+// errorEvent session.error 事件字段
+type errorEvent struct {
+	SessionID string `json:"sessionID"`
+	Error     struct {
+		Name string `json:"name"`
+		Data struct {
+			Message string `json:"message"`
+		} `json:"data"`
+	} `json:"error"`
+}
+
+// printer 将事件流中的助手回复逐段输出
+// 文本部分每次更新都带有完整内容，只输出尚未输出的后缀
+type printer struct {
+	w         io.Writer
+	sessionID string
+	color     bool
+
+	roles   map[string]string // 消息 ID -> 角色
+	printed map[string]int    // 文本部分 ID -> 已输出的字节数
+	tools   map[string]string // 工具部分 ID -> 已输出的状态
+	pending []streamPart      // 所属消息角色未知的部分
+	started bool              // 会话已开始处理本次请求
+	newline bool              // 最后一次输出是否以换行结束
+}
+
+func newPrinter(w io.Writer, sessionID string, color bool) *printer {
+	return &printer{
+		w:         w,
+		sessionID: sessionID,
+		color:     color,
+		roles:     map[string]string{},
+		printed:   map[string]int{},
+		tools:     map[string]string{},
+		newline:   true,
+	}
+}
+
+// handle 处理一个事件，会话回到空闲时返回 true；会话出错时返回错误
+func (p *printer) handle(e types.Event) (bool, error) {
+	if event.SessionID(e) != p.sessionID {
+		return false, nil
+	}
+
+	switch e.Type {
+	case event.TypeMessageUpdated:
+		var m messageEvent
+		if err := json.Unmarshal(e.Properties, &m); err != nil || m.Info.ID == "" {
+			return false, nil
+		}
+		p.roles[m.Info.ID] = m.Info.Role
+		if m.Info.Role == "assistant" {
+			p.started = true
+		}
+		p.flush()
+
+	case event.TypeMessagePartUpdated:
+		var m struct {
+			Part streamPart `json:"part"`
+		}
+		if err := json.Unmarshal(e.Properties, &m); err != nil || m.Part.ID == "" {
+			return false, nil
+		}
+		if _, ok := p.roles[m.Part.MessageID]; !ok {
+			p.pending = append(p.pending, m.Part)
+			return false, nil
+		}
+		p.part(m.Part)
+
+	case event.TypeSessionStatus:
+		var s statusEvent
+		if err := json.Unmarshal(e.Properties, &s); err != nil {
+			return false, nil
+		}
+		if s.Status.Working() {
+			p.started = true
+		} else if s.Status.Type == "idle" && p.started {
+			p.finish()
+			return true, nil
+		}
+
+	case event.TypeSessionIdle:
+		p.finish()
+		return true, nil
+
+	case event.TypeSessionError:
+		var s errorEvent
+		_ = json.Unmarshal(e.Properties, &s)
+		p.finish()
+		msg := s.Error.Data.Message
+		if msg == "" {
+			msg = s.Error.Name
+		}
+		if msg == "" {
+			msg = "session error"
+		}
+		return true, errors.New(msg)
+	}
+	return false, nil
+}
+
+// flush 输出角色已确定的待处理部分
+func (p *printer) flush() {
+	pending := p.pending[:0]
+	for _, part := range p.pending {
+		if _, ok := p.roles[part.MessageID]; ok {
+			p.part(part)
+		} else {
+			pending = append(pending, part)
+		}
+	}
+	p.pending = pending
+}
+
+// part 输出助手消息的文本和已结束的工具调用
+func (p *printer) part(part streamPart) {
+	if p.roles[part.MessageID] != "assistant" {
+		return
+	}
+	p.started = true
+
+	switch part.Type {
+	case "text":
+		done := p.printed[part.ID]
+		if len(part.Text) <= done {
+			return
+		}
+		p.write(part.Text[done:])
+		p.printed[part.ID] = len(part.Text)
+
+	case "tool":
+		status := part.State.Status
+		if (status != "completed" && status != "error") || p.tools[part.ID] == status {
+			return
+		}
+		p.tools[part.ID] = status
+		if !p.newline {
+			p.write("\n")
+		}
+		line := part.Tool
+		if part.State.Title != "" {
+			line += ": " + part.State.Title
+		}
+		if status == "error" {
+			p.write(p.paint(ansiRed, "✗ "+line+" ("+strings.TrimSpace(part.State.Error)+")") + "\n")
+		} else {
+			p.write(p.paint(ansiDim, "⚙ "+line) + "\n")
+		}
+	}
+}
+
+// finish 保证回复以换行结束
+func (p *printer) finish() {
+	if !p.newline {
+		p.write("\n")
+	}
+}
+
+func (p *printer) write(s string) {
+	if s == "" {
+		return
+	}
+	fmt.Fprint(p.w, s)
+	p.newline = strings.HasSuffix(s, "\n")
+}
+
+func (p *printer) paint(code, s string) string {
+	if !p.color {
+		return s
+	}
+	return code + s + ansiReset
+}
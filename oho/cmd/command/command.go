@@ -1,15 +1,16 @@
 package command
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
-	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
+ "github.com/anomalyco/oho/internal/schema"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 // Cmd 命令管理
@@ -22,9 +23,10 @@ var Cmd = &cobra.Command{
 var listCmd = &cobra.Command{
  Use:   "list",
  Short: "列出所有命令",
+ Annotations: map[string]string{"mcp": "readonly"},
  RunE: func(cmd *cobra.Command, args []string) error {
   c := client.NewClient()
-  ctx := context.Background()
+  ctx := cmd.Context()
 
   resp, err := c.Get(ctx, "/command")
   if err != nil {
@@ -36,23 +38,21 @@ var listCmd = &cobra.Command{
    return err
   }
 
-  if config.Get().JSON {
-   data, _ := json.MarshalIndent(commands, "", "  ")
-   fmt.Println(string(data))
-   return nil
+  if ok, err := util.Render(ctx, commands); ok || err != nil {
+   return err
   }
 
   if len(commands) == 0 {
-   fmt.Println("没有可用命令")
+   fmt.Println(i18n.T("没有可用命令"))
    return nil
   }
 
-  fmt.Printf("共 %d 个命令:\n\n", len(commands))
+  i18n.Printf("共 %d 个命令:\n\n", len(commands))
   for _, c := range commands {
    fmt.Printf("/%s\n", c.Name)
-   fmt.Printf("   描述：%s\n", c.Description)
+   i18n.Printf("   描述：%s\n", c.Description)
    if c.Usage != "" {
-    fmt.Printf("   用法：%s\n", c.Usage)
+    i18n.Printf("   用法：%s\n", c.Usage)
    }
    fmt.Println()
   }
@@ -63,4 +63,7 @@ var listCmd = &cobra.Command{
 
 func init() {
  Cmd.AddCommand(listCmd)
+
+ // JSON 输出 Schema
+ schema.Register("command list", []types.Command{})
 }
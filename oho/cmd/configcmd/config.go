@@ -1,7 +1,6 @@
 package configcmd
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -9,8 +8,10 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
-	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 // Cmd 配置命令
@@ -22,11 +23,12 @@ var Cmd = &cobra.Command{
 
 var (
 	getCmd = &cobra.Command{
-		Use:   "get",
-		Short: "获取配置",
+		Use:         "get",
+		Short:       "获取配置",
+		Annotations: map[string]string{"mcp": "readonly"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			resp, err := c.Get(ctx, "/config")
 			if err != nil {
@@ -35,23 +37,21 @@ var (
 
 			var cfg types.Config
 			if err := json.Unmarshal(resp, &cfg); err != nil {
-				return fmt.Errorf("解析配置失败：%w", err)
+				return i18n.Errorf("解析配置失败：%w", err)
 			}
 
-			if config.Get().JSON {
-				data, _ := json.MarshalIndent(cfg, "", "  ")
-				fmt.Println(string(data))
-				return nil
+			if ok, err := util.Render(ctx, cfg); ok || err != nil {
+				return err
 			}
 
-			fmt.Println("当前配置:")
-			fmt.Printf("  默认模型：%s\n", cfg.DefaultModel)
-			fmt.Printf("  主题：%s\n", cfg.Theme)
-			fmt.Printf("  语言：%s\n", cfg.Language)
-			fmt.Printf("  最大 Token：%d\n", cfg.MaxTokens)
-			fmt.Printf("  温度：%.2f\n", cfg.Temperature)
+			fmt.Println(i18n.T("当前配置:"))
+			i18n.Printf("  默认模型：%s\n", cfg.DefaultModel)
+			i18n.Printf("  主题：%s\n", cfg.Theme)
+			i18n.Printf("  语言：%s\n", cfg.Language)
+			i18n.Printf("  最大 Token：%d\n", cfg.MaxTokens)
+			i18n.Printf("  温度：%.2f\n", cfg.Temperature)
 			if len(cfg.AutoApprove) > 0 {
-				fmt.Printf("  自动批准：%s\n", strings.Join(cfg.AutoApprove, ", "))
+				i18n.Printf("  自动批准：%s\n", strings.Join(cfg.AutoApprove, ", "))
 			}
 			return nil
 		},
@@ -79,7 +79,7 @@ var (
   export OPENCODE_MODEL="provider/model-id"`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			// 构建更新请求
 			updates := make(map[string]interface{})
@@ -104,7 +104,7 @@ var (
 			}
 
 			if len(updates) == 0 {
-				return fmt.Errorf("请提供至少一个要更新的配置项")
+				return i18n.Errorf("请提供至少一个要更新的配置项")
 			}
 
 			resp, err := c.Patch(ctx, "/config", updates)
@@ -114,20 +114,24 @@ var (
 
 			var cfg types.Config
 			if err := json.Unmarshal(resp, &cfg); err != nil {
-				return fmt.Errorf("解析响应失败：%w", err)
+				return i18n.Errorf("解析响应失败：%w", err)
 			}
 
-			fmt.Println("配置已更新")
+			if ok, err := util.Render(ctx, cfg); ok || err != nil {
+				return err
+			}
+			fmt.Println(i18n.T("配置已更新"))
 			return nil
 		},
 	}
 
 	providersCmd = &cobra.Command{
-		Use:   "providers",
-		Short: "列出提供商和默认模型",
+		Use:         "providers",
+		Short:       "列出提供商和默认模型",
+		Annotations: map[string]string{"mcp": "readonly"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			resp, err := c.Get(ctx, "/config/providers")
 			if err != nil {
@@ -171,27 +175,25 @@ var (
 				} else {
 					// 尝试直接解析为 providers 数组
 					if err := json.Unmarshal(resp, &providers); err != nil {
-						return fmt.Errorf("解析提供商列表失败：%w", err)
+						return i18n.Errorf("解析提供商列表失败：%w", err)
 					}
 				}
 			}
 
-			if config.Get().JSON {
-				data, _ := json.MarshalIndent(map[string]interface{}{
-					"providers": providers,
-					"default":   defaultMap,
-				}, "", "  ")
-				fmt.Println(string(data))
-				return nil
+			if ok, err := util.Render(ctx, map[string]interface{}{
+				"providers": providers,
+				"default":   defaultMap,
+			}); ok || err != nil {
+				return err
 			}
 
-			fmt.Println("可用提供商:")
+			fmt.Println(i18n.T("可用提供商:"))
 			for _, p := range providers {
 				fmt.Printf("  - %s (%s)\n", p.Name, p.ID)
 			}
 
 			if len(defaultMap) > 0 {
-				fmt.Println("\n默认模型:")
+				fmt.Println(i18n.T("\n默认模型:"))
 				for provider, model := range defaultMap {
 					fmt.Printf("  %s: %s\n", provider, model)
 				}
@@ -220,4 +222,12 @@ func init() {
 	setCmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "最大 Token 数")
 	setCmd.Flags().Float64Var(&temperature, "temperature", 0, "温度参数")
 	setCmd.Flags().StringSliceVar(&autoApprove, "auto-approve", nil, "自动批准的工具列表（注意：可能不被服务器支持）")
+
+	// JSON 输出 Schema
+	schema.Register("config get", types.Config{})
+	schema.Register("config set", types.Config{})
+	schema.Register("config providers", struct {
+		Providers []types.Provider  `json:"providers"`
+		Default   map[string]string `json:"default"`
+	}{})
 }
@@ -1,15 +1,16 @@
 package file
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
-	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 // Cmd 文件命令
@@ -20,12 +21,13 @@ var Cmd = &cobra.Command{
 }
 
 var listCmd = &cobra.Command{
-	Use:   "list [path]",
-	Short: "列出文件和目录",
-	Args:  cobra.MaximumNArgs(1),
+	Use:         "list [path]",
+	Short:       "列出文件和目录",
+	Annotations: map[string]string{"mcp": "readonly"},
+	Args:        cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		filePath := ""
 		if len(args) > 0 {
@@ -47,14 +49,12 @@ var listCmd = &cobra.Command{
 			return err
 		}
 
-		if config.Get().JSON {
-			data, _ := json.MarshalIndent(nodes, "", "  ")
-			fmt.Println(string(data))
-			return nil
+		if ok, err := util.Render(ctx, nodes); ok || err != nil {
+			return err
 		}
 
 		if len(nodes) == 0 {
-			fmt.Println("空目录")
+			fmt.Println(i18n.T("空目录"))
 			return nil
 		}
 
@@ -71,12 +71,13 @@ var listCmd = &cobra.Command{
 }
 
 var contentCmd = &cobra.Command{
-	Use:   "content <path>",
-	Short: "读取文件内容",
-	Args:  cobra.ExactArgs(1),
+	Use:         "content <path>",
+	Short:       "读取文件内容",
+	Annotations: map[string]string{"mcp": "readonly"},
+	Args:        cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		queryParams := map[string]string{
 			"path": args[0],
@@ -92,14 +93,12 @@ var contentCmd = &cobra.Command{
 			return err
 		}
 
-		if config.Get().JSON {
-			data, _ := json.MarshalIndent(content, "", "  ")
-			fmt.Println(string(data))
-			return nil
+		if ok, err := util.Render(ctx, content); ok || err != nil {
+			return err
 		}
 
-		fmt.Printf("文件：%s\n", content.Path)
-		fmt.Printf("编码：%s\n\n", content.Encoding)
+		i18n.Printf("文件：%s\n", content.Path)
+		i18n.Printf("编码：%s\n\n", content.Encoding)
 		fmt.Println(content.Content)
 
 		return nil
@@ -107,11 +106,12 @@ var contentCmd = &cobra.Command{
 }
 
 var statusCmd = &cobra.Command{
-	Use:   "status",
-	Short: "获取已跟踪文件的状态",
+	Use:         "status",
+	Short:       "获取已跟踪文件的状态",
+	Annotations: map[string]string{"mcp": "readonly"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		resp, err := c.Get(ctx, "/file/status")
 		if err != nil {
@@ -123,18 +123,16 @@ var statusCmd = &cobra.Command{
 			return err
 		}
 
-		if config.Get().JSON {
-			data, _ := json.MarshalIndent(files, "", "  ")
-			fmt.Println(string(data))
-			return nil
+		if ok, err := util.Render(ctx, files); ok || err != nil {
+			return err
 		}
 
 		if len(files) == 0 {
-			fmt.Println("没有已跟踪的文件")
+			fmt.Println(i18n.T("没有已跟踪的文件"))
 			return nil
 		}
 
-		fmt.Printf("共 %d 个已跟踪文件:\n\n", len(files))
+		i18n.Printf("共 %d 个已跟踪文件:\n\n", len(files))
 		for _, f := range files {
 			fmt.Printf("- %s\n", f.Path)
 		}
@@ -147,4 +145,9 @@ func init() {
 	Cmd.AddCommand(listCmd)
 	Cmd.AddCommand(contentCmd)
 	Cmd.AddCommand(statusCmd)
+
+	// JSON 输出 Schema
+	schema.Register("file list", []types.FileNode{})
+	schema.Register("file content", types.FileContent{})
+	schema.Register("file status", []types.File{})
 }
@@ -1,15 +1,16 @@
 package find
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
-	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
+ "github.com/anomalyco/oho/internal/schema"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 // Cmd 查找命令
@@ -23,10 +24,11 @@ var (
  textCmd = &cobra.Command{
   Use:   "text <pattern>",
   Short: "在文件中搜索文本",
+  Annotations: map[string]string{"mcp": "readonly"},
   Args:  cobra.ExactArgs(1),
   RunE: func(cmd *cobra.Command, args []string) error {
    c := client.NewClient()
-   ctx := context.Background()
+   ctx := cmd.Context()
 
    queryParams := map[string]string{
     "pattern": args[0],
@@ -42,24 +44,22 @@ var (
     return err
    }
 
-   if config.Get().JSON {
-    data, _ := json.MarshalIndent(matches, "", "  ")
-    fmt.Println(string(data))
-    return nil
+   if ok, err := util.Render(ctx, matches); ok || err != nil {
+    return err
    }
 
    if len(matches) == 0 {
-    fmt.Println("未找到匹配")
+    fmt.Println(i18n.T("未找到匹配"))
     return nil
    }
 
-   fmt.Printf("找到 %d 个匹配:\n\n", len(matches))
+   i18n.Printf("找到 %d 个匹配:\n\n", len(matches))
    for _, m := range matches {
-    fmt.Printf("📄 %s (行 %d)\n", m.Path, m.LineNumber)
+    i18n.Printf("📄 %s (行 %d)\n", m.Path, m.LineNumber)
     fmt.Printf("   %s\n", m.Lines)
     if len(m.Submatches) > 0 {
      for _, s := range m.Submatches {
-      fmt.Printf("   └─ 匹配位置：%d-%d\n", s.Start, s.End)
+      i18n.Printf("   └─ 匹配位置：%d-%d\n", s.Start, s.End)
      }
     }
     fmt.Println()
@@ -72,10 +72,11 @@ var (
  fileCmd = &cobra.Command{
   Use:   "file <query>",
   Short: "按名称查找文件",
+  Annotations: map[string]string{"mcp": "readonly"},
   Args:  cobra.ExactArgs(1),
   RunE: func(cmd *cobra.Command, args []string) error {
    c := client.NewClient()
-   ctx := context.Background()
+   ctx := cmd.Context()
 
    queryParams := map[string]string{
     "query": args[0],
@@ -101,18 +102,16 @@ var (
     return err
    }
 
-   if config.Get().JSON {
-    data, _ := json.MarshalIndent(paths, "", "  ")
-    fmt.Println(string(data))
-    return nil
+   if ok, err := util.Render(ctx, paths); ok || err != nil {
+    return err
    }
 
    if len(paths) == 0 {
-    fmt.Println("未找到文件")
+    fmt.Println(i18n.T("未找到文件"))
     return nil
    }
 
-   fmt.Printf("找到 %d 个文件:\n\n", len(paths))
+   i18n.Printf("找到 %d 个文件:\n\n", len(paths))
    for _, p := range paths {
     fmt.Printf("📄 %s\n", p)
    }
@@ -124,10 +123,11 @@ var (
  symbolCmd = &cobra.Command{
   Use:   "symbol <query>",
   Short: "查找工作区符号",
+  Annotations: map[string]string{"mcp": "readonly"},
   Args:  cobra.ExactArgs(1),
   RunE: func(cmd *cobra.Command, args []string) error {
    c := client.NewClient()
-   ctx := context.Background()
+   ctx := cmd.Context()
 
    queryParams := map[string]string{
     "query": args[0],
@@ -143,18 +143,16 @@ var (
     return err
    }
 
-   if config.Get().JSON {
-    data, _ := json.MarshalIndent(symbols, "", "  ")
-    fmt.Println(string(data))
-    return nil
+   if ok, err := util.Render(ctx, symbols); ok || err != nil {
+    return err
    }
 
    if len(symbols) == 0 {
-    fmt.Println("未找到符号")
+    fmt.Println(i18n.T("未找到符号"))
     return nil
    }
 
-   fmt.Printf("找到 %d 个符号:\n\n", len(symbols))
+   i18n.Printf("找到 %d 个符号:\n\n", len(symbols))
    for _, s := range symbols {
     icon := "🔖"
     switch s.Kind {
@@ -168,9 +166,9 @@ var (
      icon = "🔤"
     }
     fmt.Printf("%s %s (%s)\n", icon, s.Name, s.Kind)
-    fmt.Printf("   位置：%s:%d:%d\n", s.Path, s.Line, s.Column)
+    i18n.Printf("   位置：%s:%d:%d\n", s.Path, s.Line, s.Column)
     if s.Container != "" {
-     fmt.Printf("   容器：%s\n", s.Container)
+     i18n.Printf("   容器：%s\n", s.Container)
     }
    }
 
@@ -187,4 +185,9 @@ func init() {
  fileCmd.Flags().String("type", "", "文件类型限制 (file/directory)")
  fileCmd.Flags().String("directory", "", "搜索目录")
  fileCmd.Flags().Int("limit", 100, "最大结果数")
+
+ // JSON 输出 Schema
+ schema.Register("find text", []types.FindMatch{})
+ schema.Register("find file", []string{})
+ schema.Register("find symbol", []types.Symbol{})
 }
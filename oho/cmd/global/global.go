@@ -1,15 +1,16 @@
 package global
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
-	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 // Cmd 全局命令
@@ -20,11 +21,12 @@ var Cmd = &cobra.Command{
 }
 
 var healthCmd = &cobra.Command{
-	Use:   "health",
-	Short: "检查服务器健康状态",
+	Use:         "health",
+	Short:       "检查服务器健康状态",
+	Annotations: map[string]string{"mcp": "readonly"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		resp, err := c.Get(ctx, "/global/health")
 		if err != nil {
@@ -36,18 +38,16 @@ var healthCmd = &cobra.Command{
 			return err
 		}
 
-		if config.Get().JSON {
-			data, _ := json.MarshalIndent(health, "", "  ")
-			fmt.Println(string(data))
-			return nil
+		if ok, err := util.Render(ctx, health); ok || err != nil {
+			return err
 		}
 
-		status := "❌ 不健康"
+		status := "❌ " + i18n.T("不健康")
 		if health.Healthy {
-			status = "✅ 健康"
+			status = "✅ " + i18n.T("健康")
 		}
-		fmt.Printf("服务器状态：%s\n", status)
-		fmt.Printf("版本：%s\n", health.Version)
+		i18n.Printf("服务器状态：%s\n", status)
+		i18n.Printf("版本：%s\n", health.Version)
 		return nil
 	},
 }
@@ -55,16 +55,18 @@ var healthCmd = &cobra.Command{
 var eventCmd = &cobra.Command{
 	Use:   "event",
 	Short: "监听全局事件流 (SSE)",
+	// 长时间运行的命令，不暴露为 MCP 工具
+	Annotations: map[string]string{"mcp": "skip"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		eventChan, errChan, err := c.SSEStream(ctx, "/global/event")
 		if err != nil {
 			return err
 		}
 
-		fmt.Println("正在监听全局事件... (Ctrl+C 停止)")
+		fmt.Println(i18n.T("正在监听全局事件... (Ctrl+C 停止)"))
 
 		for {
 			select {
@@ -87,4 +89,7 @@ var eventCmd = &cobra.Command{
 func init() {
 	Cmd.AddCommand(healthCmd)
 	Cmd.AddCommand(eventCmd)
+
+	// JSON 输出 Schema
+	schema.Register("global health", types.HealthResponse{})
 }
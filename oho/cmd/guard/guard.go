@@ -0,0 +1,145 @@
+package guard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/cache"
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/guard"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// checkInterval 检查运行时长的间隔
+const checkInterval = time.Second
+
+var (
+	limits     guard.Limits
+	dryRun     bool
+	retryDelay time.Duration
+)
+
+func init() {
+	limits.AddFlags(Cmd.Flags())
+	Cmd.Flags().BoolVar(&dryRun, "dry-run", false, "只报告超出上限的会话，不中止")
+	Cmd.Flags().DurationVar(&retryDelay, "retry", 3*time.Second, "事件流断开后的重连间隔")
+
+	schema.Register("guard", types.GuardAction{})
+}
+
+// Cmd 预算守护命令
+var Cmd = &cobra.Command{
+	Use:   "guard",
+	Short: "对服务器上的所有会话执行预算上限",
+	Long: `订阅事件流，对服务器上所有会话的每次运行执行预算上限，超出时中止会话及其子会话。
+
+一次运行从会话开始工作到空闲为止，子代理创建的子会话的用量计入根会话。
+费用和 token 数来自每个步骤结束（step-finish）时的用量，token 数包括缓存读写。
+启动时已在运行的会话从启动时开始计算。
+
+每次中止输出一行记录，-o json 或 -o jsonl 时输出 JSON。
+
+示例:
+  oho guard --max-cost 5 --max-duration 1h
+  oho guard --max-tokens 2000000 --dry-run -o jsonl >> guard.jsonl`,
+	// 长时间运行的命令，不暴露为 MCP 工具
+	Annotations: map[string]string{"mcp": "skip"},
+	Args:        cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if limits.Empty() {
+			return i18n.Errorf("请至少指定 --max-cost、--max-tokens 或 --max-duration 中的一个")
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		c := client.NewClient()
+		g := guard.New(limits, true)
+		if err := startRuns(ctx, c, g, time.Now()); err != nil {
+			return err
+		}
+		i18n.Fprintf(os.Stderr, "预算守护已启动（%d 个会话正在运行）\n", len(g.Runs()))
+		return run(ctx, c, g)
+	},
+}
+
+// startRuns 记录会话的父子关系，并开始跟踪已在运行的会话
+func startRuns(ctx context.Context, c client.ClientInterface, g *guard.Guard, now time.Time) error {
+	sessions, err := cache.Fetch(ctx, c)
+	if err != nil {
+		return err
+	}
+	g.AddSessions(sessions)
+
+	resp, err := c.Get(ctx, "/session/status")
+	if err != nil {
+		return err
+	}
+	var statuses map[string]types.SessionStatus
+	if err := json.Unmarshal(resp, &statuses); err != nil {
+		return i18n.Errorf("解析会话状态失败：%w", err)
+	}
+	for id, status := range statuses {
+		if status.Working() || status.Type == "busy" || status.Type == "retry" {
+			g.Start(id, now)
+		}
+	}
+	return nil
+}
+
+// run 监听事件流并定期检查运行时长，直到 ctx 取消
+func run(ctx context.Context, c client.ClientInterface, g *guard.Guard) error {
+	events := event.Watch(ctx, c, event.DefaultPath, retryDelay, func(err error) {
+		i18n.Fprintf(os.Stderr, "事件流断开：%v，%s 后重连\n", err, retryDelay)
+	})
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		var exceeded []*guard.Run
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if r := g.Handle(e, time.Now()); r != nil {
+				exceeded = append(exceeded, r)
+			}
+		case now := <-ticker.C:
+			exceeded = g.Check(now)
+		}
+		for _, r := range exceeded {
+			if err := report(ctx, g.Abort(ctx, c, r, dryRun, time.Now())); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// report 输出一条中止记录
+func report(ctx context.Context, action types.GuardAction) error {
+	if ok, err := util.Render(ctx, action); ok || err != nil {
+		return err
+	}
+	at := time.UnixMilli(action.Time).Format("15:04:05")
+	switch {
+	case action.DryRun:
+		fmt.Printf("%s (dry-run) %s: %s\n", at, action.SessionID, action.Reason)
+	case action.Error != "":
+		i18n.Printf("%s ✗ %s：%s（%s）\n", at, action.SessionID, action.Reason, action.Error)
+	default:
+		i18n.Printf("%s ✓ 已中止 %s：%s\n", at, action.SessionID, action.Reason)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package guard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/guard"
+)
+
+func TestStartRuns(t *testing.T) {
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			switch path {
+			case "/session":
+				return []byte(`[{"id":"ses_a"},{"id":"ses_b","parentID":"ses_a"},{"id":"ses_c"},{"id":"ses_d"}]`), nil
+			case "/session/status":
+				return []byte(`{"ses_b":{"type":"busy"},"ses_c":{"type":"idle"},"ses_d":{"isWorking":true}}`), nil
+			}
+			t.Errorf("Unexpected path: %s", path)
+			return nil, nil
+		},
+	}
+	g := guard.New(guard.Limits{MaxCost: 1}, true)
+	if err := startRuns(context.Background(), mock, g, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	// 运行中的子会话按根会话跟踪
+	runs := g.Runs()
+	if len(runs) != 2 || runs[0].SessionID != "ses_a" || runs[1].SessionID != "ses_d" {
+		t.Errorf("runs = %+v", runs)
+	}
+}
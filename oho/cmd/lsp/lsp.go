@@ -1,15 +1,16 @@
 package lsp
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
-	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 // Cmd LSP 命令
@@ -20,11 +21,12 @@ var Cmd = &cobra.Command{
 }
 
 var statusCmd = &cobra.Command{
-	Use:   "status",
-	Short: "获取 LSP 服务器状态",
+	Use:         "status",
+	Short:       "获取 LSP 服务器状态",
+	Annotations: map[string]string{"mcp": "readonly"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		resp, err := c.Get(ctx, "/lsp")
 		if err != nil {
@@ -36,24 +38,22 @@ var statusCmd = &cobra.Command{
 			return err
 		}
 
-		if config.Get().JSON {
-			data, _ := json.MarshalIndent(status, "", "  ")
-			fmt.Println(string(data))
-			return nil
+		if ok, err := util.Render(ctx, status); ok || err != nil {
+			return err
 		}
 
 		if len(status) == 0 {
-			fmt.Println("没有 LSP 服务器")
+			fmt.Println(i18n.T("没有 LSP 服务器"))
 			return nil
 		}
 
-		fmt.Println("LSP 服务器状态:")
+		fmt.Println(i18n.T("LSP 服务器状态:"))
 		for _, s := range status {
 			icon := "❌"
 			if s.Status == "running" {
 				icon = "✅"
 			}
-			fmt.Printf("%s %s (端口：%d, 状态：%s)\n", icon, s.Name, s.Port, s.Status)
+			i18n.Printf("%s %s (端口：%d, 状态：%s)\n", icon, s.Name, s.Port, s.Status)
 		}
 
 		return nil
@@ -62,4 +62,7 @@ var statusCmd = &cobra.Command{
 
 func init() {
 	Cmd.AddCommand(statusCmd)
+
+	// JSON 输出 Schema
+	schema.Register("lsp status", []types.LSPStatus{})
 }
@@ -1,30 +1,44 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/cmd/add"
 	"github.com/anomalyco/oho/cmd/agent"
 	"github.com/anomalyco/oho/cmd/auth"
+	"github.com/anomalyco/oho/cmd/chat"
 	"github.com/anomalyco/oho/cmd/command"
 	"github.com/anomalyco/oho/cmd/configcmd"
 	"github.com/anomalyco/oho/cmd/file"
 	"github.com/anomalyco/oho/cmd/find"
 	"github.com/anomalyco/oho/cmd/formatter"
 	"github.com/anomalyco/oho/cmd/global"
+	"github.com/anomalyco/oho/cmd/guard"
 	"github.com/anomalyco/oho/cmd/lsp"
 	"github.com/anomalyco/oho/cmd/mcp"
 	"github.com/anomalyco/oho/cmd/mcpserver"
 	"github.com/anomalyco/oho/cmd/message"
+	"github.com/anomalyco/oho/cmd/notify"
+	"github.com/anomalyco/oho/cmd/permissions"
 	"github.com/anomalyco/oho/cmd/project"
 	"github.com/anomalyco/oho/cmd/provider"
+	"github.com/anomalyco/oho/cmd/schema"
 	"github.com/anomalyco/oho/cmd/session"
 	"github.com/anomalyco/oho/cmd/tool"
 	"github.com/anomalyco/oho/cmd/tui"
+	"github.com/anomalyco/oho/cmd/usage"
+	"github.com/anomalyco/oho/cmd/watchdog"
+	"github.com/anomalyco/oho/cmd/worktree"
+	"github.com/anomalyco/oho/internal/cache"
+	"github.com/anomalyco/oho/internal/client"
 	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 var (
@@ -51,6 +65,12 @@ var rootCmd = &cobra.Command{
   oho session list                # 列出所有会话
   oho config get                  # 获取配置
   oho provider list               # 列出所有提供商`,
+	// 标志在执行前才完成解析，因此在这里绑定配置
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		config.BindFlags(cmd.Root().PersistentFlags())
+		_, _, err := util.ParseOutputFormat(config.Get().Output)
+		return err
+	},
 }
 
 // Execute 执行根命令
@@ -68,26 +88,22 @@ func SetVersionInfo(ver, commit, date string) {
 	rootCmd.Version = fmt.Sprintf("%s (commit: %s, built: %s)", versionStr, commitStr, dateStr)
 }
 
-func main() {
-	SetVersionInfo(Version, Commit, Date)
-
-	// 初始化配置
-	if err := config.Init(); err != nil {
-		fmt.Fprintf(os.Stderr, "警告：配置初始化失败：%v\n", err)
-	}
+func init() {
+	// 子命令组的 PersistentPreRunE（如解析 -s 会话引用）在根命令之后执行，而不是替代根命令
+	cobra.EnableTraverseRunHooks = true
 
 	// 全局标志
 	rootCmd.PersistentFlags().StringP("host", "", "127.0.0.1", "服务器主机地址")
 	rootCmd.PersistentFlags().IntP("port", "p", 4096, "服务器端口")
 	rootCmd.PersistentFlags().StringP("password", "", "", "服务器密码 (覆盖环境变量)")
 	rootCmd.PersistentFlags().BoolP("json", "j", false, "以 JSON 格式输出")
-
-	// 绑定配置
-	config.BindFlags(rootCmd.PersistentFlags())
+	rootCmd.PersistentFlags().StringP("output", "o", "", "输出格式 ("+util.OutputFormats+")")
+	rootCmd.PersistentFlags().String("lang", "", "界面语言 ("+i18n.Languages+"，默认根据 LANG 环境变量)")
 
 	// 添加子命令
 	rootCmd.AddCommand(
 		add.Cmd,
+		chat.Cmd,
 		global.Cmd,
 		project.Cmd,
 		session.Cmd,
@@ -105,10 +121,55 @@ func main() {
 		mcpserver.Cmd,
 		tui.Cmd,
 		auth.Cmd,
+		permissions.Cmd,
+		schema.Cmd,
+		worktree.Cmd,
+		usage.Cmd,
+		guard.Cmd,
+		watchdog.Cmd,
+		notify.Cmd,
 	)
+}
+
+// langFlag 在解析标志前取出 --lang 的值，帮助信息和配置加载的提示也需要按语言输出
+func langFlag(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if v, ok := strings.CutPrefix(arg, "--lang="); ok {
+			return v
+		}
+		if arg == "--lang" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func main() {
+	SetVersionInfo(Version, Commit, Date)
+
+	// 先根据 --lang 和环境变量确定语言，读取配置文件后再确定一次
+	lang := langFlag(os.Args[1:])
+	i18n.SetLanguage(i18n.Detect(lang, ""))
+
+	// 初始化配置
+	if err := config.Init(); err != nil {
+		i18n.Fprintf(os.Stderr, "警告：配置初始化失败：%v\n", err)
+	}
+	i18n.SetLanguage(i18n.Detect(lang, config.Get().Lang))
+	i18n.Localize(rootCmd)
+
+	// 任何写请求之后本地会话索引失效，不论命令是否成功
+	client.OnWrite(cache.Expire)
 
+	// 错误由这里统一输出，JSON 模式下输出错误信封
+	rootCmd.SilenceErrors = true
 	if err := Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if !util.RenderError(context.Background(), err) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
 		os.Exit(1)
 	}
 }
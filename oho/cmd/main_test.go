@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/anomalyco/oho/internal/i18n"
+)
+
+func TestLangFlag(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"session", "list"}, want: ""},
+		{args: []string{"--lang", "en", "session", "list"}, want: "en"},
+		{args: []string{"session", "list", "--lang=zh"}, want: "zh"},
+		{args: []string{"message", "add", "--", "--lang=en"}, want: ""},
+		{args: []string{"--lang"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := langFlag(tt.args); got != tt.want {
+			t.Errorf("langFlag(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+// TestHelpTranslated 切换到英文后，帮助文本和标志说明中不应再有中文
+func TestHelpTranslated(t *testing.T) {
+	defer i18n.SetLanguage(i18n.Language())
+	i18n.SetLanguage(i18n.English)
+	i18n.Localize(rootCmd)
+
+	check := func(cmd *cobra.Command, field, text string) {
+		for _, r := range text {
+			if unicode.Is(unicode.Han, r) {
+				t.Errorf("%s: untranslated %s %q", cmd.CommandPath(), field, text)
+				return
+			}
+		}
+	}
+
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		check(cmd, "Short", cmd.Short)
+		check(cmd, "Long", cmd.Long)
+		check(cmd, "Example", cmd.Example)
+		visit := func(f *pflag.Flag) {
+			check(cmd, "flag --"+f.Name, f.Usage)
+		}
+		cmd.Flags().VisitAll(visit)
+		cmd.PersistentFlags().VisitAll(visit)
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(rootCmd)
+}
@@ -1,15 +1,16 @@
 package mcp
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
-	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 // Cmd MCP 命令
@@ -23,11 +24,12 @@ var (
 	mcpConfig string
 
 	listCmd = &cobra.Command{
-		Use:   "list",
-		Short: "列出 MCP 服务器状态",
+		Use:         "list",
+		Short:       "列出 MCP 服务器状态",
+		Annotations: map[string]string{"mcp": "readonly"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			resp, err := c.Get(ctx, "/mcp")
 			if err != nil {
@@ -39,26 +41,24 @@ var (
 				return err
 			}
 
-			if config.Get().JSON {
-				data, _ := json.MarshalIndent(status, "", "  ")
-				fmt.Println(string(data))
-				return nil
+			if ok, err := util.Render(ctx, status); ok || err != nil {
+				return err
 			}
 
 			if len(status) == 0 {
-				fmt.Println("没有 MCP 服务器")
+				fmt.Println(i18n.T("没有 MCP 服务器"))
 				return nil
 			}
 
-			fmt.Println("MCP 服务器状态:")
+			fmt.Println(i18n.T("MCP 服务器状态:"))
 			for name, s := range status {
 				icon := "❌"
 				if s.Status == "running" {
 					icon = "✅"
 				}
-				fmt.Printf("%s %s (状态：%s)\n", icon, name, s.Status)
+				i18n.Printf("%s %s (状态：%s)\n", icon, name, s.Status)
 				if s.Error != "" {
-					fmt.Printf("   错误：%s\n", s.Error)
+					i18n.Printf("   错误：%s\n", s.Error)
 				}
 			}
 
@@ -72,16 +72,16 @@ var (
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if mcpConfig == "" {
-				return fmt.Errorf("请提供 --config 参数 (JSON 格式)")
+				return i18n.Errorf("请提供 --config 参数 (JSON 格式)")
 			}
 
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			// 解析配置
 			var configData map[string]interface{}
 			if err := json.Unmarshal([]byte(mcpConfig), &configData); err != nil {
-				return fmt.Errorf("解析配置失败：%w", err)
+				return i18n.Errorf("解析配置失败：%w", err)
 			}
 
 			req := types.MCPConfig{
@@ -99,7 +99,10 @@ var (
 				return err
 			}
 
-			fmt.Printf("MCP 服务器 %s 已添加\n", args[0])
+			if ok, err := util.Render(ctx, status); ok || err != nil {
+				return err
+			}
+			i18n.Printf("MCP 服务器 %s 已添加\n", args[0])
 			return nil
 		},
 	}
@@ -111,4 +114,8 @@ func init() {
 
 	addCmd.Flags().StringVar(&mcpConfig, "config", "", "MCP 服务器配置 (JSON 格式)")
 	_ = addCmd.MarkFlagRequired("config")
+
+	// JSON 输出 Schema
+	schema.Register("mcp list", map[string]types.MCPStatus{})
+	schema.Register("mcp add", map[string]types.MCPStatus{})
 }
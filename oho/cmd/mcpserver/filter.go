@@ -0,0 +1,91 @@
+package mcpserver
+
+import (
+	"path"
+
+	"github.com/anomalyco/oho/internal/i18n"
+)
+
+var (
+	allowTools   []string
+	denyTools    []string
+	readOnlyMode bool
+)
+
+func init() {
+	Cmd.Flags().StringSliceVar(&allowTools, "allow", nil, "只暴露匹配的工具（支持通配符，如 session_*，可多次使用）")
+	Cmd.Flags().StringSliceVar(&denyTools, "deny", nil, "隐藏匹配的工具（优先于 --allow，可多次使用）")
+	Cmd.Flags().BoolVar(&readOnlyMode, "read-only", false, "只暴露只读工具")
+}
+
+// toolFilter 决定哪些工具对 MCP 客户端可见、可调用
+type toolFilter struct {
+	allow    []string
+	deny     []string
+	readOnly bool
+}
+
+// activeFilter 根据命令行标志构建过滤器
+func activeFilter() toolFilter {
+	return toolFilter{allow: allowTools, deny: denyTools, readOnly: readOnlyMode}
+}
+
+// validate 检查通配符是否合法
+func (f toolFilter) validate() error {
+	for _, pattern := range append(append([]string{}, f.allow...), f.deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return i18n.Errorf("无效的工具匹配模式：%s", pattern)
+		}
+	}
+	return nil
+}
+
+// permits 判断工具是否可用：deny 优先，其次 allow（为空时允许全部），最后是只读模式
+func (f toolFilter) permits(t Tool) bool {
+	if matchAny(f.deny, t.Name) {
+		return false
+	}
+	if len(f.allow) > 0 && !matchAny(f.allow, t.Name) {
+		return false
+	}
+	if f.readOnly && (t.Annotations == nil || !t.Annotations.ReadOnlyHint) {
+		return false
+	}
+	return true
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filterTools 返回过滤后的工具列表
+func filterTools(tools []Tool, f toolFilter) []Tool {
+	var result []Tool
+	for _, t := range tools {
+		if f.permits(t) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// availableTools 返回当前过滤器下可用的工具
+func availableTools() []Tool {
+	return filterTools(allTools(), activeFilter())
+}
+
+// isToolAllowed 判断工具是否可调用；未知工具交给 handleToolCall 报错
+func isToolAllowed(name string) bool {
+	f := activeFilter()
+	for _, t := range allTools() {
+		if t.Name == name {
+			return f.permits(t)
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package mcpserver
+
+import (
+	"testing"
+)
+
+func toolNames(tools []Tool) map[string]bool {
+	names := make(map[string]bool)
+	for _, t := range tools {
+		names[t.Name] = true
+	}
+	return names
+}
+
+func TestFilterTools(t *testing.T) {
+	tools := getToolsList()
+
+	tests := []struct {
+		name    string
+		filter  toolFilter
+		include []string
+		exclude []string
+	}{
+		{
+			name:    "no filter",
+			filter:  toolFilter{},
+			include: []string{"session_list", "session_delete", "message_add"},
+		},
+		{
+			name:    "read only",
+			filter:  toolFilter{readOnly: true},
+			include: []string{"session_list", "file_content", "global_health"},
+			exclude: []string{"session_delete", "session_create", "message_add"},
+		},
+		{
+			name:    "allow glob",
+			filter:  toolFilter{allow: []string{"session_*"}},
+			include: []string{"session_list", "session_delete"},
+			exclude: []string{"message_add", "file_list"},
+		},
+		{
+			name:    "deny wins over allow",
+			filter:  toolFilter{allow: []string{"session_*"}, deny: []string{"session_delete"}},
+			include: []string{"session_list"},
+			exclude: []string{"session_delete", "message_add"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := toolNames(filterTools(tools, tt.filter))
+			for _, n := range tt.include {
+				if !names[n] {
+					t.Errorf("Expected %s to be included", n)
+				}
+			}
+			for _, n := range tt.exclude {
+				if names[n] {
+					t.Errorf("Expected %s to be excluded", n)
+				}
+			}
+		})
+	}
+}
+
+func TestToolFilterValidate(t *testing.T) {
+	if err := (toolFilter{allow: []string{"session_*"}}).validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := (toolFilter{deny: []string{"session_["}}).validate(); err == nil {
+		t.Error("Expected error for malformed pattern")
+	}
+}
+
+func TestToolAnnotations(t *testing.T) {
+	for _, tool := range getToolsList() {
+		if tool.Annotations == nil {
+			t.Errorf("Tool %s has no annotations", tool.Name)
+		}
+	}
+
+	byName := make(map[string]Tool)
+	for _, tool := range getToolsList() {
+		byName[tool.Name] = tool
+	}
+	if a := byName["session_delete"].Annotations; a.ReadOnlyHint || !a.DestructiveHint {
+		t.Errorf("session_delete should be destructive, got %+v", a)
+	}
+	if a := byName["session_list"].Annotations; !a.ReadOnlyHint {
+		t.Errorf("session_list should be read-only, got %+v", a)
+	}
+}
+
+func TestIsToolAllowed(t *testing.T) {
+	orig := readOnlyMode
+	defer func() { readOnlyMode = orig }()
+
+	readOnlyMode = true
+	if isToolAllowed("session_delete") {
+		t.Error("Expected session_delete to be blocked in read-only mode")
+	}
+	if !isToolAllowed("session_list") {
+		t.Error("Expected session_list to be allowed in read-only mode")
+	}
+	if !isToolAllowed("unknown_tool") {
+		t.Error("Expected unknown tools to pass through to handleToolCall")
+	}
+}
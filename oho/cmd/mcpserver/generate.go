@@ -0,0 +1,552 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// AnnotationKey cobra 命令注解键，控制命令如何暴露为 MCP 工具
+//
+//	skip        不生成工具（交互式或长时间运行的命令）
+//	readonly    只读工具，--read-only 时只暴露这些工具
+//	destructive 破坏性工具
+//
+// 没有注解的命令视为会修改状态
+// 标志的 mcp 注解为 skip 时不作为工具参数；必填的标志被跳过时不生成工具
+const AnnotationKey = "mcp"
+
+var generateTools bool
+
+// rootCommand CLI 根命令，在 mcpserver 启动时设置
+var rootCommand *cobra.Command
+
+// groupLocks 每个顶层命令一把锁：同一顶层命令下的命令在同一个包中，用包级变量保存标志，不能同时执行
+// 不同顶层命令的工具可以并发执行，输出通过 context 分别捕获
+var (
+	groupMu    sync.Mutex
+	groupLocks = map[*cobra.Command]*sync.Mutex{}
+)
+
+func init() {
+	Cmd.Flags().BoolVar(&generateTools, "generate-tools", true, "根据 CLI 命令树自动生成额外的 MCP 工具")
+}
+
+// positionalArg 从 Use 中解析出的位置参数
+type positionalArg struct {
+	name     string
+	required bool
+	variadic bool
+}
+
+// generatedTool 由 cobra 命令生成的 MCP 工具
+type generatedTool struct {
+	Tool
+	command    *cobra.Command
+	positional []positionalArg
+	flags      []*pflag.Flag
+}
+
+// destructiveVerbs 破坏性命令的动词，没有注解时据此推断
+var destructiveVerbs = map[string]bool{
+	"delete": true, "abort": true, "revert": true, "remove": true, "rm": true,
+	"clean": true, "gc": true, "unshare": true, "clear": true, "archive": true,
+}
+
+// skippedCommands 不生成工具的命令路径
+var skippedCommands = map[string]bool{
+	"help":       true,
+	"completion": true,
+	"mcpserver":  true,
+}
+
+// generateToolsFrom 遍历命令树，为每个可执行命令生成工具
+func generateToolsFrom(root *cobra.Command) []generatedTool {
+	if root == nil {
+		return nil
+	}
+
+	var tools []generatedTool
+	seen := map[string]bool{}
+	var walk func(cmd *cobra.Command, path []string)
+	walk = func(cmd *cobra.Command, path []string) {
+		for _, child := range cmd.Commands() {
+			childPath := append(append([]string{}, path...), child.Name())
+			if skippedCommands[strings.Join(childPath, " ")] || child.Hidden || child.Deprecated != "" {
+				continue
+			}
+			if child.Annotations[AnnotationKey] == "skip" {
+				continue
+			}
+			if child.Runnable() && !requiresSkippedFlag(child) {
+				tool := buildGeneratedTool(root, child, childPath)
+				// 多个命令可能同名（如 provider oauth authorize/callback），保留先注册的
+				if !seen[tool.Name] {
+					seen[tool.Name] = true
+					tools = append(tools, tool)
+				}
+			}
+			walk(child, childPath)
+		}
+	}
+	walk(root, nil)
+
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
+	return tools
+}
+
+// buildGeneratedTool 根据命令的 Use、Short、标志和参数生成工具定义
+func buildGeneratedTool(root, cmd *cobra.Command, path []string) generatedTool {
+	name := strings.ReplaceAll(strings.Join(path, "_"), "-", "_")
+
+	description := cmd.Short
+	if cmd.Long != "" {
+		description = cmd.Long
+	}
+
+	properties := map[string]interface{}{}
+	var required []string
+
+	flags := commandFlags(root, cmd)
+	for _, f := range flags {
+		properties[f.Name] = flagSchema(f)
+		if isRequiredFlag(f) {
+			required = append(required, f.Name)
+		}
+	}
+
+	positional := parsePositional(cmd.Use)
+	for i, arg := range positional {
+		if _, exists := properties[arg.name]; exists {
+			positional[i].name = "arg_" + arg.name
+		}
+		prop := map[string]interface{}{"type": "string", "description": i18n.Sprintf("位置参数 %s", arg.name)}
+		if arg.variadic {
+			prop = map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": i18n.Sprintf("位置参数 %s", arg.name)}
+		}
+		properties[positional[i].name] = prop
+		if arg.required {
+			required = append(required, positional[i].name)
+		}
+	}
+	if len(positional) == 0 {
+		// Use 未声明位置参数时，仍允许透传（如 message add "内容"）
+		positional = []positionalArg{{name: "args", variadic: true}}
+		if _, exists := properties["args"]; !exists {
+			properties["args"] = map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": i18n.T("位置参数"),
+			}
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if required == nil {
+		required = []string{}
+	}
+	sort.Strings(required)
+	schema["required"] = required
+	inputSchema, _ := json.Marshal(schema)
+
+	return generatedTool{
+		Tool: Tool{
+			Name:        name,
+			Description: description,
+			InputSchema: inputSchema,
+			Annotations: commandAnnotations(cmd, path[len(path)-1]),
+		},
+		command:    cmd,
+		positional: positional,
+		flags:      flags,
+	}
+}
+
+// commandFlags 返回命令自身及继承的标志，不包括根命令的全局标志和带有 mcp: skip 注解的标志
+func commandFlags(root, cmd *cobra.Command) []*pflag.Flag {
+	var flags []*pflag.Flag
+	seen := map[string]bool{}
+	add := func(f *pflag.Flag) {
+		if seen[f.Name] || f.Name == "help" || f.Hidden || skippedFlag(f) || root.PersistentFlags().Lookup(f.Name) != nil {
+			return
+		}
+		seen[f.Name] = true
+		flags = append(flags, f)
+	}
+	cmd.LocalFlags().VisitAll(add)
+	cmd.InheritedFlags().VisitAll(add)
+
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+	return flags
+}
+
+// flagSchema 根据标志类型生成 JSON Schema
+func flagSchema(f *pflag.Flag) map[string]interface{} {
+	prop := map[string]interface{}{"description": f.Usage}
+
+	switch f.Value.Type() {
+	case "bool":
+		prop["type"] = "boolean"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "count":
+		prop["type"] = "integer"
+	case "float32", "float64":
+		prop["type"] = "number"
+	case "stringSlice", "stringArray":
+		prop["type"] = "array"
+		prop["items"] = map[string]interface{}{"type": "string"}
+	case "intSlice", "int32Slice", "int64Slice", "uintSlice":
+		prop["type"] = "array"
+		prop["items"] = map[string]interface{}{"type": "integer"}
+	default:
+		prop["type"] = "string"
+	}
+
+	if _, isSlice := f.Value.(pflag.SliceValue); !isSlice && f.DefValue != "" && f.DefValue != "0" && f.DefValue != "false" {
+		prop["default"] = f.DefValue
+	}
+	return prop
+}
+
+// skippedFlag 标志带有 mcp: skip 注解，如执行本地命令的钩子和读写本机文件的路径
+func skippedFlag(f *pflag.Flag) bool {
+	for _, v := range f.Annotations[AnnotationKey] {
+		if v == "skip" {
+			return true
+		}
+	}
+	return false
+}
+
+// requiresSkippedFlag 命令有必填但不暴露的标志，生成的工具无法执行
+func requiresSkippedFlag(cmd *cobra.Command) bool {
+	required := false
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if skippedFlag(f) && isRequiredFlag(f) {
+			required = true
+		}
+	})
+	return required
+}
+
+func isRequiredFlag(f *pflag.Flag) bool {
+	values := f.Annotations[cobra.BashCompOneRequiredFlag]
+	return len(values) > 0 && values[0] == "true"
+}
+
+// parsePositional 从 Use 中解析位置参数，<x> 为必需，[x] 为可选，带 ... 为可变参数
+func parsePositional(use string) []positionalArg {
+	fields := strings.Fields(use)
+	if len(fields) <= 1 {
+		return nil
+	}
+
+	var args []positionalArg
+	for _, field := range fields[1:] {
+		var arg positionalArg
+		switch {
+		case strings.HasPrefix(field, "<"):
+			arg.required = true
+		case strings.HasPrefix(field, "["):
+		default:
+			continue
+		}
+		name := strings.Trim(field, "<>[].")
+		if strings.HasSuffix(field, "...") || strings.HasSuffix(strings.TrimRight(field, "]>"), "...") {
+			arg.variadic = true
+		}
+		if name == "" || strings.EqualFold(name, "flags") {
+			continue
+		}
+		arg.name = name
+		args = append(args, arg)
+	}
+	return args
+}
+
+// commandAnnotations 根据命令注解生成工具注解，只有明确注解为 readonly 的命令才是只读的
+// 没有注解时按命令名及其别名推断是否为破坏性命令
+func commandAnnotations(cmd *cobra.Command, verb string) *ToolAnnotations {
+	switch cmd.Annotations[AnnotationKey] {
+	case "readonly":
+		return readOnly()
+	case "destructive":
+		return mutating(true, false)
+	}
+	for _, name := range append([]string{verb}, cmd.Aliases...) {
+		if destructiveVerbs[name] {
+			return mutating(true, true)
+		}
+	}
+	return mutating(false, false)
+}
+
+// buildArgv 将工具参数转换为命令行参数
+func (g generatedTool) buildArgv(args map[string]interface{}) ([]string, error) {
+	var argv []string
+
+	for _, f := range g.flags {
+		v, ok := args[f.Name]
+		if !ok || v == nil {
+			continue
+		}
+		if items, isList := v.([]interface{}); isList {
+			for _, item := range items {
+				argv = append(argv, fmt.Sprintf("--%s=%s", f.Name, formatArg(item)))
+			}
+			continue
+		}
+		argv = append(argv, fmt.Sprintf("--%s=%s", f.Name, formatArg(v)))
+	}
+
+	// 位置参数之前加 --，避免以 - 开头的值被解析为标志
+	var positional []string
+	var pending []string
+	for _, arg := range g.positional {
+		v, ok := args[arg.name]
+		if !ok || v == nil {
+			if arg.required {
+				return nil, fmt.Errorf("%s is required", arg.name)
+			}
+			// 可选参数缺失时先占位，只有后面还有参数时才补空值
+			pending = append(pending, "")
+			continue
+		}
+		positional = append(positional, pending...)
+		pending = nil
+		if items, isList := v.([]interface{}); isList {
+			for _, item := range items {
+				positional = append(positional, formatArg(item))
+			}
+			continue
+		}
+		positional = append(positional, formatArg(v))
+	}
+	if len(positional) > 0 {
+		argv = append(argv, "--")
+		argv = append(argv, positional...)
+	}
+	return argv, nil
+}
+
+// formatArg 将 JSON 值格式化为命令行参数
+func formatArg(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		data, _ := json.Marshal(val)
+		return string(data)
+	}
+}
+
+// run 在进程内执行命令，捕获 JSON 输出
+func (g generatedTool) run(ctx context.Context, args map[string]interface{}) CallToolResult {
+	argv, err := g.buildArgv(args)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
+	unlock := lockGroup(g.command)
+	defer unlock()
+
+	if err := ctx.Err(); err != nil {
+		return errorResult(err.Error())
+	}
+
+	cmd := g.command
+	resetFlags(cmd)
+	defer resetFlags(cmd)
+
+	if err := cmd.ParseFlags(argv); err != nil {
+		return errorResult(err.Error())
+	}
+	positional := cmd.Flags().Args()
+	if err := cmd.ValidateArgs(positional); err != nil {
+		return errorResult(err.Error())
+	}
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return errorResult(err.Error())
+	}
+
+	// 命令的输出写入缓冲区；stdio 传输下真实的 stdin 是 JSON-RPC 通道，命令只能读到空输入
+	var out bytes.Buffer
+	cmd.SetIn(strings.NewReader(""))
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetContext(util.WithOutput(ctx, &out, util.FormatJSON))
+	defer func() {
+		cmd.SetIn(nil)
+		cmd.SetOut(nil)
+		cmd.SetErr(nil)
+		cmd.SetContext(nil)
+	}()
+	runErr := execute(cmd, positional)
+
+	output := strings.TrimSpace(out.String())
+	if runErr != nil {
+		if output != "" {
+			return errorResult(fmt.Sprintf("%s\n%s", runErr.Error(), output))
+		}
+		return errorResult(runErr.Error())
+	}
+	return successResult(output)
+}
+
+// lockGroup 锁定 cmd 所在的顶层命令，返回解锁函数
+func lockGroup(cmd *cobra.Command) func() {
+	top := cmd
+	for top.HasParent() && top.Parent().HasParent() {
+		top = top.Parent()
+	}
+	groupMu.Lock()
+	mu, ok := groupLocks[top]
+	if !ok {
+		mu = &sync.Mutex{}
+		groupLocks[top] = mu
+	}
+	groupMu.Unlock()
+
+	mu.Lock()
+	return mu.Unlock
+}
+
+// execute 按 cobra 的顺序执行命令及其父命令的钩子（cobra.EnableTraverseRunHooks）：
+// 父命令的 PersistentPreRun 从上到下执行，命令成功后 PersistentPostRun 从下到上执行
+// 根命令的钩子只绑定全局标志，mcpserver 启动时已经执行过，这里跳过
+func execute(cmd *cobra.Command, args []string) error {
+	var chain []*cobra.Command
+	for c := cmd; c != nil && c.HasParent(); c = c.Parent() {
+		chain = append(chain, c)
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		c := chain[i]
+		if c.PersistentPreRunE != nil {
+			if err := c.PersistentPreRunE(cmd, args); err != nil {
+				return err
+			}
+		} else if c.PersistentPreRun != nil {
+			c.PersistentPreRun(cmd, args)
+		}
+	}
+	if cmd.PreRunE != nil {
+		if err := cmd.PreRunE(cmd, args); err != nil {
+			return err
+		}
+	} else if cmd.PreRun != nil {
+		cmd.PreRun(cmd, args)
+	}
+
+	if cmd.RunE != nil {
+		if err := cmd.RunE(cmd, args); err != nil {
+			return err
+		}
+	} else {
+		cmd.Run(cmd, args)
+	}
+
+	if cmd.PostRunE != nil {
+		if err := cmd.PostRunE(cmd, args); err != nil {
+			return err
+		}
+	} else if cmd.PostRun != nil {
+		cmd.PostRun(cmd, args)
+	}
+	for _, c := range chain {
+		if c.PersistentPostRunE != nil {
+			if err := c.PersistentPostRunE(cmd, args); err != nil {
+				return err
+			}
+		} else if c.PersistentPostRun != nil {
+			c.PersistentPostRun(cmd, args)
+		}
+	}
+	return nil
+}
+
+// resetFlags 将命令的标志恢复为默认值，避免上一次调用的值残留
+// 根命令的全局标志不属于工具参数，保留 mcpserver 启动时的值
+func resetFlags(cmd *cobra.Command) {
+	global := cmd.Root().PersistentFlags()
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if cmd.HasParent() && global.Lookup(f.Name) == f {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			_ = sv.Replace(sliceDefault(f.DefValue))
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+}
+
+// sliceDefault 解析切片标志的默认值，形如 [a,b]
+func sliceDefault(def string) []string {
+	def = strings.TrimSuffix(strings.TrimPrefix(def, "["), "]")
+	if def == "" {
+		return nil
+	}
+	return strings.Split(def, ",")
+}
+
+// generatedToolsList 返回未被手写工具覆盖的生成工具
+func generatedToolsList() []generatedTool {
+	if !generateTools {
+		return nil
+	}
+
+	handwritten := map[string]bool{}
+	for _, t := range getToolsList() {
+		handwritten[t.Name] = true
+	}
+
+	var result []generatedTool
+	for _, g := range generateToolsFrom(rootCommand) {
+		if !handwritten[g.Name] {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+// allTools 返回手写工具与生成工具的合集
+func allTools() []Tool {
+	tools := getToolsList()
+	for _, g := range generatedToolsList() {
+		tools = append(tools, g.Tool)
+	}
+	return tools
+}
+
+// findGeneratedTool 按名称查找生成的工具
+func findGeneratedTool(name string) (generatedTool, bool) {
+	for _, g := range generatedToolsList() {
+		if g.Name == name {
+			return g, true
+		}
+	}
+	return generatedTool{}, false
+}
@@ -0,0 +1,463 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/cmd/add"
+	"github.com/anomalyco/oho/cmd/message"
+	"github.com/anomalyco/oho/cmd/session"
+	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+func TestMain(m *testing.M) {
+	os.Setenv("OPENCODE_SERVER_HOST", "127.0.0.1")
+	os.Setenv("OPENCODE_SERVER_PORT", "4096")
+	os.Setenv("OPENCODE_SERVER_USERNAME", "opencode")
+	os.Setenv("OPENCODE_SERVER_PASSWORD", "test")
+	_ = config.Init()
+
+	m.Run()
+}
+
+// postRuns 测试命令树中 session 的 PersistentPostRun 执行次数
+var postRuns int
+
+// newTestRoot 构建用于测试的命令树
+func newTestRoot() *cobra.Command {
+	root := &cobra.Command{Use: "oho"}
+	root.PersistentFlags().Bool("json", false, "JSON 输出")
+
+	var directory string
+	session := &cobra.Command{Use: "session", Short: "会话管理"}
+	session.PersistentFlags().StringVar(&directory, "directory", "", "工作目录")
+	session.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if directory == "bad" {
+			return fmt.Errorf("invalid directory")
+		}
+		return nil
+	}
+	session.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		postRuns++
+	}
+
+	var limit int
+	var tags []string
+	list := &cobra.Command{
+		Use:         "list",
+		Short:       "列出会话",
+		Annotations: map[string]string{AnnotationKey: "readonly"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Fprintf(cmd.OutOrStdout(), `{"limit":%d,"tags":%q,"json":%v}`, limit, strings.Join(tags, ","), !util.TextOutput(cmd.Context()))
+			return nil
+		},
+	}
+	list.Flags().IntVar(&limit, "limit", 10, "数量上限")
+	list.Flags().StringSliceVar(&tags, "tag", nil, "标签")
+
+	del := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "删除会话",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fmt.Errorf("cannot delete %s", args[0])
+		},
+	}
+
+	var title string
+	update := &cobra.Command{
+		Use:   "update-title [id]",
+		Short: "更新标题",
+		Run:   func(cmd *cobra.Command, args []string) {},
+	}
+	update.Flags().StringVar(&title, "title", "", "标题")
+	_ = update.MarkFlagRequired("title")
+
+	watch := &cobra.Command{
+		Use:         "watch",
+		Annotations: map[string]string{AnnotationKey: "skip"},
+		Run:         func(cmd *cobra.Command, args []string) {},
+	}
+
+	hidden := &cobra.Command{Use: "secret", Hidden: true, Run: func(cmd *cobra.Command, args []string) {}}
+
+	// 名称像只读命令，但 --apply 会写文件
+	diff := &cobra.Command{
+		Use:         "diff [id]",
+		Annotations: map[string]string{AnnotationKey: "destructive"},
+		Run:         func(cmd *cobra.Command, args []string) {},
+	}
+	diff.Flags().Bool("apply", false, "应用差异")
+
+	session.AddCommand(list, del, update, watch, hidden, diff)
+	root.AddCommand(session)
+	return root
+}
+
+func findTool(tools []generatedTool, name string) (generatedTool, bool) {
+	for _, tool := range tools {
+		if tool.Name == name {
+			return tool, true
+		}
+	}
+	return generatedTool{}, false
+}
+
+func TestGenerateToolsFrom(t *testing.T) {
+	tools := generateToolsFrom(newTestRoot())
+
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	want := []string{"session_delete", "session_diff", "session_list", "session_update_title"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("generateToolsFrom() names = %v, want %v", names, want)
+	}
+
+	del, _ := findTool(tools, "session_delete")
+	if !del.Annotations.DestructiveHint {
+		t.Errorf("Expected session_delete to be destructive, got %+v", del.Annotations)
+	}
+	list, _ := findTool(tools, "session_list")
+	if !list.Annotations.ReadOnlyHint {
+		t.Errorf("Expected session_list to be read-only, got %+v", list.Annotations)
+	}
+	diff, _ := findTool(tools, "session_diff")
+	if diff.Annotations.ReadOnlyHint || !diff.Annotations.DestructiveHint {
+		t.Errorf("Expected session_diff to be destructive, got %+v", diff.Annotations)
+	}
+	// 只有明确注解的命令才是只读的
+	update, _ := findTool(tools, "session_update_title")
+	if update.Annotations.ReadOnlyHint {
+		t.Errorf("Expected unannotated session_update_title not to be read-only, got %+v", update.Annotations)
+	}
+}
+
+func TestGeneratedToolsSkipLocalFlags(t *testing.T) {
+	root := &cobra.Command{Use: "oho"}
+	root.AddCommand(add.Cmd, session.Cmd)
+	defer root.RemoveCommand(add.Cmd, session.Cmd)
+	tools := generateToolsFrom(root)
+
+	// 钩子在本机执行命令，不能由 MCP 客户端设置
+	for _, name := range []string{"add", "session_submit"} {
+		tool, ok := findTool(tools, name)
+		if !ok {
+			t.Fatalf("Expected tool %s", name)
+		}
+		var schema struct {
+			Properties map[string]interface{} `json:"properties"`
+		}
+		if err := json.Unmarshal(tool.InputSchema, &schema); err != nil {
+			t.Fatal(err)
+		}
+		for prop := range schema.Properties {
+			if strings.HasPrefix(prop, "on-") || strings.HasPrefix(prop, "on_") {
+				t.Errorf("%s exposes hook parameter %s", name, prop)
+			}
+		}
+	}
+
+	// --policy 是必填的本机文件，不暴露时整个命令不生成工具
+	if _, ok := findTool(tools, "session_gc"); ok {
+		t.Error("Expected session_gc not to be generated without --policy")
+	}
+}
+
+func TestSessionTreeTool(t *testing.T) {
+	root := &cobra.Command{Use: "oho"}
+	root.AddCommand(session.Cmd)
+	defer root.RemoveCommand(session.Cmd)
+
+	// 只显示一次的树是只读工具，--watch 不暴露
+	tree, ok := findTool(generateToolsFrom(root), "session_tree")
+	if !ok {
+		t.Fatal("Expected tool session_tree")
+	}
+	if !tree.Annotations.ReadOnlyHint {
+		t.Errorf("Expected session_tree to be read-only, got %+v", tree.Annotations)
+	}
+	var schema struct {
+		Properties map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal(tree.InputSchema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := schema.Properties["watch"]; ok {
+		t.Error("Expected session_tree not to expose --watch")
+	}
+}
+
+func TestGeneratedToolSchema(t *testing.T) {
+	tools := generateToolsFrom(newTestRoot())
+
+	tests := []struct {
+		tool       string
+		properties map[string]string
+		required   []string
+	}{
+		{
+			tool:       "session_list",
+			properties: map[string]string{"limit": "integer", "tag": "array", "directory": "string", "args": "array"},
+			required:   []string{},
+		},
+		{
+			tool:       "session_delete",
+			properties: map[string]string{"id": "string", "directory": "string"},
+			required:   []string{"id"},
+		},
+		{
+			tool:       "session_update_title",
+			properties: map[string]string{"id": "string", "title": "string", "directory": "string"},
+			required:   []string{"title"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			tool, ok := findTool(tools, tt.tool)
+			if !ok {
+				t.Fatalf("Tool %s not generated", tt.tool)
+			}
+
+			var schema struct {
+				Properties map[string]struct {
+					Type string `json:"type"`
+				} `json:"properties"`
+				Required []string `json:"required"`
+			}
+			if err := json.Unmarshal(tool.InputSchema, &schema); err != nil {
+				t.Fatalf("Invalid schema: %v", err)
+			}
+
+			if len(schema.Properties) != len(tt.properties) {
+				t.Errorf("Expected %d properties, got %v", len(tt.properties), schema.Properties)
+			}
+			for name, typ := range tt.properties {
+				if schema.Properties[name].Type != typ {
+					t.Errorf("Property %s type = %q, want %q", name, schema.Properties[name].Type, typ)
+				}
+			}
+			if _, ok := schema.Properties["json"]; ok {
+				t.Error("Root persistent flags should not be exposed")
+			}
+			if !reflect.DeepEqual(schema.Required, tt.required) {
+				t.Errorf("Required = %v, want %v", schema.Required, tt.required)
+			}
+		})
+	}
+}
+
+func TestCommandAnnotationsAliases(t *testing.T) {
+	// 命令名不是破坏性动词，但别名是
+	achieve := &cobra.Command{Use: "achieve [id...]", Aliases: []string{"archive"}}
+	if got := commandAnnotations(achieve, "achieve"); !got.DestructiveHint {
+		t.Errorf("Expected achieve/archive to be destructive, got %+v", got)
+	}
+	rename := &cobra.Command{Use: "rename", Aliases: []string{"mv"}}
+	if got := commandAnnotations(rename, "rename"); got.DestructiveHint || got.ReadOnlyHint {
+		t.Errorf("Expected rename to be a non-destructive mutation, got %+v", got)
+	}
+}
+
+func TestCommandAnnotationsDestructive(t *testing.T) {
+	root := &cobra.Command{Use: "oho"}
+	root.AddCommand(message.Cmd, session.Cmd)
+	defer root.RemoveCommand(message.Cmd, session.Cmd)
+	tools := generateToolsFrom(root)
+
+	// 命令名不是破坏性动词，但执行任意命令或授予批准
+	for _, name := range []string{"message_shell", "session_permissions"} {
+		tool, ok := findTool(tools, name)
+		if !ok {
+			t.Fatalf("Expected tool %s", name)
+		}
+		if tool.Annotations.ReadOnlyHint || !tool.Annotations.DestructiveHint {
+			t.Errorf("Expected %s to be destructive, got %+v", name, tool.Annotations)
+		}
+	}
+}
+
+func TestParsePositional(t *testing.T) {
+	tests := []struct {
+		use  string
+		want []positionalArg
+	}{
+		{use: "list", want: nil},
+		{use: "get <messageID>", want: []positionalArg{{name: "messageID", required: true}}},
+		{use: "permissions [id] [permissionID]", want: []positionalArg{{name: "id"}, {name: "permissionID"}}},
+		{use: "run <files>...", want: []positionalArg{{name: "files", required: true, variadic: true}}},
+		{use: "oauth authorize <provider>", want: []positionalArg{{name: "provider", required: true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.use, func(t *testing.T) {
+			if got := parsePositional(tt.use); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePositional(%q) = %+v, want %+v", tt.use, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildArgv(t *testing.T) {
+	tools := generateToolsFrom(newTestRoot())
+	list, _ := findTool(tools, "session_list")
+	del, _ := findTool(tools, "session_delete")
+
+	argv, err := list.buildArgv(map[string]interface{}{
+		"limit": float64(5),
+		"tag":   []interface{}{"a", "b"},
+		"args":  []interface{}{"-x"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{"--limit=5", "--tag=a", "--tag=b", "--", "-x"}
+	if !reflect.DeepEqual(argv, want) {
+		t.Errorf("buildArgv() = %v, want %v", argv, want)
+	}
+
+	if _, err := del.buildArgv(map[string]interface{}{}); err == nil {
+		t.Error("Expected error for missing required positional argument")
+	}
+}
+
+func TestGeneratedToolRun(t *testing.T) {
+	tools := generateToolsFrom(newTestRoot())
+	list, _ := findTool(tools, "session_list")
+	del, _ := findTool(tools, "session_delete")
+	update, _ := findTool(tools, "session_update_title")
+
+	stdout := os.Stdout
+	runs := postRuns
+	result := list.run(context.Background(), map[string]interface{}{"limit": float64(3), "tag": []interface{}{"x"}})
+	if result.IsError {
+		t.Fatalf("Unexpected error: %s", result.Content[0].Text)
+	}
+	if want := `{"limit":3,"tags":"x","json":true}`; result.Content[0].Text != want {
+		t.Errorf("Output = %s, want %s", result.Content[0].Text, want)
+	}
+	if config.Get().JSON || !util.TextOutput(context.Background()) {
+		t.Error("Expected the output format not to change outside the run")
+	}
+	if os.Stdout != stdout {
+		t.Error("Expected os.Stdout not to be replaced")
+	}
+	if postRuns != runs+1 {
+		t.Errorf("Expected PersistentPostRun to run once, got %d", postRuns-runs)
+	}
+
+	// 父命令的 PersistentPreRunE 在命令之前执行
+	result = list.run(context.Background(), map[string]interface{}{"directory": "bad"})
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "invalid directory") {
+		t.Errorf("Expected PersistentPreRunE error, got %+v", result)
+	}
+
+	// 上一次调用的标志值不应残留
+	result = list.run(context.Background(), map[string]interface{}{})
+	if want := `{"limit":10,"tags":"","json":true}`; result.Content[0].Text != want {
+		t.Errorf("Output = %s, want %s", result.Content[0].Text, want)
+	}
+
+	result = del.run(context.Background(), map[string]interface{}{"id": "ses_1"})
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "cannot delete ses_1") {
+		t.Errorf("Expected command error, got %+v", result)
+	}
+
+	result = update.run(context.Background(), map[string]interface{}{})
+	if !result.IsError {
+		t.Error("Expected error for missing required flag")
+	}
+}
+
+func TestGeneratedToolRunConcurrent(t *testing.T) {
+	// 两个顶层命令互相等待对方开始执行，只有并发执行时才能都完成
+	started := map[string]chan struct{}{"alpha": make(chan struct{}), "beta": make(chan struct{})}
+	root := &cobra.Command{Use: "oho"}
+	for name, other := range map[string]string{"alpha": "beta", "beta": "alpha"} {
+		name, other := name, other
+		group := &cobra.Command{Use: name}
+		group.AddCommand(&cobra.Command{
+			Use:         "show",
+			Annotations: map[string]string{AnnotationKey: "readonly"},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				close(started[name])
+				select {
+				case <-started[other]:
+				case <-time.After(5 * time.Second):
+					return fmt.Errorf("%s did not run concurrently", other)
+				}
+				return util.Output(cmd.Context(), map[string]string{"name": name})
+			},
+		})
+		root.AddCommand(group)
+	}
+
+	tools := generateToolsFrom(root)
+	results := make(chan CallToolResult, 2)
+	for _, name := range []string{"alpha_show", "beta_show"} {
+		tool, ok := findTool(tools, name)
+		if !ok {
+			t.Fatalf("Expected tool %s", name)
+		}
+		go func() { results <- tool.run(context.Background(), map[string]interface{}{}) }()
+	}
+
+	var outputs []string
+	for i := 0; i < 2; i++ {
+		result := <-results
+		if result.IsError {
+			t.Fatalf("Unexpected error: %s", result.Content[0].Text)
+		}
+		var env struct {
+			Data map[string]string `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(result.Content[0].Text), &env); err != nil {
+			t.Fatalf("Output is not valid JSON: %v\n%s", err, result.Content[0].Text)
+		}
+		outputs = append(outputs, env.Data["name"])
+	}
+	sort.Strings(outputs)
+	if want := []string{"alpha", "beta"}; !reflect.DeepEqual(outputs, want) {
+		t.Errorf("Outputs = %v, want %v (each run should capture only its own output)", outputs, want)
+	}
+}
+
+func TestHandwrittenToolsTakePrecedence(t *testing.T) {
+	origRoot, origGenerate := rootCommand, generateTools
+	defer func() { rootCommand, generateTools = origRoot, origGenerate }()
+
+	rootCommand = newTestRoot()
+	generateTools = true
+
+	names := toolNames(allTools())
+	if !names["session_update_title"] {
+		t.Error("Expected generated tool to be listed")
+	}
+
+	count := 0
+	for _, tool := range allTools() {
+		if tool.Name == "session_list" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("Expected session_list once, got %d", count)
+	}
+
+	generateTools = false
+	if toolNames(allTools())["session_update_title"] {
+		t.Error("Expected generated tools to be hidden when disabled")
+	}
+}
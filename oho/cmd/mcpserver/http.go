@@ -0,0 +1,408 @@
+package mcpserver
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anomalyco/oho/internal/i18n"
+)
+
+// sessionHeader Streamable HTTP 传输的会话 ID 请求头
+const sessionHeader = "Mcp-Session-Id"
+
+// maxRequestBody 单个 POST 请求体的最大字节数
+const maxRequestBody = 10 * 1024 * 1024
+
+var (
+	httpPath             string
+	authToken            string
+	allowedOrigins       []string
+	allowUnauthenticated bool
+	sessionIdleTimeout   time.Duration
+)
+
+func init() {
+	Cmd.Flags().StringVar(&httpPath, "path", "/mcp", "HTTP 传输的端点路径")
+	Cmd.Flags().StringVar(&authToken, "token", "", "HTTP 传输的 Bearer Token (默认读取环境变量 OHO_MCP_TOKEN)")
+	Cmd.Flags().StringSliceVar(&allowedOrigins, "allowed-origin", nil, "允许的浏览器 Origin（可多次使用，本机 Origin 总是允许）")
+	Cmd.Flags().BoolVar(&allowUnauthenticated, "allow-unauthenticated", false, "允许在非本机地址上不设置 --token 监听（任何能访问端口的人都可以调用 OpenCode API）")
+	Cmd.Flags().DurationVar(&sessionIdleTimeout, "session-idle-timeout", 30*time.Minute, "HTTP 会话空闲多久后过期")
+}
+
+// httpSession 一个客户端的会话，没有进行中的请求且空闲超过 idleTimeout 时过期
+type httpSession struct {
+	srv      *server
+	active   int
+	lastUsed time.Time
+}
+
+// httpHandler 实现 MCP Streamable HTTP 传输
+// 每个客户端在 initialize 时获得独立的会话 ID，一个 oho 实例可同时服务多个客户端
+type httpHandler struct {
+	token          string
+	allowedOrigins []string
+	idleTimeout    time.Duration
+	now            func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+func newHTTPHandler(token string, origins []string, idleTimeout time.Duration) *httpHandler {
+	return &httpHandler{
+		token:          token,
+		allowedOrigins: origins,
+		idleTimeout:    idleTimeout,
+		now:            time.Now,
+		sessions:       make(map[string]*httpSession),
+	}
+}
+
+// checkListen 非本机地址必须设置 token，除非明确允许不认证
+func checkListen(addr, token string, allowUnauth bool) error {
+	if token != "" || isLoopbackAddr(addr) {
+		return nil
+	}
+	if !allowUnauth {
+		return i18n.Errorf("监听非本机地址 %s 时必须设置 --token 或 OHO_MCP_TOKEN；确实不需要认证时使用 --allow-unauthenticated", addr)
+	}
+	i18n.Fprintf(os.Stderr, "警告：监听非本机地址 %s 且未设置 --token，任何人都可以调用 OpenCode API\n", addr)
+	return nil
+}
+
+// serveHTTP 启动 Streamable HTTP 传输
+func serveHTTP(addr string) error {
+	token := authToken
+	if token == "" {
+		token = os.Getenv("OHO_MCP_TOKEN")
+	}
+	if err := checkListen(addr, token, allowUnauthenticated); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(httpPath, newHTTPHandler(token, allowedOrigins, sessionIdleTimeout))
+
+	i18n.Fprintf(os.Stderr, "MCP 服务器已启动：http://%s%s\n", addr, httpPath)
+	return http.ListenAndServe(addr, mux)
+}
+
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.checkOrigin(r) {
+		http.Error(w, "Forbidden origin", http.StatusForbidden)
+		return
+	}
+	if !h.checkToken(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="oho"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		h.handlePost(w, r)
+	case http.MethodDelete:
+		h.handleDelete(w, r)
+	default:
+		// 服务器不主动向客户端推送消息，因此不提供 GET SSE 流
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *httpHandler) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBody))
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	reqs, batch, err := parseBatch(body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, newError(nil, -32700, "Parse error"))
+		return
+	}
+
+	srv, sessionID, status := h.lookupSession(r, reqs)
+	if status != http.StatusOK {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	defer h.release(sessionID)
+	if sessionID != "" {
+		w.Header().Set(sessionHeader, sessionID)
+	}
+
+	// 只有通知或客户端响应时返回 202
+	if !containsRequest(reqs) {
+		for _, req := range reqs {
+			srv.handleMessage(r.Context(), req, nil)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// 包含工具调用且客户端接受 SSE 时，使用 SSE 流返回响应
+	if acceptsSSE(r) && containsMethod(reqs, "tools/call") {
+		h.streamResponses(w, r, srv, reqs)
+		return
+	}
+
+	// 普通 JSON 响应无法携带进度通知
+	var responses []*JSONRPCResponse
+	for _, req := range reqs {
+		if resp := srv.handleMessage(r.Context(), req, nil); resp != nil {
+			responses = append(responses, resp)
+		}
+	}
+	if len(responses) == 0 {
+		// 请求已被取消
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	if batch {
+		writeJSON(w, http.StatusOK, responses)
+		return
+	}
+	writeJSON(w, http.StatusOK, responses[0])
+}
+
+// streamResponses 以 text/event-stream 逐条写出响应，写完后关闭流
+func (h *httpHandler) streamResponses(w http.ResponseWriter, r *http.Request, srv *server, reqs []JSONRPCRequest) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	sw := &sseWriter{w: w, flusher: flusher}
+	for _, req := range reqs {
+		if resp := srv.handleMessage(r.Context(), req, sw.notify); resp != nil {
+			sw.write(resp)
+		}
+	}
+}
+
+// sseWriter 并发安全地向 SSE 响应流写出消息，进度通知与最终响应共用同一个流
+type sseWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func (sw *sseWriter) write(msg interface{}) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	writeSSE(sw.w, msg)
+	sw.flusher.Flush()
+}
+
+func (sw *sseWriter) notify(method string, params interface{}) {
+	sw.write(JSONRPCNotification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (h *httpHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.Header.Get(sessionHeader)
+	if sessionID == "" {
+		http.Error(w, "Missing session ID", http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	_, exists := h.sessions[sessionID]
+	delete(h.sessions, sessionID)
+	h.mu.Unlock()
+
+	if !exists {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// lookupSession 返回请求对应的会话并标记为使用中，请求结束后必须调用 release；initialize 请求创建新会话
+func (h *httpHandler) lookupSession(r *http.Request, reqs []JSONRPCRequest) (*server, string, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expireLocked()
+
+	if containsMethod(reqs, "initialize") {
+		if len(reqs) != 1 {
+			return nil, "", http.StatusBadRequest
+		}
+		sessionID, err := newSessionID()
+		if err != nil {
+			return nil, "", http.StatusInternalServerError
+		}
+		sess := &httpSession{srv: newServer(), active: 1, lastUsed: h.now()}
+		h.sessions[sessionID] = sess
+		return sess.srv, sessionID, http.StatusOK
+	}
+
+	sessionID := r.Header.Get(sessionHeader)
+	if sessionID == "" {
+		return nil, "", http.StatusBadRequest
+	}
+	sess, ok := h.sessions[sessionID]
+	if !ok {
+		return nil, "", http.StatusNotFound
+	}
+	sess.active++
+	sess.lastUsed = h.now()
+	return sess.srv, sessionID, http.StatusOK
+}
+
+// release 请求结束，会话从此刻开始计算空闲时间
+func (h *httpHandler) release(sessionID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if sess, ok := h.sessions[sessionID]; ok {
+		sess.active--
+		sess.lastUsed = h.now()
+	}
+}
+
+// expireLocked 删除空闲超时的会话，客户端断开后不发送 DELETE 时由此回收；调用方持有 h.mu
+func (h *httpHandler) expireLocked() {
+	if h.idleTimeout <= 0 {
+		return
+	}
+	now := h.now()
+	for id, sess := range h.sessions {
+		if sess.active == 0 && now.Sub(sess.lastUsed) > h.idleTimeout {
+			delete(h.sessions, id)
+		}
+	}
+}
+
+// checkToken 校验 Authorization: Bearer <token>，未配置 token 时不校验
+func (h *httpHandler) checkToken(r *http.Request) bool {
+	if h.token == "" {
+		return true
+	}
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return false
+	}
+	given := strings.TrimSpace(auth[len(prefix):])
+	return subtle.ConstantTimeCompare([]byte(given), []byte(h.token)) == 1
+}
+
+// checkOrigin 防止 DNS 重绑定：浏览器请求的 Origin 必须是本机或显式允许的地址
+func (h *httpHandler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range h.allowedOrigins {
+		if origin == allowed {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return isLoopbackHost(u.Hostname())
+}
+
+// parseBatch 解析单条消息或批量消息
+func parseBatch(body []byte) ([]JSONRPCRequest, bool, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var reqs []JSONRPCRequest
+		if err := json.Unmarshal(trimmed, &reqs); err != nil {
+			return nil, true, err
+		}
+		if len(reqs) == 0 {
+			return nil, true, fmt.Errorf("empty batch")
+		}
+		return reqs, true, nil
+	}
+
+	var req JSONRPCRequest
+	if err := json.Unmarshal(trimmed, &req); err != nil {
+		return nil, false, err
+	}
+	return []JSONRPCRequest{req}, false, nil
+}
+
+// containsRequest 判断是否包含需要响应的请求
+func containsRequest(reqs []JSONRPCRequest) bool {
+	for _, req := range reqs {
+		if req.Method != "" && req.ID != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func containsMethod(reqs []JSONRPCRequest, method string) bool {
+	for _, req := range reqs {
+		if req.Method == method {
+			return true
+		}
+	}
+	return false
+}
+
+func acceptsSSE(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+func newSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeSSE 写出一条 SSE message 事件
+func writeSSE(w io.Writer, msg interface{}) {
+	data, _ := json.Marshal(msg)
+	fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+}
+
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	return isLoopbackHost(host)
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// 确保 httpHandler 实现 http.Handler
+var _ http.Handler = (*httpHandler)(nil)
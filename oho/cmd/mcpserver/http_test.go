@@ -0,0 +1,253 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const initializeBody = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+
+func postMCP(t *testing.T, h http.Handler, body, sessionID, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if sessionID != "" {
+		req.Header.Set(sessionHeader, sessionID)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHTTPInitializeAssignsSession(t *testing.T) {
+	h := newHTTPHandler("", nil, time.Hour)
+
+	rec := postMCP(t, h, initializeBody, "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	sessionID := rec.Header().Get(sessionHeader)
+	if sessionID == "" {
+		t.Fatal("Expected session ID header")
+	}
+
+	var resp JSONRPCResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	var result InitializeResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("Unmarshal result failed: %v", err)
+	}
+	if result.ProtocolVersion != "2025-03-26" {
+		t.Errorf("Expected negotiated version 2025-03-26, got %s", result.ProtocolVersion)
+	}
+
+	// 初始化通知返回 202
+	rec = postMCP(t, h, `{"jsonrpc":"2.0","method":"notifications/initialized"}`, sessionID, "")
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Expected 202 for notification, got %d", rec.Code)
+	}
+
+	rec = postMCP(t, h, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`, sessionID, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "session_list") {
+		t.Errorf("Expected tools in response, got %s", rec.Body.String())
+	}
+}
+
+func TestHTTPSessionRequired(t *testing.T) {
+	h := newHTTPHandler("", nil, time.Hour)
+
+	rec := postMCP(t, h, `{"jsonrpc":"2.0","id":1,"method":"ping"}`, "", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without session, got %d", rec.Code)
+	}
+
+	rec = postMCP(t, h, `{"jsonrpc":"2.0","id":1,"method":"ping"}`, "unknown", "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown session, got %d", rec.Code)
+	}
+}
+
+func TestHTTPDeleteSession(t *testing.T) {
+	h := newHTTPHandler("", nil, time.Hour)
+	sessionID := postMCP(t, h, initializeBody, "", "").Header().Get(sessionHeader)
+
+	req := httptest.NewRequest(http.MethodDelete, "/mcp", nil)
+	req.Header.Set(sessionHeader, sessionID)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", rec.Code)
+	}
+
+	rec = postMCP(t, h, `{"jsonrpc":"2.0","id":1,"method":"ping"}`, sessionID, "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 after delete, got %d", rec.Code)
+	}
+}
+
+func TestHTTPSessionIdleExpiry(t *testing.T) {
+	h := newHTTPHandler("", nil, time.Minute)
+	now := time.Unix(1000, 0)
+	h.now = func() time.Time { return now }
+
+	idle := postMCP(t, h, initializeBody, "", "").Header().Get(sessionHeader)
+	now = now.Add(30 * time.Second)
+	active := postMCP(t, h, initializeBody, "", "").Header().Get(sessionHeader)
+
+	// 客户端断开后不发送 DELETE，空闲超时后会话被回收；最近使用过的会话保留
+	now = now.Add(45 * time.Second)
+	if rec := postMCP(t, h, `{"jsonrpc":"2.0","id":1,"method":"ping"}`, idle, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an expired session, got %d", rec.Code)
+	}
+	if rec := postMCP(t, h, `{"jsonrpc":"2.0","id":1,"method":"ping"}`, active, ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for an active session, got %d", rec.Code)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.sessions) != 1 {
+		t.Errorf("Expected 1 session left, got %d", len(h.sessions))
+	}
+}
+
+func TestCheckListen(t *testing.T) {
+	tests := []struct {
+		addr        string
+		token       string
+		allowUnauth bool
+		wantErr     bool
+	}{
+		{"127.0.0.1:8765", "", false, false},
+		{"localhost:8765", "", false, false},
+		{":8765", "", false, true},
+		{"0.0.0.0:8765", "", false, true},
+		{"0.0.0.0:8765", "secret", false, false},
+		{"0.0.0.0:8765", "", true, false},
+	}
+	for _, tt := range tests {
+		if err := checkListen(tt.addr, tt.token, tt.allowUnauth); (err != nil) != tt.wantErr {
+			t.Errorf("checkListen(%q, %q, %v) error = %v, wantErr %v", tt.addr, tt.token, tt.allowUnauth, err, tt.wantErr)
+		}
+	}
+}
+
+func TestHTTPBearerToken(t *testing.T) {
+	h := newHTTPHandler("secret", nil, time.Hour)
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"missing token", "", http.StatusUnauthorized},
+		{"wrong token", "nope", http.StatusUnauthorized},
+		{"valid token", "secret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postMCP(t, h, initializeBody, "", tt.token)
+			if rec.Code != tt.want {
+				t.Errorf("Expected %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestHTTPOriginCheck(t *testing.T) {
+	h := newHTTPHandler("", []string{"https://allowed.example"}, time.Hour)
+
+	tests := []struct {
+		origin string
+		want   int
+	}{
+		{"http://localhost:3000", http.StatusOK},
+		{"https://allowed.example", http.StatusOK},
+		{"https://evil.example", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(initializeBody))
+			req.Header.Set("Origin", tt.origin)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("Expected %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestHTTPBatch(t *testing.T) {
+	h := newHTTPHandler("", nil, time.Hour)
+	sessionID := postMCP(t, h, initializeBody, "", "").Header().Get(sessionHeader)
+
+	rec := postMCP(t, h, `[{"jsonrpc":"2.0","id":1,"method":"ping"},{"jsonrpc":"2.0","id":2,"method":"ping"}]`, sessionID, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	var responses []JSONRPCResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &responses); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(responses) != 2 {
+		t.Errorf("Expected 2 responses, got %d", len(responses))
+	}
+}
+
+func TestHTTPMethodNotAllowed(t *testing.T) {
+	h := newHTTPHandler("", nil, time.Hour)
+	req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", rec.Code)
+	}
+}
+
+func TestServeStdio(t *testing.T) {
+	in := strings.NewReader(initializeBody + "\n" +
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}` + "\n" +
+		"not json\n")
+	var out bytes.Buffer
+
+	if err := serveStdio(context.Background(), in, &out); err != nil {
+		t.Fatalf("serveStdio failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 responses, got %d: %v", len(lines), lines)
+	}
+	var parseErr JSONRPCResponse
+	if err := json.Unmarshal([]byte(lines[2]), &parseErr); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if parseErr.Error == nil || parseErr.Error.Code != -32700 {
+		t.Errorf("Expected parse error, got %+v", parseErr)
+	}
+}
+
+func TestHandleMessageRequiresInitialize(t *testing.T) {
+	srv := newServer()
+	resp := srv.handleMessage(context.Background(), JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"}, nil)
+	if resp == nil || resp.Error == nil || resp.Error.Code != -32000 {
+		t.Errorf("Expected not initialized error, got %+v", resp)
+	}
+}
@@ -5,25 +5,51 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
 	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
 )
 
 // Cmd MCP Server 命令
 var Cmd = &cobra.Command{
 	Use:   "mcpserver",
 	Short: "启动 MCP 服务器",
-	Long:  "以 MCP 协议启动服务器，允许外部 MCP 客户端调用 OpenCode API",
+	Long: `以 MCP 协议启动服务器，允许外部 MCP 客户端调用 OpenCode API
+
+传输方式:
+  stdio  通过 stdin/stdout 交换换行分隔的 JSON-RPC 消息（默认，适用于本地客户端）
+  http   MCP Streamable HTTP 传输，POST /mcp 发送请求，支持 SSE 响应流和会话 ID
+
+示例:
+  oho mcpserver
+  oho mcpserver --transport http --listen :8765 --token secret
+  oho mcpserver --read-only
+  oho mcpserver --allow 'session_*' --deny session_delete`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runMCPServer()
+		rootCommand = cmd.Root()
+		return runMCPServer(cmd.Context())
 	},
 }
 
+var (
+	transport      string
+	listenAddr     string
+	maxConcurrency int
+)
+
+func init() {
+	Cmd.Flags().StringVar(&transport, "transport", "stdio", "传输方式 (stdio/http)")
+	Cmd.Flags().StringVar(&listenAddr, "listen", "127.0.0.1:8765", "HTTP 传输的监听地址")
+	Cmd.Flags().IntVar(&maxConcurrency, "max-concurrency", 4, "同时执行的工具调用数上限")
+}
+
 // JSON-RPC 2.0 types
 type JSONRPCRequest struct {
 	JSONRPC string          `json:"jsonrpc"`
@@ -69,9 +95,28 @@ type InitializeResult struct {
 }
 
 type Tool struct {
-	Name        string          `json:"name"`
-	Description string          `json:"description"`
-	InputSchema json.RawMessage `json:"inputSchema"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	InputSchema json.RawMessage  `json:"inputSchema"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations 工具行为提示，客户端据此决定是否需要用户确认
+type ToolAnnotations struct {
+	ReadOnlyHint    bool `json:"readOnlyHint"`
+	DestructiveHint bool `json:"destructiveHint"`
+	IdempotentHint  bool `json:"idempotentHint"`
+	OpenWorldHint   bool `json:"openWorldHint"`
+}
+
+// readOnly 只读工具的注解
+func readOnly() *ToolAnnotations {
+	return &ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true}
+}
+
+// mutating 会修改服务器状态的工具的注解
+func mutating(destructive, idempotent bool) *ToolAnnotations {
+	return &ToolAnnotations{DestructiveHint: destructive, IdempotentHint: idempotent}
 }
 
 type ToolsListResult struct {
@@ -81,6 +126,33 @@ type ToolsListResult struct {
 type CallToolParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
+}
+
+// RequestMeta 请求元数据，客户端通过 progressToken 请求进度通知
+type RequestMeta struct {
+	ProgressToken interface{} `json:"progressToken,omitempty"`
+}
+
+// CancelledParams notifications/cancelled 通知参数
+type CancelledParams struct {
+	RequestID interface{} `json:"requestId"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
+// ProgressParams notifications/progress 通知参数
+type ProgressParams struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         float64     `json:"total,omitempty"`
+	Message       string      `json:"message,omitempty"`
+}
+
+// JSONRPCNotification 服务器发往客户端的通知
+type JSONRPCNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
 }
 
 type ToolContent struct {
@@ -93,14 +165,46 @@ type CallToolResult struct {
 	IsError bool          `json:"isError"`
 }
 
-func runMCPServer() error {
+func runMCPServer(ctx context.Context) error {
 	// 初始化配置
 	if err := config.Init(); err != nil {
-		fmt.Fprintf(os.Stderr, "警告：配置初始化失败：%v\n", err)
+		i18n.Fprintf(os.Stderr, "警告：配置初始化失败：%v\n", err)
+	}
+
+	if err := activeFilter().validate(); err != nil {
+		return err
+	}
+
+	switch transport {
+	case "stdio", "":
+		in, out := os.Stdin, os.Stdout
+		if err := detachStdio(); err != nil {
+			return err
+		}
+		return serveStdio(ctx, in, out)
+	case "http":
+		return serveHTTP(listenAddr)
+	default:
+		return i18n.Errorf("不支持的传输方式：%s (可选 stdio/http)", transport)
+	}
+}
+
+// detachStdio 在开始服务前将进程的 os.Stdin 换成空设备、os.Stdout 换成标准错误
+// stdio 传输直接使用原来的文件，进程内执行的命令即使绕过捕获读写标准输入输出，也不会破坏 JSON-RPC 通道
+func detachStdio() error {
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		return err
 	}
+	os.Stdin, os.Stdout = devNull, os.Stderr
+	return nil
+}
 
+// serveStdio 通过 stdin/stdout 以换行分隔的 JSON-RPC 消息提供服务
+func serveStdio(ctx context.Context, in io.Reader, out io.Writer) error {
 	// 创建 scanner 读取 stdin
-	scanner := bufio.NewScanner(os.Stdin)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
 
 	// 设置 scanner 分割函数 - 按行读取 JSON-RPC 消息
 	splitFunc := func(data []byte, atEOF bool) (advance int, token []byte, err error) {
@@ -118,11 +222,31 @@ func runMCPServer() error {
 	}
 	scanner.Split(splitFunc)
 
-	// 服务器状态
-	var initialized bool
-	capabilities := map[string]interface{}{
-		"tools": struct{}{},
-	}
+	srv := newServer()
+	w := &lineWriter{out: out}
+
+	// 工具调用可能耗时很长，在读取循环中同步登记后交给调度协程，避免阻塞 ping、取消等其他消息；
+	// 调度协程取得并发槽位后才启动执行协程，协程数不超过槽位数
+	queue := make(chan *pendingCall, maxQueuedCalls)
+	var wg sync.WaitGroup
+	dispatched := make(chan struct{})
+	go func() {
+		defer close(dispatched)
+		for call := range queue {
+			if !srv.acquire(call.ctx) {
+				call.done()
+				continue
+			}
+			wg.Add(1)
+			go func(call *pendingCall) {
+				defer wg.Done()
+				defer srv.release()
+				if resp := srv.execute(call, w.notify); resp != nil {
+					w.write(resp)
+				}
+			}(call)
+		}
+	}()
 
 	// 处理每条消息
 	for scanner.Scan() {
@@ -133,85 +257,299 @@ func runMCPServer() error {
 
 		var req JSONRPCRequest
 		if err := json.Unmarshal([]byte(line), &req); err != nil {
-			sendError(nil, -32700, "Parse error")
+			w.write(newError(nil, -32700, "Parse error"))
 			continue
 		}
 
-		// 处理请求
-		switch req.Method {
-		case "initialize":
-			var params InitializeParams
-			if err := json.Unmarshal(req.Params, &params); err != nil {
-				sendError(req.ID, -32600, "Invalid params")
+		if req.Method == "tools/call" {
+			call, resp := srv.prepareCall(ctx, req)
+			if resp != nil {
+				w.write(resp)
 				continue
 			}
-
-			result := InitializeResult{
-				ProtocolVersion: "2024-11-05",
-				Capabilities:    capabilities,
-				ServerInfo: ServerInfo{
-					Name:    "oho",
-					Version: "1.0.0",
-				},
+			select {
+			case queue <- call:
+			default:
+				call.done()
+				w.write(newError(req.ID, -32000, "Too many pending tool calls"))
 			}
-			sendResult(req.ID, result)
-			initialized = true
+			continue
+		}
 
-		case "notifications/initialized":
-			// 客户端初始化完成通知，不需要响应
+		if resp := srv.handleMessage(ctx, req, w.notify); resp != nil {
+			w.write(resp)
+		}
+	}
 
-		case "tools/list":
-			if !initialized {
-				sendError(req.ID, -32000, "Server not initialized")
-				continue
-			}
-			result := ToolsListResult{
-				Tools: getToolsList(),
-			}
-			sendResult(req.ID, result)
+	if err := scanner.Err(); err != nil {
+		i18n.Fprintf(os.Stderr, "读取错误: %v\n", err)
+	}
 
-		case "tools/call":
-			if !initialized {
-				sendError(req.ID, -32000, "Server not initialized")
-				continue
-			}
-			var params CallToolParams
-			if err := json.Unmarshal(req.Params, &params); err != nil {
-				sendError(req.ID, -32600, "Invalid params")
-				continue
+	// 输入结束后等待排队和进行中的工具调用完成
+	close(queue)
+	<-dispatched
+	wg.Wait()
+	return nil
+}
+
+// lineWriter 并发安全地向 stdout 写出换行分隔的消息
+type lineWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (w *lineWriter) write(msg interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	writeMessage(w.out, msg)
+}
+
+func (w *lineWriter) notify(method string, params interface{}) {
+	w.write(JSONRPCNotification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// notifier 向客户端发送通知，传输方式不支持时为 nil
+type notifier func(method string, params interface{})
+
+// server 单个 MCP 会话的状态
+type server struct {
+	mu          sync.Mutex
+	initialized bool
+	// inflight 进行中的工具调用，按请求 ID 记录取消函数
+	inflight map[string]context.CancelFunc
+	// slots 限制同时执行的工具调用数
+	slots chan struct{}
+}
+
+// maxQueuedCalls stdio 传输下等待并发槽位的工具调用数上限，超过时直接返回错误
+const maxQueuedCalls = 256
+
+func newServer() *server {
+	n := maxConcurrency
+	if n <= 0 {
+		n = 1
+	}
+	return &server{
+		inflight: make(map[string]context.CancelFunc),
+		slots:    make(chan struct{}, n),
+	}
+}
+
+func (s *server) isInitialized() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.initialized
+}
+
+// handleMessage 处理一条 JSON-RPC 消息，通知和客户端响应不需要回复时返回 nil
+func (s *server) handleMessage(ctx context.Context, req JSONRPCRequest, notify notifier) *JSONRPCResponse {
+	// 客户端发来的响应（没有 method），忽略
+	if req.Method == "" {
+		return nil
+	}
+
+	switch req.Method {
+	case "initialize":
+		var params InitializeParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return newError(req.ID, -32600, "Invalid params")
+		}
+
+		result := InitializeResult{
+			ProtocolVersion: negotiateProtocolVersion(params.ProtocolVersion),
+			Capabilities:    serverCapabilities(),
+			ServerInfo: ServerInfo{
+				Name:    "oho",
+				Version: "1.0.0",
+			},
+		}
+		s.mu.Lock()
+		s.initialized = true
+		s.mu.Unlock()
+		return newResult(req.ID, result)
+
+	case "ping":
+		return newResult(req.ID, map[string]string{"status": "pong"})
+	}
+
+	// 通知不需要响应
+	if strings.HasPrefix(req.Method, "notifications/") {
+		if req.Method == "notifications/cancelled" {
+			var params CancelledParams
+			if err := json.Unmarshal(req.Params, &params); err == nil {
+				s.cancel(params.RequestID)
 			}
-			result := handleToolCall(params.Name, params.Arguments)
-			sendResult(req.ID, result)
+		}
+		return nil
+	}
+
+	if !s.isInitialized() {
+		return newError(req.ID, -32000, "Server not initialized")
+	}
+
+	switch req.Method {
+	case "tools/list":
+		return newResult(req.ID, ToolsListResult{
+			Tools: availableTools(),
+		})
 
-		case "ping":
-			sendResult(req.ID, map[string]string{"status": "pong"})
+	case "tools/call":
+		call, resp := s.prepareCall(ctx, req)
+		if resp != nil {
+			return resp
+		}
+		if !s.acquire(call.ctx) {
+			call.done()
+			return nil
+		}
+		defer s.release()
+		return s.execute(call, notify)
+
+	case "prompts/list":
+		prompts, err := listPrompts(ctx, client.NewClient(), getPromptsDir())
+		if err != nil {
+			return newError(req.ID, -32603, err.Error())
+		}
+		return newResult(req.ID, PromptsListResult{Prompts: prompts})
 
-		default:
-			sendError(req.ID, -32601, fmt.Sprintf("Method not found: %s", req.Method))
+	case "prompts/get":
+		var params GetPromptParams
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.Name == "" {
+			return newError(req.ID, -32602, "Invalid params")
 		}
+		result, err := getPrompt(ctx, client.NewClient(), getPromptsDir(), params)
+		if err != nil {
+			return newError(req.ID, -32602, err.Error())
+		}
+		return newResult(req.ID, result)
+
+	default:
+		return newError(req.ID, -32601, fmt.Sprintf("Method not found: %s", req.Method))
 	}
+}
 
-	if err := scanner.Err(); err != nil {
-		fmt.Fprintf(os.Stderr, "读取错误: %v\n", err)
+// pendingCall 已登记、等待执行的工具调用
+type pendingCall struct {
+	id     interface{}
+	params CallToolParams
+	ctx    context.Context
+	// done 注销调用并释放 ctx
+	done func()
+}
+
+// prepareCall 解析工具调用并同步登记取消函数，之后到达的取消通知都能找到该调用
+// 调用无法执行时返回错误响应
+func (s *server) prepareCall(ctx context.Context, req JSONRPCRequest) (*pendingCall, *JSONRPCResponse) {
+	if !s.isInitialized() {
+		return nil, newError(req.ID, -32000, "Server not initialized")
+	}
+	var params CallToolParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, newError(req.ID, -32600, "Invalid params")
+	}
+	if !isToolAllowed(params.Name) {
+		return nil, newResult(req.ID, errorResult(fmt.Sprintf("Tool not allowed: %s", params.Name)))
 	}
 
-	return nil
+	ctx, cancel := context.WithCancel(ctx)
+	key := requestKey(req.ID)
+	s.mu.Lock()
+	s.inflight[key] = cancel
+	s.mu.Unlock()
+	done := func() {
+		s.mu.Lock()
+		delete(s.inflight, key)
+		s.mu.Unlock()
+		cancel()
+	}
+	return &pendingCall{id: req.ID, params: params, ctx: ctx, done: done}, nil
+}
+
+// acquire 等待并发槽位，调用在等待期间被取消时返回 false
+func (s *server) acquire(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release 释放并发槽位
+func (s *server) release() {
+	<-s.slots
+}
+
+// execute 在已取得的槽位内执行工具调用，请求被取消时不返回响应
+func (s *server) execute(call *pendingCall, notify notifier) *JSONRPCResponse {
+	defer call.done()
+	if call.ctx.Err() != nil {
+		return nil
+	}
+
+	var progress func(string)
+	if call.params.Meta != nil && call.params.Meta.ProgressToken != nil && notify != nil {
+		progress = newProgressReporter(call.params.Meta.ProgressToken, notify)
+	}
+
+	result := handleToolCall(call.ctx, call.params.Name, call.params.Arguments, progress)
+	if call.ctx.Err() != nil {
+		return nil
+	}
+	return newResult(call.id, result)
+}
+
+// cancel 取消指定请求 ID 的工具调用
+func (s *server) cancel(id interface{}) {
+	s.mu.Lock()
+	cancel, ok := s.inflight[requestKey(id)]
+	s.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// requestKey 统一 JSON-RPC 请求 ID 的表示（数字解码为 float64）
+func requestKey(id interface{}) string {
+	return fmt.Sprintf("%v", id)
+}
+
+// supportedProtocolVersions 支持的 MCP 协议版本，第一个为默认版本
+var supportedProtocolVersions = []string{"2024-11-05", "2025-03-26"}
+
+// negotiateProtocolVersion 客户端请求的版本受支持时原样返回，否则返回默认版本
+func negotiateProtocolVersion(requested string) string {
+	for _, v := range supportedProtocolVersions {
+		if v == requested {
+			return v
+		}
+	}
+	return supportedProtocolVersions[0]
+}
+
+func serverCapabilities() map[string]interface{} {
+	return map[string]interface{}{
+		"tools":   struct{}{},
+		"prompts": struct{}{},
+	}
 }
 
-func sendResult(id interface{}, result interface{}) {
-	resp := JSONRPCResponse{
+func newResult(id interface{}, result interface{}) *JSONRPCResponse {
+	resp := &JSONRPCResponse{
 		JSONRPC: "2.0",
 		ID:      id,
 	}
 	if result != nil {
 		resp.Result, _ = json.Marshal(result)
 	}
-	data, _ := json.Marshal(resp)
-	fmt.Println(string(data))
+	return resp
 }
 
-func sendError(id interface{}, code int, message string) {
-	resp := JSONRPCResponse{
+func newError(id interface{}, code int, message string) *JSONRPCResponse {
+	return &JSONRPCResponse{
 		JSONRPC: "2.0",
 		ID:      id,
 		Error: &JSONRPCError{
@@ -219,98 +557,116 @@ func sendError(id interface{}, code int, message string) {
 			Message: message,
 		},
 	}
-	data, _ := json.Marshal(resp)
-	fmt.Println(string(data))
+}
+
+// writeMessage 以单行 JSON 写出一条消息
+func writeMessage(out io.Writer, msg interface{}) {
+	data, _ := json.Marshal(msg)
+	fmt.Fprintln(out, string(data))
 }
 
 func getToolsList() []Tool {
 	return []Tool{
 		{
 			Name:        "session_list",
-			Description: "列出所有 OpenCode 会话",
+			Description: i18n.T("列出所有 OpenCode 会话"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {}, "required": []}`),
+			Annotations: readOnly(),
 		},
 		{
 			Name:        "session_create",
-			Description: "创建新的 OpenCode 会话",
+			Description: i18n.T("创建新的 OpenCode 会话"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {"title": {"type": "string"}, "path": {"type": "string"}}, "required": []}`),
+			Annotations: mutating(false, false),
 		},
 		{
 			Name:        "session_get",
-			Description: "获取指定会话的详细信息",
+			Description: i18n.T("获取指定会话的详细信息"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {"sessionId": {"type": "string"}}, "required": ["sessionId"]}`),
+			Annotations: readOnly(),
 		},
 		{
 			Name:        "session_delete",
-			Description: "删除指定会话",
+			Description: i18n.T("删除指定会话"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {"sessionId": {"type": "string"}}, "required": ["sessionId"]}`),
+			Annotations: mutating(true, true),
 		},
 		{
 			Name:        "session_status",
-			Description: "获取所有会话的状态",
+			Description: i18n.T("获取所有会话的状态"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {}, "required": []}`),
+			Annotations: readOnly(),
 		},
 		{
 			Name:        "message_list",
-			Description: "列出指定会话的所有消息",
+			Description: i18n.T("列出指定会话的所有消息"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {"sessionId": {"type": "string"}}, "required": ["sessionId"]}`),
+			Annotations: readOnly(),
 		},
 		{
 			Name:        "message_add",
-			Description: "向指定会话发送消息",
+			Description: i18n.T("向指定会话发送消息"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {"sessionId": {"type": "string"}, "content": {"type": "string"}}, "required": ["sessionId", "content"]}`),
+			Annotations: mutating(true, false),
 		},
 		{
 			Name:        "config_get",
-			Description: "获取 OpenCode 配置",
+			Description: i18n.T("获取 OpenCode 配置"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {}, "required": []}`),
+			Annotations: readOnly(),
 		},
 		{
 			Name:        "project_list",
-			Description: "列出所有项目",
+			Description: i18n.T("列出所有项目"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {}, "required": []}`),
+			Annotations: readOnly(),
 		},
 		{
 			Name:        "project_current",
-			Description: "获取当前项目",
+			Description: i18n.T("获取当前项目"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {}, "required": []}`),
+			Annotations: readOnly(),
 		},
 		{
 			Name:        "provider_list",
-			Description: "列出所有可用的 AI 提供商",
+			Description: i18n.T("列出所有可用的 AI 提供商"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {}, "required": []}`),
+			Annotations: readOnly(),
 		},
 		{
 			Name:        "file_list",
-			Description: "列出指定目录的文件",
+			Description: i18n.T("列出指定目录的文件"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {"path": {"type": "string"}}, "required": []}`),
+			Annotations: readOnly(),
 		},
 		{
 			Name:        "file_content",
-			Description: "读取指定文件的内容",
+			Description: i18n.T("读取指定文件的内容"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {"path": {"type": "string"}}, "required": ["path"]}`),
+			Annotations: readOnly(),
 		},
 		{
 			Name:        "find_text",
-			Description: "在项目中搜索文本",
+			Description: i18n.T("在项目中搜索文本"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {"pattern": {"type": "string"}}, "required": ["pattern"]}`),
+			Annotations: readOnly(),
 		},
 		{
 			Name:        "find_file",
-			Description: "根据文件名搜索文件",
+			Description: i18n.T("根据文件名搜索文件"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {"query": {"type": "string"}}, "required": ["query"]}`),
+			Annotations: readOnly(),
 		},
 		{
 			Name:        "global_health",
-			Description: "检查 OpenCode Server 健康状态",
+			Description: i18n.T("检查 OpenCode Server 健康状态"),
 			InputSchema: json.RawMessage(`{"type": "object", "properties": {}, "required": []}`),
+			Annotations: readOnly(),
 		},
 	}
 }
 
-func handleToolCall(name string, args map[string]interface{}) CallToolResult {
-	ctx := context.Background()
-
+func handleToolCall(ctx context.Context, name string, args map[string]interface{}, progress func(string)) CallToolResult {
 	switch name {
 	case "session_list":
 		return handleSessionList(ctx)
@@ -325,7 +681,7 @@ func handleToolCall(name string, args map[string]interface{}) CallToolResult {
 	case "message_list":
 		return handleMessageList(ctx, args)
 	case "message_add":
-		return handleMessageAdd(ctx, args)
+		return handleMessageAdd(ctx, args, progress)
 	case "config_get":
 		return handleConfigGet(ctx)
 	case "project_list":
@@ -345,6 +701,9 @@ func handleToolCall(name string, args map[string]interface{}) CallToolResult {
 	case "global_health":
 		return handleGlobalHealth(ctx)
 	default:
+		if g, ok := findGeneratedTool(name); ok {
+			return g.run(ctx, args)
+		}
 		return CallToolResult{
 			Content: []ToolContent{{Type: "text", Text: fmt.Sprintf("Unknown tool: %s", name)}},
 			IsError: true,
@@ -432,7 +791,7 @@ func handleMessageList(ctx context.Context, args map[string]interface{}) CallToo
 	return successResult(string(resp))
 }
 
-func handleMessageAdd(ctx context.Context, args map[string]interface{}) CallToolResult {
+func handleMessageAdd(ctx context.Context, args map[string]interface{}, progress func(string)) CallToolResult {
 	sessionID, ok := args["sessionId"].(string)
 	if !ok || sessionID == "" {
 		return errorResult("sessionId is required")
@@ -450,8 +809,26 @@ func handleMessageAdd(ctx context.Context, args map[string]interface{}) CallTool
 		},
 	}
 
+	if progress != nil {
+		watchCtx, stopWatch := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			watchSessionProgress(watchCtx, c, sessionID, progress)
+		}()
+		// 返回前确保不再发送进度通知
+		defer func() {
+			stopWatch()
+			<-done
+		}()
+	}
+
 	resp, err := c.Post(ctx, fmt.Sprintf("/session/%s/message", sessionID), req)
 	if err != nil {
+		if ctx.Err() != nil {
+			// 客户端取消了调用，同时中止 OpenCode 会话中正在进行的任务
+			abortSession(c, sessionID)
+		}
 		return errorResult(err.Error())
 	}
 	return successResult(string(resp))
@@ -0,0 +1,118 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// abortTimeout 取消调用后中止会话请求的超时时间
+const abortTimeout = 10 * time.Second
+
+// newProgressReporter 创建进度上报函数，progress 值单调递增，连续相同的消息只上报一次
+func newProgressReporter(token interface{}, notify notifier) func(string) {
+	var mu sync.Mutex
+	var count float64
+	var last string
+
+	return func(message string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if message == last {
+			return
+		}
+		last = message
+		count++
+		notify("notifications/progress", ProgressParams{
+			ProgressToken: token,
+			Progress:      count,
+			Message:       message,
+		})
+	}
+}
+
+// watchSessionProgress 订阅事件流，将指定会话的代理进度转换为进度消息，直到 ctx 取消
+func watchSessionProgress(ctx context.Context, c client.ClientInterface, sessionID string, progress func(string)) {
+	events, _, err := event.Subscribe(ctx, c, event.DefaultPath)
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "警告：订阅事件流失败：%v\n", err)
+		return
+	}
+
+	for e := range events {
+		if event.SessionID(e) != sessionID {
+			continue
+		}
+		if msg := describeEvent(e); msg != "" {
+			progress(msg)
+		}
+	}
+}
+
+// progressPart message.part.updated 事件中与进度相关的字段
+type progressPart struct {
+	Part struct {
+		Type  string `json:"type"`
+		Tool  string `json:"tool"`
+		State struct {
+			Status string `json:"status"`
+			Title  string `json:"title"`
+		} `json:"state"`
+	} `json:"part"`
+}
+
+// progressStatus session.status 事件字段
+type progressStatus struct {
+	Status types.SessionStatus `json:"status"`
+}
+
+// describeEvent 将事件转换为简短的进度描述，无需上报时返回空字符串
+func describeEvent(e types.Event) string {
+	switch e.Type {
+	case event.TypeMessagePartUpdated:
+		var p progressPart
+		if err := json.Unmarshal(e.Properties, &p); err != nil {
+			return ""
+		}
+		switch p.Part.Type {
+		case "tool":
+			if p.Part.State.Title != "" {
+				return fmt.Sprintf("%s (%s): %s", p.Part.Tool, p.Part.State.Status, p.Part.State.Title)
+			}
+			return fmt.Sprintf("%s (%s)", p.Part.Tool, p.Part.State.Status)
+		case "text":
+			return "generating response"
+		case "reasoning":
+			return "thinking"
+		case "step-start":
+			return "step started"
+		case "step-finish":
+			return "step finished"
+		}
+	case event.TypeSessionStatus:
+		var s progressStatus
+		if err := json.Unmarshal(e.Properties, &s); err == nil && s.Status.Type != "" {
+			return "session " + s.Status.Type
+		}
+	case event.TypeSessionError:
+		return "session error"
+	}
+	return ""
+}
+
+// abortSession 中止 OpenCode 会话，调用方的 ctx 已取消，因此使用独立的超时
+func abortSession(c client.ClientInterface, sessionID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+	defer cancel()
+	if _, err := c.Post(ctx, fmt.Sprintf("/session/%s/abort", sessionID), nil); err != nil {
+		i18n.Fprintf(os.Stderr, "警告：中止会话 %s 失败：%v\n", sessionID, err)
+	}
+}
@@ -0,0 +1,169 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+func TestNewProgressReporter(t *testing.T) {
+	var got []ProgressParams
+	notify := func(method string, params interface{}) {
+		if method != "notifications/progress" {
+			t.Errorf("Unexpected method: %s", method)
+		}
+		got = append(got, params.(ProgressParams))
+	}
+
+	report := newProgressReporter("tok", notify)
+	report("thinking")
+	report("thinking")
+	report("bash (running)")
+
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 notifications (duplicates dropped), got %d", len(got))
+	}
+	if got[0].Progress != 1 || got[1].Progress != 2 {
+		t.Errorf("Expected increasing progress, got %v and %v", got[0].Progress, got[1].Progress)
+	}
+	if got[1].ProgressToken != "tok" || got[1].Message != "bash (running)" {
+		t.Errorf("Unexpected notification: %+v", got[1])
+	}
+}
+
+func TestDescribeEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		event types.Event
+		want  string
+	}{
+		{
+			name:  "tool with title",
+			event: types.Event{Type: "message.part.updated", Properties: json.RawMessage(`{"part":{"type":"tool","tool":"bash","state":{"status":"running","title":"go test"}}}`)},
+			want:  "bash (running): go test",
+		},
+		{
+			name:  "text part",
+			event: types.Event{Type: "message.part.updated", Properties: json.RawMessage(`{"part":{"type":"text","text":"hi"}}`)},
+			want:  "generating response",
+		},
+		{
+			name:  "session status",
+			event: types.Event{Type: "session.status", Properties: json.RawMessage(`{"sessionID":"s1","status":{"type":"busy"}}`)},
+			want:  "session busy",
+		},
+		{
+			name:  "unrelated event",
+			event: types.Event{Type: "server.connected"},
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeEvent(tt.event); got != tt.want {
+				t.Errorf("describeEvent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCancelQueuedToolCall(t *testing.T) {
+	srv := newServer()
+	srv.initialized = true
+
+	// 占满所有并发槽位，使工具调用排队等待
+	for i := 0; i < cap(srv.slots); i++ {
+		srv.slots <- struct{}{}
+	}
+
+	params, _ := json.Marshal(CallToolParams{Name: "global_health"})
+	done := make(chan *JSONRPCResponse, 1)
+	go func() {
+		done <- srv.handleMessage(context.Background(), JSONRPCRequest{JSONRPC: "2.0", ID: float64(7), Method: "tools/call", Params: params}, nil)
+	}()
+
+	// 等待调用登记为进行中
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		srv.mu.Lock()
+		n := len(srv.inflight)
+		srv.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Tool call was not registered as in flight")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancelParams, _ := json.Marshal(CancelledParams{RequestID: 7, Reason: "user"})
+	if resp := srv.handleMessage(context.Background(), JSONRPCRequest{JSONRPC: "2.0", Method: "notifications/cancelled", Params: cancelParams}, nil); resp != nil {
+		t.Errorf("Expected no response to notification, got %+v", resp)
+	}
+
+	select {
+	case resp := <-done:
+		if resp != nil {
+			t.Errorf("Expected no response for cancelled call, got %+v", resp)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Cancelled tool call did not return")
+	}
+}
+
+func TestRequestKey(t *testing.T) {
+	if requestKey(float64(3)) != requestKey(3) {
+		t.Error("Expected numeric IDs to produce the same key")
+	}
+	if requestKey("abc") != "abc" {
+		t.Errorf("Unexpected key for string ID: %s", requestKey("abc"))
+	}
+}
+
+func TestServeStdioCancelBeforeDispatch(t *testing.T) {
+	// 健康检查一直阻塞到请求被取消，调用只能以取消结束
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	cfg := config.Get()
+	origHost, origPort := cfg.Host, cfg.Port
+	defer func() { cfg.Host, cfg.Port = origHost, origPort }()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	cfg.Host = host
+	cfg.Port, _ = strconv.Atoi(port)
+
+	// 取消通知紧跟在工具调用之后到达
+	in := strings.NewReader(initializeBody + "\n" +
+		`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"global_health"}}` + "\n" +
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7}}` + "\n")
+	var out bytes.Buffer
+
+	done := make(chan error, 1)
+	go func() { done <- serveStdio(context.Background(), in, &out) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serveStdio failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Cancelled tool call did not return")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Errorf("Expected only the initialize response, got %d: %v", len(lines), lines)
+	}
+}
@@ -0,0 +1,273 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// MCP prompt types
+type Prompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+type PromptsListResult struct {
+	Prompts []Prompt `json:"prompts"`
+}
+
+type GetPromptParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+type PromptMessage struct {
+	Role    string      `json:"role"`
+	Content ToolContent `json:"content"`
+}
+
+type GetPromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// promptTemplate 本地提示模板文件 (<配置目录>/prompts/*.json)
+//
+// 示例:
+//
+//	{
+//	  "name": "review-diff",
+//	  "description": "审查一段 diff",
+//	  "arguments": [{"name": "diff", "description": "统一 diff 文本", "required": true}],
+//	  "template": "请审查以下改动：\n{{.diff}}"
+//	}
+type promptTemplate struct {
+	Prompt
+	Template string `json:"template"`
+}
+
+// commandArgumentName 服务器斜杠命令提示的参数名
+const commandArgumentName = "arguments"
+
+// promptsDir 本地提示模板目录，为空时使用默认目录
+var promptsDir string
+
+func init() {
+	Cmd.Flags().StringVar(&promptsDir, "prompts-dir", "", "本地提示模板目录 (默认: <配置目录>/prompts)")
+}
+
+// getPromptsDir 获取本地提示模板目录
+func getPromptsDir() string {
+	if promptsDir != "" {
+		return promptsDir
+	}
+	return filepath.Join(config.Dir(), "prompts")
+}
+
+// loadLocalPrompts 读取目录下所有 .json 提示模板，目录不存在时返回空列表
+func loadLocalPrompts(dir string) ([]promptTemplate, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, i18n.Errorf("读取提示模板目录失败：%w", err)
+	}
+
+	var prompts []promptTemplate
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".json") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, i18n.Errorf("读取提示模板失败：%s: %w", path, err)
+		}
+
+		var p promptTemplate
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, i18n.Errorf("解析提示模板失败：%s: %w", path, err)
+		}
+		if p.Name == "" {
+			p.Name = strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		}
+		if _, err := template.New(p.Name).Parse(p.Template); err != nil {
+			return nil, i18n.Errorf("解析提示模板失败：%s: %w", path, err)
+		}
+		prompts = append(prompts, p)
+	}
+
+	return prompts, nil
+}
+
+// fetchCommands 获取服务器斜杠命令列表
+func fetchCommands(ctx context.Context, c client.ClientInterface) ([]types.Command, error) {
+	resp, err := c.Get(ctx, "/command")
+	if err != nil {
+		return nil, err
+	}
+
+	var commands []types.Command
+	if err := json.Unmarshal(resp, &commands); err != nil {
+		return nil, i18n.Errorf("解析命令列表失败：%w", err)
+	}
+	return commands, nil
+}
+
+// commandPrompt 将服务器斜杠命令转换为 MCP 提示
+func commandPrompt(cmd types.Command) Prompt {
+	argDesc := i18n.T("命令参数")
+	if cmd.Usage != "" {
+		argDesc = i18n.Sprintf("命令参数 (用法：%s)", cmd.Usage)
+	}
+	return Prompt{
+		Name:        cmd.Name,
+		Description: cmd.Description,
+		Arguments: []PromptArgument{
+			{Name: commandArgumentName, Description: argDesc},
+		},
+	}
+}
+
+// listPrompts 合并服务器斜杠命令和本地模板，本地模板同名时覆盖服务器命令
+func listPrompts(ctx context.Context, c client.ClientInterface, dir string) ([]Prompt, error) {
+	local, err := loadLocalPrompts(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]Prompt)
+	commands, err := fetchCommands(ctx, c)
+	if err != nil {
+		// 服务器不可用时仍然提供本地模板
+		i18n.Fprintf(os.Stderr, "警告：获取服务器命令失败：%v\n", err)
+	}
+	for _, cmd := range commands {
+		byName[cmd.Name] = commandPrompt(cmd)
+	}
+	for _, p := range local {
+		byName[p.Name] = p.Prompt
+	}
+
+	prompts := make([]Prompt, 0, len(byName))
+	for _, p := range byName {
+		prompts = append(prompts, p)
+	}
+	sort.Slice(prompts, func(i, j int) bool {
+		return prompts[i].Name < prompts[j].Name
+	})
+	return prompts, nil
+}
+
+// getPrompt 渲染指定提示
+func getPrompt(ctx context.Context, c client.ClientInterface, dir string, params GetPromptParams) (*GetPromptResult, error) {
+	local, err := loadLocalPrompts(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range local {
+		if p.Name == params.Name {
+			text, err := renderPromptTemplate(p, params.Arguments)
+			if err != nil {
+				return nil, err
+			}
+			return newPromptResult(p.Description, text), nil
+		}
+	}
+
+	commands, err := fetchCommands(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	for _, cmd := range commands {
+		if cmd.Name == params.Name {
+			text := expandCommandTemplate(cmd, params.Arguments[commandArgumentName])
+			return newPromptResult(cmd.Description, text), nil
+		}
+	}
+
+	return nil, fmt.Errorf("prompt not found: %s", params.Name)
+}
+
+// renderPromptTemplate 校验必需参数并渲染本地模板
+func renderPromptTemplate(p promptTemplate, args map[string]string) (string, error) {
+	for _, arg := range p.Arguments {
+		if arg.Required && args[arg.Name] == "" {
+			return "", fmt.Errorf("missing required argument: %s", arg.Name)
+		}
+	}
+
+	values := make(map[string]string, len(p.Arguments)+len(args))
+	for _, arg := range p.Arguments {
+		values[arg.Name] = ""
+	}
+	for k, v := range args {
+		values[k] = v
+	}
+
+	tmpl, err := template.New(p.Name).Option("missingkey=zero").Parse(p.Template)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, values); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// placeholderPattern 命令模板中的 $ARGUMENTS 和 $1..$N 占位符
+var placeholderPattern = regexp.MustCompile(`\$(ARGUMENTS|[0-9]+)`)
+
+// expandCommandTemplate 按 OpenCode 规则展开命令模板中的 $ARGUMENTS 和 $1..$N 占位符
+// 所有占位符在一次扫描中替换，参数中的 $1 等文本不会被再次替换；没有对应参数的占位符替换为空
+// 模板为空时退化为斜杠命令调用文本
+func expandCommandTemplate(cmd types.Command, arguments string) string {
+	if cmd.Template == "" {
+		return strings.TrimSpace(fmt.Sprintf("/%s %s", cmd.Name, arguments))
+	}
+
+	fields := strings.Fields(arguments)
+	return placeholderPattern.ReplaceAllStringFunc(cmd.Template, func(m string) string {
+		name := m[1:]
+		if name == "ARGUMENTS" {
+			return arguments
+		}
+		n, err := strconv.Atoi(name)
+		if err != nil || n < 1 || n > len(fields) {
+			return ""
+		}
+		return fields[n-1]
+	})
+}
+
+func newPromptResult(description, text string) *GetPromptResult {
+	return &GetPromptResult{
+		Description: description,
+		Messages: []PromptMessage{
+			{Role: "user", Content: ToolContent{Type: "text", Text: text}},
+		},
+	}
+}
@@ -0,0 +1,202 @@
+package mcpserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/testutil"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+func writePromptFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+}
+
+func TestLoadLocalPrompts(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "review.json", `{
+		"name": "review-diff",
+		"description": "Review a diff",
+		"arguments": [{"name": "diff", "required": true}],
+		"template": "Review:\n{{.diff}}"
+	}`)
+	writePromptFile(t, dir, "unnamed.json", `{"template": "hello"}`)
+	writePromptFile(t, dir, "notes.txt", "ignored")
+
+	prompts, err := loadLocalPrompts(dir)
+	if err != nil {
+		t.Fatalf("loadLocalPrompts failed: %v", err)
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("Expected 2 prompts, got %d", len(prompts))
+	}
+
+	names := map[string]bool{}
+	for _, p := range prompts {
+		names[p.Name] = true
+	}
+	if !names["review-diff"] || !names["unnamed"] {
+		t.Errorf("Unexpected prompt names: %v", names)
+	}
+}
+
+func TestLoadLocalPromptsMissingDir(t *testing.T) {
+	prompts, err := loadLocalPrompts(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatalf("Expected no error for missing dir, got %v", err)
+	}
+	if len(prompts) != 0 {
+		t.Errorf("Expected no prompts, got %d", len(prompts))
+	}
+}
+
+func TestLoadLocalPromptsInvalidTemplate(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "bad.json", `{"name": "bad", "template": "{{.diff"}`)
+
+	if _, err := loadLocalPrompts(dir); err == nil {
+		t.Error("Expected error for invalid template")
+	}
+}
+
+func TestRenderPromptTemplate(t *testing.T) {
+	p := promptTemplate{
+		Prompt: Prompt{
+			Name:      "review",
+			Arguments: []PromptArgument{{Name: "diff", Required: true}, {Name: "focus"}},
+		},
+		Template: "Review {{.diff}}{{if .focus}} focusing on {{.focus}}{{end}}",
+	}
+
+	tests := []struct {
+		name    string
+		args    map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"required only", map[string]string{"diff": "a.go"}, "Review a.go", false},
+		{"with optional", map[string]string{"diff": "a.go", "focus": "errors"}, "Review a.go focusing on errors", false},
+		{"missing required", map[string]string{}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderPromptTemplate(p, tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("renderPromptTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandCommandTemplate(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  types.Command
+		args string
+		want string
+	}{
+		{"no template", types.Command{Name: "init"}, "", "/init"},
+		{"no template with args", types.Command{Name: "test"}, "./...", "/test ./..."},
+		{"arguments placeholder", types.Command{Name: "review", Template: "Review $ARGUMENTS now"}, "main.go", "Review main.go now"},
+		{"positional placeholders", types.Command{Name: "cmp", Template: "Compare $1 with $2"}, "a.go b.go", "Compare a.go with b.go"},
+		{"missing arguments", types.Command{Name: "cmp", Template: "Compare $1 with $2 and $3."}, "a.go", "Compare a.go with  and ."},
+		{"ten or more", types.Command{Name: "many", Template: "$10-$1"}, "a b c d e f g h i j", "j-a"},
+		{"placeholders in arguments", types.Command{Name: "echo", Template: "Run $ARGUMENTS then $1"}, "$1 $2 cost", "Run $1 $2 cost then $1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := expandCommandTemplate(tt.cmd, tt.args)
+			if got != tt.want {
+				t.Errorf("expandCommandTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListPrompts(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "build.json", `{"name": "build", "description": "local build", "template": "build it"}`)
+
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			if path != "/command" {
+				t.Errorf("Unexpected path: %s", path)
+			}
+			return testutil.MockCommandsResponse(), nil
+		},
+	}
+
+	prompts, err := listPrompts(context.Background(), mock, dir)
+	if err != nil {
+		t.Fatalf("listPrompts failed: %v", err)
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("Expected 2 prompts, got %d", len(prompts))
+	}
+	// 本地模板覆盖同名服务器命令，结果按名称排序
+	if prompts[0].Name != "build" || prompts[0].Description != "local build" {
+		t.Errorf("Expected local build prompt first, got %+v", prompts[0])
+	}
+	if prompts[1].Name != "test" || len(prompts[1].Arguments) != 1 {
+		t.Errorf("Expected server test prompt with one argument, got %+v", prompts[1])
+	}
+}
+
+func TestGetPrompt(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "greet.json", `{"name": "greet", "arguments": [{"name": "who", "required": true}], "template": "Hello {{.who}}"}`)
+
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			return testutil.MockResponse([]types.Command{
+				{Name: "review", Description: "Review code", Template: "Review $ARGUMENTS"},
+			}), nil
+		},
+	}
+
+	tests := []struct {
+		name    string
+		params  GetPromptParams
+		want    string
+		wantErr bool
+	}{
+		{"local template", GetPromptParams{Name: "greet", Arguments: map[string]string{"who": "oho"}}, "Hello oho", false},
+		{"server command", GetPromptParams{Name: "review", Arguments: map[string]string{"arguments": "main.go"}}, "Review main.go", false},
+		{"unknown prompt", GetPromptParams{Name: "missing"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := getPrompt(context.Background(), mock, dir, tt.params)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(result.Messages) != 1 || result.Messages[0].Content.Text != tt.want {
+				t.Errorf("getPrompt() = %+v, want text %q", result.Messages, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package message
 
 import (
-	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -11,8 +10,12 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
-	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/guard"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/resolve"
+	"github.com/anomalyco/oho/internal/schema"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 // Cmd 消息命令
@@ -33,6 +36,7 @@ var (
 	commandArgs  []string
 	shellCommand string
 	files        []string
+	limits       guard.Limits
 )
 
 func init() {
@@ -47,6 +51,16 @@ func init() {
 	Cmd.PersistentFlags().StringVarP(&sessionID, "session", "s", "", "会话 ID")
 	_ = Cmd.MarkPersistentFlagRequired("session")
 
+	// -s 标志支持 ID 前缀、标题、@last、@current 和别名
+	Cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		id, err := resolve.Session(cmd.Context(), client.NewClient(), sessionID)
+		if err != nil {
+			return err
+		}
+		sessionID = id
+		return nil
+	}
+
 	// list 命令标志
 	listCmd.Flags().IntP("limit", "l", 0, "限制消息数量")
 
@@ -58,6 +72,7 @@ func init() {
 	addCmd.Flags().StringVar(&systemPrompt, "system", "", "系统提示")
 	addCmd.Flags().StringSliceVar(&tools, "tools", nil, "工具列表")
 	addCmd.Flags().StringSliceVar(&files, "file", nil, "附件文件路径 (可多次使用)")
+	limits.AddFlags(addCmd.Flags())
 
 	// prompt-async 命令标志
 	promptAsyncCmd.Flags().StringVar(&messageID, "message", "", "消息 ID")
@@ -76,36 +91,24 @@ func init() {
 	shellCmd.Flags().StringVar(&agent, "agent", "", "代理 ID (必需)")
 	shellCmd.Flags().StringVar(&model, "model", "", "模型 ID")
 	shellCmd.Flags().StringVar(&shellCommand, "command", "", "Shell 命令")
-}
 
-// convertModel converts a model string to the appropriate format (string or object)
-func convertModel(model string) interface{} {
-	if model == "" {
-		return nil
-	}
-
-	// Check if the model string contains a colon, which indicates provider:model format
-	if strings.Contains(model, ":") {
-		parts := strings.SplitN(model, ":", 2)
-		if len(parts) == 2 {
-			return types.Model{
-				ProviderID: parts[0],
-				ModelID:    parts[1],
-			}
-		}
-	}
-
-	// If no colon, treat as simple string model (backward compatibility)
-	return model
+	// JSON 输出 Schema
+	schema.Register("message list", []types.MessageWithParts{})
+	schema.Register("message add", types.MessageWithParts{})
+	schema.Register("message get", types.MessageWithParts{})
+	schema.Register("message prompt-async", types.ActionResult{})
+	schema.Register("message command", types.MessageWithParts{})
+	schema.Register("message shell", types.MessageWithParts{})
 }
 
 // listCmd 列出消息
 var listCmd = &cobra.Command{
-	Use:   "list",
-	Short: "列出会话中的消息",
+	Use:         "list",
+	Short:       "列出会话中的消息",
+	Annotations: map[string]string{"mcp": "readonly"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		queryParams := map[string]string{}
 		if limit := cmd.Flag("limit"); limit != nil && limit.Value.String() != "" {
@@ -119,13 +122,11 @@ var listCmd = &cobra.Command{
 
 		var messages []types.MessageWithParts
 		if err := json.Unmarshal(resp, &messages); err != nil {
-			return fmt.Errorf("解析消息列表失败：%w", err)
+			return i18n.Errorf("解析消息列表失败：%w", err)
 		}
 
-		if config.Get().JSON {
-			data, _ := json.MarshalIndent(messages, "", "  ")
-			fmt.Println(string(data))
-			return nil
+		if ok, err := util.Render(ctx, messages); ok || err != nil {
+			return err
 		}
 
 		for _, msg := range messages {
@@ -134,7 +135,7 @@ var listCmd = &cobra.Command{
 				fmt.Printf("%s\n", msg.Info.Content)
 			}
 			for _, part := range msg.Parts {
-				fmt.Printf("  └─ 部分类型：%s\n", part.Type)
+				i18n.Printf("  └─ 部分类型：%s\n", part.Type)
 			}
 			fmt.Println("---")
 		}
@@ -155,16 +156,19 @@ var addCmd = &cobra.Command{
 			if (stat.Mode() & os.ModeCharDevice) == 0 {
 				data, err := os.ReadFile("/dev/stdin")
 				if err != nil {
-					return fmt.Errorf("读取 stdin 失败：%w", err)
+					return i18n.Errorf("读取 stdin 失败：%w", err)
 				}
 				args = []string{string(data)}
 			} else {
-				return fmt.Errorf("请提供消息内容或文件，例如：oho message add -s <session> \"你好\" 或 oho message add -s <session> --file image.jpg")
+				return i18n.Errorf("请提供消息内容或文件，例如：oho message add -s <session> \"你好\" 或 oho message add -s <session> --file image.jpg")
 			}
 		}
+		if err := limits.RequireReply(noReply); err != nil {
+			return err
+		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		// 构建 parts 数组
 		var parts []types.Part
@@ -182,17 +186,17 @@ var addCmd = &cobra.Command{
 		for _, filePath := range files {
 			// 检查文件是否存在
 			if _, err := os.Stat(filePath); os.IsNotExist(err) {
-				return fmt.Errorf("文件不存在：%s", filePath)
+				return i18n.Errorf("文件不存在：%s", filePath)
 			}
 
 			// 读取文件内容
 			fileData, err := os.ReadFile(filePath)
 			if err != nil {
-				return fmt.Errorf("读取文件失败：%s: %w", filePath, err)
+				return i18n.Errorf("读取文件失败：%s: %w", filePath, err)
 			}
 
 			// 检测 MIME 类型
-			mimeType := detectMimeType(filePath)
+			mimeType := util.DetectMimeType(filePath)
 
 			// 将文件编码为 base64 data URL
 			base64Data := base64.StdEncoding.EncodeToString(fileData)
@@ -208,7 +212,7 @@ var addCmd = &cobra.Command{
 
 		req := types.MessageRequest{
 			MessageID: messageID,
-			Model:     convertModel(model),
+			Model:     util.ConvertModel(model),
 			Agent:     agent,
 			NoReply:   noReply,
 			System:    systemPrompt,
@@ -216,36 +220,42 @@ var addCmd = &cobra.Command{
 			Parts:     parts,
 		}
 
-		resp, err := c.Post(ctx, fmt.Sprintf("/session/%s/message", sessionID), req)
+		var resp []byte
+		err := guard.Send(ctx, c, sessionID, limits, func() error {
+			var err error
+			resp, err = c.Post(ctx, fmt.Sprintf("/session/%s/message", sessionID), req)
+			return err
+		})
 		if err != nil {
 			return err
 		}
 
 		// 服务器返回空响应时处理
 		if len(resp) == 0 {
-			fmt.Println("消息已发送")
+			if ok, err := util.Render(ctx, types.ActionResult{ID: messageID, Success: true}); ok || err != nil {
+				return err
+			}
+			fmt.Println(i18n.T("消息已发送"))
 			return nil
 		}
 
 		var result types.MessageWithParts
 		if err := json.Unmarshal(resp, &result); err != nil {
-			return fmt.Errorf("解析响应失败：%w", err)
+			return i18n.Errorf("解析响应失败：%w", err)
 		}
 
-		if config.Get().JSON {
-			data, _ := json.MarshalIndent(result, "", "  ")
-			fmt.Println(string(data))
-			return nil
+		if ok, err := util.Render(ctx, result); ok || err != nil {
+			return err
 		}
 
-		fmt.Printf("消息已发送:\n")
+		i18n.Printf("消息已发送:\n")
 		fmt.Printf("  ID: %s\n", result.Info.ID)
-		fmt.Printf("  角色：%s\n", result.Info.Role)
+		i18n.Printf("  角色：%s\n", result.Info.Role)
 
 		for _, part := range result.Parts {
 			fmt.Printf("\n[%s]\n", part.Type)
 			if part.Text != nil {
-				fmt.Printf("%s\n", *part.Text)
+				util.OutputMarkdown(ctx, *part.Text)
 			}
 		}
 
@@ -255,12 +265,13 @@ var addCmd = &cobra.Command{
 
 // getCmd 获取消息详情
 var getCmd = &cobra.Command{
-	Use:   "get <messageID>",
-	Short: "获取消息详情",
-	Args:  cobra.ExactArgs(1),
+	Use:         "get <messageID>",
+	Short:       "获取消息详情",
+	Annotations: map[string]string{"mcp": "readonly"},
+	Args:        cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		resp, err := c.Get(ctx, fmt.Sprintf("/session/%s/message/%s", sessionID, args[0]))
 		if err != nil {
@@ -269,28 +280,30 @@ var getCmd = &cobra.Command{
 
 		var result types.MessageWithParts
 		if err := json.Unmarshal(resp, &result); err != nil {
-			return fmt.Errorf("解析响应失败：%w", err)
+			return i18n.Errorf("解析响应失败：%w", err)
 		}
 
-		if config.Get().JSON {
-			data, _ := json.MarshalIndent(result, "", "  ")
-			fmt.Println(string(data))
-			return nil
+		if ok, err := util.Render(ctx, result); ok || err != nil {
+			return err
 		}
 
-		fmt.Printf("消息详情:\n")
+		i18n.Printf("消息详情:\n")
 		fmt.Printf("  ID: %s\n", result.Info.ID)
-		fmt.Printf("  会话：%s\n", result.Info.SessionID)
-		fmt.Printf("  角色：%s\n", result.Info.Role)
-		fmt.Printf("  时间：%d\n", result.Info.CreatedAt)
+		i18n.Printf("  会话：%s\n", result.Info.SessionID)
+		i18n.Printf("  角色：%s\n", result.Info.Role)
+		i18n.Printf("  时间：%d\n", result.Info.CreatedAt)
 
 		if result.Info.Content != "" {
-			fmt.Printf("\n内容:\n%s\n", result.Info.Content)
+			i18n.Printf("\n内容:\n")
+			util.OutputMarkdown(ctx, result.Info.Content)
 		}
 
-		fmt.Printf("\n部分 (%d 个):\n", len(result.Parts))
+		i18n.Printf("\n部分 (%d 个):\n", len(result.Parts))
 		for i, part := range result.Parts {
-			fmt.Printf("  %d. 类型：%s\n", i+1, part.Type)
+			i18n.Printf("  %d. 类型：%s\n", i+1, part.Type)
+			if part.Text != nil {
+				util.OutputMarkdown(ctx, *part.Text)
+			}
 		}
 
 		return nil
@@ -303,11 +316,11 @@ var promptAsyncCmd = &cobra.Command{
 	Short: "异步发送消息（不等待响应）",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
-			return fmt.Errorf("请提供消息内容")
+			return i18n.Errorf("请提供消息内容")
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		parts := []types.Part{
 			{
@@ -318,7 +331,7 @@ var promptAsyncCmd = &cobra.Command{
 
 		req := types.MessageRequest{
 			MessageID: messageID,
-			Model:     convertModel(model),
+			Model:     util.ConvertModel(model),
 			Agent:     agent,
 			NoReply:   false,
 			System:    systemPrompt,
@@ -331,7 +344,10 @@ var promptAsyncCmd = &cobra.Command{
 			return err
 		}
 
-		fmt.Println("消息已异步发送")
+		if ok, err := util.Render(ctx, types.ActionResult{ID: messageID, Success: true}); ok || err != nil {
+			return err
+		}
+		fmt.Println(i18n.T("消息已异步发送"))
 		return nil
 	},
 }
@@ -343,7 +359,7 @@ var commandCmd = &cobra.Command{
 	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		// 解析命令参数
 		argMap := make(map[string]string)
@@ -357,7 +373,7 @@ var commandCmd = &cobra.Command{
 		req := types.CommandRequest{
 			MessageID: messageID,
 			Agent:     agent,
-			Model:     convertModel(model),
+			Model:     util.ConvertModel(model),
 			Command:   args[0],
 			Arguments: argMap,
 		}
@@ -369,23 +385,21 @@ var commandCmd = &cobra.Command{
 
 		var result types.MessageWithParts
 		if err := json.Unmarshal(resp, &result); err != nil {
-			return fmt.Errorf("解析响应失败：%w", err)
+			return i18n.Errorf("解析响应失败：%w", err)
 		}
 
-		if config.Get().JSON {
-			data, _ := json.MarshalIndent(result, "", "  ")
-			fmt.Println(string(data))
-			return nil
+		if ok, err := util.Render(ctx, result); ok || err != nil {
+			return err
 		}
 
-		fmt.Printf("命令已执行:\n")
-		fmt.Printf("  消息 ID: %s\n", result.Info.ID)
-		fmt.Printf("  角色：%s\n", result.Info.Role)
+		i18n.Printf("命令已执行:\n")
+		i18n.Printf("  消息 ID: %s\n", result.Info.ID)
+		i18n.Printf("  角色：%s\n", result.Info.Role)
 
 		for _, part := range result.Parts {
 			fmt.Printf("\n[%s]\n", part.Type)
 			if part.Text != nil {
-				fmt.Printf("%s\n", *part.Text)
+				util.OutputMarkdown(ctx, *part.Text)
 			}
 		}
 
@@ -397,14 +411,16 @@ var commandCmd = &cobra.Command{
 var shellCmd = &cobra.Command{
 	Use:   "shell <command>",
 	Short: "运行 shell 命令",
-	Args:  cobra.MinimumNArgs(1),
+	// 在服务器的工作目录中执行任意命令
+	Annotations: map[string]string{"mcp": "destructive"},
+	Args:        cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if agent == "" {
-			return fmt.Errorf("请提供 --agent 参数")
+			return i18n.Errorf("请提供 --agent 参数")
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		// 优先使用 --command 标志，否则使用第一个位置参数
 		cmdStr := shellCommand
@@ -413,12 +429,12 @@ var shellCmd = &cobra.Command{
 		}
 
 		if cmdStr == "" {
-			return fmt.Errorf("请提供要执行的 shell 命令")
+			return i18n.Errorf("请提供要执行的 shell 命令")
 		}
 
 		req := types.ShellRequest{
 			Agent:   agent,
-			Model:   convertModel(model),
+			Model:   util.ConvertModel(model),
 			Command: cmdStr,
 		}
 
@@ -429,17 +445,15 @@ var shellCmd = &cobra.Command{
 
 		var result types.MessageWithParts
 		if err := json.Unmarshal(resp, &result); err != nil {
-			return fmt.Errorf("解析响应失败：%w", err)
+			return i18n.Errorf("解析响应失败：%w", err)
 		}
 
-		if config.Get().JSON {
-			data, _ := json.MarshalIndent(result, "", "  ")
-			fmt.Println(string(data))
-			return nil
+		if ok, err := util.Render(ctx, result); ok || err != nil {
+			return err
 		}
 
-		fmt.Printf("Shell 命令已执行:\n")
-		fmt.Printf("  消息 ID: %s\n", result.Info.ID)
+		i18n.Printf("Shell 命令已执行:\n")
+		i18n.Printf("  消息 ID: %s\n", result.Info.ID)
 
 		for _, part := range result.Parts {
 			fmt.Printf("\n[%s]\n", part.Type)
@@ -451,65 +465,3 @@ var shellCmd = &cobra.Command{
 		return nil
 	},
 }
-
-// detectMimeType 根据文件扩展名检测 MIME 类型
-func detectMimeType(filePath string) string {
-	ext := strings.ToLower(filePath[strings.LastIndex(filePath, "."):])
-
-	mimeTypes := map[string]string{
-		// 图片
-		".jpg":  "image/jpeg",
-		".jpeg": "image/jpeg",
-		".png":  "image/png",
-		".gif":  "image/gif",
-		".webp": "image/webp",
-		".bmp":  "image/bmp",
-		".svg":  "image/svg+xml",
-
-		// 文档
-		".pdf":  "application/pdf",
-		".doc":  "application/msword",
-		".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
-		".xls":  "application/vnd.ms-excel",
-		".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
-		".ppt":  "application/vnd.ms-powerpoint",
-		".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
-
-		// 文本
-		".txt":  "text/plain",
-		".md":   "text/markdown",
-		".html": "text/html",
-		".css":  "text/css",
-		".js":   "application/javascript",
-		".json": "application/json",
-		".xml":  "application/xml",
-		".yaml": "application/x-yaml",
-		".yml":  "application/x-yaml",
-
-		// 代码
-		".py":   "text/x-python",
-		".go":   "text/x-go",
-		".java": "text/x-java",
-		".c":    "text/x-c",
-		".cpp":  "text/x-c++",
-		".h":    "text/x-c",
-		".rs":   "text/x-rust",
-		".ts":   "text/x-typescript",
-		".tsx":  "text/x-typescript",
-
-		// 其他
-		".zip": "application/zip",
-		".tar": "application/x-tar",
-		".gz":  "application/gzip",
-		".mp3": "audio/mpeg",
-		".mp4": "video/mp4",
-		".wav": "audio/wav",
-	}
-
-	if mimeType, ok := mimeTypes[ext]; ok {
-		return mimeType
-	}
-
-	// 默认返回 octet-stream
-	return "application/octet-stream"
-}
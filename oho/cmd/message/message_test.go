@@ -10,6 +10,7 @@ import (
 	"github.com/anomalyco/oho/internal/config"
 	"github.com/anomalyco/oho/internal/testutil"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 func TestMain(m *testing.M) {
@@ -115,7 +116,7 @@ func TestMessageAddCmd(t *testing.T) {
 			}
 
 			req := types.MessageRequest{
-				Model:   convertModel(tt.model),
+				Model:   util.ConvertModel(tt.model),
 				Agent:   tt.agent,
 				NoReply: tt.noReply,
 				Parts:   parts,
@@ -0,0 +1,166 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/cache"
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/hook"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/permission"
+	"github.com/anomalyco/oho/internal/schema"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+var (
+	configFile string
+	retryDelay time.Duration
+)
+
+func init() {
+	Cmd.Flags().StringVar(&configFile, "config", "", "配置文件 (YAML)")
+	Cmd.Flags().DurationVar(&retryDelay, "retry", 3*time.Second, "事件流断开后的重连间隔")
+	_ = Cmd.MarkFlagRequired("config")
+
+	schema.Register("notify", types.HookPayload{})
+}
+
+// Cmd 通知命令
+var Cmd = &cobra.Command{
+	Use:   "notify",
+	Short: "会话完成、出错或请求权限时执行钩子",
+	Long: `订阅事件流，服务器上任意会话的一次运行完成、出错或请求权限时，执行配置文件中的钩子。
+
+一次运行从会话开始工作到空闲为止。子代理创建的子会话的权限请求计入根会话，子会话的完成不单独通知。
+启动时已在运行的会话从启动时开始计算，已在等待响应的权限请求在启动时通知。
+
+钩子可以是本地命令或 webhook URL（以 http:// 或 https:// 开头）：
+命令通过 shell 执行，会话信息通过 OHO_EVENT、OHO_SESSION_ID、OHO_SESSION_TITLE、
+OHO_SESSION_DIRECTORY、OHO_DURATION_MS、OHO_ERROR 和 OHO_PERMISSION_* 环境变量传入，
+标准输入为 JSON 格式的事件；webhook 以 POST 请求发送同样的 JSON。
+
+每个事件输出一行记录，-o json 或 -o jsonl 时输出 JSON。
+
+配置示例:
+  on_complete: notify-send "oho" "$OHO_SESSION_TITLE 已完成"
+  on_error:
+    - https://hooks.example.com/oho
+  on_permission: https://hooks.example.com/oho
+  min_duration: 2m   # 运行时长短于该值的完成不通知
+
+示例:
+  oho notify --config notify.yaml
+  oho notify --config notify.yaml -o jsonl >> notify.jsonl`,
+	// 长时间运行的命令，不暴露为 MCP 工具
+	Annotations: map[string]string{"mcp": "skip"},
+	Args:        cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := hook.LoadConfig(configFile)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		c := client.NewClient()
+		n := hook.NewNotifier()
+		pending, err := load(ctx, c, n, time.Now())
+		if err != nil {
+			return err
+		}
+		i18n.Fprintf(os.Stderr, "通知已启动（%d 个会话正在运行）\n", len(n.Running()))
+		for _, p := range pending {
+			if err := fire(ctx, cfg, p); err != nil {
+				return err
+			}
+		}
+		return run(ctx, c, cfg, n)
+	},
+}
+
+// load 记录会话和已在运行的会话，返回已在等待响应的权限请求的通知
+func load(ctx context.Context, c client.ClientInterface, n *hook.Notifier, now time.Time) ([]types.HookPayload, error) {
+	sessions, err := cache.Fetch(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	n.AddSessions(sessions)
+
+	resp, err := c.Get(ctx, "/session/status")
+	if err != nil {
+		return nil, err
+	}
+	var statuses map[string]types.SessionStatus
+	if err := json.Unmarshal(resp, &statuses); err != nil {
+		return nil, i18n.Errorf("解析会话状态失败：%w", err)
+	}
+	for id, status := range statuses {
+		if status.Working() {
+			n.Start(id, now)
+		}
+	}
+
+	// 获取待处理的权限请求失败时不影响启动，之后的请求从事件流获得
+	var pending []types.HookPayload
+	if requests, err := permission.Fetch(ctx, c); err == nil {
+		for _, r := range requests {
+			if p, ok := n.Permission(r, now); ok {
+				pending = append(pending, p)
+			}
+		}
+	}
+	return pending, nil
+}
+
+// run 监听事件流并执行钩子，直到 ctx 取消
+func run(ctx context.Context, c client.ClientInterface, cfg *hook.Config, n *hook.Notifier) error {
+	events := event.Watch(ctx, c, event.DefaultPath, retryDelay, func(err error) {
+		i18n.Fprintf(os.Stderr, "事件流断开：%v，%s 后重连\n", err, retryDelay)
+	})
+	for e := range events {
+		for _, p := range n.Handle(e, time.Now()) {
+			if err := fire(ctx, cfg, p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fire 执行事件的钩子并输出一行记录，钩子失败只记录不退出
+func fire(ctx context.Context, cfg *hook.Config, p types.HookPayload) error {
+	if cfg.Skip(p.Event, time.Duration(p.Duration)*time.Millisecond) {
+		return nil
+	}
+	hookErr := cfg.Fire(ctx, p)
+	if ok, err := util.Render(ctx, p); ok || err != nil {
+		hook.Report(hookErr)
+		return err
+	}
+
+	at := time.UnixMilli(p.Time).Format("15:04:05")
+	switch p.Event {
+	case hook.EventPermission:
+		i18n.Printf("%s ? %s 请求权限：[%s] %s\n", at, p.SessionID, p.Permission.Tool, p.Permission.Summary)
+	case hook.EventError:
+		i18n.Printf("%s ✗ %s 出错：%s\n", at, p.SessionID, p.Error)
+	default:
+		i18n.Printf("%s ✓ %s 已完成（%s）\n", at, p.SessionID, (time.Duration(p.Duration) * time.Millisecond).Round(time.Second))
+	}
+	if p.Title != "" {
+		fmt.Printf("  %s\n", p.Title)
+	}
+	hook.Report(hookErr)
+	return nil
+}
@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/hook"
+)
+
+func TestLoad(t *testing.T) {
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			switch path {
+			case "/session":
+				return []byte(`[{"id":"ses_a","title":"Build"},{"id":"ses_b","parentID":"ses_a"},{"id":"ses_c"},{"id":"ses_d"}]`), nil
+			case "/session/status":
+				return []byte(`{"ses_b":{"type":"busy"},"ses_c":{"type":"idle"},"ses_d":{"isWorking":true}}`), nil
+			case "/permission":
+				return []byte(`[{"id":"per_1","sessionID":"ses_b","permission":"edit"}]`), nil
+			}
+			t.Errorf("Unexpected path: %s", path)
+			return nil, nil
+		},
+	}
+	n := hook.NewNotifier()
+	pending, err := load(context.Background(), mock, n, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 运行中的子会话按根会话跟踪，已有的权限请求按根会话通知
+	if running := n.Running(); len(running) != 2 || running[0] != "ses_a" || running[1] != "ses_d" {
+		t.Errorf("Running() = %v", running)
+	}
+	if len(pending) != 1 || pending[0].SessionID != "ses_a" || pending[0].Title != "Build" || pending[0].Permission.ID != "per_1" {
+		t.Errorf("pending = %+v", pending)
+	}
+}
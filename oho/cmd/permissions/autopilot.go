@@ -0,0 +1,202 @@
+package permissions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/permission"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+var (
+	policyFile   string
+	auditLogFile string
+	dryRun       bool
+	retryDelay   time.Duration
+)
+
+func init() {
+	autopilotCmd.Flags().StringVar(&policyFile, "policy", "", "策略文件 (YAML)")
+	autopilotCmd.Flags().StringVar(&auditLogFile, "audit-log", "", "审计日志文件 (默认 <配置目录>/permissions-audit.jsonl)")
+	autopilotCmd.Flags().BoolVar(&dryRun, "dry-run", false, "只记录决定，不响应请求")
+	autopilotCmd.Flags().DurationVar(&retryDelay, "retry", 3*time.Second, "事件流断开后的重连间隔")
+	_ = autopilotCmd.MarkFlagRequired("policy")
+
+	Cmd.AddCommand(autopilotCmd)
+}
+
+var autopilotCmd = &cobra.Command{
+	Use:   "autopilot",
+	Short: "根据策略自动响应权限请求",
+	Long: `订阅事件流，根据策略文件自动响应权限请求。
+
+规则按顺序匹配，第一条命中的规则生效。规则的所有条件都满足才算命中：
+  tool       工具名（通配符）
+  paths      文件路径 glob 列表，请求中的每个路径都必须匹配（** 匹配任意层级）；
+             路径先按会话目录规范化，用 .. 越出根目录的路径不匹配任何规则
+  command    shell 命令正则，应匹配整条命令并排除 ; & | $ 和反引号，避免命令拼接绕过
+  directory  会话目录 glob
+action 可以是 allow、always、deny 或 escalate。
+没有规则命中时使用 default（escalate 或 deny，默认 escalate）。
+escalate 的请求保持待处理，交给人工通过 oho permissions review 处理。
+
+每个决定都会以 JSON Lines 格式写入审计日志。
+
+策略示例:
+  default: escalate
+  rules:
+    - name: read-repo
+      tool: read
+      paths: ["/work/repo/**"]
+      action: allow
+    - name: safe-shell
+      tool: bash
+      command: '^(go (test|build|vet)|git (status|diff))( [^;&|$\x60<>\n]*)?$'
+      action: allow
+    - name: no-push
+      tool: bash
+      command: 'git push'
+      action: deny
+
+示例:
+  oho permissions autopilot --policy policy.yaml
+  oho permissions autopilot --policy policy.yaml -s ses_123 --dry-run`,
+	// 长时间运行的命令，不暴露为 MCP 工具
+	Annotations: map[string]string{"mcp": "skip"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policy, err := permission.LoadPolicy(policyFile)
+		if err != nil {
+			return err
+		}
+
+		logFile := auditLogFile
+		if logFile == "" {
+			logFile = filepath.Join(config.Dir(), "permissions-audit.jsonl")
+		}
+		audit, err := permission.OpenAuditLog(logFile)
+		if err != nil {
+			return i18n.Errorf("打开审计日志失败：%w", err)
+		}
+		defer audit.Close()
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		i18n.Fprintf(os.Stderr, "自动审批已启动（%d 条规则，审计日志：%s）\n", len(policy.Rules), logFile)
+
+		a := newAutopilot(client.NewClient(), policy, audit, os.Stderr)
+		a.run(ctx)
+		return nil
+	},
+}
+
+// autopilot 自动审批器
+type autopilot struct {
+	client client.ClientInterface
+	policy *permission.Policy
+	audit  *permission.AuditLog
+	out    io.Writer
+
+	handled map[string]bool
+	dirs    map[string]string
+}
+
+func newAutopilot(c client.ClientInterface, policy *permission.Policy, audit *permission.AuditLog, out io.Writer) *autopilot {
+	return &autopilot{
+		client:  c,
+		policy:  policy,
+		audit:   audit,
+		out:     out,
+		handled: make(map[string]bool),
+		dirs:    make(map[string]string),
+	}
+}
+
+// run 处理已有的待处理请求，然后持续监听新请求，直到 ctx 取消
+func (a *autopilot) run(ctx context.Context) {
+	if requests, err := permission.Fetch(ctx, a.client); err == nil {
+		for _, r := range requests {
+			a.handle(ctx, r)
+		}
+	}
+
+	events := event.Watch(ctx, a.client, event.DefaultPath, retryDelay, func(err error) {
+		i18n.Fprintf(a.out, "事件流断开：%v，%s 后重连\n", err, retryDelay)
+	})
+	for e := range events {
+		if r, ok := permission.FromEvent(e); ok {
+			a.handle(ctx, r)
+		}
+	}
+}
+
+// handle 对单个请求做出决定并记录
+func (a *autopilot) handle(ctx context.Context, r permission.Request) {
+	if a.handled[r.ID] || (sessionID != "" && r.SessionID != sessionID) {
+		return
+	}
+	a.handled[r.ID] = true
+
+	dir := a.directory(ctx, r.SessionID)
+	decision := a.policy.Decide(r, dir)
+
+	entry := permission.AuditEntry{
+		RequestID: r.ID,
+		SessionID: r.SessionID,
+		Tool:      r.Tool,
+		Command:   r.Command,
+		Paths:     r.Paths,
+		Directory: dir,
+		Decision:  decision,
+		DryRun:    dryRun,
+	}
+
+	if decision.Response == "" {
+		i18n.Fprintf(a.out, "⚠ 需要人工处理：%s [%s] %s（会话 %s）\n", r.ID, r.Tool, r.Summary(), r.SessionID)
+	} else if !dryRun {
+		if err := permission.Respond(ctx, a.client, r, decision.Response); err != nil {
+			entry.Error = err.Error()
+			i18n.Fprintf(a.out, "✗ 响应 %s 失败：%v\n", r.ID, err)
+		} else {
+			fmt.Fprintf(a.out, "✓ %s %s [%s] %s\n", decision.Response, r.ID, r.Tool, r.Summary())
+		}
+	} else {
+		fmt.Fprintf(a.out, "(dry-run) %s %s [%s] %s\n", decision.Response, r.ID, r.Tool, r.Summary())
+	}
+
+	if err := a.audit.Record(entry); err != nil {
+		i18n.Fprintf(a.out, "警告：写入审计日志失败：%v\n", err)
+	}
+}
+
+// directory 获取会话目录，结果会缓存
+func (a *autopilot) directory(ctx context.Context, id string) string {
+	if dir, ok := a.dirs[id]; ok {
+		return dir
+	}
+
+	resp, err := a.client.Get(ctx, fmt.Sprintf("/session/%s", id))
+	if err != nil {
+		return ""
+	}
+	var s types.Session
+	if err := json.Unmarshal(resp, &s); err != nil {
+		return ""
+	}
+	a.dirs[id] = strings.TrimRight(s.Directory, "/")
+	return a.dirs[id]
+}
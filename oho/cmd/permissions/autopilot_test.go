@@ -0,0 +1,95 @@
+package permissions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/permission"
+)
+
+func TestAutopilotHandle(t *testing.T) {
+	policy, err := permission.ParsePolicy([]byte(`
+rules:
+  - tool: bash
+    command: '^go test'
+    directory: /work/**
+    action: allow
+  - tool: bash
+    command: 'rm -rf'
+    action: deny
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var posted []string
+	sessionGets := 0
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			sessionGets++
+			return []byte(`{"id":"ses_1","directory":"/work/app"}`), nil
+		},
+		PostFunc: func(ctx context.Context, path string, body interface{}) ([]byte, error) {
+			posted = append(posted, path+"="+body.(map[string]interface{})["response"].(string))
+			return []byte("true"), nil
+		},
+	}
+
+	var auditBuf, out bytes.Buffer
+	a := newAutopilot(mock, policy, permission.NewAuditLog(&auditBuf), &out)
+
+	ctx := context.Background()
+	a.handle(ctx, permission.Request{ID: "per_1", SessionID: "ses_1", Tool: "bash", Command: "go test ./..."})
+	a.handle(ctx, permission.Request{ID: "per_1", SessionID: "ses_1", Tool: "bash", Command: "go test ./..."})
+	a.handle(ctx, permission.Request{ID: "per_2", SessionID: "ses_1", Tool: "bash", Command: "rm -rf /"})
+	a.handle(ctx, permission.Request{ID: "per_3", SessionID: "ses_1", Tool: "edit", Paths: []string{"main.go"}})
+
+	want := []string{"/session/ses_1/permissions/per_1=once", "/session/ses_1/permissions/per_2=reject"}
+	if strings.Join(posted, ",") != strings.Join(want, ",") {
+		t.Errorf("Posted = %v, want %v", posted, want)
+	}
+	if sessionGets != 1 {
+		t.Errorf("Expected session directory to be cached, got %d lookups", sessionGets)
+	}
+	if !strings.Contains(out.String(), "per_3") {
+		t.Errorf("Expected escalation notice for per_3:\n%s", out.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(auditBuf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 audit entries, got %d", len(lines))
+	}
+	var last permission.AuditEntry
+	if err := json.Unmarshal([]byte(lines[2]), &last); err != nil {
+		t.Fatalf("Invalid audit entry: %v", err)
+	}
+	if last.RequestID != "per_3" || last.Action != permission.ActionEscalate || last.Directory != "/work/app" {
+		t.Errorf("Unexpected audit entry: %+v", last)
+	}
+}
+
+func TestAutopilotDryRun(t *testing.T) {
+	orig := dryRun
+	defer func() { dryRun = orig }()
+	dryRun = true
+
+	policy, _ := permission.ParsePolicy([]byte("rules:\n  - tool: read\n    action: allow\n"))
+	mock := &client.MockClient{
+		PostFunc: func(ctx context.Context, path string, body interface{}) ([]byte, error) {
+			t.Errorf("Unexpected response in dry-run mode: %s", path)
+			return []byte("true"), nil
+		},
+	}
+
+	var auditBuf, out bytes.Buffer
+	a := newAutopilot(mock, policy, permission.NewAuditLog(&auditBuf), &out)
+	a.handle(context.Background(), permission.Request{ID: "per_1", SessionID: "ses_1", Tool: "read"})
+
+	if !strings.Contains(auditBuf.String(), `"dryRun":true`) {
+		t.Errorf("Expected dry-run audit entry, got %s", auditBuf.String())
+	}
+}
@@ -0,0 +1,251 @@
+package permissions
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/permission"
+	"github.com/anomalyco/oho/internal/resolve"
+	"github.com/anomalyco/oho/internal/schema"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// Cmd 权限请求命令
+var Cmd = &cobra.Command{
+	Use:   "permissions",
+	Short: "权限请求收件箱",
+	Long: `查看和响应代理发起的权限请求。
+
+待处理的请求来自服务器的权限列表以及事件流中的 permission 事件，
+命令会在 --wait 指定的时间内监听事件流以收集新的请求。
+
+示例:
+  oho permissions list
+  oho permissions list -s ses_123 --wait 10s
+  oho permissions show per_456
+  oho permissions review`,
+}
+
+var (
+	sessionID string
+	waitTime  time.Duration
+)
+
+func init() {
+	Cmd.PersistentFlags().StringVarP(&sessionID, "session", "s", "", "只处理指定会话的请求")
+	Cmd.PersistentFlags().DurationVar(&waitTime, "wait", 2*time.Second, "监听事件流收集请求的时间")
+
+	Cmd.AddCommand(listCmd, showCmd, reviewCmd)
+
+	// -s 标志支持 ID 前缀、标题、@last、@current 和别名
+	Cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		id, err := resolve.Session(cmd.Context(), client.NewClient(), sessionID)
+		if err != nil {
+			return err
+		}
+		sessionID = id
+		return nil
+	}
+
+	// JSON 输出 Schema
+	schema.Register("permissions list", []permission.Request{})
+	schema.Register("permissions show", permission.Request{})
+}
+
+var listCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "列出待处理的权限请求",
+	Annotations: map[string]string{"mcp": "readonly"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		inbox, err := collect(ctx, client.NewClient(), waitTime, nil)
+		if err != nil {
+			return err
+		}
+		requests := inbox.Pending(sessionID)
+
+		if ok, err := util.Render(ctx, requests); ok || err != nil {
+			return err
+		}
+
+		if len(requests) == 0 {
+			fmt.Println(i18n.T("没有待处理的权限请求"))
+			return nil
+		}
+
+		rows := make([][]string, 0, len(requests))
+		for _, r := range requests {
+			rows = append(rows, []string{r.ID, r.SessionID, r.Tool, util.Truncate(r.Summary(), 60)})
+		}
+		util.OutputTable(ctx, []string{"ID", i18n.T("会话"), i18n.T("工具"), i18n.T("内容")}, rows)
+		return nil
+	},
+}
+
+var showCmd = &cobra.Command{
+	Use:         "show <id>",
+	Short:       "查看权限请求详情",
+	Annotations: map[string]string{"mcp": "readonly"},
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		id := args[0]
+		inbox, err := collect(ctx, client.NewClient(), waitTime, func(b *permission.Inbox) bool {
+			_, ok := b.Get(id)
+			return ok
+		})
+		if err != nil {
+			return err
+		}
+
+		r, ok := inbox.Get(id)
+		if !ok {
+			return i18n.Errorf("未找到待处理的权限请求：%s", id)
+		}
+
+		if ok, err := util.Render(ctx, r); ok || err != nil {
+			return err
+		}
+
+		printRequest(os.Stdout, r)
+		return nil
+	},
+}
+
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "逐个审核待处理的权限请求",
+	Long: `逐个显示待处理的权限请求并提示响应：
+
+  a / allow   允许本次
+  w / always  始终允许
+  d / deny    拒绝
+  s / 回车     跳过
+  q           退出`,
+	// 交互式命令，不暴露为 MCP 工具
+	Annotations: map[string]string{"mcp": "skip"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := client.NewClient()
+		ctx := cmd.Context()
+
+		inbox, err := collect(ctx, c, waitTime, nil)
+		if err != nil {
+			return err
+		}
+
+		requests := inbox.Pending(sessionID)
+		if len(requests) == 0 {
+			fmt.Println(i18n.T("没有待处理的权限请求"))
+			return nil
+		}
+		return review(ctx, c, requests, os.Stdin, os.Stdout)
+	},
+}
+
+// collect 收集待处理请求：先查询服务器，再在 wait 时间内监听事件流
+// done 非空且返回 true 时提前结束监听
+func collect(ctx context.Context, c client.ClientInterface, wait time.Duration, done func(*permission.Inbox) bool) (*permission.Inbox, error) {
+	inbox := permission.NewInbox()
+
+	requests, fetchErr := permission.Fetch(ctx, c)
+	for _, r := range requests {
+		inbox.Add(r)
+	}
+	if done != nil && done(inbox) {
+		return inbox, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	events, _, err := event.Subscribe(ctx, c, event.DefaultPath)
+	if err != nil {
+		if fetchErr != nil {
+			return nil, err
+		}
+		return inbox, nil
+	}
+
+	for e := range events {
+		inbox.Apply(e)
+		if done != nil && done(inbox) {
+			break
+		}
+	}
+	return inbox, nil
+}
+
+// printRequest 输出请求详情
+func printRequest(out io.Writer, r permission.Request) {
+	i18n.Fprintf(out, "ID:     %s\n", r.ID)
+	i18n.Fprintf(out, "会话：   %s\n", r.SessionID)
+	i18n.Fprintf(out, "工具：   %s\n", r.Tool)
+	if r.Title != "" {
+		i18n.Fprintf(out, "标题：   %s\n", r.Title)
+	}
+	if r.Command != "" {
+		i18n.Fprintf(out, "命令：   %s\n", r.Command)
+	}
+	for _, p := range r.Paths {
+		i18n.Fprintf(out, "路径：   %s\n", p)
+	}
+	if len(r.Patterns) > 0 && r.Command == "" && len(r.Paths) == 0 {
+		i18n.Fprintf(out, "模式：   %s\n", strings.Join(r.Patterns, ", "))
+	}
+	if r.Created > 0 {
+		i18n.Fprintf(out, "时间：   %s\n", time.UnixMilli(r.Created).Format("2006-01-02 15:04:05"))
+	}
+}
+
+// review 逐个提示用户响应请求
+func review(ctx context.Context, c client.ClientInterface, requests []permission.Request, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+
+	for i, r := range requests {
+		fmt.Fprintf(out, "\n[%d/%d]\n", i+1, len(requests))
+		printRequest(out, r)
+
+		for {
+			fmt.Fprint(out, i18n.T("响应 [a]llow / al[w]ays / [d]eny / [s]kip / [q]uit: "))
+			line, err := reader.ReadString('\n')
+			if err != nil && line == "" {
+				if err == io.EOF {
+					fmt.Fprintln(out)
+					return nil
+				}
+				return err
+			}
+
+			answer := strings.ToLower(strings.TrimSpace(line))
+			if answer == "" || answer == "s" || answer == "skip" {
+				fmt.Fprintln(out, i18n.T("已跳过"))
+				break
+			}
+			if answer == "q" || answer == "quit" {
+				return nil
+			}
+
+			response, err := permission.ParseResponse(answer)
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			if err := permission.Respond(ctx, c, r, response); err != nil {
+				return i18n.Errorf("响应权限请求 %s 失败：%w", r.ID, err)
+			}
+			i18n.Fprintf(out, "已响应：%s\n", response)
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package permissions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/permission"
+)
+
+func TestMain(m *testing.M) {
+	os.Setenv("OPENCODE_SERVER_HOST", "127.0.0.1")
+	os.Setenv("OPENCODE_SERVER_PORT", "4096")
+	os.Setenv("OPENCODE_SERVER_USERNAME", "opencode")
+	os.Setenv("OPENCODE_SERVER_PASSWORD", "test")
+	_ = config.Init()
+
+	m.Run()
+}
+
+// sseMock 返回一个依次推送给定 SSE 数据块的事件流
+func sseMock(fetch string, chunks ...string) *client.MockClient {
+	return &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			if fetch == "" {
+				return nil, errors.New("not found")
+			}
+			return []byte(fetch), nil
+		},
+		SSEStreamFunc: func(ctx context.Context, path string) (<-chan []byte, <-chan error, error) {
+			out := make(chan []byte)
+			errs := make(chan error)
+			go func() {
+				defer close(out)
+				defer close(errs)
+				for _, c := range chunks {
+					select {
+					case out <- []byte(c):
+					case <-ctx.Done():
+						return
+					}
+				}
+				<-ctx.Done()
+			}()
+			return out, errs, nil
+		},
+	}
+}
+
+func TestCollect(t *testing.T) {
+	mock := sseMock(
+		`[{"id":"per_1","sessionID":"ses_1","permission":"bash","patterns":["ls"]}]`,
+		"data: {\"type\":\"permission.updated\",\"properties\":{\"id\":\"per_2\",\"type\":\"edit\",\"sessionID\":\"ses_2\"}}\n\n",
+		"data: {\"type\":\"permission.replied\",\"properties\":{\"sessionID\":\"ses_1\",\"permissionID\":\"per_1\"}}\n\n",
+	)
+
+	inbox, err := collect(context.Background(), mock, 200*time.Millisecond, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	pending := inbox.Pending("")
+	if len(pending) != 1 || pending[0].ID != "per_2" {
+		t.Errorf("Expected only per_2 pending, got %+v", pending)
+	}
+}
+
+func TestCollectStopsEarly(t *testing.T) {
+	mock := sseMock("", "data: {\"type\":\"permission.asked\",\"properties\":{\"id\":\"per_9\",\"permission\":\"bash\",\"sessionID\":\"ses_1\"}}\n\n")
+
+	start := time.Now()
+	inbox, err := collect(context.Background(), mock, 5*time.Second, func(b *permission.Inbox) bool {
+		_, ok := b.Get("per_9")
+		return ok
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := inbox.Get("per_9"); !ok {
+		t.Error("Expected per_9 to be collected")
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Error("Expected collect to stop once the request was found")
+	}
+}
+
+func TestReview(t *testing.T) {
+	var responses []string
+	mock := &client.MockClient{
+		PostFunc: func(ctx context.Context, path string, body interface{}) ([]byte, error) {
+			responses = append(responses, path+"="+body.(map[string]interface{})["response"].(string))
+			return []byte("true"), nil
+		},
+	}
+
+	requests := []permission.Request{
+		{ID: "per_1", SessionID: "ses_1", Tool: "bash", Command: "rm -rf build"},
+		{ID: "per_2", SessionID: "ses_1", Tool: "edit", Paths: []string{"main.go"}},
+		{ID: "per_3", SessionID: "ses_1", Tool: "read", Paths: []string{"go.mod"}},
+		{ID: "per_4", SessionID: "ses_1", Tool: "webfetch", Title: "fetch"},
+	}
+
+	var out bytes.Buffer
+	in := strings.NewReader("maybe\nd\n\nalways\nq\n")
+	if err := review(context.Background(), mock, requests, in, &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{
+		"/session/ses_1/permissions/per_1=reject",
+		"/session/ses_1/permissions/per_3=always",
+	}
+	if strings.Join(responses, ",") != strings.Join(want, ",") {
+		t.Errorf("Responses = %v, want %v", responses, want)
+	}
+	if !strings.Contains(out.String(), "rm -rf build") || !strings.Contains(out.String(), "main.go") {
+		t.Errorf("Expected command and path in output:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "无效的响应") {
+		t.Errorf("Expected invalid response message:\n%s", out.String())
+	}
+}
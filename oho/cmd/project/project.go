@@ -8,8 +8,10 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
-	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
+ "github.com/anomalyco/oho/internal/schema"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 // Cmd 项目命令
@@ -23,9 +25,10 @@ var (
  listCmd = &cobra.Command{
   Use:   "list",
   Short: "列出所有项目",
+  Annotations: map[string]string{"mcp": "readonly"},
   RunE: func(cmd *cobra.Command, args []string) error {
    c := client.NewClient()
-   ctx := context.Background()
+   ctx := cmd.Context()
 
    resp, err := c.Get(ctx, "/project")
    if err != nil {
@@ -37,16 +40,17 @@ var (
     return err
    }
 
-   return outputProjects(projects)
+   return outputProjects(ctx, projects)
   },
  }
 
  currentCmd = &cobra.Command{
   Use:   "current",
   Short: "获取当前项目",
+  Annotations: map[string]string{"mcp": "readonly"},
   RunE: func(cmd *cobra.Command, args []string) error {
    c := client.NewClient()
-   ctx := context.Background()
+   ctx := cmd.Context()
 
    resp, err := c.Get(ctx, "/project/current")
    if err != nil {
@@ -58,7 +62,7 @@ var (
     return err
    }
 
-   return outputProjects([]types.Project{project})
+   return outputProjects(ctx, []types.Project{project})
   },
  }
 
@@ -67,7 +71,7 @@ var (
   Short: "获取当前路径",
   RunE: func(cmd *cobra.Command, args []string) error {
    c := client.NewClient()
-   ctx := context.Background()
+   ctx := cmd.Context()
 
    resp, err := c.Get(ctx, "/path")
    if err != nil {
@@ -79,15 +83,13 @@ var (
     return err
    }
 
-   if config.Get().JSON {
-    data, _ := json.MarshalIndent(path, "", "  ")
-    fmt.Println(string(data))
-    return nil
+   if ok, err := util.Render(ctx, path); ok || err != nil {
+    return err
    }
 
-   fmt.Printf("当前路径：%s\n", path.Current)
-   fmt.Printf("主目录：%s\n", path.Home)
-   fmt.Printf("Git 仓库：%v\n", path.IsGit)
+   i18n.Printf("当前路径：%s\n", path.Current)
+   i18n.Printf("主目录：%s\n", path.Home)
+   i18n.Printf("Git 仓库：%v\n", path.IsGit)
    return nil
   },
  }
@@ -97,7 +99,7 @@ var (
   Short: "获取 VCS 信息",
   RunE: func(cmd *cobra.Command, args []string) error {
    c := client.NewClient()
-   ctx := context.Background()
+   ctx := cmd.Context()
 
    resp, err := c.Get(ctx, "/vcs")
    if err != nil {
@@ -109,17 +111,15 @@ var (
     return err
    }
 
-   if config.Get().JSON {
-    data, _ := json.MarshalIndent(vcs, "", "  ")
-    fmt.Println(string(data))
-    return nil
+   if ok, err := util.Render(ctx, vcs); ok || err != nil {
+    return err
    }
 
-   fmt.Printf("VCS 类型：%s\n", vcs.Type)
-   fmt.Printf("分支：%s\n", vcs.Branch)
-   fmt.Printf("提交：%s\n", vcs.Commit)
-   fmt.Printf("远程：%s\n", vcs.Remote)
-   fmt.Printf("有未提交更改：%v\n", vcs.IsDirty)
+   i18n.Printf("VCS 类型：%s\n", vcs.Type)
+   i18n.Printf("分支：%s\n", vcs.Branch)
+   i18n.Printf("提交：%s\n", vcs.Commit)
+   i18n.Printf("远程：%s\n", vcs.Remote)
+   i18n.Printf("有未提交更改：%v\n", vcs.IsDirty)
    return nil
   },
  }
@@ -129,7 +129,7 @@ var (
   Short: "销毁当前实例",
   RunE: func(cmd *cobra.Command, args []string) error {
    c := client.NewClient()
-   ctx := context.Background()
+   ctx := cmd.Context()
 
    resp, err := c.Post(ctx, "/instance/dispose", nil)
    if err != nil {
@@ -141,8 +141,11 @@ var (
     return err
    }
 
+   if ok, err := util.Render(ctx, types.ActionResult{Success: success}); ok || err != nil {
+    return err
+   }
    if success {
-    fmt.Println("实例已销毁")
+    fmt.Println(i18n.T("实例已销毁"))
    }
    return nil
   },
@@ -155,25 +158,30 @@ func init() {
  Cmd.AddCommand(pathCmd)
  Cmd.AddCommand(vcsCmd)
  Cmd.AddCommand(instanceDisposeCmd)
+
+ // JSON 输出 Schema
+ schema.Register("project list", []types.Project{})
+ schema.Register("project current", types.Project{})
+ schema.Register("project path", types.Path{})
+ schema.Register("project vcs", types.VcsInfo{})
+ schema.Register("project dispose", types.ActionResult{})
 }
 
-func outputProjects(projects []types.Project) error {
- if config.Get().JSON {
-  data, _ := json.MarshalIndent(projects, "", "  ")
-  fmt.Println(string(data))
-  return nil
+func outputProjects(ctx context.Context, projects []types.Project) error {
+ if ok, err := util.Render(ctx, projects); ok || err != nil {
+  return err
  }
 
  if len(projects) == 0 {
-  fmt.Println("没有项目")
+  fmt.Println(i18n.T("没有项目"))
   return nil
  }
 
- fmt.Printf("共 %d 个项目:\n\n", len(projects))
+ i18n.Printf("共 %d 个项目:\n\n", len(projects))
  for _, p := range projects {
   fmt.Printf("ID:   %s\n", p.ID)
-  fmt.Printf("名称：%s\n", p.Name)
-  fmt.Printf("路径：%s\n", p.Path)
+  i18n.Printf("名称：%s\n", p.Name)
+  i18n.Printf("路径：%s\n", p.Path)
   fmt.Printf("VCS:  %s\n", p.Vcs)
   fmt.Println("---")
  }
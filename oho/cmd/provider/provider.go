@@ -1,15 +1,16 @@
 package provider
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
-	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 // Cmd 提供商命令
@@ -21,11 +22,12 @@ var Cmd = &cobra.Command{
 
 var (
 	listCmd = &cobra.Command{
-		Use:   "list",
-		Short: "列出所有提供商",
+		Use:         "list",
+		Short:       "列出所有提供商",
+		Annotations: map[string]string{"mcp": "readonly"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			resp, err := c.Get(ctx, "/provider")
 			if err != nil {
@@ -79,22 +81,20 @@ var (
 							}
 						}
 					} else {
-						return fmt.Errorf("解析提供商列表失败：%w", err)
+						return i18n.Errorf("解析提供商列表失败：%w", err)
 					}
 				}
 			}
 
-			if config.Get().JSON {
-				data, _ := json.MarshalIndent(map[string]interface{}{
-					"all":       all,
-					"default":   defaultMap,
-					"connected": connected,
-				}, "", "  ")
-				fmt.Println(string(data))
-				return nil
+			if ok, err := util.Render(ctx, map[string]interface{}{
+				"all":       all,
+				"default":   defaultMap,
+				"connected": connected,
+			}); ok || err != nil {
+				return err
 			}
 
-			fmt.Println("所有提供商:")
+			fmt.Println(i18n.T("所有提供商:"))
 			for _, p := range all {
 				status := " "
 				for _, c := range connected {
@@ -107,7 +107,7 @@ var (
 			}
 
 			if len(defaultMap) > 0 {
-				fmt.Println("\n默认模型:")
+				fmt.Println(i18n.T("\n默认模型:"))
 				for provider, model := range defaultMap {
 					fmt.Printf("  %s: %s\n", provider, model)
 				}
@@ -122,7 +122,7 @@ var (
 		Short: "获取提供商认证方式",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			resp, err := c.Get(ctx, "/provider/auth")
 			if err != nil {
@@ -131,13 +131,11 @@ var (
 
 			var methods map[string][]types.ProviderAuthMethod
 			if err := json.Unmarshal(resp, &methods); err != nil {
-				return fmt.Errorf("解析认证方式失败：%w", err)
+				return i18n.Errorf("解析认证方式失败：%w", err)
 			}
 
-			if config.Get().JSON {
-				data, _ := json.MarshalIndent(methods, "", "  ")
-				fmt.Println(string(data))
-				return nil
+			if ok, err := util.Render(ctx, methods); ok || err != nil {
+				return err
 			}
 
 			for provider, authMethods := range methods {
@@ -145,7 +143,7 @@ var (
 				for _, m := range authMethods {
 					required := ""
 					if m.Required {
-						required = " (必需)"
+						required = i18n.T(" (必需)")
 					}
 					fmt.Printf("  - %s%s: %s\n", m.Type, required, m.Description)
 					if m.URL != "" {
@@ -164,7 +162,7 @@ var (
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			providerID := args[0]
 
@@ -175,20 +173,18 @@ var (
 
 			var auth types.ProviderAuthAuthorization
 			if err := json.Unmarshal(resp, &auth); err != nil {
-				return fmt.Errorf("解析 OAuth 响应失败：%w", err)
+				return i18n.Errorf("解析 OAuth 响应失败：%w", err)
 			}
 
-			if config.Get().JSON {
-				data, _ := json.MarshalIndent(auth, "", "  ")
-				fmt.Println(string(data))
-				return nil
+			if ok, err := util.Render(ctx, auth); ok || err != nil {
+				return err
 			}
 
-			fmt.Println("OAuth 授权信息:")
-			fmt.Printf("  授权 URL: %s\n", auth.URL)
+			fmt.Println(i18n.T("OAuth 授权信息:"))
+			i18n.Printf("  授权 URL: %s\n", auth.URL)
 			fmt.Printf("  State: %s\n", auth.State)
 			fmt.Printf("  Code Challenge: %s\n", auth.CodeChallenge)
-			fmt.Println("\n请在浏览器中打开授权 URL 完成认证")
+			fmt.Println(i18n.T("\n请在浏览器中打开授权 URL 完成认证"))
 
 			return nil
 		},
@@ -200,7 +196,7 @@ var (
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			providerID := args[0]
 
@@ -211,11 +207,14 @@ var (
 
 			var success bool
 			if err := json.Unmarshal(resp, &success); err != nil {
-				return fmt.Errorf("解析回调响应失败：%w", err)
+				return i18n.Errorf("解析回调响应失败：%w", err)
 			}
 
+			if ok, err := util.Render(ctx, types.ActionResult{ID: providerID, Success: success}); ok || err != nil {
+				return err
+			}
 			if success {
-				fmt.Printf("提供商 %s 的 OAuth 回调处理成功\n", providerID)
+				i18n.Printf("提供商 %s 的 OAuth 回调处理成功\n", providerID)
 			}
 
 			return nil
@@ -228,4 +227,14 @@ func init() {
 	Cmd.AddCommand(authCmd)
 	Cmd.AddCommand(oauthAuthorizeCmd)
 	Cmd.AddCommand(oauthCallbackCmd)
+
+	// JSON 输出 Schema
+	schema.Register("provider list", struct {
+		All       []types.Provider  `json:"all"`
+		Default   map[string]string `json:"default"`
+		Connected []string          `json:"connected"`
+	}{})
+	schema.Register("provider auth", map[string][]types.ProviderAuthMethod{})
+	schema.Register("provider oauth authorize", types.ProviderAuthAuthorization{})
+	schema.Register("provider oauth callback", types.ActionResult{})
 }
@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+var allSchemas bool
+
+// Cmd 输出 JSON Schema 命令
+var Cmd = &cobra.Command{
+	Use:   "schema [command...]",
+	Short: "输出命令 JSON 输出的 Schema",
+	Long: `输出命令在 --json 模式下的 JSON Schema。
+
+所有命令的 JSON 输出都使用同一个信封：
+  成功：{"version": 1, "ok": true, "data": ...}
+  失败：{"version": 1, "ok": false, "error": {"code": "...", "message": "...", "status": 404}}
+
+示例:
+  oho schema                  # 列出有 Schema 的命令
+  oho schema session list     # 输出 session list 的 Schema
+  oho schema --all            # 输出所有命令的 Schema`,
+	Annotations: map[string]string{"mcp": "readonly"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if allSchemas {
+			all := make(map[string]interface{})
+			for _, p := range schema.Paths() {
+				all[p], _ = schema.Lookup(p)
+			}
+			return printJSON(cmd.OutOrStdout(), all)
+		}
+
+		if len(args) == 0 {
+			paths := schema.Paths()
+			if ok, err := util.Render(ctx, paths); ok || err != nil {
+				return err
+			}
+			for _, p := range paths {
+				fmt.Println(p)
+			}
+			return nil
+		}
+
+		path := strings.Join(args, " ")
+		s, ok := schema.Lookup(path)
+		if !ok {
+			return i18n.Errorf("命令 %q 没有登记输出 Schema，运行 oho schema 查看可用命令", path)
+		}
+		return printJSON(cmd.OutOrStdout(), s)
+	},
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&allSchemas, "all", false, "输出所有命令的 Schema")
+
+	schema.Register("schema", []string{})
+}
+
+// printJSON Schema 本身就是 JSON 文档，不再包装信封
+func printJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
@@ -0,0 +1,183 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/resolve"
+	"github.com/anomalyco/oho/internal/schema"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+func init() {
+	aliasCmd.AddCommand(aliasSetCmd, aliasListCmd, aliasRemoveCmd)
+	Cmd.AddCommand(aliasCmd)
+	schema.Register("session alias list", []types.SessionAlias{})
+
+	// -s 标志支持 ID 前缀、标题、@last、@current 和别名，破坏性的批量命令只支持 ID、ID 前缀和别名
+	Cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		resolveRef := resolveSession
+		if strictRefs[cmd] {
+			resolveRef = resolveStrict
+		}
+		id, err := resolveRef(cmd.Context(), sessionID)
+		if err != nil {
+			return err
+		}
+		sessionID = id
+		return nil
+	}
+}
+
+// resolveSession 将会话引用解析为完整的会话 ID
+func resolveSession(ctx context.Context, ref string) (string, error) {
+	return resolve.Session(ctx, client.NewClient(), ref)
+}
+
+// resolveStrict 将会话引用解析为完整的会话 ID，只接受完整 ID、唯一的 ID 前缀和别名
+func resolveStrict(ctx context.Context, ref string) (string, error) {
+	return resolve.Strict(ctx, client.NewClient(), ref)
+}
+
+// strictSessionArg 与 sessionArg 相同，但参数按 resolveStrict 解析，用于破坏性命令
+func strictSessionArg(ctx context.Context, args []string) (string, error) {
+	if len(args) > 0 {
+		return resolveStrict(ctx, args[0])
+	}
+	return sessionArg(ctx, nil)
+}
+
+// sessionArg 从参数或 -s 标志获取会话 ID，参数中的引用会被解析
+func sessionArg(ctx context.Context, args []string) (string, error) {
+	if len(args) > 0 {
+		return resolveSession(ctx, args[0])
+	}
+	if sessionID == "" {
+		return "", i18n.Errorf("请提供会话 ID 或使用 -s 标志")
+	}
+	return sessionID, nil
+}
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "管理会话别名",
+	Long: `为常用会话设置别名，之后可以在任何接受会话 ID 的位置使用 @<别名>。
+
+除别名外，会话 ID 还可以写作：
+  ID 前缀     至少 4 个字符，可省略 ses_，如 34dbffe
+  标题        不区分大小写，完全相同优先，其次是包含（至少 4 个字符）
+  @last       最近更新的顶层会话
+  @current    当前目录中最近更新的顶层会话
+匹配多个会话时列出候选并报错。
+delete、abort、archive、unshare、revert 和 unrevert 只接受完整 ID、ID 前缀和别名。`,
+	Example: `  oho session alias set api @current
+  oho session alias set login 34dbffe
+  oho message list -s @api
+  oho session alias list`,
+}
+
+var aliasSetCmd = &cobra.Command{
+	Use:   "set <name> <session>",
+	Short: "设置会话别名",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		name := strings.TrimPrefix(args[0], "@")
+		if err := resolve.ValidateAlias(name); err != nil {
+			return err
+		}
+		// 别名保存解析结果，引用必须匹配服务器上的会话，否则以后每次使用都会失败
+		id, err := resolve.Existing(ctx, client.NewClient(), args[1])
+		if err != nil {
+			return err
+		}
+
+		aliases, err := resolve.LoadAliases()
+		if err != nil {
+			return err
+		}
+		aliases[name] = id
+		if err := aliases.Save(); err != nil {
+			return err
+		}
+
+		if ok, err := util.Render(ctx, types.SessionAlias{Name: name, ID: id}); ok || err != nil {
+			return err
+		}
+		i18n.Printf("已设置别名 @%s -> %s\n", name, id)
+		return nil
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "列出会话别名",
+	Annotations: map[string]string{"mcp": "readonly"},
+	Args:        cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		aliases, err := resolve.LoadAliases()
+		if err != nil {
+			return err
+		}
+		list := make([]types.SessionAlias, 0, len(aliases))
+		for name, id := range aliases {
+			list = append(list, types.SessionAlias{Name: name, ID: id})
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+		if ok, err := util.Render(ctx, list); ok || err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			fmt.Println(i18n.T("没有会话别名"))
+			return nil
+		}
+		rows := make([][]string, 0, len(list))
+		for _, a := range list {
+			rows = append(rows, []string{"@" + a.Name, a.ID})
+		}
+		util.OutputTable(ctx, []string{i18n.T("别名"), "ID"}, rows)
+		return nil
+	},
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:     "remove <name...>",
+	Aliases: []string{"rm"},
+	Short:   "删除会话别名",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		aliases, err := resolve.LoadAliases()
+		if err != nil {
+			return err
+		}
+		removed := make([]types.SessionAlias, 0, len(args))
+		for _, arg := range args {
+			name := strings.TrimPrefix(arg, "@")
+			id, ok := aliases[name]
+			if !ok {
+				return i18n.Errorf("别名不存在：%s", arg)
+			}
+			delete(aliases, name)
+			removed = append(removed, types.SessionAlias{Name: name, ID: id})
+		}
+		if err := aliases.Save(); err != nil {
+			return err
+		}
+
+		if ok, err := util.Render(ctx, removed); ok || err != nil {
+			return err
+		}
+		i18n.Printf("已删除 %d 个别名\n", len(removed))
+		return nil
+	},
+}
@@ -0,0 +1,481 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/cache"
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/resolve"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// 批量操作结果状态
+const (
+	bulkDone    = "done"
+	bulkFailed  = "failed"
+	bulkPlanned = "planned" // --dry-run 时只列出将要操作的会话
+)
+
+// stdinArg 表示从标准输入读取会话 ID 的参数
+const stdinArg = "-"
+
+var (
+	whereExprs   []string
+	bulkDryRun   bool
+	bulkYes      bool
+	bulkParallel int
+	olderThan    string
+)
+
+// sessionFilter 会话过滤条件，session list 的过滤标志和批量操作的 --where 共用
+type sessionFilter struct {
+	ID        string
+	Title     string
+	ProjectID string
+	Directory string
+	Created   int64
+	Updated   int64
+	Status    string
+	OlderThan time.Duration // 最后更新时间早于现在减去该时长
+}
+
+// empty 是否没有任何条件
+func (f sessionFilter) empty() bool {
+	return f == sessionFilter{}
+}
+
+// matchFields 检查状态以外的条件
+func (f sessionFilter) matchFields(s types.Session, now time.Time) bool {
+	switch {
+	case f.ID != "" && !contains(s.ID, f.ID):
+		return false
+	case f.Title != "" && !contains(s.Title, f.Title):
+		return false
+	case f.Created != 0 && s.Time.Created != f.Created:
+		return false
+	case f.Updated != 0 && s.Time.Updated != f.Updated:
+		return false
+	case f.ProjectID != "" && !contains(s.ProjectID, f.ProjectID):
+		return false
+	case f.Directory != "" && !contains(s.Directory, f.Directory):
+		return false
+	case f.OlderThan > 0 && !olderThanAge(s, now, f.OlderThan):
+		return false
+	}
+	return true
+}
+
+// olderThanAge 会话最后更新（没有更新时间时取创建时间）是否早于 now 之前 age
+func olderThanAge(s types.Session, now time.Time, age time.Duration) bool {
+	last := s.Time.Updated
+	if last == 0 {
+		last = s.Time.Created
+	}
+	return now.Sub(time.UnixMilli(last)) >= age
+}
+
+// matchStatus 按 --status 过滤，status 为服务器返回的状态，exists 表示会话是否出现在状态列表中
+func matchStatus(filter string, status types.SessionStatus, exists bool) bool {
+	switch filter {
+	case "running":
+		// running 状态必须在状态列表中存在且正在工作
+		return exists && status.Working()
+	case "completed", "idle":
+		// 不在状态列表中说明不在工作中，视为 completed
+		return !exists || !status.Working()
+	case "error":
+		return exists && status.Status == "error"
+	case "aborted":
+		return exists && status.Status == "aborted"
+	}
+	// 未知状态不匹配任何会话，避免批量操作选中全部会话
+	return filter == ""
+}
+
+// validateStatus 检查状态过滤条件是否为 matchStatus 支持的值
+func validateStatus(filter string) error {
+	switch filter {
+	case "running", "completed", "idle", "error", "aborted":
+		return nil
+	}
+	return i18n.Errorf("无效的状态：%s（可用：running、completed、idle、error、aborted）", filter)
+}
+
+// parseWhere 解析 --where 条件，格式为 key=value，可用逗号分隔或多次指定
+func parseWhere(exprs []string) (sessionFilter, error) {
+	var f sessionFilter
+	for _, expr := range exprs {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(expr, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || value == "" {
+			return f, i18n.Errorf("无效的条件：%s（格式为 key=value）", expr)
+		}
+		switch key {
+		case "id":
+			f.ID = value
+		case "title":
+			f.Title = value
+		case "status":
+			if err := validateStatus(value); err != nil {
+				return f, err
+			}
+			f.Status = value
+		case "directory":
+			f.Directory = value
+		case "project-id":
+			f.ProjectID = value
+		case "older-than":
+			age, err := util.ParseAge(value)
+			if err != nil {
+				return f, err
+			}
+			f.OlderThan = age
+		default:
+			return f, i18n.Errorf("未知的条件：%s（可用：id、title、status、directory、project-id、older-than）", key)
+		}
+	}
+	if f.empty() {
+		return f, i18n.Errorf("--where 至少需要一个条件")
+	}
+	return f, nil
+}
+
+// strictRefs 破坏性的命令，会话引用只接受完整 ID、唯一的 ID 前缀和别名
+var strictRefs = map[*cobra.Command]bool{}
+
+// addBulkFlags 为 delete、abort、archive 添加批量操作标志
+func addBulkFlags(cmd *cobra.Command) {
+	strictRefs[cmd] = true
+	cmd.Flags().StringSliceVar(&whereExprs, "where", nil, "按条件选择会话 (key=value：id、title、status、directory、project-id、older-than)")
+	cmd.Flags().BoolVar(&bulkDryRun, "dry-run", false, "只列出将要操作的会话")
+	cmd.Flags().BoolVarP(&bulkYes, "yes", "y", false, "跳过确认")
+	cmd.Flags().IntVar(&bulkParallel, "parallel", 4, "并发执行的数量")
+}
+
+// bulkAction 对单个会话执行的批量操作
+type bulkAction struct {
+	name    string // 结果中的操作名
+	confirm string // 确认提示，参数为会话数量
+	run     func(ctx context.Context, c client.ClientInterface, s types.Session) error
+}
+
+// isBulk 是否使用批量模式：指定了 --where 或 --dry-run、多个 ID 或从 stdin 读取 ID
+func isBulk(args []string) bool {
+	return len(whereExprs) > 0 || bulkDryRun || len(args) > 1 || (len(args) == 1 && args[0] == stdinArg)
+}
+
+// runBulk 选出会话，确认后并发执行操作并输出每个会话的结果
+func runBulk(ctx context.Context, args []string, action bulkAction) error {
+	c := client.NewClient()
+
+	targets, fromStdin, err := bulkTargets(ctx, c, args, os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		if ok, err := util.Render(ctx, []types.BulkResult{}); ok || err != nil {
+			return err
+		}
+		fmt.Println(i18n.T("没有匹配的会话"))
+		return nil
+	}
+
+	if bulkDryRun {
+		results := make([]types.BulkResult, len(targets))
+		for i, s := range targets {
+			results[i] = types.BulkResult{ID: s.ID, Title: s.Title, Action: action.name, Status: bulkPlanned}
+		}
+		if ok, err := util.Render(ctx, results); ok || err != nil {
+			return err
+		}
+		i18n.Printf("以下 %d 个会话将被处理（--dry-run，未执行）:\n", len(targets))
+		printTargets(targets)
+		return nil
+	}
+
+	if !bulkYes {
+		// 确认需要从终端读取回答：stdin 已用于读取 ID 或使用结构化输出时必须指定 --yes
+		if fromStdin {
+			return i18n.Errorf("从 stdin 读取会话 ID 时请使用 --yes 确认操作")
+		}
+		if !util.TextOutput(ctx) {
+			return i18n.Errorf("使用 --output 或 --json 时请使用 --yes 确认操作")
+		}
+		printTargets(targets)
+		if !util.Confirm(ctx, i18n.Sprintf(action.confirm, len(targets))) {
+			fmt.Println(i18n.T("已取消"))
+			return nil
+		}
+	}
+
+	results := runParallel(ctx, c, targets, action, bulkParallel)
+	if ok, err := util.Render(ctx, results); ok || err != nil {
+		return err
+	}
+	return printBulkResults(ctx, results)
+}
+
+// bulkTargets 按 --where 条件或参数中的 ID 选出要操作的会话，返回是否从 stdin 读取了 ID
+func bulkTargets(ctx context.Context, c client.ClientInterface, args []string, stdin io.Reader) ([]types.Session, bool, error) {
+	if len(whereExprs) > 0 {
+		if len(args) > 0 {
+			return nil, false, i18n.Errorf("--where 不能与会话 ID 同时使用")
+		}
+		filter, err := parseWhere(whereExprs)
+		if err != nil {
+			return nil, false, err
+		}
+		sessions, err := findSessions(ctx, c, filter, time.Now())
+		return sessions, false, err
+	}
+
+	var ids []string
+	fromStdin := false
+	for _, arg := range args {
+		if arg != stdinArg {
+			// 命令行中的引用在执行前解析，有歧义时不处理任何会话
+			id, err := resolve.Strict(ctx, c, arg)
+			if err != nil {
+				return nil, false, err
+			}
+			ids = append(ids, id)
+			continue
+		}
+		fromStdin = true
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, false, i18n.Errorf("读取 stdin 失败：%w", err)
+		}
+		ids = append(ids, parseIDs(string(data))...)
+	}
+	if len(ids) == 0 && sessionID != "" && !fromStdin {
+		ids = append(ids, sessionID)
+	}
+	if len(ids) == 0 && !fromStdin {
+		return nil, false, i18n.Errorf("请提供会话 ID、- 或 --where 条件")
+	}
+
+	targets := make([]types.Session, 0, len(ids))
+	for _, id := range ids {
+		targets = append(targets, types.Session{ID: id})
+	}
+	return targets, fromStdin, nil
+}
+
+// parseIDs 从文本中提取会话 ID：以空白分隔，忽略 # 开头的注释行和重复的 ID
+func parseIDs(text string) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for _, id := range strings.Fields(line) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// findSessions 获取会话列表并按条件过滤，需要时获取会话状态
+func findSessions(ctx context.Context, c client.ClientInterface, filter sessionFilter, now time.Time) ([]types.Session, error) {
+	sessions, err := cache.Fetch(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var statusMap map[string]types.SessionStatus
+	if filter.Status != "" {
+		resp, err := c.Get(ctx, "/session/status")
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(resp, &statusMap); err != nil {
+			return nil, i18n.Errorf("解析会话状态失败：%w", err)
+		}
+	}
+
+	var matched []types.Session
+	for _, s := range sessions {
+		status, exists := statusMap[s.ID]
+		if filter.matchFields(s, now) && (filter.Status == "" || matchStatus(filter.Status, status, exists)) {
+			matched = append(matched, s)
+		}
+	}
+	return matched, nil
+}
+
+// runParallel 以最多 parallel 个并发执行操作，结果顺序与 targets 一致
+func runParallel(ctx context.Context, c client.ClientInterface, targets []types.Session, action bulkAction, parallel int) []types.BulkResult {
+	if parallel < 1 {
+		parallel = 1
+	}
+	results := make([]types.BulkResult, len(targets))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i, s := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, s types.Session) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result := types.BulkResult{ID: s.ID, Title: s.Title, Action: action.name, Status: bulkDone}
+			if err := action.run(ctx, c, s); err != nil {
+				result.Status = bulkFailed
+				result.Error = err.Error()
+			}
+			results[i] = result
+		}(i, s)
+	}
+	wg.Wait()
+	return results
+}
+
+// printTargets 列出将要操作的会话
+func printTargets(targets []types.Session) {
+	for _, s := range targets {
+		if s.Title != "" {
+			fmt.Printf("  %s  %s\n", s.ID, s.Title)
+		} else {
+			fmt.Printf("  %s\n", s.ID)
+		}
+	}
+}
+
+// printBulkResults 输出每个会话的结果，有失败时返回错误
+func printBulkResults(ctx context.Context, results []types.BulkResult) error {
+	failed := 0
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		if r.Status == bulkFailed {
+			failed++
+		}
+		// 错误信息可能包含多行的响应内容，表格中压缩为一行
+		rows = append(rows, []string{r.ID, util.Truncate(r.Title, 40), r.Status, util.Truncate(strings.Join(strings.Fields(r.Error), " "), 80)})
+	}
+	util.OutputTable(ctx, []string{"ID", i18n.T("标题"), i18n.T("结果"), i18n.T("错误")}, rows)
+	i18n.Printf("\n%d 个成功，%d 个失败\n", len(results)-failed, failed)
+	if failed > 0 {
+		return i18n.Errorf("%d 个会话操作失败", failed)
+	}
+	return nil
+}
+
+// deleteSession 删除会话，服务器返回 false 时视为失败
+func deleteSession(ctx context.Context, c client.ClientInterface, id string) (bool, error) {
+	resp, err := c.Delete(ctx, fmt.Sprintf("/session/%s", id))
+	if err != nil {
+		return false, err
+	}
+	var deleted bool
+	if err := json.Unmarshal(resp, &deleted); err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
+
+// abortSession 中止会话
+func abortSession(ctx context.Context, c client.ClientInterface, id string) (bool, error) {
+	resp, err := c.Post(ctx, fmt.Sprintf("/session/%s/abort", id), nil)
+	if err != nil {
+		return false, err
+	}
+	var success bool
+	if err := json.Unmarshal(resp, &success); err != nil {
+		return false, err
+	}
+	return success, nil
+}
+
+// archiveSession 归档会话，dir 作为 directory 查询参数
+func archiveSession(ctx context.Context, c client.ClientInterface, id, dir string) (types.Session, error) {
+	var session types.Session
+	req := map[string]interface{}{
+		"time": map[string]interface{}{
+			"archived": time.Now().UnixMilli(),
+		},
+	}
+	resp, err := c.PatchWithQuery(ctx, fmt.Sprintf("/session/%s", id), map[string]string{"directory": dir}, req)
+	if err != nil {
+		return session, err
+	}
+	if err := json.Unmarshal(resp, &session); err != nil {
+		return session, err
+	}
+	return session, nil
+}
+
+// 批量操作定义
+var (
+	bulkDelete = bulkAction{
+		name:    "delete",
+		confirm: "确定删除以上 %d 个会话？",
+		run: func(ctx context.Context, c client.ClientInterface, s types.Session) error {
+			deleted, err := deleteSession(ctx, c, s.ID)
+			if err == nil && !deleted {
+				err = i18n.Errorf("服务器未删除会话")
+			}
+			return err
+		},
+	}
+	bulkAbort = bulkAction{
+		name:    "abort",
+		confirm: "确定中止以上 %d 个会话？",
+		run: func(ctx context.Context, c client.ClientInterface, s types.Session) error {
+			aborted, err := abortSession(ctx, c, s.ID)
+			if err == nil && !aborted {
+				err = i18n.Errorf("服务器未中止会话")
+			}
+			return err
+		},
+	}
+	bulkArchive = bulkAction{
+		name:    "archive",
+		confirm: "确定归档以上 %d 个会话？",
+		run: func(ctx context.Context, c client.ClientInterface, s types.Session) error {
+			// 优先使用会话自身的目录
+			dir := s.Directory
+			if dir == "" {
+				var err error
+				if dir, err = archiveDir(); err != nil {
+					return err
+				}
+			}
+			_, err := archiveSession(ctx, c, s.ID, dir)
+			return err
+		},
+	}
+)
+
+// archiveDir 归档请求的目录：--directory 或当前目录
+func archiveDir() (string, error) {
+	if directory != "" {
+		return directory, nil
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", i18n.Errorf("failed to get current directory: %w", err)
+	}
+	return dir, nil
+}
@@ -0,0 +1,220 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/cache"
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// heartbeat --watch 时事件流保持连接的情况下刷新索引有效期的间隔
+const heartbeat = 15 * time.Second
+
+var (
+	refresh    bool
+	syncWatch  bool
+	syncClear  bool
+	syncRetry  time.Duration
+	offlineMsg sync.Once
+)
+
+func init() {
+	listCmd.Flags().BoolVar(&refresh, "refresh", false, "忽略本地索引，从服务器重新获取")
+	getCmd.Flags().BoolVar(&refresh, "refresh", false, "忽略本地索引，从服务器重新获取")
+	searchCmd.Flags().BoolVar(&refresh, "refresh", false, "忽略本地索引，从服务器重新获取")
+
+	syncCmd.Flags().BoolVar(&syncWatch, "watch", false, "同步后订阅事件流，持续更新索引")
+	syncCmd.Flags().BoolVar(&syncClear, "clear", false, "删除本地索引和消息快照")
+	syncCmd.Flags().DurationVar(&syncRetry, "retry", 3*time.Second, "事件流断开后的重连间隔")
+	Cmd.AddCommand(syncCmd)
+	schema.Register("session sync", types.CacheInfo{})
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "同步本地会话索引",
+	Long: `从服务器获取会话列表，更新用户缓存目录中的本地索引。
+
+session list、get 和 search 在索引有效期内（默认 1 分钟，可用 OHO_CACHE_TTL 调整）
+直接使用索引，无法连接服务器时使用最后一次同步的快照。
+使用 --watch 时持续订阅事件流，增量更新索引并保持索引有效。`,
+	Example: `  oho session sync
+  oho session sync --watch &
+  oho session sync --clear`,
+	Args: cobra.NoArgs,
+	// --watch 是长时间运行的命令，本地索引对 MCP 客户端也没有意义
+	Annotations: map[string]string{"mcp": "skip"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		idx, err := cache.OpenDefault()
+		if err != nil {
+			return err
+		}
+		if syncClear {
+			if err := idx.Clear(); err != nil {
+				return i18n.Errorf("删除本地索引失败：%w", err)
+			}
+			i18n.Printf("已删除本地索引 %s\n", idx.Dir())
+			return nil
+		}
+
+		c := client.NewClient()
+		ctx := cmd.Context()
+		if err := idx.Sync(ctx, c); err != nil {
+			return err
+		}
+		if !syncWatch {
+			info := types.CacheInfo{Dir: idx.Dir(), Server: idx.Server, Sessions: len(idx.Sessions), Synced: idx.Synced}
+			if ok, err := util.Render(ctx, info); ok || err != nil {
+				return err
+			}
+			i18n.Printf("已同步 %d 个会话到 %s\n", len(idx.Sessions), idx.Dir())
+			return nil
+		}
+
+		i18n.Fprintf(os.Stderr, "已同步 %d 个会话，正在监听事件流（Ctrl+C 退出）\n", len(idx.Sessions))
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return watchIndex(ctx, c, idx, syncRetry)
+	},
+}
+
+// warnOffline 提示正在使用离线快照，每次运行只提示一次
+func warnOffline(idx *cache.Index, err error) {
+	offlineMsg.Do(func() {
+		synced := time.UnixMilli(idx.Synced).Format("2006-01-02 15:04:05")
+		i18n.Fprintf(os.Stderr, "警告：无法连接服务器（%v），使用 %s 同步的本地快照\n", err, synced)
+	})
+}
+
+// loadSessions 获取会话列表
+// 索引有效且未指定 --refresh 时直接使用索引，否则请求服务器并更新索引；无法连接服务器时使用索引中的快照
+func loadSessions(ctx context.Context, c client.ClientInterface) ([]types.Session, error) {
+	idx, err := cache.OpenDefault()
+	if err != nil {
+		return cache.Fetch(ctx, c)
+	}
+	if !refresh && idx.Fresh(time.Now(), cache.TTL()) {
+		return idx.Sessions, nil
+	}
+
+	if err := idx.Sync(ctx, c); err != nil {
+		if client.IsUnavailable(err) && idx.Synced != 0 {
+			warnOffline(idx, err)
+			return idx.Sessions, nil
+		}
+		return nil, err
+	}
+	return idx.Sessions, nil
+}
+
+// loadSession 获取单个会话，规则与 loadSessions 相同
+func loadSession(ctx context.Context, c client.ClientInterface, id string) (types.Session, error) {
+	idx, idxErr := cache.OpenDefault()
+	if idxErr == nil && !refresh && idx.Fresh(time.Now(), cache.TTL()) {
+		if s, ok := idx.Get(id); ok {
+			return s, nil
+		}
+	}
+
+	var session types.Session
+	resp, err := c.Get(ctx, fmt.Sprintf("/session/%s", id))
+	if err != nil {
+		if idxErr == nil && client.IsUnavailable(err) {
+			if s, ok := idx.Get(id); ok {
+				warnOffline(idx, err)
+				return s, nil
+			}
+		}
+		return session, err
+	}
+	if err := json.Unmarshal(resp, &session); err != nil {
+		return session, err
+	}
+	if idxErr == nil && idx.Synced != 0 {
+		idx.Upsert(session)
+		_ = idx.Save()
+	}
+	return session, nil
+}
+
+// loadMessages 获取会话的消息
+// 快照在会话最后一次更新之后获取且未指定 --refresh 时直接使用快照，否则请求服务器并保存快照；
+// 无法连接服务器时使用已有的快照
+func loadMessages(ctx context.Context, c client.ClientInterface, idx *cache.Index, s types.Session) ([]types.MessageWithParts, error) {
+	var cached []types.MessageWithParts
+	found := false
+	if idx != nil {
+		var current bool
+		cached, current, found = idx.Messages(s.ID, s.Time.Updated)
+		if found && current && !refresh {
+			return cached, nil
+		}
+	}
+
+	messages, err := cache.FetchMessages(ctx, c, s.ID)
+	if err != nil {
+		if found && client.IsUnavailable(err) {
+			warnOffline(idx, err)
+			return cached, nil
+		}
+		return nil, err
+	}
+	if idx != nil {
+		_ = idx.SaveMessages(s.ID, s.Time.Updated, messages)
+	}
+	return messages, nil
+}
+
+// watchIndex 订阅事件流增量更新索引，直到 ctx 取消
+// 断开期间的事件会丢失，重新连接后收到第一个事件时先完整同步一次
+func watchIndex(ctx context.Context, c client.ClientInterface, idx *cache.Index, retry time.Duration) error {
+	var connected atomic.Bool
+	connected.Store(true)
+	events := event.Watch(ctx, c, event.DefaultPath, retry, func(err error) {
+		connected.Store(false)
+		i18n.Fprintf(os.Stderr, "事件流断开：%v，%s 后重连\n", err, retry)
+	})
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if !connected.Load() {
+				if err := idx.Sync(ctx, c); err != nil {
+					i18n.Fprintf(os.Stderr, "同步失败：%v\n", err)
+					continue
+				}
+				connected.Store(true)
+			}
+			if idx.Apply(e, time.Now()) {
+				if err := idx.Save(); err != nil {
+					i18n.Fprintf(os.Stderr, "写入本地索引失败：%v\n", err)
+				}
+			}
+		case <-ticker.C:
+			if connected.Load() {
+				idx.Touch(time.Now())
+				_ = idx.Save()
+			}
+		}
+	}
+}
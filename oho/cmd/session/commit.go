@@ -0,0 +1,333 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/diff"
+	"github.com/anomalyco/oho/internal/git"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// 提交信息 trailer 的键
+const (
+	trailerSessionID = "Session-Id"
+	trailerParentID  = "Session-Parent"
+)
+
+var (
+	commitBranch string
+	commitDir    string
+	commitDryRun bool
+)
+
+func init() {
+	commitCmd.Flags().StringVarP(&commitBranch, "branch", "b", "", "要创建的分支")
+	commitCmd.Flags().StringVarP(&commitDir, "dir", "C", ".", "本地仓库目录")
+	commitCmd.Flags().BoolVar(&commitDryRun, "dry-run", false, "只显示将要提交的文件和提交信息")
+	_ = commitCmd.MarkFlagRequired("branch")
+
+	Cmd.AddCommand(commitCmd)
+	schema.Register("session commit", types.CommitResult{})
+}
+
+// commitCmd 将会话变更提交到本地仓库
+var commitCmd = &cobra.Command{
+	Use:   "commit [id]",
+	Short: "将会话变更提交到本地仓库的新分支",
+	Long: `将会话的文件变更写入本地仓库，基于当前 HEAD 创建新分支并提交。
+
+提交信息由会话标题、总结和待办事项生成，末尾的 Session-Id trailer 指向会话。
+写入文件的规则与 oho session diff --apply 相同；任何文件存在冲突时不做任何修改。
+暂存区已有改动或涉及的文件有未提交的改动时拒绝提交，避免混入无关内容；
+提交失败时恢复文件、切回原分支并删除新分支。`,
+	Example: `  oho session commit ses_xxx --branch feat/login
+  oho session commit ses_xxx -b feat/login -C ~/src/project --dry-run`,
+	// 在本地任意仓库中写入文件、创建分支并提交
+	Annotations: map[string]string{"mcp": "destructive"},
+	Args:        cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := sessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
+		}
+
+		repo, err := git.Open(commitDir)
+		if err != nil {
+			return i18n.Errorf("打开 git 仓库失败：%w", err)
+		}
+		if err := git.ValidateBranch(commitBranch); err != nil {
+			return err
+		}
+		if repo.BranchExists(commitBranch) {
+			return i18n.Errorf("分支 %s 已存在", commitBranch)
+		}
+		staged, err := repo.HasStaged()
+		if err != nil {
+			return err
+		}
+		if staged {
+			return i18n.Errorf("暂存区有未提交的改动，请先提交或取消暂存")
+		}
+
+		c := client.NewClient()
+		ctx := cmd.Context()
+
+		session, diffs, err := fetchCommitSource(ctx, c, id)
+		if err != nil {
+			return err
+		}
+		if len(diffs) == 0 {
+			return i18n.Errorf("会话 %s 没有文件变更", id)
+		}
+
+		// 先检查所有文件，有冲突时不修改工作区
+		results, err := diff.Check(repo.Dir, diffs, diff.DefaultContext)
+		if err != nil {
+			return i18n.Errorf("应用差异失败：%w", err)
+		}
+		var conflicts []string
+		for _, r := range results {
+			if r.Status == diff.ResultConflict {
+				conflicts = append(conflicts, fmt.Sprintf("%s (%s)", r.Path, conflictReason(r)))
+			}
+		}
+		if len(conflicts) > 0 {
+			return i18n.Errorf("以下文件与本地内容冲突：\n  %s", strings.Join(conflicts, "\n  "))
+		}
+
+		todos, err := fetchTodos(ctx, c, id)
+		if err != nil {
+			return err
+		}
+		summary, err := fetchSummary(ctx, c, id)
+		if err != nil {
+			return err
+		}
+
+		base, _ := repo.CurrentBranch()
+		result := types.CommitResult{
+			SessionID: id,
+			Branch:    commitBranch,
+			Base:      base,
+			Message:   commitMessage(session, summary, todos),
+			DryRun:    commitDryRun,
+		}
+		for _, d := range diffs {
+			result.Files = append(result.Files, d.Path)
+		}
+
+		// 涉及的文件有未提交的改动时拒绝提交，否则这些改动会被一起提交
+		dirty, err := repo.Changed(result.Files)
+		if err != nil {
+			return err
+		}
+		if len(dirty) > 0 {
+			return i18n.Errorf("以下文件有未提交的改动，请先提交或撤销：\n  %s", strings.Join(dirty, "\n  "))
+		}
+
+		if !commitDryRun {
+			if result.Commit, err = commitOnBranch(repo, base, diffs, result); err != nil {
+				return err
+			}
+		}
+
+		if ok, err := util.Render(ctx, result); ok || err != nil {
+			return err
+		}
+
+		if commitDryRun {
+			i18n.Printf("将在 %s 上创建分支 %s 并提交 %d 个文件:\n", base, commitBranch, len(result.Files))
+		} else {
+			i18n.Printf("已在分支 %s 上提交 %s (%d 个文件):\n", commitBranch, git.ShortHash(result.Commit), len(result.Files))
+		}
+		for _, f := range result.Files {
+			fmt.Printf("  %s\n", f)
+		}
+		if commitDryRun {
+			fmt.Printf("\n%s", result.Message)
+		}
+		return nil
+	},
+}
+
+// commitOnBranch 创建分支、写入文件并提交，失败时恢复文件、切回原分支并删除新分支
+func commitOnBranch(repo *git.Repo, base string, diffs []types.FileDiff, result types.CommitResult) (string, error) {
+	// 分离 HEAD 时切回原来的提交
+	if base == "" || base == "HEAD" {
+		head, err := repo.Head()
+		if err != nil {
+			return "", err
+		}
+		base = head
+	}
+	if err := repo.CreateBranch(result.Branch); err != nil {
+		return "", err
+	}
+
+	hash, err := func() (string, error) {
+		if _, err := diff.Apply(repo.Dir, diffs, diff.DefaultContext); err != nil {
+			return "", i18n.Errorf("应用差异失败：%w", err)
+		}
+		if err := repo.Add(result.Files); err != nil {
+			return "", err
+		}
+		if staged, err := repo.HasStaged(); err != nil {
+			return "", err
+		} else if !staged {
+			return "", i18n.Errorf("会话变更与分支 %s 的内容相同，没有可提交的改动", result.Branch)
+		}
+		return repo.Commit(result.Message)
+	}()
+	if err == nil {
+		return hash, nil
+	}
+
+	if rbErr := rollback(repo, base, result); rbErr != nil {
+		return "", i18n.Errorf("%v；回滚失败：%w", err, rbErr)
+	}
+	return "", err
+}
+
+// rollback 撤销 commitOnBranch 的修改
+func rollback(repo *git.Repo, base string, result types.CommitResult) error {
+	if err := repo.Restore(result.Files); err != nil {
+		return err
+	}
+	if err := repo.Checkout(base); err != nil {
+		return err
+	}
+	return repo.DeleteBranch(result.Branch, true)
+}
+
+// fetchCommitSource 获取会话信息和文件差异
+func fetchCommitSource(ctx context.Context, c client.ClientInterface, id string) (types.Session, []types.FileDiff, error) {
+	var session types.Session
+	resp, err := c.Get(ctx, fmt.Sprintf("/session/%s", id))
+	if err != nil {
+		return session, nil, err
+	}
+	if err := json.Unmarshal(resp, &session); err != nil {
+		return session, nil, i18n.Errorf("解析会话失败：%w", err)
+	}
+	if session.ID == "" {
+		session.ID = id
+	}
+
+	diffs, err := fetchDiffs(ctx, c, id, "")
+	if err != nil {
+		return session, nil, err
+	}
+
+	// 跳过内容没有变化的文件
+	changed := diffs[:0]
+	for _, d := range diffs {
+		if diff.Unified(d, 0) != "" {
+			changed = append(changed, d)
+		}
+	}
+	return session, changed, nil
+}
+
+// fetchTodos 获取会话的待办事项
+func fetchTodos(ctx context.Context, c client.ClientInterface, id string) ([]types.Todo, error) {
+	resp, err := c.Get(ctx, fmt.Sprintf("/session/%s/todo", id))
+	if err != nil {
+		return nil, err
+	}
+	var todos []types.Todo
+	if err := json.Unmarshal(resp, &todos); err != nil {
+		return nil, i18n.Errorf("解析待办事项失败：%w", err)
+	}
+	return todos, nil
+}
+
+// fetchSummary 获取会话总结：优先使用 session summarize 生成的总结，否则取最后一条助手回复
+func fetchSummary(ctx context.Context, c client.ClientInterface, id string) (string, error) {
+	resp, err := c.Get(ctx, fmt.Sprintf("/session/%s/message", id))
+	if err != nil {
+		return "", err
+	}
+	var messages []types.MessageWithParts
+	if err := json.Unmarshal(resp, &messages); err != nil {
+		return "", i18n.Errorf("解析消息列表失败：%w", err)
+	}
+	return sessionSummary(messages), nil
+}
+
+func sessionSummary(messages []types.MessageWithParts) string {
+	var last string
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		if msg.Info.Role != "assistant" {
+			continue
+		}
+		text := messageText(msg)
+		if text == "" {
+			continue
+		}
+		if msg.Info.Summary {
+			return text
+		}
+		if last == "" {
+			last = text
+		}
+	}
+	return last
+}
+
+// messageText 拼接消息中的文本部分
+func messageText(msg types.MessageWithParts) string {
+	var texts []string
+	if msg.Info.Content != "" {
+		texts = append(texts, msg.Info.Content)
+	}
+	for _, part := range msg.Parts {
+		if part.Type == "text" && part.Text != nil && strings.TrimSpace(*part.Text) != "" {
+			texts = append(texts, strings.TrimSpace(*part.Text))
+		}
+	}
+	return strings.TrimSpace(strings.Join(texts, "\n\n"))
+}
+
+// commitMessage 由会话标题、总结和待办事项生成提交信息
+func commitMessage(session types.Session, summary string, todos []types.Todo) string {
+	subject := strings.TrimSpace(strings.SplitN(session.Title, "\n", 2)[0])
+	if subject == "" {
+		subject = "Apply changes from session " + session.ID
+	}
+
+	var body []string
+	if summary != "" {
+		body = append(body, summary)
+	}
+
+	var items []string
+	for _, todo := range todos {
+		switch todo.Status {
+		case "cancelled":
+			continue
+		case "completed":
+			items = append(items, "- [x] "+todo.Content)
+		default:
+			items = append(items, "- [ ] "+todo.Content)
+		}
+	}
+	if len(items) > 0 {
+		body = append(body, "Tasks:\n"+strings.Join(items, "\n"))
+	}
+
+	trailers := []git.Trailer{{Key: trailerSessionID, Value: session.ID}}
+	if session.ParentID != "" {
+		trailers = append(trailers, git.Trailer{Key: trailerParentID, Value: session.ParentID})
+	}
+	return git.FormatMessage(subject, strings.Join(body, "\n\n"), trailers)
+}
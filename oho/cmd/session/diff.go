@@ -0,0 +1,192 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/diff"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+var (
+	diffPatch      bool
+	diffSideBySide bool
+	diffContext    int
+	diffApply      bool
+	diffApplyDir   string
+	diffMessageID  string
+)
+
+func init() {
+	diffCmd.Flags().BoolVar(&diffPatch, "patch", false, "输出可用于 git apply 的补丁")
+	diffCmd.Flags().BoolVarP(&diffSideBySide, "side-by-side", "y", false, "并排显示差异")
+	diffCmd.Flags().IntVarP(&diffContext, "context", "U", diff.DefaultContext, "差异上下文行数")
+	diffCmd.Flags().BoolVar(&diffApply, "apply", false, "将差异应用到本地工作目录")
+	diffCmd.Flags().StringVarP(&diffApplyDir, "dir", "C", ".", "应用差异的本地目录")
+	diffCmd.Flags().StringVar(&diffMessageID, "message", "", "只显示指定消息产生的差异")
+	diffCmd.MarkFlagsMutuallyExclusive("patch", "apply", "side-by-side")
+}
+
+// diffCmd 获取会话差异
+var diffCmd = &cobra.Command{
+	Use:   "diff [id]",
+	Short: "获取会话差异",
+	Long: `获取会话中文件的变更，以统一格式显示差异。
+
+服务端工作目录在远程时，可以用 --patch 导出补丁，或用 --apply 直接应用到本地检出的代码：
+本地文件与变更前一致时直接写入，已有改动时按上下文逐段应用。
+无法应用的补丁段写入 <文件>.rej，该文件保持不变。`,
+	Example: `  oho session diff ses_xxx
+  oho session diff ses_xxx -y
+  oho session diff ses_xxx --patch | git apply
+  oho session diff ses_xxx --apply -C ~/src/project`,
+	// --apply 会写入本地任意目录，不能作为只读工具暴露
+	Annotations: map[string]string{"mcp": "destructive"},
+	Args:        cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := sessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
+		}
+
+		c := client.NewClient()
+		ctx := cmd.Context()
+
+		diffs, err := fetchDiffs(ctx, c, id, diffMessageID)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case diffPatch:
+			_, err := fmt.Fprint(cmd.OutOrStdout(), diff.Patch(diffs, diffContext))
+			return err
+		case diffApply:
+			return applyDiffs(ctx, diffs)
+		}
+
+		if ok, err := util.Render(ctx, diffs); ok || err != nil {
+			return err
+		}
+
+		printDiffs(diffs)
+		return nil
+	},
+}
+
+// fetchDiffs 获取会话的文件差异
+func fetchDiffs(ctx context.Context, c client.ClientInterface, id, message string) ([]types.FileDiff, error) {
+	queryParams := map[string]string{}
+	if message != "" {
+		queryParams["messageID"] = message
+	}
+
+	resp, err := c.GetWithQuery(ctx, fmt.Sprintf("/session/%s/diff", id), queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []types.FileDiff
+	if err := json.Unmarshal(resp, &diffs); err != nil {
+		return nil, i18n.Errorf("解析差异失败：%w", err)
+	}
+	return diffs, nil
+}
+
+// printDiffs 以统一格式或并排方式输出差异
+func printDiffs(diffs []types.FileDiff) {
+	color := util.ColorEnabled()
+	files, added, deleted := 0, 0, 0
+
+	for _, d := range diffs {
+		hunks := diff.FileHunks(d, diffContext)
+		a, r := diff.Stat(hunks)
+		if len(hunks) == 0 && diff.Status(d) == diff.StatusModified {
+			continue
+		}
+		files++
+		added += a
+		deleted += r
+
+		if diffSideBySide {
+			fmt.Print(diff.SideBySide(d, diffContext, util.TerminalWidth(), color))
+			continue
+		}
+		patch := diff.Unified(d, diffContext)
+		if color {
+			patch = diff.Colorize(patch)
+		}
+		fmt.Print(patch)
+	}
+
+	if files == 0 {
+		i18n.Printf("没有文件变更\n")
+		return
+	}
+	i18n.Printf("\n%d 个文件变更，+%d -%d\n", files, added, deleted)
+}
+
+// applyDiffs 将差异应用到本地目录，存在冲突时返回错误
+func applyDiffs(ctx context.Context, diffs []types.FileDiff) error {
+	results, err := diff.Apply(diffApplyDir, diffs, diffContext)
+	if err != nil {
+		return i18n.Errorf("应用差异失败：%w", err)
+	}
+
+	conflicts := 0
+	for _, r := range results {
+		if r.Status == diff.ResultConflict {
+			conflicts++
+		}
+	}
+
+	// JSON 输出中冲突通过 status 体现
+	if ok, err := util.Render(ctx, results); ok || err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		switch r.Status {
+		case diff.ResultApplied:
+			i18n.Printf("已应用：%s\n", r.Path)
+		case diff.ResultCreated:
+			i18n.Printf("已创建：%s\n", r.Path)
+		case diff.ResultDeleted:
+			i18n.Printf("已删除：%s\n", r.Path)
+		case diff.ResultUnchanged:
+			i18n.Printf("无需变更：%s\n", r.Path)
+		case diff.ResultConflict:
+			i18n.Printf("冲突：%s (%s)\n", r.Path, conflictReason(r))
+			for _, h := range r.Conflicts {
+				fmt.Printf("  %s\n", h)
+			}
+			if r.Reject != "" {
+				i18n.Printf("  未应用的补丁段已写入 %s\n", r.Reject)
+			}
+		}
+	}
+
+	if conflicts > 0 {
+		return i18n.Errorf("%d 个文件存在冲突", conflicts)
+	}
+	return nil
+}
+
+// conflictReason 冲突原因的本地化描述
+func conflictReason(r diff.Result) string {
+	switch r.Reason {
+	case diff.ReasonExists:
+		return i18n.T("本地文件已存在")
+	case diff.ReasonNotFound:
+		return i18n.T("本地文件不存在")
+	case diff.ReasonModified:
+		return i18n.T("本地文件有改动")
+	}
+	return i18n.Sprintf("%d 个补丁段无法应用", len(r.Conflicts))
+}
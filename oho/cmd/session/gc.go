@@ -0,0 +1,255 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/cache"
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/retention"
+	"github.com/anomalyco/oho/internal/schema"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+var (
+	gcPolicy     string
+	gcDryRun     bool
+	gcYes        bool
+	gcParallel   int
+	gcReportFile string
+)
+
+func init() {
+	gcCmd.Flags().StringVar(&gcPolicy, "policy", "", "保留策略文件 (YAML)")
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "只输出报告，不修改会话")
+	gcCmd.Flags().BoolVarP(&gcYes, "yes", "y", false, "跳过确认")
+	gcCmd.Flags().IntVar(&gcParallel, "parallel", 4, "并发执行的数量")
+	gcCmd.Flags().StringVar(&gcReportFile, "report", "", "将报告以 JSON Lines 格式追加到文件")
+	_ = gcCmd.MarkFlagRequired("policy")
+	// 读写本机文件的路径不作为 MCP 工具的参数
+	_ = gcCmd.Flags().SetAnnotation("policy", "mcp", []string{"skip"})
+	_ = gcCmd.Flags().SetAnnotation("report", "mcp", []string{"skip"})
+
+	Cmd.AddCommand(gcCmd)
+	schema.Register("session gc", types.GCReport{})
+}
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "按保留策略归档和删除旧会话",
+	Long: `按保留策略归档空闲的会话，并删除归档已久的会话。
+
+策略字段:
+  archive_after  空闲（最后更新）超过该时长的会话归档
+  delete_after   归档超过该时长的会话删除
+  keep_last      每个目录保留最近更新的会话数，这些会话不会被处理
+时长支持 90m、12h、7d、2w 等格式。
+
+已分享的会话、正在运行的会话以及有正在运行的子会话的会话不会被处理，
+它们在报告中标记为 skipped。
+
+策略示例:
+  archive_after: 14d
+  delete_after: 30d
+  keep_last: 5`,
+	Example: `  oho session gc --policy retention.yaml --dry-run
+  oho session gc --policy retention.yaml --yes --report ~/.local/state/oho/gc.jsonl`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policy, err := retention.LoadPolicy(gcPolicy)
+		if err != nil {
+			return err
+		}
+
+		c := client.NewClient()
+		ctx := cmd.Context()
+
+		now := time.Now()
+		results, err := planGC(ctx, c, policy, now)
+		if err != nil {
+			return err
+		}
+
+		planned := 0
+		for _, r := range results {
+			if r.Status == retention.StatusPlanned {
+				planned++
+			}
+		}
+
+		if planned > 0 && !gcDryRun {
+			if !gcYes {
+				// 确认需要从终端读取回答，结构化输出时必须指定 --yes
+				if !util.TextOutput(ctx) {
+					return i18n.Errorf("使用 --output 或 --json 时请使用 --yes 确认操作")
+				}
+				printGCResults(ctx, results)
+				if !util.Confirm(ctx, i18n.Sprintf("确定处理以上 %d 个会话？", planned)) {
+					fmt.Println(i18n.T("已取消"))
+					return nil
+				}
+				fmt.Println()
+			}
+			results = runGC(ctx, c, results, gcParallel)
+		}
+
+		report := gcReport(results, gcPolicy, gcDryRun, now)
+		if gcReportFile != "" {
+			if err := appendReport(gcReportFile, report); err != nil {
+				return err
+			}
+		}
+
+		if ok, err := util.Render(ctx, report); ok || err != nil {
+			return err
+		}
+		return printGCReport(ctx, report)
+	},
+}
+
+// planGC 获取会话列表和状态，按策略计算清理计划
+// 会话状态用于保护正在运行的会话，获取失败时不继续执行
+func planGC(ctx context.Context, c client.ClientInterface, policy *retention.Policy, now time.Time) ([]types.GCResult, error) {
+	sessions, err := cache.Fetch(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Get(ctx, "/session/status")
+	if err != nil {
+		return nil, err
+	}
+	var statuses map[string]types.SessionStatus
+	if err := json.Unmarshal(resp, &statuses); err != nil {
+		return nil, i18n.Errorf("解析会话状态失败：%w", err)
+	}
+
+	return policy.Plan(sessions, statuses, now), nil
+}
+
+// runGC 并发执行计划中的动作，返回更新了状态的结果
+func runGC(ctx context.Context, c client.ClientInterface, results []types.GCResult, parallel int) []types.GCResult {
+	var targets []types.Session
+	index := map[string]int{}
+	for i, r := range results {
+		if r.Status == retention.StatusPlanned {
+			index[r.ID] = i
+			targets = append(targets, types.Session{ID: r.ID, Title: r.Title, Directory: r.Directory})
+		}
+	}
+
+	action := bulkAction{
+		name: "gc",
+		run: func(ctx context.Context, c client.ClientInterface, s types.Session) error {
+			if results[index[s.ID]].Action == retention.ActionDelete {
+				return bulkDelete.run(ctx, c, s)
+			}
+			return bulkArchive.run(ctx, c, s)
+		},
+	}
+
+	updated := append([]types.GCResult(nil), results...)
+	for _, r := range runParallel(ctx, c, targets, action, parallel) {
+		i := index[r.ID]
+		updated[i].Status = r.Status
+		updated[i].Error = r.Error
+	}
+	return updated
+}
+
+// gcReport 汇总结果
+func gcReport(results []types.GCResult, policy string, dryRun bool, now time.Time) types.GCReport {
+	if abs, err := filepath.Abs(policy); err == nil {
+		policy = abs
+	}
+	report := types.GCReport{
+		Time:    now.UnixMilli(),
+		Policy:  policy,
+		DryRun:  dryRun,
+		Results: results,
+	}
+	if report.Results == nil {
+		report.Results = []types.GCResult{}
+	}
+	for _, r := range results {
+		switch {
+		case r.Status == bulkDone && r.Action == retention.ActionArchive:
+			report.Archived++
+		case r.Status == bulkDone && r.Action == retention.ActionDelete:
+			report.Deleted++
+		case r.Status == retention.StatusSkipped:
+			report.Skipped++
+		case r.Status == bulkFailed:
+			report.Failed++
+		}
+	}
+	return report
+}
+
+// appendReport 将报告作为一行 JSON 追加到文件，便于定时任务保留历史
+func appendReport(file string, report types.GCReport) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return i18n.Errorf("写入报告失败：%w", err)
+	}
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return i18n.Errorf("写入报告失败：%w", err)
+	}
+	defer f.Close()
+	if err := json.NewEncoder(f).Encode(report); err != nil {
+		return i18n.Errorf("写入报告失败：%w", err)
+	}
+	return nil
+}
+
+// printGCResults 以表格列出每个会话的动作和结果
+func printGCResults(ctx context.Context, results []types.GCResult) {
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		note := r.Reason
+		if r.Error != "" {
+			note = util.Truncate(strings.Join(strings.Fields(r.Error), " "), 60)
+		}
+		rows = append(rows, []string{r.ID, util.Truncate(r.Title, 30), util.Truncate(r.Directory, 30), r.Action, r.Age, r.Status, note})
+	}
+	util.OutputTable(ctx, []string{"ID", i18n.T("标题"), i18n.T("目录"), i18n.T("动作"), i18n.T("时长"), i18n.T("结果"), i18n.T("说明")}, rows)
+}
+
+// printGCReport 输出报告，有失败时返回错误
+func printGCReport(ctx context.Context, report types.GCReport) error {
+	if len(report.Results) == 0 {
+		fmt.Println(i18n.T("没有需要处理的会话"))
+		return nil
+	}
+	printGCResults(ctx, report.Results)
+	fmt.Println()
+	if report.DryRun {
+		archive, del := 0, 0
+		for _, r := range report.Results {
+			if r.Status != retention.StatusPlanned {
+				continue
+			}
+			if r.Action == retention.ActionDelete {
+				del++
+			} else {
+				archive++
+			}
+		}
+		i18n.Printf("将归档 %d 个，删除 %d 个，跳过 %d 个（--dry-run，未执行）\n", archive, del, report.Skipped)
+		return nil
+	}
+	i18n.Printf("已归档 %d 个，已删除 %d 个，跳过 %d 个，失败 %d 个\n", report.Archived, report.Deleted, report.Skipped, report.Failed)
+	if report.Failed > 0 {
+		return i18n.Errorf("%d 个会话操作失败", report.Failed)
+	}
+	return nil
+}
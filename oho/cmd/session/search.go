@@ -0,0 +1,314 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/cache"
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
+	"github.com/anomalyco/oho/internal/search"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// 搜索范围
+const (
+	fieldText       = "text"
+	fieldToolInput  = "tool-input"
+	fieldToolOutput = "tool-output"
+	fieldFile       = "file"
+)
+
+// 片段中匹配前后保留的字符数
+const (
+	snippetBefore = 40
+	snippetAfter  = 80
+)
+
+var (
+	searchRegex         bool
+	searchCaseSensitive bool
+	searchIn            []string
+	searchLimit         int
+	searchParallel      int
+)
+
+func init() {
+	searchCmd.Flags().BoolVarP(&searchRegex, "regex", "E", false, "将查询作为正则表达式")
+	searchCmd.Flags().BoolVar(&searchCaseSensitive, "case-sensitive", false, "区分大小写")
+	searchCmd.Flags().StringSliceVar(&searchIn, "in", []string{"text", "tool", "file"}, "搜索范围 (text、tool、file)")
+	searchCmd.Flags().StringVar(&filterProjectID, "project-id", "", "只搜索指定项目的会话（支持模糊查询）")
+	searchCmd.Flags().StringVar(&filterDirectory, "directory", "", "只搜索指定目录的会话（支持模糊查询）")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 20, "最多显示的结果数 (0 表示不限)")
+	searchCmd.Flags().IntVar(&searchParallel, "parallel", 4, "同时获取消息的会话数")
+
+	Cmd.AddCommand(searchCmd)
+	schema.Register("session search", []types.SearchHit{})
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "在会话记录中全文搜索",
+	Long: `在会话的消息文本、工具调用的输入输出和文件路径中搜索。
+
+默认将查询按空白分隔为多个词，不区分大小写，命中任意一个词即为匹配；
+命中的词越多、完整短语出现的结果排名越靠前。使用 -E 将查询作为正则表达式。
+匹配在压缩空白后的单行文本上进行。
+
+使用 -s 只搜索一个会话，使用 --project-id 或 --directory 限定会话范围。`,
+	Example: `  oho session search "rate limit"
+  oho session search -E 'func \w+Handler' --directory api --in tool
+  oho session search migration -s ses_xxx --limit 0`,
+	Annotations: map[string]string{"mcp": "readonly"},
+	Args:        cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m, err := search.New(strings.Join(args, " "), searchRegex, searchCaseSensitive)
+		if err != nil {
+			return err
+		}
+		fields, err := searchFields(searchIn)
+		if err != nil {
+			return err
+		}
+
+		c := client.NewClient()
+		ctx := cmd.Context()
+
+		all, err := loadSessions(ctx, c)
+		if err != nil {
+			return err
+		}
+		filter := sessionFilter{ProjectID: filterProjectID, Directory: filterDirectory}
+		now := time.Now()
+		var sessions []types.Session
+		for _, s := range all {
+			if filter.matchFields(s, now) {
+				sessions = append(sessions, s)
+			}
+		}
+		if sessionID != "" {
+			sessions = sessionByID(sessions, sessionID)
+		}
+
+		// 索引不可用时不使用消息快照
+		idx, err := cache.OpenDefault()
+		if err != nil {
+			idx = nil
+		}
+		hits, failed := searchSessions(ctx, c, idx, sessions, m, fields, searchParallel)
+		for _, r := range failed {
+			i18n.Fprintf(os.Stderr, "警告：获取会话 %s 的消息失败：%s\n", r.ID, r.Error)
+		}
+
+		total := len(hits)
+		if searchLimit > 0 && len(hits) > searchLimit {
+			hits = hits[:searchLimit]
+		}
+		if hits == nil {
+			hits = []types.SearchHit{}
+		}
+
+		if ok, err := util.Render(ctx, hits); ok || err != nil {
+			return err
+		}
+		printSearchHits(hits, total)
+		return nil
+	},
+}
+
+// searchFields 将 --in 的值展开为字段集合，tool 包含工具的输入和输出
+func searchFields(in []string) (map[string]bool, error) {
+	fields := map[string]bool{}
+	for _, v := range in {
+		switch strings.TrimSpace(v) {
+		case "text":
+			fields[fieldText] = true
+		case "tool":
+			fields[fieldToolInput] = true
+			fields[fieldToolOutput] = true
+		case "file":
+			fields[fieldFile] = true
+		default:
+			return nil, i18n.Errorf("无效的搜索范围：%s（可用：text、tool、file）", v)
+		}
+	}
+	return fields, nil
+}
+
+// sessionByID 只保留指定 ID 的会话，列表中没有时仍按该 ID 搜索
+func sessionByID(sessions []types.Session, id string) []types.Session {
+	for _, s := range sessions {
+		if s.ID == id {
+			return []types.Session{s}
+		}
+	}
+	return []types.Session{{ID: id}}
+}
+
+// searchSessions 并发获取会话的消息并搜索，结果按得分降序排列
+// 得分相同时最近更新的会话在前，同一会话内按消息顺序排列；返回获取失败的会话
+// idx 不为 nil 时使用并更新其中的消息快照
+func searchSessions(ctx context.Context, c client.ClientInterface, idx *cache.Index, sessions []types.Session, m *search.Matcher, fields map[string]bool, parallel int) ([]types.SearchHit, []types.BulkResult) {
+	sorted := append([]types.Session(nil), sessions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Updated > sorted[j].Time.Updated
+	})
+
+	var mu sync.Mutex
+	perSession := make(map[string][]types.SearchHit, len(sorted))
+	action := bulkAction{
+		name: "search",
+		run: func(ctx context.Context, c client.ClientInterface, s types.Session) error {
+			messages, err := loadMessages(ctx, c, idx, s)
+			if err != nil {
+				return err
+			}
+			hits := searchMessages(s, messages, m, fields)
+			mu.Lock()
+			perSession[s.ID] = hits
+			mu.Unlock()
+			return nil
+		},
+	}
+
+	var failed []types.BulkResult
+	for _, r := range runParallel(ctx, c, sorted, action, parallel) {
+		if r.Status == bulkFailed {
+			failed = append(failed, r)
+		}
+	}
+
+	var hits []types.SearchHit
+	for _, s := range sorted {
+		hits = append(hits, perSession[s.ID]...)
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+	return hits, failed
+}
+
+// searchMessages 在一个会话的消息中搜索，每个消息部分的每个字段最多产生一条结果
+func searchMessages(s types.Session, messages []types.MessageWithParts, m *search.Matcher, fields map[string]bool) []types.SearchHit {
+	var hits []types.SearchHit
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			for _, f := range partFields(part) {
+				if !fields[f.name] {
+					continue
+				}
+				text := search.Normalize(f.text)
+				score, ranges := m.Match(text)
+				if score == 0 {
+					continue
+				}
+				snippet, highlights := search.Snippet(text, ranges, snippetBefore, snippetAfter)
+				hits = append(hits, types.SearchHit{
+					SessionID:    s.ID,
+					SessionTitle: s.Title,
+					MessageID:    msg.Info.ID,
+					Role:         msg.Info.Role,
+					Field:        f.name,
+					Tool:         part.Tool,
+					Score:        score,
+					Snippet:      snippet,
+					Highlights:   highlights,
+				})
+			}
+		}
+	}
+	return hits
+}
+
+// partField 消息部分中可搜索的一段内容
+type partField struct {
+	name string
+	text string
+}
+
+// partFields 提取消息部分中可搜索的内容
+func partFields(part types.Part) []partField {
+	var fields []partField
+	switch part.Type {
+	case "text", "reasoning":
+		if part.Text != nil {
+			fields = append(fields, partField{fieldText, *part.Text})
+		}
+	case "tool":
+		if part.State == nil {
+			break
+		}
+		if len(part.State.Input) > 0 {
+			var input bytes.Buffer
+			if json.Compact(&input, part.State.Input) == nil {
+				fields = append(fields, partField{fieldToolInput, input.String()})
+			}
+		}
+		if output := strings.TrimSpace(part.State.Output + "\n" + part.State.Error); output != "" {
+			fields = append(fields, partField{fieldToolOutput, output})
+		}
+	case "file":
+		var paths []string
+		if part.Filename != "" {
+			paths = append(paths, part.Filename)
+		}
+		if part.Source != nil && part.Source.Path != "" {
+			paths = append(paths, part.Source.Path)
+		}
+		// data URL 是文件内容，不作为路径搜索
+		if part.URL != "" && !strings.HasPrefix(part.URL, "data:") {
+			paths = append(paths, part.URL)
+		}
+		if len(paths) > 0 {
+			fields = append(fields, partField{fieldFile, strings.Join(paths, " ")})
+		}
+	case "patch":
+		if len(part.Files) > 0 {
+			fields = append(fields, partField{fieldFile, strings.Join(part.Files, " ")})
+		}
+	}
+	return fields
+}
+
+// printSearchHits 输出结果，终端中高亮匹配内容
+func printSearchHits(hits []types.SearchHit, total int) {
+	if total == 0 {
+		fmt.Println(i18n.T("没有找到匹配的内容"))
+		return
+	}
+
+	open, close := "", ""
+	if util.ColorEnabled() {
+		open, close = "\x1b[1;33m", "\x1b[0m"
+	}
+	for i, h := range hits {
+		where := h.Field
+		if h.Tool != "" {
+			where += " (" + h.Tool + ")"
+		}
+		title := ""
+		if h.SessionTitle != "" {
+			title = "  " + util.Truncate(h.SessionTitle, 40)
+		}
+		fmt.Printf("%d. %s%s  %s  %s  %s\n", i+1, h.SessionID, title, h.MessageID, h.Role, where)
+		fmt.Printf("   %s\n", search.Highlight(h.Snippet, h.Highlights, open, close))
+	}
+
+	fmt.Println()
+	if len(hits) < total {
+		i18n.Printf("显示前 %d 条，共 %d 条结果（使用 --limit 0 显示全部）\n", len(hits), total)
+	} else {
+		i18n.Printf("共 %d 条结果\n", total)
+	}
+}
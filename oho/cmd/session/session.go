@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"sort"
@@ -13,8 +14,13 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
-	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/guard"
+	"github.com/anomalyco/oho/internal/hook"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+	"github.com/anomalyco/oho/internal/worktree"
 )
 
 // Cmd 会话命令
@@ -55,6 +61,10 @@ var (
 	filterUpdated   int64
 	filterProjectID string
 	filterDirectory string
+	filterOlderThan time.Duration
+	useWorktree     bool
+	submitLimits    guard.Limits
+	submitHooks     hook.Hooks
 )
 
 func init() {
@@ -96,28 +106,72 @@ func init() {
 	listCmd.Flags().Int64Var(&filterUpdated, "updated", 0, "按更新时间过滤（时间戳，精确匹配）")
 	listCmd.Flags().StringVar(&filterProjectID, "project-id", "", "按项目 ID 过滤（支持模糊查询）")
 	listCmd.Flags().StringVar(&filterDirectory, "directory", "", "按目录过滤（支持模糊查询）")
+	listCmd.Flags().StringVar(&olderThan, "older-than", "", "只显示最后更新早于指定时长之前的会话（如 12h、7d）")
+
+	// 批量操作标志
+	addBulkFlags(deleteCmd)
+	addBulkFlags(abortCmd)
+	addBulkFlags(achieveCmd)
+
+	// 其他破坏性命令的会话引用同样只接受完整 ID、唯一的 ID 前缀和别名
+	for _, cmd := range []*cobra.Command{unshareCmd, revertCmd, unrevertCmd} {
+		strictRefs[cmd] = true
+	}
 
 	// createCmd 标志
 	createCmd.Flags().StringVar(&parentID, "parent", "", "父会话 ID（用于创建子会话）")
 	createCmd.Flags().StringVar(&title, "title", "", "会话标题")
+
+	// JSON 输出 Schema
+	schema.Register("session list", []types.Session{})
+	schema.Register("session create", types.Session{})
+	schema.Register("session status", map[string]types.SessionStatus{})
+	schema.Register("session get", types.Session{})
+	schema.Register("session delete", types.ActionResult{})
+	schema.Register("session update", types.Session{})
+	schema.Register("session children", []types.Session{})
+	schema.Register("session todo", []types.Todo{})
+	schema.Register("session init", types.ActionResult{})
+	schema.Register("session fork", types.Session{})
+	schema.Register("session abort", types.ActionResult{})
+	schema.Register("session share", types.Session{})
+	schema.Register("session unshare", types.Session{})
+	schema.Register("session diff", []types.FileDiff{})
+	schema.Register("session summarize", types.ActionResult{})
+	schema.Register("session revert", types.ActionResult{})
+	schema.Register("session unrevert", types.ActionResult{})
+	schema.Register("session permissions", types.ActionResult{})
+	schema.Register("session submit", types.SubmitResult{})
+	schema.Register("session achieve", types.Session{})
 }
 
 // listCmd 列出所有会话
 var listCmd = &cobra.Command{
-	Use:   "list",
-	Short: "列出所有会话",
+	Use:         "list",
+	Short:       "列出所有会话",
+	Annotations: map[string]string{"mcp": "readonly"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		c := client.NewClient()
-		ctx := context.Background()
+		if statusFilter != "" {
+			if err := validateStatus(statusFilter); err != nil {
+				return err
+			}
+		}
 
-		// 获取会话列表
-		resp, err := c.Get(ctx, "/session")
-		if err != nil {
-			return err
+		filterOlderThan = 0
+		if olderThan != "" {
+			age, err := util.ParseAge(olderThan)
+			if err != nil {
+				return err
+			}
+			filterOlderThan = age
 		}
 
-		var sessions []types.Session
-		if err := json.Unmarshal(resp, &sessions); err != nil {
+		c := client.NewClient()
+		ctx := cmd.Context()
+
+		// 获取会话列表（优先使用本地索引）
+		sessions, err := loadSessions(ctx, c)
+		if err != nil {
 			return err
 		}
 
@@ -146,27 +200,7 @@ var listCmd = &cobra.Command{
 			var filteredSessions []types.Session
 			for _, session := range sessions {
 				status, exists := statusMap[session.ID]
-				match := false
-				switch statusFilter {
-				case "running":
-					// running 状态必须在 statusMap 中存在且 IsWorking=true
-					match = exists && status.IsWorking
-				case "completed", "idle":
-					// completed/idle: 如果 statusMap 中没有该会话，说明不在工作中，视为 completed
-					// 如果存在且不是 working 状态，也是 completed
-					if !exists {
-						match = true
-					} else {
-						match = !status.IsWorking
-					}
-				case "error":
-					match = exists && status.Status == "error"
-				case "aborted":
-					match = exists && status.Status == "aborted"
-				default:
-					match = true
-				}
-				if match {
+				if matchStatus(statusFilter, status, exists) {
 					filteredSessions = append(filteredSessions, session)
 				}
 			}
@@ -206,7 +240,7 @@ var listCmd = &cobra.Command{
 			sessions = sessions[start:end]
 		}
 
-		return outputSessions(sessions)
+		return outputSessions(ctx, sessions)
 	},
 }
 
@@ -216,12 +250,17 @@ var createCmd = &cobra.Command{
 	Short: "创建新会话",
 	Long:  "创建一个新的 OpenCode 会话，可选择指定父会话和标题",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		parent, err := resolveSession(cmd.Context(), parentID)
+		if err != nil {
+			return err
+		}
+
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		req := map[string]interface{}{}
-		if parentID != "" {
-			req["parentID"] = parentID
+		if parent != "" {
+			req["parentID"] = parent
 		}
 		if title != "" {
 			req["title"] = title
@@ -237,12 +276,16 @@ var createCmd = &cobra.Command{
 			return err
 		}
 
-		fmt.Printf("会话创建成功:\n")
+		if ok, err := util.Render(ctx, session); ok || err != nil {
+			return err
+		}
+
+		i18n.Printf("会话创建成功:\n")
 		fmt.Printf("  ID: %s\n", session.ID)
 		if session.Title != "" {
-			fmt.Printf("  标题：%s\n", session.Title)
+			i18n.Printf("  标题：%s\n", session.Title)
 		}
-		fmt.Printf("  模型：%s\n", session.Model)
+		i18n.Printf("  模型：%s\n", session.Model)
 
 		return nil
 	},
@@ -250,11 +293,12 @@ var createCmd = &cobra.Command{
 
 // statusCmd 获取所有会话状态
 var statusCmd = &cobra.Command{
-	Use:   "status",
-	Short: "获取所有会话状态",
+	Use:         "status",
+	Short:       "获取所有会话状态",
+	Annotations: map[string]string{"mcp": "readonly"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		resp, err := c.Get(ctx, "/session/status")
 		if err != nil {
@@ -266,14 +310,12 @@ var statusCmd = &cobra.Command{
 			return err
 		}
 
-		if config.Get().JSON {
-			data, _ := json.MarshalIndent(status, "", "  ")
-			fmt.Println(string(data))
-			return nil
+		if ok, err := util.Render(ctx, status); ok || err != nil {
+			return err
 		}
 
 		for id, s := range status {
-			fmt.Printf("%s: %s (就绪：%v, 工作中：%v)\n", id, s.Status, s.IsReady, s.IsWorking)
+			i18n.Printf("%s: %s (就绪：%v, 工作中：%v)\n", id, s.Status, s.IsReady, s.IsWorking)
 		}
 
 		return nil
@@ -282,64 +324,64 @@ var statusCmd = &cobra.Command{
 
 // getCmd 获取会话详情
 var getCmd = &cobra.Command{
-	Use:   "get [id]",
-	Short: "获取会话详情",
-	Args:  cobra.MaximumNArgs(1),
+	Use:         "get [id]",
+	Short:       "获取会话详情",
+	Annotations: map[string]string{"mcp": "readonly"},
+	Args:        cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
-		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
+		id, err := sessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
-		resp, err := c.Get(ctx, fmt.Sprintf("/session/%s", id))
+		session, err := loadSession(ctx, c, id)
 		if err != nil {
 			return err
 		}
 
-		var session types.Session
-		if err := json.Unmarshal(resp, &session); err != nil {
-			return err
-		}
-
-		return outputSessions([]types.Session{session})
+		return outputSessions(ctx, []types.Session{session})
 	},
 }
 
 // deleteCmd 删除会话
 var deleteCmd = &cobra.Command{
-	Use:   "delete [id]",
+	Use:   "delete [id...|-]",
 	Short: "删除会话",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `删除会话。
+
+指定多个 ID、使用 - 从 stdin 读取 ID 或使用 --where 按条件选择会话时进入批量模式：
+先列出会话并确认，然后并发执行，最后输出每个会话的结果。`,
+	Example: `  oho session delete ses_xxx
+  oho session delete --where status=idle --where older-than=7d --dry-run
+  oho session list --status error -o json | jq -r '.data[].id' | oho session delete - -y`,
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
+		if isBulk(args) {
+			return runBulk(cmd.Context(), args, bulkDelete)
 		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
+
+		id, err := strictSessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
-		resp, err := c.Delete(ctx, fmt.Sprintf("/session/%s", id))
+		deleted, err := deleteSession(ctx, c, id)
 		if err != nil {
 			return err
 		}
 
-		var deleted bool
-		if err := json.Unmarshal(resp, &deleted); err != nil {
+		if ok, err := util.Render(ctx, types.ActionResult{ID: id, Success: deleted}); ok || err != nil {
 			return err
 		}
 
 		if deleted {
-			fmt.Printf("会话 %s 已删除\n", id)
+			i18n.Printf("会话 %s 已删除\n", id)
 		}
 
 		return nil
@@ -352,19 +394,16 @@ var updateCmd = &cobra.Command{
 	Short: "更新会话属性",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
-		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
+		id, err := sessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
 		}
 		if title == "" {
-			return fmt.Errorf("请使用 --title 指定新标题")
+			return i18n.Errorf("请使用 --title 指定新标题")
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		req := map[string]interface{}{"title": title}
 		resp, err := c.Patch(ctx, fmt.Sprintf("/session/%s", id), req)
@@ -377,27 +416,29 @@ var updateCmd = &cobra.Command{
 			return err
 		}
 
-		fmt.Printf("会话标题已更新为：%s\n", session.Title)
+		if ok, err := util.Render(ctx, session); ok || err != nil {
+			return err
+		}
+
+		i18n.Printf("会话标题已更新为：%s\n", session.Title)
 		return nil
 	},
 }
 
 // childrenCmd 获取子会话
 var childrenCmd = &cobra.Command{
-	Use:   "children [id]",
-	Short: "获取子会话",
-	Args:  cobra.MaximumNArgs(1),
+	Use:         "children [id]",
+	Short:       "获取子会话",
+	Annotations: map[string]string{"mcp": "readonly"},
+	Args:        cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
-		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
+		id, err := sessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		resp, err := c.Get(ctx, fmt.Sprintf("/session/%s/children", id))
 		if err != nil {
@@ -409,26 +450,24 @@ var childrenCmd = &cobra.Command{
 			return err
 		}
 
-		return outputSessions(sessions)
+		return outputSessions(ctx, sessions)
 	},
 }
 
 // todoCmd 获取待办事项
 var todoCmd = &cobra.Command{
-	Use:   "todo [id]",
-	Short: "获取会话待办事项",
-	Args:  cobra.MaximumNArgs(1),
+	Use:         "todo [id]",
+	Short:       "获取会话待办事项",
+	Annotations: map[string]string{"mcp": "readonly"},
+	Args:        cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
-		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
+		id, err := sessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		resp, err := c.Get(ctx, fmt.Sprintf("/session/%s/todo", id))
 		if err != nil {
@@ -440,10 +479,8 @@ var todoCmd = &cobra.Command{
 			return err
 		}
 
-		if config.Get().JSON {
-			data, _ := json.MarshalIndent(todos, "", "  ")
-			fmt.Println(string(data))
-			return nil
+		if ok, err := util.Render(ctx, todos); ok || err != nil {
+			return err
 		}
 
 		for _, todo := range todos {
@@ -464,19 +501,16 @@ var initCmd = &cobra.Command{
 	Short: "分析应用并创建 AGENTS.md",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
-		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
+		id, err := sessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
 		}
 		if providerID == "" || modelID == "" {
-			return fmt.Errorf("请提供 --provider 和 --model 参数")
+			return i18n.Errorf("请提供 --provider 和 --model 参数")
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		req := map[string]interface{}{
 			"messageID":  messageID,
@@ -494,8 +528,12 @@ var initCmd = &cobra.Command{
 			return err
 		}
 
+		if ok, err := util.Render(ctx, types.ActionResult{ID: id, Success: success}); ok || err != nil {
+			return err
+		}
+
 		if success {
-			fmt.Println("AGENTS.md 创建成功")
+			fmt.Println(i18n.T("AGENTS.md 创建成功"))
 		}
 
 		return nil
@@ -508,16 +546,13 @@ var forkCmd = &cobra.Command{
 	Short: "在某条消息处分叉会话",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
-		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
+		id, err := sessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		req := map[string]interface{}{}
 		if messageID != "" {
@@ -534,41 +569,50 @@ var forkCmd = &cobra.Command{
 			return err
 		}
 
-		fmt.Printf("会话分叉成功:\n")
-		fmt.Printf("  新 ID: %s\n", session.ID)
+		if ok, err := util.Render(ctx, session); ok || err != nil {
+			return err
+		}
+
+		i18n.Printf("会话分叉成功:\n")
+		i18n.Printf("  新 ID: %s\n", session.ID)
 		return nil
 	},
 }
 
 // abortCmd 中止会话
 var abortCmd = &cobra.Command{
-	Use:   "abort [id]",
+	Use:   "abort [id...|-]",
 	Short: "中止正在运行的会话",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `中止正在运行的会话。
+
+指定多个 ID、使用 - 从 stdin 读取 ID 或使用 --where 按条件选择会话时进入批量模式。`,
+	Example: `  oho session abort ses_xxx
+  oho session abort --where status=running --where directory=/repo -y`,
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
+		if isBulk(args) {
+			return runBulk(cmd.Context(), args, bulkAbort)
 		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
+
+		id, err := strictSessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
-		resp, err := c.Post(ctx, fmt.Sprintf("/session/%s/abort", id), nil)
+		success, err := abortSession(ctx, c, id)
 		if err != nil {
 			return err
 		}
 
-		var success bool
-		if err := json.Unmarshal(resp, &success); err != nil {
+		if ok, err := util.Render(ctx, types.ActionResult{ID: id, Success: success}); ok || err != nil {
 			return err
 		}
 
 		if success {
-			fmt.Printf("会话 %s 已中止\n", id)
+			i18n.Printf("会话 %s 已中止\n", id)
 		}
 
 		return nil
@@ -581,16 +625,13 @@ var shareCmd = &cobra.Command{
 	Short: "分享会话",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
-		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
+		id, err := sessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		resp, err := c.Post(ctx, fmt.Sprintf("/session/%s/share", id), nil)
 		if err != nil {
@@ -602,7 +643,11 @@ var shareCmd = &cobra.Command{
 			return err
 		}
 
-		fmt.Printf("会话已分享\n")
+		if ok, err := util.Render(ctx, session); ok || err != nil {
+			return err
+		}
+
+		i18n.Printf("会话已分享\n")
 		return nil
 	},
 }
@@ -613,16 +658,13 @@ var unshareCmd = &cobra.Command{
 	Short: "取消分享会话",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
-		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
+		id, err := strictSessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		resp, err := c.Delete(ctx, fmt.Sprintf("/session/%s/share", id))
 		if err != nil {
@@ -634,53 +676,11 @@ var unshareCmd = &cobra.Command{
 			return err
 		}
 
-		fmt.Printf("会话已取消分享\n")
-		return nil
-	},
-}
-
-// diffCmd 获取会话差异
-var diffCmd = &cobra.Command{
-	Use:   "diff [id]",
-	Short: "获取会话差异",
-	Args:  cobra.MaximumNArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
-		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
-		}
-
-		c := client.NewClient()
-		ctx := context.Background()
-
-		queryParams := map[string]string{}
-		if messageID != "" {
-			queryParams["messageID"] = messageID
-		}
-
-		resp, err := c.GetWithQuery(ctx, fmt.Sprintf("/session/%s/diff", id), queryParams)
-		if err != nil {
+		if ok, err := util.Render(ctx, session); ok || err != nil {
 			return err
 		}
 
-		var diffs []types.FileDiff
-		if err := json.Unmarshal(resp, &diffs); err != nil {
-			return err
-		}
-
-		if config.Get().JSON {
-			data, _ := json.MarshalIndent(diffs, "", "  ")
-			fmt.Println(string(data))
-			return nil
-		}
-
-		for _, diff := range diffs {
-			fmt.Printf("文件：%s (状态：%s)\n", diff.Path, diff.Status)
-		}
-
+		i18n.Printf("会话已取消分享\n")
 		return nil
 	},
 }
@@ -691,19 +691,16 @@ var summarizeCmd = &cobra.Command{
 	Short: "总结会话",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
-		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
+		id, err := sessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
 		}
 		if providerID == "" || modelID == "" {
-			return fmt.Errorf("请提供 --provider 和 --model 参数")
+			return i18n.Errorf("请提供 --provider 和 --model 参数")
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		req := map[string]interface{}{
 			"providerID": providerID,
@@ -720,8 +717,12 @@ var summarizeCmd = &cobra.Command{
 			return err
 		}
 
+		if ok, err := util.Render(ctx, types.ActionResult{ID: id, Success: success}); ok || err != nil {
+			return err
+		}
+
 		if success {
-			fmt.Println("会话总结完成")
+			fmt.Println(i18n.T("会话总结完成"))
 		}
 
 		return nil
@@ -734,19 +735,16 @@ var revertCmd = &cobra.Command{
 	Short: "回退消息",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
-		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
+		id, err := strictSessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
 		}
 		if messageID == "" {
-			return fmt.Errorf("请提供 --message 参数")
+			return i18n.Errorf("请提供 --message 参数")
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		req := map[string]interface{}{
 			"messageID": messageID,
@@ -765,8 +763,12 @@ var revertCmd = &cobra.Command{
 			return err
 		}
 
+		if ok, err := util.Render(ctx, types.ActionResult{ID: id, Success: success}); ok || err != nil {
+			return err
+		}
+
 		if success {
-			fmt.Println("消息已回退")
+			fmt.Println(i18n.T("消息已回退"))
 		}
 
 		return nil
@@ -779,16 +781,13 @@ var unrevertCmd = &cobra.Command{
 	Short: "恢复所有已回退的消息",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
-		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
+		id, err := strictSessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		resp, err := c.Post(ctx, fmt.Sprintf("/session/%s/unrevert", id), nil)
 		if err != nil {
@@ -800,8 +799,12 @@ var unrevertCmd = &cobra.Command{
 			return err
 		}
 
+		if ok, err := util.Render(ctx, types.ActionResult{ID: id, Success: success}); ok || err != nil {
+			return err
+		}
+
 		if success {
-			fmt.Println("已恢复所有回退的消息")
+			fmt.Println(i18n.T("已恢复所有回退的消息"))
 		}
 
 		return nil
@@ -812,14 +815,13 @@ var unrevertCmd = &cobra.Command{
 var permissionsCmd = &cobra.Command{
 	Use:   "permissions [id] [permissionID]",
 	Short: "响应权限请求",
-	Args:  cobra.MaximumNArgs(2),
+	// 可以授予 always 批准，之后同类操作不再询问
+	Annotations: map[string]string{"mcp": "destructive"},
+	Args:        cobra.MaximumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
-		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
+		id, err := sessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
 		}
 
 		permID := permissionID
@@ -827,14 +829,14 @@ var permissionsCmd = &cobra.Command{
 			permID = args[1]
 		}
 		if permID == "" {
-			return fmt.Errorf("请提供权限 ID")
+			return i18n.Errorf("请提供权限 ID")
 		}
 		if permissionResp == "" {
-			return fmt.Errorf("请提供 --response 参数 (allow/deny)")
+			return i18n.Errorf("请提供 --response 参数 (allow/deny)")
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		req := map[string]interface{}{
 			"response": permissionResp,
@@ -851,53 +853,45 @@ var permissionsCmd = &cobra.Command{
 			return err
 		}
 
+		if ok, err := util.Render(ctx, types.ActionResult{ID: permID, Success: success}); ok || err != nil {
+			return err
+		}
+
 		if success {
-			fmt.Printf("权限请求 %s 已响应：%s\n", permID, permissionResp)
+			i18n.Printf("权限请求 %s 已响应：%s\n", permID, permissionResp)
 		}
 
 		return nil
 	},
 }
 
-func outputSessions(sessions []types.Session) error {
-	if config.Get().JSON {
-		data, _ := json.MarshalIndent(sessions, "", "  ")
-		fmt.Println(string(data))
-		return nil
+func outputSessions(ctx context.Context, sessions []types.Session) error {
+	if ok, err := util.Render(ctx, sessions); ok || err != nil {
+		return err
 	}
 
 	if len(sessions) == 0 {
-		fmt.Println("没有会话")
+		fmt.Println(i18n.T("没有会话"))
 		return nil
 	}
 
-	fmt.Printf("共 %d 个会话:\n\n", len(sessions))
+	i18n.Printf("共 %d 个会话:\n\n", len(sessions))
 	for _, s := range sessions {
-		fmt.Printf("ID:     %s\n", s.ID)
+		i18n.Printf("ID:     %s\n", s.ID)
 		if s.Title != "" {
-			fmt.Printf("标题：   %s\n", s.Title)
-		}
-		// Handle Model as interface{} (can be string or Model object)
-		if s.Model != nil {
-			switch v := s.Model.(type) {
-			case string:
-				fmt.Printf("模型：   %s\n", v)
-			case map[string]interface{}:
-				if providerID, ok := v["providerID"].(string); ok {
-					if modelID, ok := v["modelID"].(string); ok {
-						fmt.Printf("模型：   %s/%s\n", providerID, modelID)
-					}
-				}
-			}
+			i18n.Printf("标题：   %s\n", s.Title)
+		}
+		if model := modelName(s.Model); model != "" {
+			i18n.Printf("模型：   %s\n", model)
 		}
 		if s.Agent != "" {
-			fmt.Printf("代理：   %s\n", s.Agent)
+			i18n.Printf("代理：   %s\n", s.Agent)
 		}
 		if s.Directory != "" {
-			fmt.Printf("目录：   %s\n", s.Directory)
+			i18n.Printf("目录：   %s\n", s.Directory)
 		}
 		if s.ProjectID != "" {
-			fmt.Printf("项目：   %s\n", s.ProjectID)
+			i18n.Printf("项目：   %s\n", s.ProjectID)
 		}
 		fmt.Println("---")
 	}
@@ -905,53 +899,44 @@ func outputSessions(sessions []types.Session) error {
 	return nil
 }
 
+// modelName 会话的模型名称，Model 可能是字符串或 {providerID, modelID} 对象
+func modelName(model interface{}) string {
+	switch v := model.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		providerID, _ := v["providerID"].(string)
+		modelID, _ := v["modelID"].(string)
+		if providerID != "" && modelID != "" {
+			return providerID + "/" + modelID
+		}
+	}
+	return ""
+}
+
 // applyFieldFilter 应用字段过滤
 func applyFieldFilter(sessions []types.Session) []types.Session {
+	filter := sessionFilter{
+		ID:        filterID,
+		Title:     filterTitle,
+		ProjectID: filterProjectID,
+		Directory: filterDirectory,
+		Created:   filterCreated,
+		Updated:   filterUpdated,
+		OlderThan: filterOlderThan,
+	}
 	// 如果没有设置任何过滤条件，返回原列表
-	if filterID == "" && filterTitle == "" && filterCreated == 0 &&
-		filterUpdated == 0 && filterProjectID == "" && filterDirectory == "" {
+	if filter.empty() {
 		return sessions
 	}
 
+	now := time.Now()
 	var filtered []types.Session
 	for _, s := range sessions {
-		match := true
-
-		// ID 过滤（支持模糊查询）
-		if filterID != "" && !contains(s.ID, filterID) {
-			match = false
-		}
-
-		// 标题过滤（支持模糊查询）
-		if filterTitle != "" && !contains(s.Title, filterTitle) {
-			match = false
-		}
-
-		// 创建时间过滤（精确匹配）
-		if filterCreated != 0 && s.Time.Created != filterCreated {
-			match = false
-		}
-
-		// 更新时间过滤（精确匹配）
-		if filterUpdated != 0 && s.Time.Updated != filterUpdated {
-			match = false
-		}
-
-		// 项目 ID 过滤（支持模糊查询）
-		if filterProjectID != "" && !contains(s.ProjectID, filterProjectID) {
-			match = false
-		}
-
-		// 目录过滤（支持模糊查询）
-		if filterDirectory != "" && !contains(s.Directory, filterDirectory) {
-			match = false
-		}
-
-		if match {
+		if filter.matchFields(s, now) {
 			filtered = append(filtered, s)
 		}
 	}
-
 	return filtered
 }
 
@@ -963,27 +948,6 @@ func contains(s, substr string) bool {
 	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
 }
 
-// convertModel converts a model string to the appropriate format (string or object)
-func convertModel(model string) interface{} {
-	if model == "" {
-		return nil
-	}
-
-	// Check if the model string contains a colon, which indicates provider:model format
-	if strings.Contains(model, ":") {
-		parts := strings.SplitN(model, ":", 2)
-		if len(parts) == 2 {
-			return types.Model{
-				ProviderID: parts[0],
-				ModelID:    parts[1],
-			}
-		}
-	}
-
-	// If no colon, treat as simple string model (backward compatibility)
-	return model
-}
-
 // submitCmd 提交任务命令
 var submitCmd = &cobra.Command{
 	Use:   "submit [message]",
@@ -994,12 +958,18 @@ var submitCmd = &cobra.Command{
 		// Step 1: Validate flags
 		if initProject {
 			if providerID == "" || modelID == "" {
-				return fmt.Errorf("when using --init-project, --provider and --model are required")
+				return i18n.Errorf("when using --init-project, --provider and --model are required")
 			}
 		}
+		if err := submitLimits.RequireReply(noReply); err != nil {
+			return err
+		}
+		if err := submitHooks.RequireReply(noReply); err != nil {
+			return err
+		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		// Step 2: Create session
 		// 根据 OpenCode SDK: directory 是 query 参数，不是 body 参数
@@ -1015,23 +985,43 @@ var submitCmd = &cobra.Command{
 			var err error
 			sessionDir, err = os.Getwd()
 			if err != nil {
-				return fmt.Errorf("failed to get current directory: %w", err)
+				return i18n.Errorf("failed to get current directory: %w", err)
 			}
 		}
 
 		// 使用 PostWithQuery 发送 directory 作为 query 参数
-		queryParams := map[string]string{"directory": sessionDir}
-		resp, err := c.PostWithQuery(ctx, "/session", queryParams, req)
-		if err != nil {
-			return fmt.Errorf("failed to create session: %w", err)
+		var session types.Session
+		create := func(dir string) (string, error) {
+			resp, err := c.PostWithQuery(ctx, "/session", map[string]string{"directory": dir}, req)
+			if err != nil {
+				return "", err
+			}
+			if err := json.Unmarshal(resp, &session); err != nil {
+				return "", err
+			}
+			return session.ID, nil
 		}
 
-		var session types.Session
-		if err := json.Unmarshal(resp, &session); err != nil {
-			return fmt.Errorf("failed to create session: %w", err)
+		// 在独立的工作树中执行任务
+		var wt worktree.Entry
+		if useWorktree {
+			task := title
+			if task == "" {
+				task = args[0]
+			}
+			var err error
+			if wt, err = worktree.Setup(sessionDir, task, create); err != nil {
+				return err
+			}
+			sessionDir = wt.Path
+		} else if _, err := create(sessionDir); err != nil {
+			return i18n.Errorf("failed to create session: %w", err)
 		}
 
-		fmt.Printf("Session created: %s\n", session.ID)
+		util.OutputText(ctx, i18n.T("Session created: %s\n"), session.ID)
+		if useWorktree {
+			util.OutputText(ctx, i18n.T("Worktree: %s (branch %s)\n"), wt.Path, wt.Branch)
+		}
 
 		// Step 3: Initialize session (if requested)
 		if initProject {
@@ -1042,10 +1032,10 @@ var submitCmd = &cobra.Command{
 
 			_, err := c.Post(ctx, fmt.Sprintf("/session/%s/init", session.ID), initReq)
 			if err != nil {
-				return fmt.Errorf("failed to initialize session: %w", err)
+				return i18n.Errorf("failed to initialize session: %w", err)
 			}
 
-			fmt.Println("Session initialized successfully")
+			util.OutputLine(ctx, i18n.T("Session initialized successfully"))
 		}
 
 		// Step 4: Prepare message parts
@@ -1062,17 +1052,17 @@ var submitCmd = &cobra.Command{
 		for _, filePath := range files {
 			// Check if file exists
 			if _, err := os.Stat(filePath); os.IsNotExist(err) {
-				return fmt.Errorf("file not found: %s", filePath)
+				return i18n.Errorf("file not found: %s", filePath)
 			}
 
 			// Read file content
 			fileData, err := os.ReadFile(filePath)
 			if err != nil {
-				return fmt.Errorf("failed to read file: %s: %w", filePath, err)
+				return i18n.Errorf("failed to read file: %s: %w", filePath, err)
 			}
 
 			// Detect MIME type
-			mimeType := detectMimeType(filePath)
+			mimeType := util.DetectMimeType(filePath)
 
 			// Encode to base64 data URL
 			base64Data := base64.StdEncoding.EncodeToString(fileData)
@@ -1088,7 +1078,7 @@ var submitCmd = &cobra.Command{
 		// Step 5: Send message
 		msgReq := types.MessageRequest{
 			MessageID: messageID,
-			Model:     convertModel(messageModel),
+			Model:     util.ConvertModel(messageModel),
 			Agent:     messageAgent,
 			NoReply:   noReply,
 			System:    systemPrompt,
@@ -1096,76 +1086,108 @@ var submitCmd = &cobra.Command{
 			Parts:     parts,
 		}
 
-		msgResp, err := c.Post(ctx, fmt.Sprintf("/session/%s/message", session.ID), msgReq)
+		watcher, err := hook.Watch(ctx, c, types.Session{ID: session.ID, Title: session.Title, Directory: sessionDir}, submitHooks)
+		if err != nil {
+			return err
+		}
+		var msgResp []byte
+		err = guard.Send(ctx, c, session.ID, submitLimits, func() error {
+			var err error
+			msgResp, err = c.Post(ctx, fmt.Sprintf("/session/%s/message", session.ID), msgReq)
+			return err
+		})
+		hook.Report(watcher.Finish(messageIDOf(msgResp), err))
+		var abortErr *guard.AbortError
+		if errors.As(err, &abortErr) {
+			return err
+		}
 		if err != nil {
-			return fmt.Errorf("failed to send message: %w", err)
+			return i18n.Errorf("failed to send message: %w", err)
+		}
+
+		submitted := types.SubmitResult{
+			SessionID: session.ID,
+			Directory: sessionDir,
+			Title:     session.Title,
+			Branch:    wt.Branch,
+			Status:    "success",
 		}
 
 		// Handle empty response
 		if len(msgResp) == 0 {
-			fmt.Println("Message sent successfully")
+			if ok, err := util.Render(ctx, submitted); ok || err != nil {
+				return err
+			}
+			fmt.Println(i18n.T("Message sent successfully"))
 			return nil
 		}
 
 		var result types.MessageWithParts
 		if err := json.Unmarshal(msgResp, &result); err != nil {
-			return fmt.Errorf("failed to send message: %w", err)
+			return i18n.Errorf("failed to send message: %w", err)
 		}
 
-		fmt.Printf("Message sent successfully: %s\n", result.Info.ID)
+		submitted.MessageID = result.Info.ID
+		if ok, err := util.Render(ctx, submitted); ok || err != nil {
+			return err
+		}
+		i18n.Printf("Message sent successfully: %s\n", result.Info.ID)
 
 		// Step 6: Return nil on success
 		return nil
 	},
 }
 
+// messageIDOf 从发送消息的响应中取出消息 ID
+func messageIDOf(resp []byte) string {
+	var result types.MessageWithParts
+	if len(resp) == 0 || json.Unmarshal(resp, &result) != nil {
+		return ""
+	}
+	return result.Info.ID
+}
+
 // achieveCmd 归档会话
 var achieveCmd = &cobra.Command{
-	Use:   "achieve [id]",
-	Short: "归档会话",
-	Args:  cobra.MaximumNArgs(1),
+	Use:     "achieve [id...|-]",
+	Aliases: []string{"archive"},
+	Short:   "归档会话",
+	Long: `归档会话。
+
+指定多个 ID、使用 - 从 stdin 读取 ID 或使用 --where 按条件选择会话时进入批量模式；
+批量模式下优先使用会话自身的目录。`,
+	Example: `  oho session archive ses_xxx
+  oho session archive --where older-than=30d --dry-run`,
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id := sessionID
-		if len(args) > 0 {
-			id = args[0]
+		if isBulk(args) {
+			return runBulk(cmd.Context(), args, bulkArchive)
 		}
-		if id == "" {
-			return fmt.Errorf("请提供会话 ID 或使用 -s 标志")
+
+		id, err := strictSessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
 		}
 
 		c := client.NewClient()
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		// 获取当前工作目录（如果用户未指定）
-		sessionDir := directory
-		if sessionDir == "" {
-			var err error
-			sessionDir, err = os.Getwd()
-			if err != nil {
-				return fmt.Errorf("failed to get current directory: %w", err)
-			}
-		}
-
-		// 构建请求体：{"time":{"archived":timestamp}}
-		req := map[string]interface{}{
-			"time": map[string]interface{}{
-				"archived": time.Now().UnixMilli(),
-			},
+		sessionDir, err := archiveDir()
+		if err != nil {
+			return err
 		}
 
-		// 使用 PatchWithQuery 发送 directory 作为 query 参数
-		queryParams := map[string]string{"directory": sessionDir}
-		resp, err := c.PatchWithQuery(ctx, fmt.Sprintf("/session/%s", id), queryParams, req)
+		session, err := archiveSession(ctx, c, id, sessionDir)
 		if err != nil {
 			return err
 		}
 
-		var session types.Session
-		if err := json.Unmarshal(resp, &session); err != nil {
+		if ok, err := util.Render(ctx, session); ok || err != nil {
 			return err
 		}
 
-		fmt.Printf("会话 %s 已归档\n", id)
+		i18n.Printf("会话 %s 已归档\n", id)
 		return nil
 	},
 }
@@ -1177,6 +1199,7 @@ func init() {
 	submitCmd.Flags().StringVar(&modelID, "model", "", "Model ID for initialization")
 	submitCmd.Flags().StringVar(&title, "title", "", "Session title")
 	submitCmd.Flags().StringVar(&directory, "directory", "", "Working directory for the session")
+	submitCmd.Flags().BoolVar(&useWorktree, "worktree", false, "Run the task in a new git worktree on its own branch")
 
 	// Message flags
 	submitCmd.Flags().StringVar(&messageAgent, "agent", "", "Agent ID for message")
@@ -1185,69 +1208,9 @@ func init() {
 	submitCmd.Flags().StringVar(&systemPrompt, "system", "", "System prompt")
 	submitCmd.Flags().StringSliceVar(&tools, "tools", nil, "Tools list (can be specified multiple times)")
 	submitCmd.Flags().StringSliceVar(&files, "file", nil, "File attachments (can be specified multiple times)")
+	submitLimits.AddFlags(submitCmd.Flags())
+	submitHooks.AddFlags(submitCmd.Flags())
 
 	// achieveCmd flags
 	achieveCmd.Flags().StringVar(&directory, "directory", "", "Working directory for the session")
 }
-
-// detectMimeType 根据文件扩展名检测 MIME 类型
-func detectMimeType(filePath string) string {
-	ext := strings.ToLower(filePath[strings.LastIndex(filePath, "."):])
-
-	mimeTypes := map[string]string{
-		// 图片
-		".jpg":  "image/jpeg",
-		".jpeg": "image/jpeg",
-		".png":  "image/png",
-		".gif":  "image/gif",
-		".webp": "image/webp",
-		".bmp":  "image/bmp",
-		".svg":  "image/svg+xml",
-
-		// 文档
-		".pdf":  "application/pdf",
-		".doc":  "application/msword",
-		".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
-		".xls":  "application/vnd.ms-excel",
-		".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
-		".ppt":  "application/vnd.ms-powerpoint",
-		".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
-
-		// 文本
-		".txt":  "text/plain",
-		".md":   "text/markdown",
-		".html": "text/html",
-		".css":  "text/css",
-		".js":   "application/javascript",
-		".json": "application/json",
-		".xml":  "application/xml",
-		".yaml": "application/x-yaml",
-		".yml":  "application/x-yaml",
-
-		// 代码
-		".py":   "text/x-python",
-		".go":   "text/x-go",
-		".java": "text/x-java",
-		".c":    "text/x-c",
-		".cpp":  "text/x-c++",
-		".h":    "text/x-c",
-		".rs":   "text/x-rust",
-		".ts":   "text/x-typescript",
-		".tsx":  "text/x-typescript",
-
-		// 其他
-		".zip": "application/zip",
-		".tar": "application/x-tar",
-		".gz":  "application/gzip",
-		".mp3": "audio/mpeg",
-		".mp4": "video/mp4",
-		".wav": "audio/wav",
-	}
-
-	if mimeType, ok := mimeTypes[ext]; ok {
-		return mimeType
-	}
-
-	// 默认返回 octet-stream
-	return "application/octet-stream"
-}
@@ -3,12 +3,32 @@ package session
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/cache"
 	"github.com/anomalyco/oho/internal/client"
 	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/git"
+	"github.com/anomalyco/oho/internal/hook"
+	"github.com/anomalyco/oho/internal/resolve"
+	"github.com/anomalyco/oho/internal/retention"
+	"github.com/anomalyco/oho/internal/search"
 	"github.com/anomalyco/oho/internal/testutil"
 	"github.com/anomalyco/oho/internal/types"
 )
@@ -20,6 +40,11 @@ func TestMain(m *testing.M) {
 	os.Setenv("OPENCODE_SERVER_PASSWORD", "test")
 	_ = config.Init()
 
+	// 本地索引写入临时目录
+	cacheDir, _ := os.MkdirTemp("", "oho-cache")
+	os.Setenv("OHO_CACHE_DIR", cacheDir)
+	defer os.RemoveAll(cacheDir)
+
 	m.Run()
 }
 
@@ -382,6 +407,28 @@ func TestSessionDiffCmd(t *testing.T) {
 	}
 }
 
+func TestFetchDiffs(t *testing.T) {
+	var gotPath string
+	var gotQuery map[string]string
+	mock := &client.MockClient{
+		GetWithQueryFunc: func(ctx context.Context, path string, queryParams map[string]string) ([]byte, error) {
+			gotPath, gotQuery = path, queryParams
+			return testutil.MockDiffResponse(), nil
+		},
+	}
+
+	diffs, err := fetchDiffs(context.Background(), mock, "session1", "msg1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotPath != "/session/session1/diff" || gotQuery["messageID"] != "msg1" {
+		t.Errorf("Unexpected request %s %v", gotPath, gotQuery)
+	}
+	if len(diffs) != 1 || diffs[0].Path != "main.go" {
+		t.Errorf("Unexpected diffs: %+v", diffs)
+	}
+}
+
 func TestSessionSummarizeCmd(t *testing.T) {
 	mock := &client.MockClient{
 		PostFunc: func(ctx context.Context, path string, body interface{}) ([]byte, error) {
@@ -1138,3 +1185,854 @@ func TestSessionListCmdStatusFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestCommitMessage(t *testing.T) {
+	summary := "Added login form.\n\n- validates email"
+	todos := []types.Todo{
+		{Content: "Build form", Status: "completed"},
+		{Content: "Write tests", Status: "pending"},
+		{Content: "Dropped idea", Status: "cancelled"},
+	}
+
+	tests := []struct {
+		name    string
+		session types.Session
+		summary string
+		todos   []types.Todo
+		want    string
+	}{
+		{
+			name:    "full",
+			session: types.Session{ID: "ses_1", Title: "Add login", ParentID: "ses_0"},
+			summary: summary,
+			todos:   todos,
+			want:    "Add login\n\nAdded login form.\n\n- validates email\n\nTasks:\n- [x] Build form\n- [ ] Write tests\n\nSession-Id: ses_1\nSession-Parent: ses_0\n",
+		},
+		{
+			name:    "untitled",
+			session: types.Session{ID: "ses_2"},
+			want:    "Apply changes from session ses_2\n\nSession-Id: ses_2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commitMessage(tt.session, tt.summary, tt.todos); got != tt.want {
+				t.Errorf("commitMessage() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionSummary(t *testing.T) {
+	text := func(s string) []types.Part {
+		return []types.Part{{Type: "text", Text: &s}}
+	}
+
+	messages := []types.MessageWithParts{
+		{Info: types.Message{Role: "user"}, Parts: text("do it")},
+		{Info: types.Message{Role: "assistant", Summary: true}, Parts: text("summary")},
+		{Info: types.Message{Role: "assistant"}, Parts: text("last reply")},
+		{Info: types.Message{Role: "user"}, Parts: text("thanks")},
+	}
+	if got := sessionSummary(messages); got != "summary" {
+		t.Errorf("sessionSummary() = %q, want the summary message", got)
+	}
+
+	if got := sessionSummary(messages[2:]); got != "last reply" {
+		t.Errorf("sessionSummary() = %q, want the last assistant reply", got)
+	}
+	if got := sessionSummary(nil); got != "" {
+		t.Errorf("sessionSummary(nil) = %q", got)
+	}
+}
+
+func TestCommitOnBranchRollback(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for key, value := range map[string]string{
+		"GIT_AUTHOR_NAME":     "test",
+		"GIT_AUTHOR_EMAIL":    "test@example.com",
+		"GIT_COMMITTER_NAME":  "test",
+		"GIT_COMMITTER_EMAIL": "test@example.com",
+		"GIT_CONFIG_GLOBAL":   os.DevNull,
+	} {
+		t.Setenv(key, value)
+	}
+	dir := t.TempDir()
+	if err := exec.Command("git", "-C", dir, "init", "-q", "-b", "main").Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := git.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CommitAll("initial\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 空的提交信息让 git commit 失败
+	diffs := []types.FileDiff{
+		{Path: "README", Before: "hello\n", After: "changed\n"},
+		{Path: "new.txt", After: "new\n"},
+	}
+	result := types.CommitResult{Branch: "feat/x", Files: []string{"README", "new.txt"}}
+	if _, err := commitOnBranch(repo, "main", diffs, result); err == nil {
+		t.Fatal("Expected the commit to fail")
+	}
+
+	if branch, _ := repo.CurrentBranch(); branch != "main" {
+		t.Errorf("CurrentBranch() = %s, want main", branch)
+	}
+	if repo.BranchExists("feat/x") {
+		t.Error("Expected feat/x to be deleted")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "README")); string(data) != "hello\n" {
+		t.Errorf("README = %q, want the original content", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.txt")); !os.IsNotExist(err) {
+		t.Error("Expected new.txt to be removed")
+	}
+	if dirty, _ := repo.Dirty(); dirty {
+		t.Error("Expected a clean working tree after rollback")
+	}
+}
+
+func TestParseWhere(t *testing.T) {
+	tests := []struct {
+		name    string
+		exprs   []string
+		want    sessionFilter
+		wantErr bool
+	}{
+		{"fields", []string{"status=idle", "title=fix", "older-than=2d"}, sessionFilter{Status: "idle", Title: "fix", OlderThan: 48 * time.Hour}, false},
+		{"directory and project", []string{"directory=/repo", " project-id = p1 "}, sessionFilter{Directory: "/repo", ProjectID: "p1"}, false},
+		{"missing value", []string{"title="}, sessionFilter{}, true},
+		{"unknown key", []string{"color=red"}, sessionFilter{}, true},
+		{"bad age", []string{"older-than=soon"}, sessionFilter{}, true},
+		{"unknown status", []string{"status=runing"}, sessionFilter{}, true},
+		{"empty", []string{""}, sessionFilter{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWhere(tt.exprs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseWhere() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseWhere() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchStatus(t *testing.T) {
+	busy := types.SessionStatus{Type: "busy"}
+	idle := types.SessionStatus{Type: "idle"}
+	tests := []struct {
+		filter string
+		status types.SessionStatus
+		exists bool
+		want   bool
+	}{
+		{"running", busy, true, true},
+		{"running", idle, true, false},
+		{"running", types.SessionStatus{}, false, false},
+		{"idle", types.SessionStatus{}, false, true},
+		{"completed", busy, true, false},
+		{"error", types.SessionStatus{Status: "error"}, true, true},
+		{"aborted", idle, true, false},
+		{"", busy, true, true},
+		{"runing", busy, true, false},
+	}
+	for _, tt := range tests {
+		if got := matchStatus(tt.filter, tt.status, tt.exists); got != tt.want {
+			t.Errorf("matchStatus(%q, %+v, %v) = %v, want %v", tt.filter, tt.status, tt.exists, got, tt.want)
+		}
+	}
+}
+
+func TestSessionFilterOlderThan(t *testing.T) {
+	now := time.UnixMilli(10 * 24 * 3600 * 1000)
+	day := int64(24 * 3600 * 1000)
+	sessions := []types.Session{
+		{ID: "old", Time: types.SessionTime{Created: 0, Updated: day}},
+		{ID: "recent", Time: types.SessionTime{Created: 0, Updated: 9 * day}},
+		{ID: "created-only", Time: types.SessionTime{Created: 2 * day}},
+	}
+	f := sessionFilter{OlderThan: 7 * 24 * time.Hour}
+
+	var got []string
+	for _, s := range sessions {
+		if f.matchFields(s, now) {
+			got = append(got, s.ID)
+		}
+	}
+	if strings.Join(got, ",") != "old,created-only" {
+		t.Errorf("matched = %v", got)
+	}
+}
+
+func TestParseIDs(t *testing.T) {
+	text := "ses_1 ses_2\n# comment ses_9\n\n  ses_3\nses_1\n"
+	if got := parseIDs(text); strings.Join(got, ",") != "ses_1,ses_2,ses_3" {
+		t.Errorf("parseIDs() = %v", got)
+	}
+}
+
+func TestBulkTargets(t *testing.T) {
+	defer func() { whereExprs, sessionID = nil, "" }()
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			switch path {
+			case "/session":
+				return []byte(`[{"id":"ses_1","title":"fix bug"},{"id":"ses_2","title":"docs"},{"id":"ses_3","title":"fix typo"},{"id":"ses_abcd1","title":"other"},{"id":"ses_abcd2","title":"other"}]`), nil
+			case "/session/status":
+				return []byte(`{"ses_3":{"type":"busy"}}`), nil
+			}
+			return nil, errors.New("unexpected path " + path)
+		},
+	}
+	ctx := context.Background()
+
+	ids := func(sessions []types.Session) string {
+		var out []string
+		for _, s := range sessions {
+			out = append(out, s.ID)
+		}
+		return strings.Join(out, ",")
+	}
+
+	whereExprs = []string{"title=fix", "status=idle"}
+	targets, _, err := bulkTargets(ctx, mock, nil, nil)
+	if err != nil || ids(targets) != "ses_1" {
+		t.Errorf("--where targets = %v, %v", ids(targets), err)
+	}
+	if _, _, err := bulkTargets(ctx, mock, []string{"ses_2"}, nil); err == nil {
+		t.Error("Expected error when combining --where with IDs")
+	}
+
+	whereExprs = nil
+	targets, fromStdin, err := bulkTargets(ctx, mock, []string{"ses_9", "-"}, strings.NewReader("ses_4\nses_5\n"))
+	if err != nil || !fromStdin || ids(targets) != "ses_9,ses_4,ses_5" {
+		t.Errorf("stdin targets = %v, %v, %v", ids(targets), fromStdin, err)
+	}
+
+	// 命令行中的引用只按 ID 前缀解析，标题原样交给服务器，前缀有歧义时报错
+	t.Setenv("OHO_CACHE_DIR", t.TempDir())
+	if targets, _, err := bulkTargets(ctx, mock, []string{"docs", "ses_abcd1"}, nil); err != nil || ids(targets) != "docs,ses_abcd1" {
+		t.Errorf("resolved targets = %v, %v", ids(targets), err)
+	}
+	if _, _, err := bulkTargets(ctx, mock, []string{"docs", "abcd"}, nil); err == nil || !strings.Contains(err.Error(), "ses_abcd2") {
+		t.Errorf("Expected ambiguity error listing candidates, got %v", err)
+	}
+	if _, _, err := bulkTargets(ctx, mock, []string{"@last"}, nil); err == nil {
+		t.Error("Expected @last to be rejected for bulk operations")
+	}
+
+	sessionID = "ses_7"
+	if targets, _, err := bulkTargets(ctx, mock, nil, nil); err != nil || ids(targets) != "ses_7" {
+		t.Errorf("-s targets = %v, %v", ids(targets), err)
+	}
+	sessionID = ""
+	if _, _, err := bulkTargets(ctx, mock, nil, nil); err == nil {
+		t.Error("Expected error without any target")
+	}
+}
+
+func TestRunParallel(t *testing.T) {
+	var mu sync.Mutex
+	var deleted []string
+	mock := &client.MockClient{
+		DeleteFunc: func(ctx context.Context, path string) ([]byte, error) {
+			mu.Lock()
+			deleted = append(deleted, path)
+			mu.Unlock()
+			switch path {
+			case "/session/ses_2":
+				return nil, errors.New("not found")
+			case "/session/ses_3":
+				return []byte(`false`), nil
+			}
+			return []byte(`true`), nil
+		},
+	}
+	targets := []types.Session{{ID: "ses_1", Title: "a"}, {ID: "ses_2"}, {ID: "ses_3"}, {ID: "ses_4"}}
+	results := runParallel(context.Background(), mock, targets, bulkDelete, 2)
+
+	if len(deleted) != 4 {
+		t.Errorf("deleted = %v", deleted)
+	}
+	var statuses []string
+	for i, r := range results {
+		if r.ID != targets[i].ID || r.Action != "delete" {
+			t.Errorf("results[%d] = %+v", i, r)
+		}
+		statuses = append(statuses, r.Status)
+	}
+	if strings.Join(statuses, ",") != "done,failed,failed,done" {
+		t.Errorf("statuses = %v", statuses)
+	}
+	if results[1].Error != "not found" || results[0].Title != "a" {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestSessionGC(t *testing.T) {
+	day := int64(24 * time.Hour / time.Millisecond)
+	now := time.UnixMilli(100 * day)
+	var calls []string
+	var mu sync.Mutex
+	record := func(call string) {
+		mu.Lock()
+		calls = append(calls, call)
+		mu.Unlock()
+	}
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			switch path {
+			case "/session":
+				return json.Marshal([]types.Session{
+					{ID: "ses_old", Directory: "/w", Time: types.SessionTime{Updated: 80 * day}},
+					{ID: "ses_gone", Directory: "/w", Time: types.SessionTime{Updated: 10 * day, Archived: 50 * day}},
+					{ID: "ses_busy", Directory: "/w", Time: types.SessionTime{Updated: 70 * day}},
+					{ID: "ses_new", Directory: "/w", Time: types.SessionTime{Updated: 99 * day}},
+				})
+			case "/session/status":
+				return []byte(`{"ses_busy":{"type":"busy"}}`), nil
+			}
+			return nil, errors.New("unexpected path " + path)
+		},
+		DeleteFunc: func(ctx context.Context, path string) ([]byte, error) {
+			record("DELETE " + path)
+			return []byte(`true`), nil
+		},
+		PatchWithQueryFunc: func(ctx context.Context, path string, query map[string]string, body interface{}) ([]byte, error) {
+			record("PATCH " + path + " " + query["directory"])
+			return []byte(`{"id":"ses_old"}`), nil
+		},
+	}
+	policy, err := retention.ParsePolicy([]byte("archive_after: 7d\ndelete_after: 30d\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := planGC(context.Background(), mock, policy, now)
+	if err != nil {
+		t.Fatalf("planGC() error: %v", err)
+	}
+	results = runGC(context.Background(), mock, results, 2)
+
+	sort.Strings(calls)
+	if want := "DELETE /session/ses_gone,PATCH /session/ses_old /w"; strings.Join(calls, ",") != want {
+		t.Errorf("calls = %v, want %s", calls, want)
+	}
+
+	report := gcReport(results, "policy.yaml", false, now)
+	if report.Archived != 1 || report.Deleted != 1 || report.Skipped != 1 || report.Failed != 0 {
+		t.Errorf("report = %+v", report)
+	}
+	if report.Results[2].ID != "ses_busy" || report.Results[2].Reason != retention.SkipWorking {
+		t.Errorf("results = %+v", report.Results)
+	}
+
+	file := filepath.Join(t.TempDir(), "logs", "gc.jsonl")
+	for i := 0; i < 2; i++ {
+		if err := appendReport(file, report); err != nil {
+			t.Fatalf("appendReport() error: %v", err)
+		}
+	}
+	data, err := os.ReadFile(file)
+	if err != nil || strings.Count(string(data), "\n") != 2 {
+		t.Errorf("report file = %q, %v", data, err)
+	}
+}
+
+func TestSearchSessions(t *testing.T) {
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			switch path {
+			case "/session/ses_1/message":
+				return []byte(`[
+					{"info":{"id":"msg_1","role":"user"},"parts":[{"type":"text","text":"Fix the rate limit bug"}]},
+					{"info":{"id":"msg_2","role":"assistant"},"parts":[
+						{"type":"tool","tool":"read","state":{"status":"completed","input":{"filePath":"/repo/limit.go"},"output":"package ratelimit"}},
+						{"type":"patch","files":["/repo/limit.go"]},
+						{"type":"file","url":"data:text/plain;base64,bGltaXQ=","filename":"notes.txt"}
+					]}
+				]`), nil
+			case "/session/ses_2/message":
+				return []byte(`[{"info":{"id":"msg_3","role":"assistant"},"parts":[{"type":"text","text":"limit"}]}]`), nil
+			case "/session/ses_3/message":
+				return nil, errors.New("boom")
+			}
+			return nil, errors.New("unexpected path " + path)
+		},
+	}
+	sessions := []types.Session{
+		{ID: "ses_2", Time: types.SessionTime{Updated: 1}},
+		{ID: "ses_1", Title: "Rate limits", Time: types.SessionTime{Updated: 2}},
+		{ID: "ses_3"},
+	}
+	m, err := search.New("rate limit", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, err := searchFields([]string{"text", "tool", "file"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hits, failed := searchSessions(context.Background(), mock, nil, sessions, m, fields, 2)
+	if len(failed) != 1 || failed[0].ID != "ses_3" {
+		t.Errorf("failed = %+v", failed)
+	}
+
+	var got []string
+	for _, h := range hits {
+		got = append(got, h.SessionID+"/"+h.MessageID+"/"+h.Role+"/"+h.Field)
+	}
+	want := []string{
+		"ses_1/msg_1/user/text",
+		"ses_1/msg_2/assistant/tool-output",
+		"ses_1/msg_2/assistant/tool-input",
+		"ses_1/msg_2/assistant/file",
+		"ses_2/msg_3/assistant/text",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("hits = %v, want %v", got, want)
+	}
+	if hits[0].Snippet != "Fix the rate limit bug" || hits[0].SessionTitle != "Rate limits" {
+		t.Errorf("hits[0] = %+v", hits[0])
+	}
+
+	// 只搜索文本
+	fields, _ = searchFields([]string{"text"})
+	hits, _ = searchSessions(context.Background(), mock, nil, sessions[:2], m, fields, 1)
+	if len(hits) != 2 {
+		t.Errorf("text-only hits = %+v", hits)
+	}
+	if _, err := searchFields([]string{"body"}); err == nil {
+		t.Error("Expected error for unknown --in value")
+	}
+}
+
+func TestLoadSessions(t *testing.T) {
+	t.Setenv("OHO_CACHE_DIR", t.TempDir())
+	t.Setenv("OHO_CACHE_TTL", "1h")
+	defer func() { refresh = false }()
+
+	calls := 0
+	offline := false
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			if offline {
+				return nil, &url.Error{Op: "Get", URL: path, Err: errors.New("connection refused")}
+			}
+			calls++
+			switch path {
+			case "/session":
+				return []byte(`[{"id":"ses_1","title":"one"}]`), nil
+			case "/session/ses_2":
+				return []byte(`{"id":"ses_2","title":"two"}`), nil
+			}
+			return nil, errors.New("unexpected path " + path)
+		},
+	}
+	ctx := context.Background()
+
+	// 没有索引时请求服务器，之后在有效期内使用索引
+	for i := 0; i < 2; i++ {
+		sessions, err := loadSessions(ctx, mock)
+		if err != nil || len(sessions) != 1 || sessions[0].ID != "ses_1" {
+			t.Fatalf("loadSessions = %v, %v", sessions, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+
+	refresh = true
+	if _, err := loadSessions(ctx, mock); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("--refresh should request the server, calls = %d", calls)
+	}
+
+	// 索引中没有的会话从服务器获取并加入索引，不刷新有效期
+	refresh = false
+	if s, err := loadSession(ctx, mock, "ses_2"); err != nil || s.Title != "two" {
+		t.Fatalf("loadSession = %+v, %v", s, err)
+	}
+	if s, err := loadSession(ctx, mock, "ses_1"); err != nil || s.Title != "one" || calls != 3 {
+		t.Errorf("loadSession from index = %+v, %v, calls = %d", s, err, calls)
+	}
+
+	// 无法连接服务器时使用快照，其他错误照常返回
+	offline, refresh = true, true
+	sessions, err := loadSessions(ctx, mock)
+	if err != nil || len(sessions) != 2 {
+		t.Errorf("offline loadSessions = %v, %v", sessions, err)
+	}
+	if s, err := loadSession(ctx, mock, "ses_2"); err != nil || s.Title != "two" {
+		t.Errorf("offline loadSession = %+v, %v", s, err)
+	}
+	if _, err := loadSession(ctx, mock, "ses_9"); err == nil {
+		t.Error("Expected error for session missing from the snapshot")
+	}
+
+	cache.Expire()
+	offline, refresh = false, false
+	if _, err := loadSessions(ctx, mock); err != nil || calls != 4 {
+		t.Errorf("expired index should request the server, calls = %d, err = %v", calls, err)
+	}
+}
+
+func TestLoadMessages(t *testing.T) {
+	t.Setenv("OHO_CACHE_DIR", t.TempDir())
+	defer func() { refresh = false }()
+
+	calls := 0
+	offline := false
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			if offline {
+				return nil, &url.Error{Op: "Get", URL: path, Err: errors.New("connection refused")}
+			}
+			calls++
+			return []byte(`[{"info":{"id":"msg_1","role":"user"},"parts":[]}]`), nil
+		},
+	}
+	idx, err := cache.OpenDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	s := types.Session{ID: "ses_1", Time: types.SessionTime{Updated: 100}}
+
+	for i := 0; i < 2; i++ {
+		msgs, err := loadMessages(ctx, mock, idx, s)
+		if err != nil || len(msgs) != 1 {
+			t.Fatalf("loadMessages = %v, %v", msgs, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("snapshot should be reused, calls = %d", calls)
+	}
+
+	// 会话更新后重新获取
+	s.Time.Updated = 200
+	if _, err := loadMessages(ctx, mock, idx, s); err != nil || calls != 2 {
+		t.Errorf("updated session should refetch, calls = %d, err = %v", calls, err)
+	}
+
+	// 离线时使用旧快照，没有快照时返回错误
+	offline = true
+	s.Time.Updated = 300
+	if msgs, err := loadMessages(ctx, mock, idx, s); err != nil || len(msgs) != 1 {
+		t.Errorf("offline loadMessages = %v, %v", msgs, err)
+	}
+	if _, err := loadMessages(ctx, mock, idx, types.Session{ID: "ses_2"}); err == nil {
+		t.Error("Expected error without snapshot")
+	}
+	if _, err := loadMessages(ctx, mock, nil, s); err == nil {
+		t.Error("Expected error without index")
+	}
+}
+
+func TestBuildTree(t *testing.T) {
+	sessions := []types.Session{
+		{ID: "ses_a", Time: types.SessionTime{Created: 1, Updated: 10}},
+		{ID: "ses_b", Time: types.SessionTime{Created: 2, Updated: 20}},
+		{ID: "ses_a2", ParentID: "ses_a", Time: types.SessionTime{Created: 5}},
+		{ID: "ses_a1", ParentID: "ses_a", Time: types.SessionTime{Created: 3}},
+		{ID: "ses_a11", ParentID: "ses_a1", Agent: "explore", Model: map[string]interface{}{"providerID": "anthropic", "modelID": "claude"}},
+		{ID: "ses_orphan", ParentID: "ses_gone", Time: types.SessionTime{Updated: 5}},
+	}
+	statuses := map[string]types.SessionStatus{
+		"ses_a11": {Type: "busy"},
+		"ses_b":   {IsWorking: true},
+	}
+
+	// flatten 按深度优先顺序列出 ID，用缩进表示层级
+	var flatten func(nodes []types.SessionNode, depth int) []string
+	flatten = func(nodes []types.SessionNode, depth int) []string {
+		var out []string
+		for _, n := range nodes {
+			out = append(out, strings.Repeat(".", depth)+n.ID)
+			out = append(out, flatten(n.Children, depth+1)...)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		root    string
+		want    []string
+		wantErr bool
+	}{
+		{"all", "", []string{"ses_b", "ses_a", ".ses_a1", "..ses_a11", ".ses_a2", "ses_orphan"}, false},
+		{"subtree", "ses_a1", []string{"ses_a1", ".ses_a11"}, false},
+		{"missing", "ses_x", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := buildTree(sessions, statuses, tt.root)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildTree() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := flatten(nodes, 0); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("tree = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	nodes, _ := buildTree(sessions, statuses, "ses_a1")
+	leaf := nodes[0].Children[0]
+	if leaf.Status != "busy" || leaf.Agent != "explore" || leaf.Model != "anthropic/claude" || leaf.ParentID != "ses_a1" {
+		t.Errorf("leaf = %+v", leaf)
+	}
+	if nodes[0].Status != "idle" {
+		t.Errorf("session without status should be idle, got %q", nodes[0].Status)
+	}
+
+	// 循环引用不会导致无限递归
+	cyclic := []types.Session{{ID: "x", ParentID: "y"}, {ID: "y", ParentID: "x"}}
+	if nodes, err := buildTree(cyclic, nil, "x"); err != nil || len(flatten(nodes, 0)) != 2 {
+		t.Errorf("cyclic tree = %v, %v", flatten(nodes, 0), err)
+	}
+}
+
+func TestStatusName(t *testing.T) {
+	tests := []struct {
+		status types.SessionStatus
+		exists bool
+		want   string
+	}{
+		{types.SessionStatus{}, false, "idle"},
+		{types.SessionStatus{Type: "retry"}, true, "retry"},
+		{types.SessionStatus{IsWorking: true, Status: "running"}, true, "busy"},
+		{types.SessionStatus{Status: "error"}, true, "error"},
+		{types.SessionStatus{}, true, "idle"},
+	}
+	for _, tt := range tests {
+		if got := statusName(tt.status, tt.exists); got != tt.want {
+			t.Errorf("statusName(%+v, %v) = %q, want %q", tt.status, tt.exists, got, tt.want)
+		}
+	}
+}
+
+func TestLongRunningCommandsSkipMCP(t *testing.T) {
+	// 这些命令可能一直运行，作为 MCP 工具会永久占用执行锁
+	for _, cmd := range []*cobra.Command{syncCmd, waitCmd} {
+		if cmd.Annotations["mcp"] != "skip" {
+			t.Errorf("%s should be annotated mcp: skip", cmd.Name())
+		}
+	}
+	// tree 只跳过 --watch，只显示一次的树仍是只读工具
+	if treeCmd.Annotations["mcp"] != "readonly" {
+		t.Errorf("tree should be annotated mcp: readonly")
+	}
+	if got := treeCmd.Flags().Lookup("watch").Annotations["mcp"]; len(got) != 1 || got[0] != "skip" {
+		t.Errorf("tree --watch should be annotated mcp: skip, got %v", got)
+	}
+}
+
+func TestPrintTree(t *testing.T) {
+	now := time.UnixMilli(100 * 60 * 1000)
+	nodes := []types.SessionNode{{
+		ID: "ses_root", Title: "Root", Status: "busy", Updated: now.Add(-5 * time.Minute).UnixMilli(),
+		Children: []types.SessionNode{
+			{ID: "ses_c1", Status: "idle", Children: []types.SessionNode{{ID: "ses_c11", Status: "busy"}}},
+			{ID: "ses_c2", Status: "idle", Agent: "explore"},
+		},
+	}}
+
+	var buf strings.Builder
+	if err := printTree(&buf, nodes, now); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	wantPrefixes := []string{"ses_root ", "├── ses_c1 ", "│   └── ses_c11 ", "└── ses_c2 "}
+	for i, want := range wantPrefixes {
+		if !strings.HasPrefix(lines[i+1], want) {
+			t.Errorf("line %d = %q, want prefix %q", i+1, lines[i+1], want)
+		}
+	}
+	if !strings.Contains(lines[1], "5m") || !strings.Contains(lines[4], "explore") {
+		t.Errorf("unexpected rows:\n%s", buf.String())
+	}
+	if summary := lines[len(lines)-2]; !strings.Contains(summary, "4") || !strings.Contains(summary, "2") {
+		t.Errorf("summary = %q", summary)
+	}
+}
+
+func TestWaitSession(t *testing.T) {
+	waitPollInterval = 10 * time.Millisecond
+	out := filepath.Join(t.TempDir(), "fired")
+	hooks := hook.Hooks{
+		OnComplete:   hook.Commands{`echo "complete $OHO_SESSION_TITLE" >> ` + out},
+		OnError:      hook.Commands{`echo "error $OHO_ERROR" >> ` + out},
+		OnPermission: hook.Commands{`echo "permission $OHO_PERMISSION_SESSION_ID" >> ` + out},
+	}
+	hook.Output = &strings.Builder{}
+
+	frame := func(typ string, props map[string]interface{}) []byte {
+		data, _ := json.Marshal(map[string]interface{}{"type": typ, "properties": props})
+		return []byte("data: " + string(data) + "\n\n")
+	}
+	status := func(id, s string) []byte {
+		return frame("session.status", map[string]interface{}{"sessionID": id, "status": map[string]string{"type": s}})
+	}
+
+	tests := []struct {
+		name     string
+		statuses []string // 依次返回的 /session/status，最后一个重复使用
+		frames   [][]byte
+		maxTime  time.Duration
+		want     string
+		fired    string
+	}{
+		{"already idle", []string{`{}`}, nil, 0, waitIdle, "complete Task"},
+		{
+			"permission then idle",
+			[]string{`{"ses_a":{"type":"busy"}}`},
+			[][]byte{
+				frame("permission.asked", map[string]interface{}{"id": "per_1", "sessionID": "ses_b", "permission": "bash"}),
+				status("ses_b", "idle"),
+				status("ses_a", "idle"),
+			},
+			0, waitIdle, "permission ses_b,complete Task",
+		},
+		{
+			"session error",
+			[]string{`{"ses_a":{"type":"busy"}}`},
+			[][]byte{
+				frame("session.error", map[string]interface{}{"sessionID": "ses_a", "error": map[string]interface{}{"name": "APIError", "data": map[string]string{"message": "overloaded"}}}),
+				frame("session.idle", map[string]interface{}{"sessionID": "ses_a"}),
+			},
+			0, waitError, "error overloaded",
+		},
+		{"timeout", []string{`{"ses_a":{"type":"busy"}}`}, nil, 50 * time.Millisecond, waitTimeout, "error 等待超过 50ms"},
+		{"status poll", []string{`{"ses_a":{"type":"busy"}}`, `{"ses_a":{"type":"busy"}}`, `{}`}, nil, 0, waitIdle, "complete Task"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(out)
+			chunks := make(chan []byte, len(tt.frames))
+			for _, f := range tt.frames {
+				chunks <- f
+			}
+			calls := 0
+			mock := &client.MockClient{
+				SSEStreamFunc: func(ctx context.Context, path string) (<-chan []byte, <-chan error, error) {
+					return chunks, make(chan error), nil
+				},
+				GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+					switch path {
+					case "/session":
+						return []byte(`[{"id":"ses_a","title":"Task"},{"id":"ses_b","parentID":"ses_a"}]`), nil
+					case "/session/status":
+						resp := tt.statuses[min(calls, len(tt.statuses)-1)]
+						calls++
+						return []byte(resp), nil
+					case "/permission":
+						return []byte(`[]`), nil
+					}
+					t.Errorf("Unexpected path: %s", path)
+					return nil, errors.New("unexpected")
+				},
+			}
+
+			result, err := waitSession(context.Background(), mock, "ses_a", hooks, tt.maxTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.want || result.Title != "Task" {
+				t.Errorf("result = %+v", result)
+			}
+			data, _ := os.ReadFile(out)
+			if fired := strings.Join(strings.Split(strings.TrimSpace(string(data)), "\n"), ","); fired != tt.fired {
+				t.Errorf("fired = %q, want %q", fired, tt.fired)
+			}
+		})
+	}
+}
+
+func TestAliasSetRequiresMatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":"ses_34dbffe0dffe8SfdMTbL53MWFP","title":"Fix login bug"}]`))
+	}))
+	defer server.Close()
+	cfg := config.Get()
+	origHost, origPort := cfg.Host, cfg.Port
+	defer func() { cfg.Host, cfg.Port = origHost, origPort }()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	cfg.Host = host
+	cfg.Port, _ = strconv.Atoi(port)
+
+	t.Setenv("OHO_CACHE_DIR", t.TempDir())
+	t.Setenv("OHO_ALIAS_FILE", filepath.Join(t.TempDir(), "aliases.json"))
+	aliasSetCmd.SetContext(context.Background())
+
+	// 不匹配任何会话的引用不能保存为别名
+	if err := aliasSetCmd.RunE(aliasSetCmd, []string{"api", "typo"}); err == nil {
+		t.Fatal("Expected alias set to fail for a reference that matches no session")
+	}
+	aliases, err := resolve.LoadAliases()
+	if err != nil || len(aliases) != 0 {
+		t.Fatalf("Expected no aliases to be saved, got %v, %v", aliases, err)
+	}
+
+	if err := aliasSetCmd.RunE(aliasSetCmd, []string{"api", "34dbffe"}); err != nil {
+		t.Fatal(err)
+	}
+	aliases, _ = resolve.LoadAliases()
+	if aliases["api"] != "ses_34dbffe0dffe8SfdMTbL53MWFP" {
+		t.Errorf("aliases = %v", aliases)
+	}
+}
+
+func TestDestructiveCommandsRequireStrictRefs(t *testing.T) {
+	var reverted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			reverted = append(reverted, r.URL.Path)
+			_, _ = w.Write([]byte(`true`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id":"ses_34dbffe0dffe8SfdMTbL53MWFP","title":"Fix login bug"}]`))
+	}))
+	defer server.Close()
+	cfg := config.Get()
+	origHost, origPort := cfg.Host, cfg.Port
+	defer func() { cfg.Host, cfg.Port = origHost, origPort }()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	cfg.Host = host
+	cfg.Port, _ = strconv.Atoi(port)
+	t.Setenv("OHO_CACHE_DIR", t.TempDir())
+
+	// -s 同样只接受完整 ID、唯一的 ID 前缀和别名
+	for _, cmd := range []*cobra.Command{deleteCmd, abortCmd, achieveCmd, unshareCmd, revertCmd, unrevertCmd} {
+		if !strictRefs[cmd] {
+			t.Errorf("Expected %s to resolve -s strictly", cmd.Name())
+		}
+	}
+
+	// 标题片段不解析为会话，原样交给服务器
+	revertCmd.SetContext(context.Background())
+	messageID = "msg_1"
+	defer func() { messageID = "" }()
+	_ = revertCmd.RunE(revertCmd, []string{"login"})
+	if err := revertCmd.RunE(revertCmd, []string{"34dbffe"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/session/login/revert", "/session/ses_34dbffe0dffe8SfdMTbL53MWFP/revert"}; !reflect.DeepEqual(reverted, want) {
+		t.Errorf("Requests = %v, want %v", reverted, want)
+	}
+}
@@ -0,0 +1,300 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/cache"
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// treeDebounce --watch 时合并连续事件的间隔，避免运行中的会话频繁重绘
+const treeDebounce = 300 * time.Millisecond
+
+var (
+	treeWatch bool
+	treeRetry time.Duration
+)
+
+func init() {
+	treeCmd.Flags().BoolVarP(&treeWatch, "watch", "w", false, "持续监听事件流，实时显示新的子会话和状态")
+	treeCmd.Flags().DurationVar(&treeRetry, "retry", 3*time.Second, "事件流断开后的重连间隔")
+	// --watch 会一直运行，不作为 MCP 工具的参数，工具只显示一次
+	_ = treeCmd.Flags().SetAnnotation("watch", "mcp", []string{"skip"})
+	_ = treeCmd.Flags().SetAnnotation("retry", "mcp", []string{"skip"})
+
+	Cmd.AddCommand(treeCmd)
+	schema.Register("session tree", []types.SessionNode{})
+}
+
+var treeCmd = &cobra.Command{
+	Use:   "tree [id]",
+	Short: "以树形显示会话及其子会话",
+	Long: `显示会话的父子层级，包括子代理创建的子会话，以及每个会话的状态、代理、模型和最后更新至今的时长。
+
+指定 ID 时只显示该会话及其所有后代，否则显示全部会话。
+使用 --watch 持续监听事件流，运行中的会话创建子会话或状态变化时重新显示。`,
+	Example: `  oho session tree
+  oho session tree ses_xxx
+  oho session tree ses_xxx --watch`,
+	Annotations: map[string]string{"mcp": "readonly"},
+	Args:        cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := sessionID
+		if len(args) > 0 {
+			var err error
+			if id, err = resolveSession(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+		}
+
+		c := client.NewClient()
+		ctx := cmd.Context()
+
+		if !treeWatch {
+			nodes, err := loadTree(ctx, c, id)
+			if err != nil {
+				return err
+			}
+			return renderTree(ctx, nodes, time.Now())
+		}
+
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return watchTree(ctx, c, id)
+	},
+}
+
+// loadTree 获取会话列表和状态，构建以 root 为根的树；root 为空时包含所有会话
+func loadTree(ctx context.Context, c client.ClientInterface, root string) ([]types.SessionNode, error) {
+	sessions, err := cache.Fetch(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Get(ctx, "/session/status")
+	if err != nil {
+		return nil, err
+	}
+	var statuses map[string]types.SessionStatus
+	if err := json.Unmarshal(resp, &statuses); err != nil {
+		return nil, i18n.Errorf("解析会话状态失败：%w", err)
+	}
+	return buildTree(sessions, statuses, root)
+}
+
+// buildTree 按 ParentID 构建会话树
+// 根会话按最后更新时间降序排列，子会话按创建时间升序排列；父会话不在列表中的会话作为根
+func buildTree(sessions []types.Session, statuses map[string]types.SessionStatus, root string) ([]types.SessionNode, error) {
+	byID := make(map[string]types.Session, len(sessions))
+	children := map[string][]types.Session{}
+	for _, s := range sessions {
+		byID[s.ID] = s
+	}
+	var roots []types.Session
+	for _, s := range sessions {
+		if _, ok := byID[s.ParentID]; ok && s.ParentID != s.ID {
+			children[s.ParentID] = append(children[s.ParentID], s)
+		} else {
+			roots = append(roots, s)
+		}
+	}
+	for _, list := range children {
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Time.Created < list[j].Time.Created
+		})
+	}
+
+	if root != "" {
+		s, ok := byID[root]
+		if !ok {
+			return nil, i18n.Errorf("会话不存在：%s", root)
+		}
+		roots = []types.Session{s}
+	} else {
+		sort.SliceStable(roots, func(i, j int) bool {
+			return roots[i].Time.Updated > roots[j].Time.Updated
+		})
+	}
+
+	// visited 防止错误数据中的循环引用
+	visited := map[string]bool{}
+	var build func(s types.Session) types.SessionNode
+	build = func(s types.Session) types.SessionNode {
+		visited[s.ID] = true
+		status, exists := statuses[s.ID]
+		node := types.SessionNode{
+			ID:        s.ID,
+			Title:     s.Title,
+			ParentID:  s.ParentID,
+			Directory: s.Directory,
+			Agent:     s.Agent,
+			Model:     modelName(s.Model),
+			Status:    statusName(status, exists),
+			Created:   s.Time.Created,
+			Updated:   s.Time.Updated,
+		}
+		for _, child := range children[s.ID] {
+			if !visited[child.ID] {
+				node.Children = append(node.Children, build(child))
+			}
+		}
+		return node
+	}
+
+	nodes := make([]types.SessionNode, 0, len(roots))
+	for _, s := range roots {
+		if !visited[s.ID] {
+			nodes = append(nodes, build(s))
+		}
+	}
+	return nodes, nil
+}
+
+// statusName 会话状态的名称，不在状态列表中的会话视为 idle
+func statusName(status types.SessionStatus, exists bool) string {
+	switch {
+	case !exists:
+		return "idle"
+	case status.Type != "":
+		return status.Type
+	case status.Working():
+		return "busy"
+	case status.Status != "":
+		return status.Status
+	}
+	return "idle"
+}
+
+// renderTree 按输出格式输出会话树
+func renderTree(ctx context.Context, nodes []types.SessionNode, now time.Time) error {
+	if ok, err := util.Render(ctx, nodes); ok || err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		fmt.Println(i18n.T("没有会话"))
+		return nil
+	}
+	return printTree(os.Stdout, nodes, now)
+}
+
+// printTree 以表格输出会话树，ID 列带有树形前缀
+func printTree(w io.Writer, nodes []types.SessionNode, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join([]string{"ID", i18n.T("标题"), i18n.T("状态"), i18n.T("代理"), i18n.T("模型"), i18n.T("更新")}, "\t"))
+
+	total, working := 0, 0
+	var walk func(n types.SessionNode, prefix, branch string)
+	walk = func(n types.SessionNode, prefix, branch string) {
+		total++
+		if n.Status == "busy" || n.Status == "retry" {
+			working++
+		}
+		age := "-"
+		if n.Updated > 0 {
+			age = util.FormatAge(now.Sub(time.UnixMilli(n.Updated)))
+		}
+		fmt.Fprintln(tw, strings.Join([]string{prefix + branch + n.ID, util.Truncate(n.Title, 40), n.Status, orDash(n.Agent), orDash(n.Model), age}, "\t"))
+
+		// 子会话的前缀：父会话是最后一个时不再画竖线
+		next := prefix
+		switch branch {
+		case "├── ":
+			next += "│   "
+		case "└── ":
+			next += "    "
+		}
+		for i, child := range n.Children {
+			if i == len(n.Children)-1 {
+				walk(child, next, "└── ")
+			} else {
+				walk(child, next, "├── ")
+			}
+		}
+	}
+	for _, n := range nodes {
+		walk(n, "", "")
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	i18n.Fprintf(w, "共 %d 个会话，%d 个正在运行\n", total, working)
+	return nil
+}
+
+// orDash 空值显示为 -
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// watchTree 订阅事件流，会话创建、更新、删除或状态变化时重新显示会话树，直到 ctx 取消
+func watchTree(ctx context.Context, c client.ClientInterface, root string) error {
+	// 终端中每次重绘前清屏，否则依次输出
+	clear := util.TextOutput(ctx) && util.IsTerminal(os.Stdout)
+	draw := func() error {
+		nodes, err := loadTree(ctx, c, root)
+		if err != nil {
+			return err
+		}
+		if clear {
+			fmt.Print("\x1b[H\x1b[2J")
+		}
+		if err := renderTree(ctx, nodes, time.Now()); err != nil {
+			return err
+		}
+		if util.TextOutput(ctx) {
+			i18n.Printf("%s 更新，正在监听事件流（Ctrl+C 退出）\n", time.Now().Format("15:04:05"))
+			if !clear {
+				fmt.Println()
+			}
+		}
+		return nil
+	}
+	if err := draw(); err != nil {
+		return err
+	}
+
+	events := event.Watch(ctx, c, event.DefaultPath, treeRetry, func(err error) {
+		i18n.Fprintf(os.Stderr, "事件流断开：%v，%s 后重连\n", err, treeRetry)
+	})
+	var pending <-chan time.Time
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if strings.HasPrefix(e.Type, "session.") && pending == nil {
+				pending = time.After(treeDebounce)
+			}
+		case <-pending:
+			pending = nil
+			if err := draw(); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				i18n.Fprintf(os.Stderr, "刷新失败：%v\n", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/cache"
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/hook"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/permission"
+	"github.com/anomalyco/oho/internal/schema"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// wait 的结果状态
+const (
+	waitIdle    = "idle"
+	waitError   = "error"
+	waitTimeout = "timeout"
+)
+
+// waitPollInterval 事件流之外定期确认会话状态的间隔，避免事件流断开期间错过空闲事件
+var waitPollInterval = 10 * time.Second
+
+var (
+	waitHooks   hook.Hooks
+	waitMaxTime time.Duration
+)
+
+func init() {
+	waitHooks.AddFlags(waitCmd.Flags())
+	waitCmd.Flags().DurationVar(&waitMaxTime, "timeout", 0, "最长等待时间，0 表示一直等待")
+
+	Cmd.AddCommand(waitCmd)
+	schema.Register("session wait", types.WaitResult{})
+}
+
+var waitCmd = &cobra.Command{
+	Use:   "wait [id]",
+	Short: "等待会话运行结束",
+	Long: `等待会话运行结束，子代理创建的子会话请求权限时同样触发 --on-permission。
+
+会话已经空闲时立即返回。会话出错或等待超时时以非零状态退出。
+
+钩子可以是本地命令或 webhook URL（以 http:// 或 https:// 开头）：
+命令通过 shell 执行，会话信息通过 OHO_EVENT、OHO_SESSION_ID、OHO_SESSION_TITLE、
+OHO_SESSION_DIRECTORY、OHO_DURATION_MS、OHO_ERROR 和 OHO_PERMISSION_* 环境变量传入，
+标准输入为 JSON 格式的事件；webhook 以 POST 请求发送同样的 JSON。`,
+	Example: `  oho session wait ses_xxx
+  oho session wait @last --on-complete 'notify-send "oho" "$OHO_SESSION_TITLE 已完成"'
+  oho session wait ses_xxx --timeout 1h --on-error https://hooks.example.com/oho`,
+	// 长时间运行的命令，不暴露为 MCP 工具
+	Annotations: map[string]string{"mcp": "skip"},
+	Args:        cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := sessionArg(cmd.Context(), args)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		result, err := waitSession(ctx, client.NewClient(), id, waitHooks, waitMaxTime)
+		if err != nil {
+			return err
+		}
+		if ok, err := util.Render(ctx, result); ok || err != nil {
+			return err
+		}
+		duration := (time.Duration(result.Duration) * time.Millisecond).Round(time.Second)
+		switch result.Status {
+		case waitError:
+			return i18n.Errorf("会话 %s 出错：%s", result.SessionID, result.Error)
+		case waitTimeout:
+			return i18n.Errorf("等待会话 %s 超时（%s）", result.SessionID, duration)
+		}
+		i18n.Printf("会话 %s 已结束（等待 %s）\n", result.SessionID, duration)
+		return nil
+	},
+}
+
+// waitSession 等待会话空闲，期间按事件触发钩子；maxTime 为 0 时不限时长
+func waitSession(ctx context.Context, c client.ClientInterface, id string, hooks hook.Hooks, maxTime time.Duration) (types.WaitResult, error) {
+	start := time.Now()
+	// 钩子使用外层的 ctx，超时后仍然可以执行 on-error
+	watchCtx := ctx
+	if maxTime > 0 {
+		var cancel context.CancelFunc
+		watchCtx, cancel = context.WithTimeout(ctx, maxTime)
+		defer cancel()
+	}
+
+	// 先订阅再检查状态，避免错过检查之后的空闲事件
+	subCtx, cancel := context.WithCancel(watchCtx)
+	defer cancel()
+	events, _, err := event.Subscribe(subCtx, c, event.DefaultPath)
+	if err != nil {
+		return types.WaitResult{}, i18n.Errorf("订阅事件流失败：%w", err)
+	}
+
+	sessions, err := cache.Fetch(ctx, c)
+	if err != nil {
+		return types.WaitResult{}, err
+	}
+	n := hook.NewNotifier()
+	n.Only = id
+	n.AddSessions(sessions)
+	session := types.Session{ID: id}
+	for _, s := range sessions {
+		if s.ID == id {
+			session = s
+		}
+	}
+	result := types.WaitResult{SessionID: id, Title: session.Title, Directory: session.Directory, Status: waitIdle}
+
+	working, err := sessionWorking(ctx, c, id)
+	if err != nil {
+		return types.WaitResult{}, err
+	}
+	if !working {
+		hook.Report(hooks.Fire(ctx, hook.Payload(hook.EventComplete, session)))
+		return result, nil
+	}
+	n.Start(id, start)
+
+	// 等待开始前已在等待响应的权限请求
+	if requests, err := permission.Fetch(ctx, c); err == nil {
+		for _, r := range requests {
+			if p, ok := n.Permission(r, time.Now()); ok {
+				hook.Report(hooks.Fire(ctx, p))
+			}
+		}
+	}
+
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+	for {
+		var payloads []types.HookPayload
+		select {
+		case <-watchCtx.Done():
+			if ctx.Err() != nil {
+				return types.WaitResult{}, ctx.Err()
+			}
+			result.Status = waitTimeout
+			result.Error = i18n.Sprintf("等待超过 %s", maxTime)
+			result.Duration = time.Since(start).Milliseconds()
+			p := hook.Payload(hook.EventError, session)
+			p.Duration, p.Error = result.Duration, result.Error
+			hook.Report(hooks.Fire(ctx, p))
+			return result, nil
+		case e, ok := <-events:
+			if !ok {
+				events = event.Watch(subCtx, c, event.DefaultPath, time.Second, nil)
+				continue
+			}
+			payloads = n.Handle(e, time.Now())
+		case <-ticker.C:
+			if working, err := sessionWorking(watchCtx, c, id); err == nil && !working {
+				props, _ := json.Marshal(map[string]string{"sessionID": id})
+				payloads = n.Handle(types.Event{Type: event.TypeSessionIdle, Properties: props}, time.Now())
+			}
+		}
+
+		for _, p := range payloads {
+			hook.Report(hooks.Fire(ctx, p))
+			if p.Event == hook.EventPermission {
+				continue
+			}
+			result.Duration = time.Since(start).Milliseconds()
+			if p.Event == hook.EventError {
+				result.Status, result.Error = waitError, p.Error
+			}
+			return result, nil
+		}
+	}
+}
+
+// sessionWorking 查询会话是否正在工作
+func sessionWorking(ctx context.Context, c client.ClientInterface, id string) (bool, error) {
+	resp, err := c.Get(ctx, "/session/status")
+	if err != nil {
+		return false, err
+	}
+	var statuses map[string]types.SessionStatus
+	if err := json.Unmarshal(resp, &statuses); err != nil {
+		return false, i18n.Errorf("解析会话状态失败：%w", err)
+	}
+	return statuses[id].Working(), nil
+}
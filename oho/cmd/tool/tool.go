@@ -1,15 +1,16 @@
 package tool
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
-	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 // Cmd 工具命令
@@ -24,11 +25,12 @@ var (
 	modelID    string
 
 	idsCmd = &cobra.Command{
-		Use:   "ids",
-		Short: "列出所有工具 ID",
+		Use:         "ids",
+		Short:       "列出所有工具 ID",
+		Annotations: map[string]string{"mcp": "readonly"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			resp, err := c.Get(ctx, "/experimental/tool/ids")
 			if err != nil {
@@ -40,18 +42,16 @@ var (
 				return err
 			}
 
-			if config.Get().JSON {
-				data, _ := json.MarshalIndent(toolIDs, "", "  ")
-				fmt.Println(string(data))
-				return nil
+			if ok, err := util.Render(ctx, toolIDs); ok || err != nil {
+				return err
 			}
 
 			if len(toolIDs.IDs) == 0 {
-				fmt.Println("没有可用工具")
+				fmt.Println(i18n.T("没有可用工具"))
 				return nil
 			}
 
-			fmt.Printf("共 %d 个工具:\n\n", len(toolIDs.IDs))
+			i18n.Printf("共 %d 个工具:\n\n", len(toolIDs.IDs))
 			for _, id := range toolIDs.IDs {
 				fmt.Printf("🔧 %s\n", id)
 			}
@@ -61,15 +61,16 @@ var (
 	}
 
 	listCmd = &cobra.Command{
-		Use:   "list",
-		Short: "列出指定模型的工具",
+		Use:         "list",
+		Short:       "列出指定模型的工具",
+		Annotations: map[string]string{"mcp": "readonly"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if providerID == "" || modelID == "" {
-				return fmt.Errorf("请提供 --provider 和 --model 参数")
+				return i18n.Errorf("请提供 --provider 和 --model 参数")
 			}
 
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			queryParams := map[string]string{
 				"provider": providerID,
@@ -86,21 +87,19 @@ var (
 				return err
 			}
 
-			if config.Get().JSON {
-				data, _ := json.MarshalIndent(toolList, "", "  ")
-				fmt.Println(string(data))
-				return nil
+			if ok, err := util.Render(ctx, toolList); ok || err != nil {
+				return err
 			}
 
 			if len(toolList.Tools) == 0 {
-				fmt.Println("没有可用工具")
+				fmt.Println(i18n.T("没有可用工具"))
 				return nil
 			}
 
-			fmt.Printf("共 %d 个工具:\n\n", len(toolList.Tools))
+			i18n.Printf("共 %d 个工具:\n\n", len(toolList.Tools))
 			for _, t := range toolList.Tools {
 				fmt.Printf("🔧 %s\n", t.Name)
-				fmt.Printf("   描述：%s\n", t.Description)
+				i18n.Printf("   描述：%s\n", t.Description)
 				fmt.Println()
 			}
 
@@ -117,4 +116,8 @@ func init() {
 	listCmd.Flags().StringVar(&modelID, "model", "", "模型 ID")
 	_ = listCmd.MarkFlagRequired("provider")
 	_ = listCmd.MarkFlagRequired("model")
+
+	// JSON 输出 Schema
+	schema.Register("tool ids", types.ToolIDs{})
+	schema.Register("tool list", types.ToolList{})
 }
@@ -1,14 +1,16 @@
 package tui
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
 	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
 )
 
 // Cmd TUI 控制命令
@@ -32,7 +34,7 @@ var (
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			resp, err := c.Post(ctx, "/tui/append-prompt", map[string]string{"text": args[0]})
 			if err != nil {
@@ -44,8 +46,11 @@ var (
 				return err
 			}
 
+			if ok, err := util.Render(ctx, types.ActionResult{Success: success}); ok || err != nil {
+				return err
+			}
 			if success {
-				fmt.Println("提示词已追加")
+				fmt.Println(i18n.T("提示词已追加"))
 			}
 			return nil
 		},
@@ -56,7 +61,7 @@ var (
 		Short: "打开帮助对话框",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			resp, err := c.Post(ctx, "/tui/open-help", nil)
 			if err != nil {
@@ -68,8 +73,11 @@ var (
 				return err
 			}
 
+			if ok, err := util.Render(ctx, types.ActionResult{Success: success}); ok || err != nil {
+				return err
+			}
 			if success {
-				fmt.Println("帮助对话框已打开")
+				fmt.Println(i18n.T("帮助对话框已打开"))
 			}
 			return nil
 		},
@@ -80,7 +88,7 @@ var (
 		Short: "打开会话选择器",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			resp, err := c.Post(ctx, "/tui/open-sessions", nil)
 			if err != nil {
@@ -92,8 +100,11 @@ var (
 				return err
 			}
 
+			if ok, err := util.Render(ctx, types.ActionResult{Success: success}); ok || err != nil {
+				return err
+			}
 			if success {
-				fmt.Println("会话选择器已打开")
+				fmt.Println(i18n.T("会话选择器已打开"))
 			}
 			return nil
 		},
@@ -104,7 +115,7 @@ var (
 		Short: "打开主题选择器",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			resp, err := c.Post(ctx, "/tui/open-themes", nil)
 			if err != nil {
@@ -116,8 +127,11 @@ var (
 				return err
 			}
 
+			if ok, err := util.Render(ctx, types.ActionResult{Success: success}); ok || err != nil {
+				return err
+			}
 			if success {
-				fmt.Println("主题选择器已打开")
+				fmt.Println(i18n.T("主题选择器已打开"))
 			}
 			return nil
 		},
@@ -128,7 +142,7 @@ var (
 		Short: "打开模型选择器",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			resp, err := c.Post(ctx, "/tui/open-models", nil)
 			if err != nil {
@@ -140,8 +154,11 @@ var (
 				return err
 			}
 
+			if ok, err := util.Render(ctx, types.ActionResult{Success: success}); ok || err != nil {
+				return err
+			}
 			if success {
-				fmt.Println("模型选择器已打开")
+				fmt.Println(i18n.T("模型选择器已打开"))
 			}
 			return nil
 		},
@@ -152,7 +169,7 @@ var (
 		Short: "提交当前提示词",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			resp, err := c.Post(ctx, "/tui/submit-prompt", nil)
 			if err != nil {
@@ -164,8 +181,11 @@ var (
 				return err
 			}
 
+			if ok, err := util.Render(ctx, types.ActionResult{Success: success}); ok || err != nil {
+				return err
+			}
 			if success {
-				fmt.Println("提示词已提交")
+				fmt.Println(i18n.T("提示词已提交"))
 			}
 			return nil
 		},
@@ -176,7 +196,7 @@ var (
 		Short: "清除提示词",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			resp, err := c.Post(ctx, "/tui/clear-prompt", nil)
 			if err != nil {
@@ -188,8 +208,11 @@ var (
 				return err
 			}
 
+			if ok, err := util.Render(ctx, types.ActionResult{Success: success}); ok || err != nil {
+				return err
+			}
 			if success {
-				fmt.Println("提示词已清除")
+				fmt.Println(i18n.T("提示词已清除"))
 			}
 			return nil
 		},
@@ -200,11 +223,11 @@ var (
 		Short: "执行命令",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if command == "" {
-				return fmt.Errorf("请提供 --command 参数")
+				return i18n.Errorf("请提供 --command 参数")
 			}
 
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			req := types.TUICommandRequest{Command: command}
 			resp, err := c.Post(ctx, "/tui/execute-command", req)
@@ -217,8 +240,11 @@ var (
 				return err
 			}
 
+			if ok, err := util.Render(ctx, types.ActionResult{Success: success}); ok || err != nil {
+				return err
+			}
 			if success {
-				fmt.Printf("命令 %s 已执行\n", command)
+				i18n.Printf("命令 %s 已执行\n", command)
 			}
 			return nil
 		},
@@ -229,11 +255,11 @@ var (
 		Short: "显示提示消息",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if message == "" {
-				return fmt.Errorf("请提供 --message 参数")
+				return i18n.Errorf("请提供 --message 参数")
 			}
 
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			req := types.TUIToastRequest{
 				Title:   title,
@@ -251,8 +277,11 @@ var (
 				return err
 			}
 
+			if ok, err := util.Render(ctx, types.ActionResult{Success: success}); ok || err != nil {
+				return err
+			}
 			if success {
-				fmt.Println("提示消息已显示")
+				fmt.Println(i18n.T("提示消息已显示"))
 			}
 			return nil
 		},
@@ -263,14 +292,17 @@ var (
 		Short: "等待下一个控制请求",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			resp, err := c.Get(ctx, "/tui/control/next")
 			if err != nil {
 				return err
 			}
 
-			fmt.Printf("控制请求：%s\n", string(resp))
+			if ok, err := util.Render(ctx, json.RawMessage(resp)); ok || err != nil {
+				return err
+			}
+			i18n.Printf("控制请求：%s\n", string(resp))
 			return nil
 		},
 	}
@@ -280,15 +312,15 @@ var (
 		Short: "响应控制请求",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if body == "" {
-				return fmt.Errorf("请提供 --body 参数")
+				return i18n.Errorf("请提供 --body 参数")
 			}
 
 			c := client.NewClient()
-			ctx := context.Background()
+			ctx := cmd.Context()
 
 			var bodyData interface{}
 			if err := json.Unmarshal([]byte(body), &bodyData); err != nil {
-				return fmt.Errorf("解析 body 失败：%w", err)
+				return i18n.Errorf("解析 body 失败：%w", err)
 			}
 
 			resp, err := c.Post(ctx, "/tui/control/response", map[string]interface{}{"body": bodyData})
@@ -301,8 +333,11 @@ var (
 				return err
 			}
 
+			if ok, err := util.Render(ctx, types.ActionResult{Success: success}); ok || err != nil {
+				return err
+			}
 			if success {
-				fmt.Println("控制请求已响应")
+				fmt.Println(i18n.T("控制请求已响应"))
 			}
 			return nil
 		},
@@ -332,4 +367,17 @@ func init() {
 
 	controlResponseCmd.Flags().StringVar(&body, "body", "", "响应体 (JSON 格式)")
 	_ = controlResponseCmd.MarkFlagRequired("body")
+
+	// JSON 输出 Schema
+	schema.Register("tui append-prompt", types.ActionResult{})
+	schema.Register("tui open-help", types.ActionResult{})
+	schema.Register("tui open-sessions", types.ActionResult{})
+	schema.Register("tui open-themes", types.ActionResult{})
+	schema.Register("tui open-models", types.ActionResult{})
+	schema.Register("tui submit-prompt", types.ActionResult{})
+	schema.Register("tui clear-prompt", types.ActionResult{})
+	schema.Register("tui execute-command", types.ActionResult{})
+	schema.Register("tui show-toast", types.ActionResult{})
+	schema.Register("tui control-response", types.ActionResult{})
+	schema.Register("tui control-next", json.RawMessage{})
 }
@@ -0,0 +1,205 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/cache"
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/schema"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/usage"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// Cmd 用量命令
+var Cmd = &cobra.Command{
+	Use:   "usage",
+	Short: "统计 token 用量和费用",
+	Long: `统计助手消息的 token 用量和费用。
+
+示例:
+  oho usage report
+  oho usage report --since 30d --group-by project -o csv`,
+}
+
+var (
+	reportSince    string
+	reportGroupBy  string
+	reportParallel int
+)
+
+func init() {
+	reportCmd.Flags().StringVar(&reportSince, "since", "7d", "统计起点，时长（如 7d、12h）或日期（如 2026-10-01）")
+	reportCmd.Flags().StringVar(&reportGroupBy, "group-by", usage.ByModel, "分组方式 ("+usage.GroupBys+")")
+	reportCmd.Flags().IntVar(&reportParallel, "parallel", 4, "同时获取消息的会话数")
+
+	Cmd.AddCommand(reportCmd)
+	schema.Register("usage report", types.UsageReport{})
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "按模型、代理、项目或日期汇总 token 用量和费用",
+	Long: `汇总统计范围内所有会话（包括子代理的子会话）中助手消息的输入、输出、推理、
+缓存读取和缓存写入 token 数以及费用。
+
+--group-by 的分组方式：
+  model     提供商/模型
+  agent     代理
+  project   项目根目录
+  day       消息创建的日期（本地时间）
+
+-o json 和 -o yaml 输出包含合计的完整报告，-o csv 只输出各分组，便于导入表格。
+会话在上次统计后没有更新时使用本地的消息快照。`,
+	Example: `  oho usage report --since 7d --group-by model
+  oho usage report --since 2026-10-01 --group-by day -o csv > usage.csv
+  oho usage report --group-by project -o json`,
+	Annotations: map[string]string{"mcp": "readonly"},
+	Args:        cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := usage.ValidGroupBy(reportGroupBy); err != nil {
+			return err
+		}
+		now := time.Now()
+		since, err := usage.ParseSince(reportSince, now)
+		if err != nil {
+			return err
+		}
+
+		c := client.NewClient()
+		ctx := cmd.Context()
+
+		// 索引不可用时不使用消息快照
+		idx, err := cache.OpenDefault()
+		if err != nil {
+			idx = nil
+		}
+		sessions, err := fetchSessions(ctx, c, idx)
+		if err != nil {
+			return err
+		}
+		sessions = usage.Active(sessions, since)
+
+		messages, failed := collectMessages(ctx, c, idx, sessions, reportParallel)
+		for id, err := range failed {
+			i18n.Fprintf(os.Stderr, "警告：获取会话 %s 的消息失败：%s\n", id, err.Error())
+		}
+
+		report := usage.Report(sessions, messages, reportGroupBy, since, now)
+		if ok, err := util.Render(ctx, report); ok || err != nil {
+			return err
+		}
+		printReport(ctx, report)
+		return nil
+	},
+}
+
+// fetchSessions 从服务器获取会话列表，并更新索引
+func fetchSessions(ctx context.Context, c client.ClientInterface, idx *cache.Index) ([]types.Session, error) {
+	if idx == nil {
+		return cache.Fetch(ctx, c)
+	}
+	if err := idx.Sync(ctx, c); err != nil {
+		return nil, err
+	}
+	return idx.Sessions, nil
+}
+
+// collectMessages 以最多 parallel 个并发获取会话的消息，返回消息和获取失败的会话
+// 快照在会话最后一次更新之后获取时直接使用快照，否则请求服务器并保存快照
+func collectMessages(ctx context.Context, c client.ClientInterface, idx *cache.Index, sessions []types.Session, parallel int) (map[string][]types.MessageWithParts, map[string]error) {
+	if parallel < 1 {
+		parallel = 1
+	}
+	messages := make(map[string][]types.MessageWithParts, len(sessions))
+	failed := map[string]error{}
+	var mu sync.Mutex
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for _, s := range sessions {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(s types.Session) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if idx != nil {
+				if cached, current, ok := idx.Messages(s.ID, s.Time.Updated); ok && current {
+					mu.Lock()
+					messages[s.ID] = cached
+					mu.Unlock()
+					return
+				}
+			}
+			list, err := cache.FetchMessages(ctx, c, s.ID)
+			if err == nil && idx != nil {
+				_ = idx.SaveMessages(s.ID, s.Time.Updated, list)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[s.ID] = err
+				return
+			}
+			messages[s.ID] = list
+		}(s)
+	}
+	wg.Wait()
+	return messages, failed
+}
+
+// printReport 以表格输出报告，最后一行为合计
+func printReport(ctx context.Context, report types.UsageReport) {
+	layout := "2006-01-02 15:04"
+	i18n.Printf("统计范围：%s 至 %s\n\n", time.UnixMilli(report.Since).Format(layout), time.UnixMilli(report.Until).Format(layout))
+	if len(report.Groups) == 0 {
+		fmt.Println(i18n.T("没有 token 用量"))
+		return
+	}
+
+	headers := []string{groupHeader(report.GroupBy), i18n.T("消息数"), i18n.T("会话数"), i18n.T("输入"), i18n.T("输出"),
+		i18n.T("推理"), i18n.T("缓存读取"), i18n.T("缓存写入"), i18n.T("费用")}
+	rows := make([][]string, 0, len(report.Groups)+1)
+	for _, g := range report.Groups {
+		rows = append(rows, usageRow(g.Key, g))
+	}
+	rows = append(rows, usageRow(i18n.T("合计"), report.Total))
+	util.OutputTable(ctx, headers, rows)
+}
+
+// groupHeader 分组列的表头
+func groupHeader(groupBy string) string {
+	switch groupBy {
+	case usage.ByAgent:
+		return i18n.T("代理")
+	case usage.ByProject:
+		return i18n.T("项目")
+	case usage.ByDay:
+		return i18n.T("日期")
+	}
+	return i18n.T("模型")
+}
+
+func usageRow(key string, g types.UsageGroup) []string {
+	return []string{
+		key,
+		strconv.Itoa(g.Messages),
+		strconv.Itoa(g.Sessions),
+		strconv.FormatInt(g.Input, 10),
+		strconv.FormatInt(g.Output, 10),
+		strconv.FormatInt(g.Reasoning, 10),
+		strconv.FormatInt(g.CacheRead, 10),
+		strconv.FormatInt(g.CacheWrite, 10),
+		"$" + strconv.FormatFloat(g.Cost, 'f', 4, 64),
+	}
+}
@@ -0,0 +1,59 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/anomalyco/oho/internal/cache"
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+func TestCollectMessages(t *testing.T) {
+	idx, err := cache.Open(t.TempDir(), "http://127.0.0.1:4096")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls atomic.Int32
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			calls.Add(1)
+			if strings.Contains(path, "ses_bad") {
+				return nil, errors.New("API 错误 [500]")
+			}
+			return []byte(`[{"info":{"id":"msg_1","role":"assistant","modelID":"m","cost":0.5,"tokens":{"input":3,"output":4,"reasoning":0,"cache":{"read":1,"write":0}}}}]`), nil
+		},
+	}
+	sessions := []types.Session{
+		{ID: "ses_a", Time: types.SessionTime{Updated: 10}},
+		{ID: "ses_bad", Time: types.SessionTime{Updated: 10}},
+	}
+
+	messages, failed := collectMessages(context.Background(), mock, idx, sessions, 2)
+	if len(failed) != 1 || failed["ses_bad"] == nil {
+		t.Errorf("failed = %v", failed)
+	}
+	got := messages["ses_a"]
+	if len(got) != 1 || got[0].Info.ModelID != "m" || got[0].Info.Cost != 0.5 || got[0].Info.Tokens == nil || got[0].Info.Tokens.Cache.Read != 1 {
+		t.Fatalf("messages = %+v", got)
+	}
+
+	// 会话没有更新时使用快照
+	calls.Store(0)
+	messages, _ = collectMessages(context.Background(), mock, idx, sessions[:1], 2)
+	if calls.Load() != 0 || len(messages["ses_a"]) != 1 {
+		t.Errorf("Expected snapshot to be used, calls = %d", calls.Load())
+	}
+}
+
+func TestGroupHeader(t *testing.T) {
+	tests := map[string]string{"model": "模型", "agent": "代理", "project": "项目", "day": "日期"}
+	for groupBy, want := range tests {
+		if got := groupHeader(groupBy); got != want {
+			t.Errorf("groupHeader(%q) = %q, want %q", groupBy, got, want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package watchdog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/cache"
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/permission"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+	"github.com/anomalyco/oho/internal/watchdog"
+)
+
+var (
+	configFile string
+	silence    time.Duration
+	action     string
+	maxResends int
+	logFile    string
+	dryRun     bool
+	retryDelay time.Duration
+)
+
+func init() {
+	Cmd.Flags().StringVar(&configFile, "config", "", "配置文件 (YAML)，其中的设置优先于命令行参数")
+	Cmd.Flags().DurationVar(&silence, "silence", 10*time.Minute, "正在工作的会话超过该时长没有事件视为卡住")
+	Cmd.Flags().StringVar(&action, "action", watchdog.ActionWarn, "会话卡住时的动作 ("+watchdog.Actions+")")
+	Cmd.Flags().IntVar(&maxResends, "max-resends", 1, "每个会话最多重发的次数，用完后改为中止")
+	Cmd.Flags().StringVar(&logFile, "log", "", "日志文件 (默认 <配置目录>/watchdog.jsonl)")
+	Cmd.Flags().BoolVar(&dryRun, "dry-run", false, "只记录卡住的会话，不中止或重发")
+	Cmd.Flags().DurationVar(&retryDelay, "retry", 3*time.Second, "事件流断开后的重连间隔")
+}
+
+// Cmd 看门狗命令
+var Cmd = &cobra.Command{
+	Use:   "watchdog",
+	Short: "发现长时间没有进展的会话并告警、中止或重发",
+	Long: `订阅事件流，跟踪每个正在工作的会话最后一次事件的时间，会话沉默超过阈值时执行动作：
+  warn    只记录
+  abort   中止会话及其子会话
+  resend  中止会话，等待停止后以相同的代理和模型重新发送最后一条用户消息；
+          每个会话最多重发 max_resends 次，之后改为中止
+
+子代理创建的子会话的事件计为根会话的活动。等待人工响应权限请求期间不计沉默。
+启动时已在运行的会话从启动时开始计算。每次沉默只处理一次，直到会话再次有活动。
+
+每个动作以 JSON Lines 格式写入日志文件。
+
+配置示例（规则按顺序匹配，第一条命中的规则覆盖默认的 silence 和 action）:
+  silence: 10m
+  action: warn
+  max_resends: 1
+  rules:
+    - name: ci
+      directory: /work/ci/**
+      silence: 20m
+      action: resend
+    - name: build
+      agent: build
+      action: abort
+
+示例:
+  oho watchdog --silence 15m --action abort
+  oho watchdog --config watchdog.yaml --log /var/log/oho-watchdog.jsonl`,
+	// 长时间运行的命令，不暴露为 MCP 工具
+	Annotations: map[string]string{"mcp": "skip"},
+	Args:        cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := watchdog.LoadConfig(configFile, watchdog.Config{
+			Silence:    util.Duration(silence),
+			Action:     action,
+			MaxResends: maxResends,
+		})
+		if err != nil {
+			return err
+		}
+
+		file := logFile
+		if file == "" {
+			file = filepath.Join(config.Dir(), "watchdog.jsonl")
+		}
+		log, err := watchdog.OpenLog(file)
+		if err != nil {
+			return i18n.Errorf("打开日志文件失败：%w", err)
+		}
+		defer log.Close()
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		d := &dog{client: client.NewClient(), config: cfg, log: log, out: os.Stdout, tracker: watchdog.New()}
+		if err := d.load(ctx, time.Now()); err != nil {
+			return err
+		}
+		i18n.Fprintf(os.Stderr, "看门狗已启动（%d 个会话正在运行，日志：%s）\n", len(d.tracker.Working()), file)
+		d.run(ctx)
+		return nil
+	},
+}
+
+// dog 看门狗
+type dog struct {
+	client  client.ClientInterface
+	config  *watchdog.Config
+	log     *watchdog.Log
+	out     io.Writer
+	tracker *watchdog.Tracker
+
+	// 动作在后台执行，mu 保护输出和日志，actions 在退出前等待进行中的动作
+	mu      sync.Mutex
+	actions sync.WaitGroup
+}
+
+// load 记录会话、已在运行的会话和待处理的权限请求
+func (d *dog) load(ctx context.Context, now time.Time) error {
+	sessions, err := cache.Fetch(ctx, d.client)
+	if err != nil {
+		return err
+	}
+	d.tracker.AddSessions(sessions)
+
+	resp, err := d.client.Get(ctx, "/session/status")
+	if err != nil {
+		return err
+	}
+	var statuses map[string]types.SessionStatus
+	if err := json.Unmarshal(resp, &statuses); err != nil {
+		return i18n.Errorf("解析会话状态失败：%w", err)
+	}
+	for id, status := range statuses {
+		if status.Working() {
+			d.tracker.Start(id, now)
+		}
+	}
+
+	// 获取待处理的权限请求失败时不影响启动，之后的请求从事件流获得
+	if requests, err := permission.Fetch(ctx, d.client); err == nil {
+		for _, r := range requests {
+			d.tracker.AddPermission(r.ID, r.SessionID)
+		}
+	}
+	return nil
+}
+
+// run 监听事件流并定期检查沉默的会话，直到 ctx 取消
+func (d *dog) run(ctx context.Context) {
+	events := event.Watch(ctx, d.client, event.DefaultPath, retryDelay, func(err error) {
+		i18n.Fprintf(os.Stderr, "事件流断开：%v，%s 后重连\n", err, retryDelay)
+	})
+	ticker := time.NewTicker(checkInterval(d.config))
+	defer ticker.Stop()
+	defer d.actions.Wait()
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			d.tracker.Handle(e, time.Now())
+		case now := <-ticker.C:
+			d.check(ctx, now)
+		}
+	}
+}
+
+// check 对新超过沉默阈值的会话执行动作并记录
+// 动作在后台执行：重发要等待会话停止，期间事件循环继续处理事件和其他卡住的会话
+func (d *dog) check(ctx context.Context, now time.Time) {
+	for _, st := range d.tracker.Stalled(d.config, now) {
+		task := d.tracker.Act(st, d.config.MaxResends, dryRun, time.Now())
+		d.actions.Add(1)
+		go func() {
+			defer d.actions.Done()
+			d.record(task.Run(ctx, d.client))
+		}()
+	}
+}
+
+// record 输出并记录一个动作的结果
+func (d *dog) record(entry watchdog.Entry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.report(entry)
+	if err := d.log.Record(entry); err != nil {
+		i18n.Fprintf(os.Stderr, "警告：写入日志失败：%v\n", err)
+	}
+}
+
+// report 输出一条记录
+func (d *dog) report(e watchdog.Entry) {
+	at := e.Time.Format("15:04:05")
+	switch {
+	case e.Error != "":
+		i18n.Fprintf(d.out, "%s ✗ %s %s（沉默 %s）：%s\n", at, e.Action, e.SessionID, e.Silence, e.Error)
+	case e.DryRun:
+		i18n.Fprintf(d.out, "%s (dry-run) %s %s（沉默 %s）\n", at, e.Action, e.SessionID, e.Silence)
+	case e.Action == watchdog.ActionWarn:
+		i18n.Fprintf(d.out, "%s ⚠ 会话 %s 已沉默 %s：%s\n", at, e.SessionID, e.Silence, e.Title)
+	case e.Action == watchdog.ActionResend:
+		i18n.Fprintf(d.out, "%s ✓ 已中止 %s 并重发消息 %s（沉默 %s）\n", at, e.SessionID, e.Resent, e.Silence)
+	default:
+		i18n.Fprintf(d.out, "%s ✓ 已中止 %s（沉默 %s）\n", at, e.SessionID, e.Silence)
+	}
+	if e.Note != "" {
+		fmt.Fprintf(d.out, "  %s\n", e.Note)
+	}
+}
+
+// checkInterval 检查间隔为最短沉默时长的十分之一，介于 1 秒和 30 秒之间
+func checkInterval(c *watchdog.Config) time.Duration {
+	interval := c.MinSilence() / 10
+	switch {
+	case interval < time.Second:
+		return time.Second
+	case interval > 30*time.Second:
+		return 30 * time.Second
+	}
+	return interval
+}
@@ -0,0 +1,108 @@
+package watchdog
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/util"
+	"github.com/anomalyco/oho/internal/watchdog"
+)
+
+func TestLoadAndCheck(t *testing.T) {
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			switch path {
+			case "/session":
+				return []byte(`[{"id":"ses_a","title":"Build"},{"id":"ses_b","parentID":"ses_a"},{"id":"ses_c"},{"id":"ses_d"}]`), nil
+			case "/session/status":
+				return []byte(`{"ses_b":{"type":"busy"},"ses_c":{"type":"idle"},"ses_d":{"isWorking":true}}`), nil
+			case "/permission":
+				return []byte(`[{"id":"per_1","sessionID":"ses_d","permission":"bash"}]`), nil
+			}
+			t.Errorf("Unexpected path: %s", path)
+			return nil, nil
+		},
+	}
+	var out, logBuf bytes.Buffer
+	cfg := &watchdog.Config{Silence: util.Duration(time.Minute), Action: watchdog.ActionWarn}
+	d := &dog{client: mock, config: cfg, log: watchdog.NewLog(&logBuf), out: &out, tracker: watchdog.New()}
+
+	start := time.Now()
+	if err := d.load(context.Background(), start); err != nil {
+		t.Fatal(err)
+	}
+	// 运行中的子会话按根会话跟踪
+	if working := d.tracker.Working(); strings.Join(working, ",") != "ses_a,ses_d" {
+		t.Errorf("Working() = %v", working)
+	}
+
+	// ses_d 等待权限响应，只有 ses_a 被记录
+	d.check(context.Background(), start.Add(2*time.Minute))
+	d.actions.Wait()
+	if !strings.Contains(out.String(), "ses_a") || strings.Contains(out.String(), "ses_d") {
+		t.Errorf("output = %q", out.String())
+	}
+	if lines := strings.Split(strings.TrimSpace(logBuf.String()), "\n"); len(lines) != 1 || !strings.Contains(lines[0], `"action":"warn"`) {
+		t.Errorf("log = %q", logBuf.String())
+	}
+}
+
+func TestCheckInterval(t *testing.T) {
+	tests := []struct {
+		silence time.Duration
+		want    time.Duration
+	}{
+		{5 * time.Second, time.Second},
+		{time.Minute, 6 * time.Second},
+		{time.Hour, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		c := &watchdog.Config{Silence: util.Duration(tt.silence)}
+		if got := checkInterval(c); got != tt.want {
+			t.Errorf("checkInterval(%v) = %v, want %v", tt.silence, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRunsActionsInBackground(t *testing.T) {
+	// 重发等待会话停止期间，check 立即返回，事件循环可以继续处理
+	release := make(chan struct{})
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			if path == "/session/status" {
+				<-release
+				return []byte(`{}`), nil
+			}
+			return []byte(`[{"info":{"id":"msg_1","role":"user"},"parts":[{"type":"text","text":"retry"}]}]`), nil
+		},
+		PostFunc: func(ctx context.Context, path string, body interface{}) ([]byte, error) {
+			return []byte("true"), nil
+		},
+	}
+	var out, logBuf bytes.Buffer
+	cfg := &watchdog.Config{Silence: util.Duration(time.Minute), Action: watchdog.ActionResend, MaxResends: 1}
+	d := &dog{client: mock, config: cfg, log: watchdog.NewLog(&logBuf), out: &out, tracker: watchdog.New()}
+
+	start := time.Now()
+	d.tracker.Start("ses_a", start)
+	done := make(chan struct{})
+	go func() {
+		d.check(context.Background(), start.Add(2*time.Minute))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("check() blocked while the resend was waiting")
+	}
+
+	close(release)
+	d.actions.Wait()
+	if !strings.Contains(logBuf.String(), `"resent":"msg_1"`) {
+		t.Errorf("log = %q", logBuf.String())
+	}
+}
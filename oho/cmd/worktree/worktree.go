@@ -0,0 +1,257 @@
+package worktree
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/git"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/resolve"
+	"github.com/anomalyco/oho/internal/schema"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+	"github.com/anomalyco/oho/internal/worktree"
+)
+
+// 清理结果状态
+const (
+	cleanMerged  = "merged"
+	cleanRemoved = "removed"
+	cleanSkipped = "skipped"
+)
+
+// Cmd 工作树命令
+var Cmd = &cobra.Command{
+	Use:   "worktree",
+	Short: "管理任务工作树",
+	Long: `管理 oho add --worktree 和 oho session submit --worktree 创建的 git 工作树。
+
+每个任务在独立的工作树和 oho/<任务名> 分支中执行，工作树与会话的对应关系
+记录在配置目录的 worktrees.json 中。任务完成后使用 clean 合并或删除工作树。
+
+示例:
+  oho worktree list
+  oho worktree clean ses_123 --merge
+  oho worktree clean --all`,
+}
+
+var (
+	cleanAll   bool
+	cleanMerge bool
+	cleanForce bool
+)
+
+func init() {
+	cleanCmd.Flags().BoolVar(&cleanAll, "all", false, "清理所有未在运行的工作树")
+	cleanCmd.Flags().BoolVar(&cleanMerge, "merge", false, "提交工作树中的改动并将分支合并到创建工作树时的分支")
+	cleanCmd.Flags().BoolVar(&cleanForce, "force", false, "删除有未提交改动或仍在运行的工作树，并强制删除未合并的分支")
+
+	Cmd.AddCommand(listCmd, cleanCmd)
+
+	schema.Register("worktree list", []types.WorktreeInfo{})
+	schema.Register("worktree clean", []types.WorktreeCleanResult{})
+}
+
+var listCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "列出任务工作树",
+	Annotations: map[string]string{"mcp": "readonly"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		registry, err := worktree.Load(worktree.DefaultFile())
+		if err != nil {
+			return i18n.Errorf("读取工作树记录失败：%w", err)
+		}
+		// 服务器不可用时只显示工作树目录的状态
+		busy, _ := busySessions(ctx, client.NewClient())
+		infos := describe(registry.Entries, busy)
+
+		if ok, err := util.Render(ctx, infos); ok || err != nil {
+			return err
+		}
+
+		if len(infos) == 0 {
+			fmt.Println(i18n.T("没有任务工作树"))
+			return nil
+		}
+
+		rows := make([][]string, 0, len(infos))
+		for _, info := range infos {
+			rows = append(rows, []string{info.SessionID, info.Branch, info.State, info.Path})
+		}
+		util.OutputTable(ctx, []string{i18n.T("会话"), i18n.T("分支"), i18n.T("状态"), i18n.T("路径")}, rows)
+		return nil
+	},
+}
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean [session|branch...]",
+	Short: "合并或删除已完成的任务工作树",
+	Long: `删除任务工作树和对应的分支，并从记录中移除。
+
+默认跳过有未提交改动的工作树和仍在运行的会话，无法获取会话状态时拒绝清理；
+分支有未合并的提交时只删除工作树，保留分支。
+使用 --merge 时先提交工作树中的改动，再将分支合并到创建工作树时的分支，合并失败时保留工作树。
+主仓库必须在该分支上且没有未提交的改动，否则拒绝合并。`,
+	Example: `  oho worktree clean ses_123
+  oho worktree clean oho/fix-login --merge
+  oho worktree clean --all --force`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if len(args) == 0 && !cleanAll {
+			return i18n.Errorf("请指定会话 ID 或分支，或使用 --all")
+		}
+
+		// 清理期间锁定登记表，避免与同时创建的工作树互相覆盖记录
+		var results []types.WorktreeCleanResult
+		err := worktree.Update(worktree.DefaultFile(), func(registry *worktree.Registry) error {
+			entries := registry.Entries
+			if !cleanAll {
+				entries = nil
+				for _, key := range args {
+					e, ok := registry.Find(key)
+					if !ok {
+						// 不是分支或完整的会话 ID 时按 ID 前缀或别名解析
+						id, err := resolve.Strict(ctx, client.NewClient(), key)
+						if err != nil {
+							return err
+						}
+						e, ok = registry.Find(id)
+					}
+					if !ok {
+						return i18n.Errorf("没有找到工作树：%s", key)
+					}
+					entries = append(entries, e)
+				}
+			}
+
+			// 无法确认会话是否在运行时拒绝清理，--force 本来就会删除运行中的工作树
+			busy := map[string]bool{}
+			if !cleanForce {
+				var err error
+				if busy, err = busySessions(ctx, client.NewClient()); err != nil {
+					return i18n.Errorf("无法获取会话状态，拒绝清理（使用 --force 强制清理）：%w", err)
+				}
+			}
+
+			results = make([]types.WorktreeCleanResult, 0, len(entries))
+			for _, e := range entries {
+				result := clean(e, busy[e.SessionID])
+				if result.Status != cleanSkipped {
+					registry.Remove(e.Path)
+				}
+				results = append(results, result)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if ok, err := util.Render(ctx, results); ok || err != nil {
+			return err
+		}
+
+		if len(results) == 0 {
+			fmt.Println(i18n.T("没有任务工作树"))
+			return nil
+		}
+		for _, r := range results {
+			switch r.Status {
+			case cleanMerged:
+				i18n.Printf("已合并 %s (%s) 并删除工作树\n", r.Branch, git.ShortHash(r.Commit))
+			case cleanRemoved:
+				i18n.Printf("已删除工作树 %s\n", r.Path)
+			default:
+				i18n.Printf("跳过 %s：%s\n", r.Branch, r.Reason)
+			}
+			if r.BranchKept {
+				i18n.Printf("  分支 %s 有未合并的提交，已保留\n", r.Branch)
+			}
+		}
+		return nil
+	},
+}
+
+// clean 合并或删除单个工作树
+func clean(e worktree.Entry, busy bool) types.WorktreeCleanResult {
+	result := types.WorktreeCleanResult{SessionID: e.SessionID, Branch: e.Branch, Path: e.Path}
+	skip := func(reason string) types.WorktreeCleanResult {
+		result.Status = cleanSkipped
+		result.Reason = reason
+		return result
+	}
+
+	state := worktree.State(e)
+	if busy && !cleanForce {
+		return skip(i18n.T("会话仍在运行"))
+	}
+
+	if cleanMerge {
+		if state == worktree.StateMissing {
+			return skip(i18n.T("工作树目录不存在"))
+		}
+		commit, err := worktree.Merge(e)
+		if err != nil {
+			return skip(i18n.Sprintf("合并失败：%v", err))
+		}
+		result.Commit = commit
+	} else if state == worktree.StateDirty && !cleanForce {
+		return skip(i18n.T("有未提交的改动"))
+	}
+
+	deleted, err := worktree.Remove(e, cleanForce)
+	if err != nil {
+		return skip(err.Error())
+	}
+	result.Status = cleanRemoved
+	if cleanMerge {
+		result.Status = cleanMerged
+	}
+	result.BranchKept = !deleted
+	return result
+}
+
+// describe 为每个工作树附加状态，会话正在运行时状态为 busy
+func describe(entries []worktree.Entry, busy map[string]bool) []types.WorktreeInfo {
+	infos := make([]types.WorktreeInfo, 0, len(entries))
+	for _, e := range entries {
+		state := worktree.State(e)
+		if busy[e.SessionID] && state != worktree.StateMissing {
+			state = worktree.StateBusy
+		}
+		infos = append(infos, types.WorktreeInfo{
+			SessionID: e.SessionID,
+			Branch:    e.Branch,
+			Path:      e.Path,
+			Repo:      e.Repo,
+			Title:     e.Title,
+			State:     state,
+			Created:   e.Created.UnixMilli(),
+		})
+	}
+	return infos
+}
+
+// busySessions 返回正在运行的会话
+func busySessions(ctx context.Context, c client.ClientInterface) (map[string]bool, error) {
+	resp, err := c.Get(ctx, "/session/status")
+	if err != nil {
+		return nil, err
+	}
+	var status map[string]types.SessionStatus
+	if err := json.Unmarshal(resp, &status); err != nil {
+		return nil, i18n.Errorf("解析会话状态失败：%w", err)
+	}
+	busy := map[string]bool{}
+	for id, s := range status {
+		if s.Working() {
+			busy[id] = true
+		}
+	}
+	return busy, nil
+}
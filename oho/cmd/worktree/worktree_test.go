@@ -0,0 +1,116 @@
+package worktree
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/worktree"
+)
+
+func TestMain(m *testing.M) {
+	os.Setenv("OPENCODE_SERVER_HOST", "127.0.0.1")
+	os.Setenv("OPENCODE_SERVER_PORT", "4096")
+	_ = config.Init()
+
+	m.Run()
+}
+
+func TestBusySessions(t *testing.T) {
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			if path != "/session/status" {
+				t.Errorf("Unexpected path: %s", path)
+			}
+			return []byte(`{"ses_1":{"type":"busy"},"ses_2":{"type":"idle"},"ses_3":{"isWorking":true}}`), nil
+		},
+	}
+	busy, err := busySessions(context.Background(), mock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !busy["ses_1"] || busy["ses_2"] || !busy["ses_3"] {
+		t.Errorf("busySessions() = %v", busy)
+	}
+
+	// 服务器不可用时返回错误，不能当作没有会话在运行
+	mock.GetFunc = func(ctx context.Context, path string) ([]byte, error) {
+		return nil, errors.New("connection refused")
+	}
+	if _, err := busySessions(context.Background(), mock); err == nil {
+		t.Error("Expected busySessions() to fail when the server is unavailable")
+	}
+}
+
+func TestCleanRefusesWithoutStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	cfg := config.Get()
+	origHost, origPort := cfg.Host, cfg.Port
+	defer func() { cfg.Host, cfg.Port = origHost, origPort }()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	cfg.Host = host
+	cfg.Port, _ = strconv.Atoi(port)
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	entry := worktree.Entry{SessionID: "ses_1", Branch: "oho/a", Path: t.TempDir()}
+	if err := worktree.Register(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	cleanAll, cleanForce, cleanMerge = true, false, false
+	defer func() { cleanAll = false }()
+	cleanCmd.SetContext(context.Background())
+	err := cleanCmd.RunE(cleanCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("clean --all error = %v, want a refusal without session status", err)
+	}
+	registry, err := worktree.Load(worktree.DefaultFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registry.Entries) != 1 {
+		t.Errorf("Expected the worktree record to be kept, got %+v", registry.Entries)
+	}
+	if _, err := os.Stat(entry.Path); err != nil {
+		t.Errorf("Expected the worktree directory to be kept: %v", err)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "gone")
+	entries := []worktree.Entry{
+		{SessionID: "ses_1", Branch: "oho/a", Path: missing},
+		{SessionID: "ses_2", Branch: "oho/b", Path: missing},
+	}
+	infos := describe(entries, map[string]bool{"ses_1": true})
+	if len(infos) != 2 {
+		t.Fatalf("Expected 2 infos, got %d", len(infos))
+	}
+	// 目录不存在时不再标记为 busy
+	for _, info := range infos {
+		if info.State != worktree.StateMissing {
+			t.Errorf("%s state = %s, want missing", info.SessionID, info.State)
+		}
+	}
+}
+
+func TestCleanSkipsBusy(t *testing.T) {
+	cleanForce, cleanMerge = false, false
+	result := clean(worktree.Entry{SessionID: "ses_1", Branch: "oho/a", Path: t.TempDir()}, true)
+	if result.Status != cleanSkipped || result.Reason == "" {
+		t.Errorf("clean() = %+v, want skipped", result)
+	}
+}
@@ -0,0 +1,316 @@
+// Package cache 在用户缓存目录中保存会话索引和消息快照，用于快速查询和离线访问
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// version 索引格式版本，版本不一致的索引视为空
+const version = 1
+
+// defaultTTL 索引在最后一次同步后的有效期，可用 OHO_CACHE_TTL 调整
+const defaultTTL = time.Minute
+
+// Index 一个服务器的会话索引
+type Index struct {
+	dir      string
+	Version  int             `json:"version"`
+	Server   string          `json:"server"`
+	Synced   int64           `json:"synced"`  // 最后一次从 /session 完整同步的时间（毫秒）
+	Updated  int64           `json:"updated"` // 最后一次更新（完整同步或事件流）的时间（毫秒）
+	Sessions []types.Session `json:"sessions"`
+}
+
+// snapshot 一个会话的消息快照
+type snapshot struct {
+	Updated  int64                    `json:"updated"` // 获取消息时会话的更新时间
+	Messages []types.MessageWithParts `json:"messages"`
+}
+
+// DefaultDir 服务器的索引目录：<缓存目录>/<主机>_<端口>
+func DefaultDir(server string) string {
+	key := strings.TrimPrefix(strings.TrimPrefix(server, "http://"), "https://")
+	key = strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, key)
+	return filepath.Join(config.CacheDir(), key)
+}
+
+// TTL 索引有效期，OHO_CACHE_TTL 为 0 时总是请求服务器（仍在离线时使用快照）
+func TTL() time.Duration {
+	if v := os.Getenv("OHO_CACHE_TTL"); v != "" {
+		if ttl, err := time.ParseDuration(v); err == nil && ttl >= 0 {
+			return ttl
+		}
+	}
+	return defaultTTL
+}
+
+// Fetch 从服务器获取会话列表
+func Fetch(ctx context.Context, c client.ClientInterface) ([]types.Session, error) {
+	resp, err := c.Get(ctx, "/session")
+	if err != nil {
+		return nil, err
+	}
+	var sessions []types.Session
+	if err := json.Unmarshal(resp, &sessions); err != nil {
+		return nil, i18n.Errorf("解析会话列表失败：%w", err)
+	}
+	return sessions, nil
+}
+
+// FetchMessages 从服务器获取会话的消息
+func FetchMessages(ctx context.Context, c client.ClientInterface, id string) ([]types.MessageWithParts, error) {
+	resp, err := c.Get(ctx, fmt.Sprintf("/session/%s/message", id))
+	if err != nil {
+		return nil, err
+	}
+	var messages []types.MessageWithParts
+	if err := json.Unmarshal(resp, &messages); err != nil {
+		return nil, i18n.Errorf("解析消息列表失败：%w", err)
+	}
+	return messages, nil
+}
+
+// OpenDefault 打开当前服务器的索引
+func OpenDefault() (*Index, error) {
+	server := config.GetBaseURL()
+	idx, err := Open(DefaultDir(server), server)
+	if err != nil {
+		return nil, i18n.Errorf("读取本地索引失败：%w", err)
+	}
+	return idx, nil
+}
+
+// expireMu 防止并发的写请求同时改写索引文件
+var expireMu sync.Mutex
+
+// Expire 使当前服务器的索引失效，下次查询时从服务器重新获取
+func Expire() {
+	expireMu.Lock()
+	defer expireMu.Unlock()
+	idx, err := OpenDefault()
+	if err != nil || idx.Synced == 0 || idx.Updated == 0 {
+		return
+	}
+	idx.Updated = 0
+	_ = idx.Save()
+}
+
+// Open 读取目录中的索引，不存在或版本不一致时返回空索引
+func Open(dir, server string) (*Index, error) {
+	x := &Index{dir: dir, Version: version, Server: server}
+	data, err := os.ReadFile(x.file())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return x, nil
+		}
+		return nil, err
+	}
+	var stored Index
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Version != version {
+		return x, nil
+	}
+	stored.dir = dir
+	return &stored, nil
+}
+
+func (x *Index) file() string {
+	return filepath.Join(x.dir, "sessions.json")
+}
+
+// Dir 索引所在的目录
+func (x *Index) Dir() string {
+	return x.dir
+}
+
+// Save 写入索引，先写临时文件再替换，避免写到一半的文件
+func (x *Index) Save() error {
+	if err := os.MkdirAll(x.dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(x)
+	if err != nil {
+		return err
+	}
+	return writeFile(x.file(), data)
+}
+
+// writeFile 先写入同目录下唯一的临时文件再替换，多个进程同时写入时不会互相覆盖临时文件
+func writeFile(file string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// Fresh 索引在 ttl 内同步或更新过时视为最新
+func (x *Index) Fresh(now time.Time, ttl time.Duration) bool {
+	return x.Synced != 0 && now.Sub(time.UnixMilli(x.Updated)) <= ttl
+}
+
+// Replace 用完整的会话列表替换索引，并删除已不存在的会话的消息快照
+func (x *Index) Replace(sessions []types.Session, now time.Time) {
+	keep := make(map[string]bool, len(sessions))
+	for _, s := range sessions {
+		keep[s.ID] = true
+	}
+	for _, s := range x.Sessions {
+		if !keep[s.ID] {
+			x.Invalidate(s.ID)
+		}
+	}
+	x.Sessions = sessions
+	x.Synced = now.UnixMilli()
+	x.Updated = x.Synced
+}
+
+// Sync 从服务器获取完整的会话列表并写入索引
+func (x *Index) Sync(ctx context.Context, c client.ClientInterface) error {
+	sessions, err := Fetch(ctx, c)
+	if err != nil {
+		return err
+	}
+	x.Replace(sessions, time.Now())
+	if err := x.Save(); err != nil {
+		return i18n.Errorf("写入本地索引失败：%w", err)
+	}
+	return nil
+}
+
+// Get 按 ID 查找会话
+func (x *Index) Get(id string) (types.Session, bool) {
+	for _, s := range x.Sessions {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return types.Session{}, false
+}
+
+// Upsert 添加或更新会话，不改变索引的有效期
+func (x *Index) Upsert(s types.Session) {
+	for i := range x.Sessions {
+		if x.Sessions[i].ID == s.ID {
+			x.Sessions[i] = s
+			return
+		}
+	}
+	x.Sessions = append(x.Sessions, s)
+}
+
+// Remove 删除会话及其消息快照，不改变索引的有效期
+func (x *Index) Remove(id string) {
+	sessions := x.Sessions[:0]
+	for _, s := range x.Sessions {
+		if s.ID != id {
+			sessions = append(sessions, s)
+		}
+	}
+	x.Sessions = sessions
+	x.Invalidate(id)
+}
+
+// 消息删除事件，event 包中没有对应常量
+const (
+	typeMessageRemoved     = "message.removed"
+	typeMessagePartRemoved = "message.part.removed"
+)
+
+// Apply 根据事件流中的事件更新索引，会话列表有变化时返回 true
+// 消息相关的事件只删除对应会话的消息快照，下次使用时重新获取
+func (x *Index) Apply(e types.Event, now time.Time) bool {
+	switch e.Type {
+	case event.TypeSessionCreated, event.TypeSessionUpdated, event.TypeSessionDeleted:
+		var p struct {
+			Info types.Session `json:"info"`
+		}
+		if err := json.Unmarshal(e.Properties, &p); err != nil || p.Info.ID == "" {
+			return false
+		}
+		if e.Type == event.TypeSessionDeleted {
+			x.Remove(p.Info.ID)
+		} else {
+			x.Upsert(p.Info)
+		}
+		x.Touch(now)
+		return true
+	case event.TypeMessageUpdated, event.TypeMessagePartUpdated, typeMessageRemoved, typeMessagePartRemoved:
+		if id := event.SessionID(e); id != "" {
+			x.Invalidate(id)
+		}
+	}
+	return false
+}
+
+// Touch 记录索引仍在同步（事件流保持连接）
+func (x *Index) Touch(now time.Time) {
+	x.Updated = now.UnixMilli()
+}
+
+func (x *Index) messagesFile(id string) string {
+	return filepath.Join(x.dir, "messages", filepath.Base(id)+".json")
+}
+
+// Messages 读取会话的消息快照
+// updated 为会话当前的更新时间，快照在之后获取时 current 为 true；updated 为 0 时不检查
+func (x *Index) Messages(id string, updated int64) (messages []types.MessageWithParts, current bool, ok bool) {
+	data, err := os.ReadFile(x.messagesFile(id))
+	if err != nil {
+		return nil, false, false
+	}
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, false, false
+	}
+	return snap.Messages, updated == 0 || snap.Updated >= updated, true
+}
+
+// SaveMessages 保存会话的消息快照，updated 为获取消息时会话的更新时间
+func (x *Index) SaveMessages(id string, updated int64, messages []types.MessageWithParts) error {
+	file := x.messagesFile(id)
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(snapshot{Updated: updated, Messages: messages})
+	if err != nil {
+		return err
+	}
+	return writeFile(file, data)
+}
+
+// Invalidate 删除会话的消息快照
+func (x *Index) Invalidate(id string) {
+	_ = os.Remove(x.messagesFile(id))
+}
+
+// Clear 删除索引和所有消息快照
+func (x *Index) Clear() error {
+	x.Sessions, x.Synced, x.Updated = nil, 0, 0
+	return os.RemoveAll(x.dir)
+}
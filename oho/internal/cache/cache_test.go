@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anomalyco/oho/internal/types"
+)
+
+func TestDefaultDir(t *testing.T) {
+	t.Setenv("OHO_CACHE_DIR", "/tmp/oho-cache")
+	if got := DefaultDir("http://127.0.0.1:4096"); got != filepath.Join("/tmp/oho-cache", "127.0.0.1_4096") {
+		t.Errorf("DefaultDir = %q", got)
+	}
+	if got := DefaultDir("https://example.com/oc"); got != filepath.Join("/tmp/oho-cache", "example.com_oc") {
+		t.Errorf("DefaultDir = %q", got)
+	}
+}
+
+func TestOpenSave(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "srv")
+	now := time.UnixMilli(1000000)
+
+	idx, err := Open(dir, "http://srv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.Synced != 0 || idx.Fresh(now, time.Hour) {
+		t.Errorf("empty index should not be fresh: %+v", idx)
+	}
+
+	idx.Replace([]types.Session{{ID: "ses_1", Title: "one"}}, now)
+	if err := idx.Save(); err != nil {
+		t.Fatal(err)
+	}
+	idx, err = Open(dir, "http://srv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Sessions) != 1 || idx.Synced != now.UnixMilli() || idx.Dir() != dir {
+		t.Errorf("reopened index = %+v", idx)
+	}
+
+	// 版本不一致的索引视为空
+	if err := os.WriteFile(filepath.Join(dir, "sessions.json"), []byte(`{"version":99,"synced":1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	idx, _ = Open(dir, "http://srv")
+	if idx.Synced != 0 || idx.Version != version {
+		t.Errorf("stale version index = %+v", idx)
+	}
+}
+
+func TestSaveConcurrent(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "srv")
+	now := time.UnixMilli(1000000)
+
+	// 多个进程共用缓存目录，同时写入索引和消息快照
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			idx, err := Open(dir, "http://srv")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			idx.Replace([]types.Session{{ID: "ses_" + strconv.Itoa(i)}}, now)
+			if err := idx.Save(); err != nil {
+				t.Error(err)
+			}
+			if err := idx.SaveMessages("ses_1", int64(i), nil); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	idx, err := Open(dir, "http://srv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Sessions) != 1 || idx.Synced != now.UnixMilli() {
+		t.Errorf("Expected one complete index, got %+v", idx)
+	}
+	if _, _, ok := idx.Messages("ses_1", 0); !ok {
+		t.Error("Expected a complete message snapshot")
+	}
+
+	// 不留下临时文件
+	tmps, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	messageTmps, _ := filepath.Glob(filepath.Join(filepath.Dir(idx.messagesFile("ses_1")), "*.tmp"))
+	if len(tmps)+len(messageTmps) != 0 {
+		t.Errorf("Leftover temp files: %v %v", tmps, messageTmps)
+	}
+}
+
+func TestFresh(t *testing.T) {
+	now := time.UnixMilli(10 * 60 * 1000)
+	idx := &Index{dir: t.TempDir()}
+	idx.Replace(nil, now.Add(-2*time.Minute))
+	if idx.Fresh(now, time.Minute) {
+		t.Error("index synced 2m ago should be stale with 1m ttl")
+	}
+	if !idx.Fresh(now, 5*time.Minute) {
+		t.Error("index synced 2m ago should be fresh with 5m ttl")
+	}
+
+	// 事件流保持连接时刷新有效期，单个会话的更新不刷新
+	idx.Upsert(types.Session{ID: "ses_1"})
+	if idx.Fresh(now, time.Minute) {
+		t.Error("Upsert should not refresh the index")
+	}
+	idx.Touch(now)
+	if !idx.Fresh(now, time.Minute) {
+		t.Error("Touch should refresh the index")
+	}
+}
+
+func TestApply(t *testing.T) {
+	idx := &Index{dir: t.TempDir()}
+	now := time.UnixMilli(1000)
+	idx.Replace([]types.Session{{ID: "ses_1", Title: "old"}, {ID: "ses_2"}}, now)
+	if err := idx.SaveMessages("ses_1", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	later := now.Add(time.Minute)
+	tests := []struct {
+		name    string
+		event   string
+		changed bool
+	}{
+		{"updated", `{"type":"session.updated","properties":{"info":{"id":"ses_1","title":"new"}}}`, true},
+		{"created", `{"type":"session.created","properties":{"info":{"id":"ses_3"}}}`, true},
+		{"deleted", `{"type":"session.deleted","properties":{"info":{"id":"ses_2"}}}`, true},
+		{"message", `{"type":"message.updated","properties":{"info":{"id":"msg_1","sessionID":"ses_1"}}}`, false},
+		{"status", `{"type":"session.status","properties":{"sessionID":"ses_1"}}`, false},
+		{"invalid", `{"type":"session.updated","properties":{"info":{}}}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e types.Event
+			if err := json.Unmarshal([]byte(tt.event), &e); err != nil {
+				t.Fatal(err)
+			}
+			if got := idx.Apply(e, later); got != tt.changed {
+				t.Errorf("Apply() = %v, want %v", got, tt.changed)
+			}
+		})
+	}
+
+	var ids []string
+	for _, s := range idx.Sessions {
+		ids = append(ids, s.ID)
+	}
+	if len(ids) != 2 || ids[0] != "ses_1" || ids[1] != "ses_3" {
+		t.Errorf("sessions = %v", ids)
+	}
+	if s, _ := idx.Get("ses_1"); s.Title != "new" {
+		t.Errorf("ses_1 = %+v", s)
+	}
+	if idx.Updated != later.UnixMilli() || idx.Synced != now.UnixMilli() {
+		t.Errorf("Updated = %d, Synced = %d", idx.Updated, idx.Synced)
+	}
+	if _, _, ok := idx.Messages("ses_1", 0); ok {
+		t.Error("message event should invalidate the snapshot")
+	}
+}
+
+func TestMessages(t *testing.T) {
+	idx := &Index{dir: t.TempDir()}
+	msgs := []types.MessageWithParts{{Info: types.Message{ID: "msg_1"}}}
+	if _, _, ok := idx.Messages("ses_1", 0); ok {
+		t.Error("expected no snapshot")
+	}
+	if err := idx.SaveMessages("ses_1", 200, msgs); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		updated int64
+		current bool
+	}{
+		{0, true},
+		{100, true},
+		{200, true},
+		{300, false},
+	}
+	for _, tt := range tests {
+		got, current, ok := idx.Messages("ses_1", tt.updated)
+		if !ok || current != tt.current || len(got) != 1 || got[0].Info.ID != "msg_1" {
+			t.Errorf("Messages(%d) = %v, %v, %v", tt.updated, got, current, ok)
+		}
+	}
+
+	// 完整同步时删除已不存在的会话的快照
+	idx.Replace([]types.Session{{ID: "ses_1"}}, time.Now())
+	idx.Replace([]types.Session{{ID: "ses_2"}}, time.Now())
+	if _, _, ok := idx.Messages("ses_1", 0); ok {
+		t.Error("Replace should drop snapshots of removed sessions")
+	}
+}
+
+func TestClear(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "srv")
+	idx, _ := Open(dir, "http://srv")
+	idx.Replace([]types.Session{{ID: "ses_1"}}, time.Now())
+	if err := idx.Save(); err != nil {
+		t.Fatal(err)
+	}
+	_ = idx.SaveMessages("ses_1", 1, nil)
+
+	if err := idx.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Clear should remove %s: %v", dir, err)
+	}
+	if idx.Synced != 0 || len(idx.Sessions) != 0 {
+		t.Errorf("cleared index = %+v", idx)
+	}
+}
@@ -4,17 +4,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
 )
 
+// authFailedMessage 认证失败时的提示
+const authFailedMessage = "认证失败 [401]: 用户名或密码错误\n\n请配置认证信息，选择以下任一方式:\n  1. 环境变量 (推荐):\n     export OPENCODE_SERVER_HOST=127.0.0.1\n     export OPENCODE_SERVER_PORT=4096\n     export OPENCODE_SERVER_USERNAME=opencode\n     export OPENCODE_SERVER_PASSWORD=your-password\n\n  2. 命令行标志:\n     oho --password your-password <command>\n\n  3. 配置文件 (~/.config/oho/config.json):\n     {\"password\": \"your-password\"}"
+
 // Client OpenCode API 客户端
 type Client struct {
 	baseURL    string
@@ -48,21 +54,34 @@ func NewClient() *Client {
 	}
 }
 
+// afterWrite 写请求结束后调用，不论请求是否成功
+var afterWrite func()
+
+// OnWrite 设置写请求（POST、PUT、PATCH、DELETE）结束后的回调，用于使本地缓存失效
+func OnWrite(fn func()) {
+	afterWrite = fn
+}
+
 // Request 发送 HTTP 请求
 func (c *Client) Request(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	// 请求失败时服务器也可能已经修改了数据
+	if method != http.MethodGet && method != http.MethodHead && afterWrite != nil {
+		defer afterWrite()
+	}
+
 	var reqBody io.Reader
 
 	if body != nil {
 		data, err := json.Marshal(body)
 		if err != nil {
-			return nil, fmt.Errorf("序列化请求体失败：%w", err)
+			return nil, i18n.Errorf("序列化请求体失败：%w", err)
 		}
 		reqBody = bytes.NewReader(data)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("创建请求失败：%w", err)
+		return nil, i18n.Errorf("创建请求失败：%w", err)
 	}
 
 	// 设置认证
@@ -81,24 +100,24 @@ func (c *Client) Request(ctx context.Context, method, path string, body interfac
 	if err != nil {
 		// 检查是否是超时错误
 		if strings.Contains(err.Error(), "context deadline exceeded") || strings.Contains(err.Error(), "Client.Timeout exceeded") {
-			return nil, fmt.Errorf("请求超时（%d 秒）\n\n建议:\n  1. 使用 --no-reply 参数避免等待\n  2. 设置环境变量增加超时：export OPENCODE_CLIENT_TIMEOUT=600\n  3. 使用异步命令：oho message prompt-async -s <session-id> \"任务\"", c.timeoutSec)
+			return nil, i18n.Errorf("请求超时（%d 秒）：%w\n\n建议:\n  1. 使用 --no-reply 参数避免等待\n  2. 设置环境变量增加超时：export OPENCODE_CLIENT_TIMEOUT=600\n  3. 使用异步命令：oho message prompt-async -s <session-id> \"任务\"", c.timeoutSec, err)
 		}
-		return nil, fmt.Errorf("请求失败：%w", err)
+		return nil, i18n.Errorf("请求失败：%w", err)
 	}
 	defer resp.Body.Close()
 
 	// 读取响应
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("读取响应失败：%w", err)
+		return nil, i18n.Errorf("读取响应失败：%w", err)
 	}
 
 	// 检查状态码
 	if resp.StatusCode >= 400 {
 		if resp.StatusCode == 401 {
-			return nil, fmt.Errorf("认证失败 [401]: 用户名或密码错误\n\n请配置认证信息，选择以下任一方式:\n  1. 环境变量 (推荐):\n     export OPENCODE_SERVER_HOST=127.0.0.1\n     export OPENCODE_SERVER_PORT=4096\n     export OPENCODE_SERVER_USERNAME=opencode\n     export OPENCODE_SERVER_PASSWORD=your-password\n\n  2. 命令行标志:\n     oho --password your-password <command>\n\n  3. 配置文件 (~/.config/oho/config.json):\n     {\"password\": \"your-password\"}")
+			return nil, errors.New(i18n.T(authFailedMessage))
 		}
-		return nil, fmt.Errorf("API 错误 [%d]: %s", resp.StatusCode, string(respBody))
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
 	}
 
 	return respBody, nil
@@ -180,7 +199,11 @@ func (c *Client) SSEStream(ctx context.Context, path string) (<-chan []byte, <-c
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
 
-	resp, err := c.httpClient.Do(req)
+	// 事件流是长连接，不能受整体请求超时限制，由 ctx 控制生命周期
+	streamClient := *c.httpClient
+	streamClient.Timeout = 0
+
+	resp, err := streamClient.Do(req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -189,9 +212,9 @@ func (c *Client) SSEStream(ctx context.Context, path string) (<-chan []byte, <-c
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
 		if resp.StatusCode == 401 {
-			return nil, nil, fmt.Errorf("认证失败 [401]: 用户名或密码错误\n\n请配置认证信息，选择以下任一方式:\n  1. 环境变量 (推荐):\n     export OPENCODE_SERVER_HOST=127.0.0.1\n     export OPENCODE_SERVER_PORT=4096\n     export OPENCODE_SERVER_USERNAME=opencode\n     export OPENCODE_SERVER_PASSWORD=your-password\n\n  2. 命令行标志:\n     oho --password your-password <command>\n\n  3. 配置文件 (~/.config/oho/config.json):\n     {\"password\": \"your-password\"}")
+			return nil, nil, errors.New(i18n.T(authFailedMessage))
 		}
-		return nil, nil, fmt.Errorf("SSE 错误 [%d]: %s", resp.StatusCode, string(body))
+		return nil, nil, i18n.Errorf("SSE 错误 [%d]: %s", resp.StatusCode, string(body))
 	}
 
 	eventChan := make(chan []byte)
@@ -212,7 +235,11 @@ func (c *Client) SSEStream(ctx context.Context, path string) (<-chan []byte, <-c
 				if n > 0 {
 					data := make([]byte, n)
 					copy(data, buf[:n])
-					eventChan <- data
+					select {
+					case eventChan <- data:
+					case <-ctx.Done():
+						return
+					}
 				}
 				if err != nil {
 					if err != io.EOF {
@@ -226,3 +253,9 @@ func (c *Client) SSEStream(ctx context.Context, path string) (<-chan []byte, <-c
 
 	return eventChan, errChan, nil
 }
+
+// IsUnavailable 判断请求是否因为无法连接服务器而失败（而不是服务器返回了错误）
+func IsUnavailable(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
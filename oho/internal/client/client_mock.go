@@ -2,7 +2,8 @@ package client
 
 import (
 	"context"
-	"fmt"
+
+	"github.com/anomalyco/oho/internal/i18n"
 )
 
 // APIError API 错误
@@ -12,7 +13,7 @@ type APIError struct {
 }
 
 func (e *APIError) Error() string {
-	return fmt.Sprintf("API Error [%d]: %s", e.StatusCode, e.Message)
+	return i18n.Sprintf("API 错误 [%d]: %s", e.StatusCode, e.Message)
 }
 
 // MockClient implements ClientInterface for testing
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/anomalyco/oho/internal/config"
 )
@@ -120,3 +122,65 @@ func TestClientErrorResponse(t *testing.T) {
 		t.Fatal("Expected error, got nil")
 	}
 }
+
+func TestIsUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Not Found", http.StatusNotFound)
+	}))
+	c := &Client{baseURL: server.URL, httpClient: &http.Client{}}
+
+	// 服务器返回的错误不算不可用
+	if _, err := c.Get(context.Background(), "/test"); err == nil || IsUnavailable(err) {
+		t.Errorf("IsUnavailable(%v) should be false", err)
+	}
+
+	server.Close()
+	if _, err := c.Get(context.Background(), "/test"); !IsUnavailable(err) {
+		t.Errorf("IsUnavailable(%v) should be true after the server is closed", err)
+	}
+}
+
+func TestIsUnavailableTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	c := &Client{baseURL: server.URL, httpClient: &http.Client{Timeout: 50 * time.Millisecond}, timeoutSec: 1}
+	_, err := c.Get(context.Background(), "/test")
+	if err == nil {
+		t.Fatal("Expected a timeout error")
+	}
+	// 超时的提示信息中保留原始错误，仍然视为无法连接服务器
+	if !strings.Contains(err.Error(), "OPENCODE_CLIENT_TIMEOUT") || !IsUnavailable(err) {
+		t.Errorf("IsUnavailable(%v) should be true for a timeout", err)
+	}
+}
+
+func TestOnWrite(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	writes := 0
+	OnWrite(func() { writes++ })
+	defer OnWrite(nil)
+
+	c := &Client{baseURL: server.URL, httpClient: &http.Client{}}
+	_, _ = c.Get(context.Background(), "/session")
+	if writes != 0 {
+		t.Errorf("GET triggered %d write callbacks", writes)
+	}
+	// 失败的写请求也会触发回调
+	_, _ = c.Delete(context.Background(), "/session/ses_1")
+	_, _ = c.Post(context.Background(), "/session", nil)
+	if writes != 2 {
+		t.Errorf("Expected 2 write callbacks, got %d", writes)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"runtime"
 
 	"github.com/spf13/pflag"
+
+	"github.com/anomalyco/oho/internal/i18n"
 )
 
 // Config 存储 CLI 配置
@@ -18,6 +20,8 @@ type Config struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	JSON     bool   `json:"json"`
+	Output   string `json:"output,omitempty"`
+	Lang     string `json:"lang,omitempty"`
 }
 
 var cfg *Config
@@ -38,14 +42,14 @@ func Init() error {
 	configFile := findConfigFile()
 	if configFile != "" {
 		if data, err := os.ReadFile(configFile); err == nil {
-			fmt.Fprintf(os.Stderr, "[config] 成功读取配置文件: %s\n", configFile)
+			i18n.Fprintf(os.Stderr, "[config] 成功读取配置文件: %s\n", configFile)
 			if err := json.Unmarshal(data, cfg); err != nil {
-				return fmt.Errorf("解析配置文件失败：%w", err)
+				return i18n.Errorf("解析配置文件失败：%w", err)
 			}
 		}
 	} else {
-		fmt.Fprintf(os.Stderr, "[config] 配置文件不存在，请创建或设置环境变量\n")
-		fmt.Fprintf(os.Stderr, "[config] 尝试过的路径:\n")
+		i18n.Fprintf(os.Stderr, "[config] 配置文件不存在，请创建或设置环境变量\n")
+		i18n.Fprintf(os.Stderr, "[config] 尝试过的路径:\n")
 		for _, p := range getConfigSearchPaths() {
 			fmt.Fprintf(os.Stderr, "[config]   - %s\n", p)
 		}
@@ -83,6 +87,16 @@ func BindFlags(flags *pflag.FlagSet) {
 	if jsonOut, _ := flags.GetBool("json"); jsonOut {
 		cfg.JSON = jsonOut
 	}
+	if output, _ := flags.GetString("output"); output != "" {
+		cfg.Output = output
+	}
+	if lang, _ := flags.GetString("lang"); lang != "" {
+		cfg.Lang = lang
+	}
+	// -o json 与 --json 等价
+	if cfg.Output == "json" {
+		cfg.JSON = true
+	}
 }
 
 // Get 获取配置
@@ -203,3 +217,19 @@ func getConfigPath() string {
 
 	return filepath.Join(".", ".config", "oho", "config.json")
 }
+
+// Dir 获取 oho 本地数据目录（与配置文件同目录）
+func Dir() string {
+	return filepath.Dir(getConfigPath())
+}
+
+// CacheDir 获取 oho 缓存目录：OHO_CACHE_DIR，或用户缓存目录下的 oho
+func CacheDir() string {
+	if dir := os.Getenv("OHO_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "oho")
+	}
+	return filepath.Join(Dir(), "cache")
+}
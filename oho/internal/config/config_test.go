@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/spf13/pflag"
 )
 
 func TestInitWithMissingConfig(t *testing.T) {
@@ -146,3 +148,25 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestBindFlagsOutput(t *testing.T) {
+	cfg = &Config{Port: 4096}
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("host", "", "")
+	flags.Int("port", 4096, "")
+	flags.String("password", "", "")
+	flags.Bool("json", false, "")
+	flags.String("output", "", "")
+	if err := flags.Parse([]string{"--output", "json", "--port", "5000"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	BindFlags(flags)
+	if cfg.Output != "json" || !cfg.JSON {
+		t.Errorf("Expected -o json to enable JSON mode, got Output=%q JSON=%v", cfg.Output, cfg.JSON)
+	}
+	if cfg.Port != 5000 {
+		t.Errorf("Expected port 5000, got %d", cfg.Port)
+	}
+}
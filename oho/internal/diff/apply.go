@@ -0,0 +1,239 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// 应用结果状态
+const (
+	ResultApplied   = "applied"
+	ResultCreated   = "created"
+	ResultDeleted   = "deleted"
+	ResultUnchanged = "unchanged"
+	ResultConflict  = "conflict"
+)
+
+// 冲突原因
+const (
+	ReasonExists   = "file already exists"
+	ReasonNotFound = "file not found"
+	ReasonModified = "file has local changes"
+)
+
+// RejectSuffix 冲突补丁段写入的文件后缀
+const RejectSuffix = ".rej"
+
+// Result 单个文件的应用结果
+type Result struct {
+	Path      string   `json:"path"`
+	Status    string   `json:"status"`
+	Conflicts []string `json:"conflicts,omitempty"` // 无法应用的补丁段头
+	Reason    string   `json:"reason,omitempty"`
+	Reject    string   `json:"reject,omitempty"` // 冲突补丁段所在文件
+}
+
+// ErrUnsafePath 补丁中的路径超出目标目录
+var ErrUnsafePath = errors.New("unsafe path")
+
+// Apply 将差异应用到本地目录 root
+// 本地内容与变更后一致时跳过；与变更前一致时直接写入；否则按补丁段查找上下文应用。
+// 存在冲突的文件保持不变，冲突的补丁段写入 <path>.rej
+func Apply(root string, diffs []types.FileDiff, context int) ([]Result, error) {
+	return apply(root, diffs, context, true)
+}
+
+// Check 与 Apply 相同，但只返回结果而不修改任何文件
+func Check(root string, diffs []types.FileDiff, context int) ([]Result, error) {
+	return apply(root, diffs, context, false)
+}
+
+func apply(root string, diffs []types.FileDiff, context int, write bool) ([]Result, error) {
+	targets := make([]string, len(diffs))
+	for i, d := range diffs {
+		target, err := resolve(root, d.Path)
+		if err != nil {
+			return nil, err
+		}
+		targets[i] = target
+	}
+
+	results := make([]Result, 0, len(diffs))
+	for i, d := range diffs {
+		result, err := applyFile(targets[i], d, context, write)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// resolve 返回文件在 root 下的路径，拒绝绝对路径和 ..
+func resolve(root, path string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(path))
+	if path == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafePath, path)
+	}
+	return filepath.Join(root, clean), nil
+}
+
+func applyFile(target string, d types.FileDiff, context int, write bool) (Result, error) {
+	result := Result{Path: d.Path}
+	status := Status(d)
+
+	data, err := os.ReadFile(target)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return result, err
+	}
+	local := string(data)
+
+	switch {
+	case status == StatusDeleted && !exists:
+		result.Status = ResultUnchanged
+		return result, nil
+
+	case status != StatusDeleted && exists && local == d.After:
+		result.Status = ResultUnchanged
+		return result, nil
+
+	case status == StatusAdded && exists:
+		result.Status = ResultConflict
+		result.Reason = ReasonExists
+		return reject(target, d, FileHunks(d, context), result, write)
+
+	case status != StatusAdded && !exists:
+		result.Status = ResultConflict
+		result.Reason = ReasonNotFound
+		return reject(target, d, FileHunks(d, context), result, write)
+
+	case status == StatusDeleted && local != d.Before:
+		result.Status = ResultConflict
+		result.Reason = ReasonModified
+		return reject(target, d, FileHunks(d, context), result, write)
+
+	case status == StatusDeleted:
+		if write {
+			if err := os.Remove(target); err != nil {
+				return result, err
+			}
+		}
+		result.Status = ResultDeleted
+		return result, nil
+	}
+
+	content := d.After
+	if exists && local != d.Before {
+		lines, rejected := applyHunks(Lines(local), FileHunks(d, context))
+		if len(rejected) > 0 {
+			result.Status = ResultConflict
+			for _, h := range rejected {
+				result.Conflicts = append(result.Conflicts, h.Header())
+			}
+			return reject(target, d, rejected, result, write)
+		}
+		content = strings.Join(lines, "")
+	}
+
+	result.Status = ResultApplied
+	if !exists {
+		result.Status = ResultCreated
+	}
+	if !write {
+		return result, nil
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(target); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return result, err
+	}
+	if err := os.WriteFile(target, []byte(content), mode); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// reject 将冲突的补丁段写入 .rej 文件
+func reject(target string, d types.FileDiff, hunks []Hunk, result Result, write bool) (Result, error) {
+	if len(hunks) == 0 || !write {
+		return result, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", d.Path, d.Path)
+	writeHunks(&b, hunks)
+
+	file := target + RejectSuffix
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return result, err
+	}
+	if err := os.WriteFile(file, []byte(b.String()), 0644); err != nil {
+		return result, err
+	}
+	result.Reject = d.Path + RejectSuffix
+	return result, nil
+}
+
+// applyHunks 依次将补丁段应用到 local，上下文不在预期位置时在附近查找
+func applyHunks(local []string, hunks []Hunk) ([]string, []Hunk) {
+	var out []string
+	var rejected []Hunk
+	pos := 0    // local 中已处理到的位置
+	offset := 0 // 实际位置相对于补丁记录位置的偏移
+
+	for _, h := range hunks {
+		old := h.old()
+		expected := h.OldStart - 1
+		if h.OldLines == 0 {
+			expected = h.OldStart
+		}
+
+		idx := find(local, old, expected+offset, pos)
+		if idx < 0 {
+			rejected = append(rejected, h)
+			continue
+		}
+		out = append(out, local[pos:idx]...)
+		out = append(out, h.new()...)
+		pos = idx + len(old)
+		offset = idx - expected
+	}
+	return append(out, local[pos:]...), rejected
+}
+
+// find 在 local[from:] 中查找与 want 完全一致的位置，优先选择离 near 最近的
+func find(local, want []string, near, from int) int {
+	if near < from {
+		near = from
+	}
+	for delta := 0; ; delta++ {
+		before, after := near-delta, near+delta
+		if before < from && after+len(want) > len(local) {
+			return -1
+		}
+		if after+len(want) <= len(local) && match(local[after:], want) {
+			return after
+		}
+		if delta > 0 && before >= from && before+len(want) <= len(local) && match(local[before:], want) {
+			return before
+		}
+	}
+}
+
+func match(lines, want []string) bool {
+	for i, w := range want {
+		if lines[i] != w {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,190 @@
+package diff
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anomalyco/oho/internal/types"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func numbered(n int, replace map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if s, ok := replace[i]; ok {
+			b.WriteString(s + "\n")
+			continue
+		}
+		b.WriteString("line " + string(rune('a'+i-1)) + "\n")
+	}
+	return b.String()
+}
+
+func TestApply(t *testing.T) {
+	before := numbered(12, nil)
+	after := numbered(12, map[int]string{2: "changed b", 11: "changed k"})
+
+	tests := []struct {
+		name      string
+		local     *string // nil 表示本地文件不存在
+		diff      types.FileDiff
+		status    string
+		want      *string
+		conflicts int
+	}{
+		{
+			name:   "matches before",
+			local:  &before,
+			diff:   types.FileDiff{Path: "f.txt", Before: before, After: after, Status: "modified"},
+			status: ResultApplied,
+			want:   &after,
+		},
+		{
+			name:   "already applied",
+			local:  &after,
+			diff:   types.FileDiff{Path: "f.txt", Before: before, After: after, Status: "modified"},
+			status: ResultUnchanged,
+			want:   &after,
+		},
+		{
+			name:   "local changes elsewhere",
+			local:  strPtr("header\n" + numbered(12, map[int]string{6: "local f"})),
+			diff:   types.FileDiff{Path: "f.txt", Before: before, After: after, Status: "modified"},
+			status: ResultApplied,
+			want:   strPtr("header\n" + numbered(12, map[int]string{2: "changed b", 6: "local f", 11: "changed k"})),
+		},
+		{
+			name:      "conflicting local change",
+			local:     strPtr(numbered(12, map[int]string{11: "local k"})),
+			diff:      types.FileDiff{Path: "f.txt", Before: before, After: after, Status: "modified"},
+			status:    ResultConflict,
+			want:      strPtr(numbered(12, map[int]string{11: "local k"})),
+			conflicts: 1,
+		},
+		{
+			name:   "create",
+			diff:   types.FileDiff{Path: "dir/new.txt", After: "new\n", Status: "added"},
+			status: ResultCreated,
+			want:   strPtr("new\n"),
+		},
+		{
+			name:   "added but exists",
+			local:  strPtr("mine\n"),
+			diff:   types.FileDiff{Path: "new.txt", After: "new\n", Status: "added"},
+			status: ResultConflict,
+			want:   strPtr("mine\n"),
+		},
+		{
+			name:   "delete",
+			local:  strPtr("x\n"),
+			diff:   types.FileDiff{Path: "old.txt", Before: "x\n", Status: "deleted"},
+			status: ResultDeleted,
+		},
+		{
+			name:   "modified but missing",
+			diff:   types.FileDiff{Path: "gone.txt", Before: "x\n", After: "y\n", Status: "modified"},
+			status: ResultConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.local != nil {
+				writeFile(t, dir, tt.diff.Path, *tt.local)
+			}
+
+			results, err := Apply(dir, []types.FileDiff{tt.diff}, DefaultContext)
+			if err != nil {
+				t.Fatalf("Apply() error: %v", err)
+			}
+			r := results[0]
+			if r.Status != tt.status {
+				t.Fatalf("Status = %s, want %s (%+v)", r.Status, tt.status, r)
+			}
+			if len(r.Conflicts) != tt.conflicts {
+				t.Errorf("Conflicts = %v, want %d", r.Conflicts, tt.conflicts)
+			}
+
+			_, statErr := os.Stat(filepath.Join(dir, tt.diff.Path))
+			if tt.want == nil {
+				if tt.local != nil && !os.IsNotExist(statErr) {
+					t.Errorf("Expected %s to be removed", tt.diff.Path)
+				}
+			} else if got := readFile(t, dir, tt.diff.Path); got != *tt.want {
+				t.Errorf("content =\n%s\nwant\n%s", got, *tt.want)
+			}
+
+			if r.Status == ResultConflict {
+				if r.Reject == "" {
+					t.Fatal("Expected a reject file")
+				}
+				if rej := readFile(t, dir, r.Reject); !strings.HasPrefix(rej, "--- a/"+tt.diff.Path) {
+					t.Errorf("reject file = %q", rej)
+				}
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "f.txt", "local\n")
+
+	diffs := []types.FileDiff{
+		{Path: "f.txt", Before: "a\n", After: "b\n", Status: "modified"},
+		{Path: "new.txt", After: "x\n", Status: "added"},
+	}
+	results, err := Check(dir, diffs, DefaultContext)
+	if err != nil {
+		t.Fatalf("Check() error: %v", err)
+	}
+	if results[0].Status != ResultConflict || results[1].Status != ResultCreated {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+
+	// 不修改任何文件
+	if got := readFile(t, dir, "f.txt"); got != "local\n" {
+		t.Errorf("f.txt changed to %q", got)
+	}
+	for _, name := range []string{"new.txt", "f.txt" + RejectSuffix} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s not to be written", name)
+		}
+	}
+}
+
+func TestApplyUnsafePath(t *testing.T) {
+	for _, path := range []string{"../escape", "/etc/passwd", ""} {
+		_, err := Apply(t.TempDir(), []types.FileDiff{{Path: path, After: "x"}}, DefaultContext)
+		if !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("Apply(%q) error = %v, want ErrUnsafePath", path, err)
+		}
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
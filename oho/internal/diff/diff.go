@@ -0,0 +1,168 @@
+// Package diff 计算文件内容差异，生成统一格式补丁并应用到本地目录
+package diff
+
+import "strings"
+
+// Op 编辑操作类型
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Edit 一行的编辑操作
+type Edit struct {
+	Op   Op
+	Text string // 包含行尾换行符，最后一行可能没有
+}
+
+// maxEditDistance 编辑距离超过该值时放弃求最短编辑脚本，直接整段替换
+const maxEditDistance = 4000
+
+// Lines 按行切分文本，每行保留换行符
+func Lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Compute 计算从 a 到 b 的逐行编辑脚本（线性空间的 Myers 算法）
+func Compute(a, b []string) []Edit {
+	return compute(a, b, nil)
+}
+
+// compute 去掉公共前后缀后，从中间把问题一分为二递归求解，结果追加到 edits
+func compute(a, b []string, edits []Edit) []Edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, line := range a[:prefix] {
+		edits = append(edits, Edit{Op: Equal, Text: line})
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if x, y, ok := bisect(midA, midB); ok {
+		edits = compute(midA[:x], midB[:y], edits)
+		edits = compute(midA[x:], midB[y:], edits)
+	} else {
+		edits = append(edits, replace(midA, midB)...)
+	}
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, Edit{Op: Equal, Text: line})
+	}
+	return edits
+}
+
+// bisect 从两端同时搜索最短编辑路径，返回两端路径相遇的位置
+// 只保存当前一轮的搜索状态，内存与输入长度成正比
+// 任一方为空、或编辑距离超过 maxEditDistance 时返回 false，由调用方整段替换
+func bisect(a, b []string) (x, y int, ok bool) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+
+	maxD := (n + m + 1) / 2
+	if maxD > maxEditDistance/2 {
+		maxD = maxEditDistance / 2
+	}
+	offset := maxD + 1
+	forward := make([]int, 2*maxD+3)
+	backward := make([]int, 2*maxD+3)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	// delta 为奇数时两端在正向搜索中相遇，否则在反向搜索中相遇
+	delta := n - m
+	odd := delta%2 != 0
+	// 越出编辑图的对角线不再搜索
+	var fStart, fEnd, bStart, bEnd int
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			var x1 int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x1 = forward[offset+k+1]
+			} else {
+				x1 = forward[offset+k-1] + 1
+			}
+			y1 := x1 - k
+			for x1 < n && y1 < m && a[x1] == b[y1] {
+				x1++
+				y1++
+			}
+			forward[offset+k] = x1
+
+			switch {
+			case x1 > n:
+				fEnd += 2
+			case y1 > m:
+				fStart += 2
+			case odd:
+				// 反向搜索中同一条对角线上的位置，坐标从末尾算起
+				bk := offset + delta - k
+				if bk >= 0 && bk < len(backward) && backward[bk] != -1 && x1 >= n-backward[bk] {
+					return x1, y1, true
+				}
+			}
+		}
+
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			var x2 int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x2 = backward[offset+k+1]
+			} else {
+				x2 = backward[offset+k-1] + 1
+			}
+			y2 := x2 - k
+			for x2 < n && y2 < m && a[n-x2-1] == b[m-y2-1] {
+				x2++
+				y2++
+			}
+			backward[offset+k] = x2
+
+			switch {
+			case x2 > n:
+				bEnd += 2
+			case y2 > m:
+				bStart += 2
+			case !odd:
+				fk := offset + delta - k
+				if fk >= 0 && fk < len(forward) && forward[fk] != -1 {
+					x1 := forward[fk]
+					y1 := x1 - (delta - k)
+					if x1 >= n-x2 {
+						return x1, y1, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// replace 删除 a 的全部行并插入 b 的全部行
+func replace(a, b []string) []Edit {
+	edits := make([]Edit, 0, len(a)+len(b))
+	for _, line := range a {
+		edits = append(edits, Edit{Op: Delete, Text: line})
+	}
+	for _, line := range b {
+		edits = append(edits, Edit{Op: Insert, Text: line})
+	}
+	return edits
+}
@@ -0,0 +1,246 @@
+package diff
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/anomalyco/oho/internal/types"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\n", []string{"a\n"}},
+		{"a\nb", []string{"a\n", "b"}},
+		{"a\n\n", []string{"a\n", "\n"}},
+	}
+
+	for _, tt := range tests {
+		got := Lines(tt.input)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("Lines(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// apply 将编辑脚本作用于 a，用于验证 Compute 的结果
+func applyEdits(edits []Edit) (string, string) {
+	var a, b strings.Builder
+	for _, e := range edits {
+		if e.Op != Insert {
+			a.WriteString(e.Text)
+		}
+		if e.Op != Delete {
+			b.WriteString(e.Text)
+		}
+	}
+	return a.String(), b.String()
+}
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		changes int
+	}{
+		{"equal", "a\nb\n", "a\nb\n", 0},
+		{"insert", "a\nc\n", "a\nb\nc\n", 1},
+		{"delete", "a\nb\nc\n", "a\nc\n", 1},
+		{"replace", "a\nb\nc\n", "a\nx\nc\n", 2},
+		{"from empty", "", "a\nb\n", 2},
+		{"to empty", "a\nb\n", "", 2},
+		{"reorder", "a\nb\nc\nd\n", "b\na\nd\nc\n", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := Compute(Lines(tt.a), Lines(tt.b))
+			a, b := applyEdits(edits)
+			if a != tt.a || b != tt.b {
+				t.Fatalf("edits do not reproduce input: %q -> %q", a, b)
+			}
+			changes := 0
+			for _, e := range edits {
+				if e.Op != Equal {
+					changes++
+				}
+			}
+			if changes != tt.changes {
+				t.Errorf("Expected %d changes, got %d", tt.changes, changes)
+			}
+		})
+	}
+}
+
+func TestComputeLarge(t *testing.T) {
+	countChanges := func(edits []Edit) int {
+		changes := 0
+		for _, e := range edits {
+			if e.Op != Equal {
+				changes++
+			}
+		}
+		return changes
+	}
+
+	// 两个完全不同的大文件：编辑距离等于总行数，内存占用不能随编辑距离平方增长
+	var a, b []string
+	for i := 0; i < 3000; i++ {
+		a = append(a, fmt.Sprintf("old %d\n", i))
+		b = append(b, fmt.Sprintf("new %d\n", i))
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	edits := Compute(a, b)
+	runtime.ReadMemStats(&after)
+
+	if gotA, gotB := applyEdits(edits); gotA != strings.Join(a, "") || gotB != strings.Join(b, "") {
+		t.Fatal("edits do not reproduce disjoint input")
+	}
+	if changes := countChanges(edits); changes != 6000 {
+		t.Errorf("Expected 6000 changes, got %d", changes)
+	}
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 16<<20 {
+		t.Errorf("Compute allocated %d bytes for disjoint input", alloc)
+	}
+
+	// 大文件中的分散修改仍然得到最短编辑脚本
+	var c []string
+	for i, line := range a {
+		if i%10 == 0 {
+			c = append(c, fmt.Sprintf("changed %d\n", i))
+			continue
+		}
+		c = append(c, line)
+	}
+	edits = Compute(a, c)
+	if gotA, gotC := applyEdits(edits); gotA != strings.Join(a, "") || gotC != strings.Join(c, "") {
+		t.Fatal("edits do not reproduce scattered changes")
+	}
+	if changes := countChanges(edits); changes != 600 {
+		t.Errorf("Expected 600 changes, got %d", changes)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		diff types.FileDiff
+		want string
+	}{
+		{
+			name: "modified",
+			diff: types.FileDiff{Path: "main.go", Before: "a\nb\nc\n", After: "a\nB\nc\n", Status: "modified"},
+			want: "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "added",
+			diff: types.FileDiff{Path: "new.txt", After: "x\n", Status: "added"},
+			want: "diff --git a/new.txt b/new.txt\nnew file mode 100644\n--- /dev/null\n+++ b/new.txt\n@@ -0,0 +1 @@\n+x\n",
+		},
+		{
+			name: "deleted",
+			diff: types.FileDiff{Path: "old.txt", Before: "x\ny\n", Status: "deleted"},
+			want: "diff --git a/old.txt b/old.txt\ndeleted file mode 100644\n--- a/old.txt\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-x\n-y\n",
+		},
+		{
+			name: "missing newline",
+			diff: types.FileDiff{Path: "f", Before: "a", After: "a\n"},
+			want: "diff --git a/f b/f\n--- a/f\n+++ b/f\n@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+a\n",
+		},
+		{
+			name: "unchanged",
+			diff: types.FileDiff{Path: "f", Before: "a\n", After: "a\n", Status: "modified"},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified(tt.diff, DefaultContext); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHunksContext(t *testing.T) {
+	var before, after []string
+	for i := 0; i < 20; i++ {
+		line := fmt.Sprintf("line %d\n", i)
+		before = append(before, line)
+		after = append(after, line)
+	}
+	after[2] = "two\n"
+	after[17] = "seventeen\n"
+
+	hunks := Hunks(Compute(before, after), 3)
+	if len(hunks) != 2 {
+		t.Fatalf("Expected 2 hunks, got %d", len(hunks))
+	}
+	if got := hunks[0].Header(); got != "@@ -1,6 +1,6 @@" {
+		t.Errorf("hunks[0] = %s", got)
+	}
+	if got := hunks[1].Header(); got != "@@ -15,6 +15,6 @@" {
+		t.Errorf("hunks[1] = %s", got)
+	}
+
+	// 上下文足够大时合并为一段
+	if hunks := Hunks(Compute(before, after), 8); len(hunks) != 1 {
+		t.Errorf("Expected changes to merge into 1 hunk, got %d", len(hunks))
+	}
+
+	added, deleted := Stat(hunks)
+	if added != 2 || deleted != 2 {
+		t.Errorf("Stat() = +%d -%d", added, deleted)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		diff types.FileDiff
+		want string
+	}{
+		{types.FileDiff{Status: "added"}, StatusAdded},
+		{types.FileDiff{After: "x"}, StatusAdded},
+		{types.FileDiff{Before: "x"}, StatusDeleted},
+		{types.FileDiff{Before: "x", After: "y"}, StatusModified},
+	}
+
+	for _, tt := range tests {
+		if got := Status(tt.diff); got != tt.want {
+			t.Errorf("Status(%+v) = %s, want %s", tt.diff, got, tt.want)
+		}
+	}
+}
+
+func TestColorize(t *testing.T) {
+	got := Colorize("@@ -1 +1 @@\n-a\n+b\n c\n")
+	want := ansiCyan + "@@ -1 +1 @@" + ansiReset + "\n" + ansiRed + "-a" + ansiReset + "\n" + ansiGreen + "+b" + ansiReset + "\n c\n"
+	if got != want {
+		t.Errorf("Colorize() = %q, want %q", got, want)
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	d := types.FileDiff{Path: "f", Before: "a\nb\nc\n", After: "a\nB\nc\nd\n"}
+	got := SideBySide(d, DefaultContext, 40, false)
+	want := strings.Join([]string{
+		"f",
+		"@@ -1,3 +1,4 @@",
+		"   1 a                  1 a",
+		"   2 b             |    2 B",
+		"   3 c                  3 c",
+		"                   >    4 d",
+		"",
+	}, "\n")
+	if got != want {
+		t.Errorf("SideBySide() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,126 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anomalyco/oho/internal/markdown"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// row 并排视图中的一行，左右为空表示该侧没有对应行
+type row struct {
+	oldNum, newNum int
+	left, right    string
+	op             byte // ' ' 相同，'|' 修改，'<' 删除，'>' 新增
+}
+
+// SideBySide 并排显示文件差异，width 为总宽度
+func SideBySide(d types.FileDiff, context, width int, color bool) string {
+	return sideBySide(d.Path, FileHunks(d, context), width, color)
+}
+
+func sideBySide(path string, hunks []Hunk, width int, color bool) string {
+	// 行号 4 列 + 空格，中间分隔 3 列
+	col := (width-3)/2 - 5
+	if col < 10 {
+		col = 10
+	}
+
+	var b strings.Builder
+	header := path
+	if color {
+		header = ansiBold + header + ansiReset
+	}
+	b.WriteString(header + "\n")
+
+	for _, h := range hunks {
+		sep := h.Header()
+		if color {
+			sep = ansiCyan + sep + ansiReset
+		}
+		b.WriteString(sep + "\n")
+
+		for _, r := range rows(h) {
+			left := cell(r.oldNum, r.left, col)
+			right := cell(r.newNum, r.right, col)
+			if color {
+				switch r.op {
+				case '|':
+					left, right = ansiRed+left+ansiReset, ansiGreen+right+ansiReset
+				case '<':
+					left = ansiRed + left + ansiReset
+				case '>':
+					right = ansiGreen + right + ansiReset
+				}
+			}
+			b.WriteString(strings.TrimRight(fmt.Sprintf("%s %c %s", left, r.op, right), " ") + "\n")
+		}
+	}
+	return b.String()
+}
+
+// rows 将补丁段转换为并排的行，连续的删除和新增两两配对
+func rows(h Hunk) []row {
+	var out []row
+	oldNum, newNum := h.OldStart, h.NewStart
+
+	edits := h.Edits
+	for i := 0; i < len(edits); {
+		if edits[i].Op == Equal {
+			out = append(out, row{oldNum: oldNum, newNum: newNum, left: edits[i].Text, right: edits[i].Text, op: ' '})
+			oldNum++
+			newNum++
+			i++
+			continue
+		}
+
+		var dels, ins []string
+		for i < len(edits) && edits[i].Op == Delete {
+			dels = append(dels, edits[i].Text)
+			i++
+		}
+		for i < len(edits) && edits[i].Op == Insert {
+			ins = append(ins, edits[i].Text)
+			i++
+		}
+		for j := 0; j < len(dels) || j < len(ins); j++ {
+			r := row{op: '|'}
+			if j < len(dels) {
+				r.oldNum, r.left = oldNum, dels[j]
+				oldNum++
+			} else {
+				r.op = '>'
+			}
+			if j < len(ins) {
+				r.newNum, r.right = newNum, ins[j]
+				newNum++
+			} else {
+				r.op = '<'
+			}
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// cell 格式化一侧的内容：行号加截断或补齐到固定宽度的文本
+func cell(num int, text string, width int) string {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\t", "    "), "\r\n")
+	number := "    "
+	if num > 0 {
+		number = fmt.Sprintf("%4d", num)
+	}
+
+	var b strings.Builder
+	w := 0
+	for _, r := range text {
+		rw := markdown.Width(string(r))
+		if w+rw > width {
+			break
+		}
+		b.WriteRune(r)
+		w += rw
+	}
+	return number + " " + b.String() + strings.Repeat(" ", width-w)
+}
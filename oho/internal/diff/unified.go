@@ -0,0 +1,256 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// DefaultContext 统一格式补丁默认的上下文行数
+const DefaultContext = 3
+
+// 文件变更状态
+const (
+	StatusAdded    = "added"
+	StatusDeleted  = "deleted"
+	StatusModified = "modified"
+)
+
+const noNewline = "\\ No newline at end of file"
+
+// Hunk 补丁中的一段连续变更
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Edits              []Edit
+}
+
+// Header 返回 @@ -a,b +c,d @@ 行
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines))
+}
+
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// old 返回变更前的行（上下文和删除行）
+func (h Hunk) old() []string {
+	var lines []string
+	for _, e := range h.Edits {
+		if e.Op != Insert {
+			lines = append(lines, e.Text)
+		}
+	}
+	return lines
+}
+
+// new 返回变更后的行（上下文和插入行）
+func (h Hunk) new() []string {
+	var lines []string
+	for _, e := range h.Edits {
+		if e.Op != Delete {
+			lines = append(lines, e.Text)
+		}
+	}
+	return lines
+}
+
+// Hunks 将编辑脚本按上下文行数分组为补丁段
+func Hunks(edits []Edit, context int) []Hunk {
+	if context < 0 {
+		context = 0
+	}
+
+	// 每个编辑之前已经过的旧行数和新行数
+	oldPos := make([]int, len(edits)+1)
+	newPos := make([]int, len(edits)+1)
+	var changes []int
+	for i, e := range edits {
+		oldPos[i+1], newPos[i+1] = oldPos[i], newPos[i]
+		if e.Op != Insert {
+			oldPos[i+1]++
+		}
+		if e.Op != Delete {
+			newPos[i+1]++
+		}
+		if e.Op != Equal {
+			changes = append(changes, i)
+		}
+	}
+
+	var hunks []Hunk
+	for i := 0; i < len(changes); {
+		// 相邻变更之间的相同行不超过两倍上下文时合并为一段
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*context+1 {
+			j++
+		}
+		start := changes[i] - context
+		if start < 0 {
+			start = 0
+		}
+		end := changes[j] + context + 1
+		if end > len(edits) {
+			end = len(edits)
+		}
+
+		h := Hunk{
+			OldLines: oldPos[end] - oldPos[start],
+			NewLines: newPos[end] - newPos[start],
+			Edits:    edits[start:end],
+		}
+		h.OldStart = oldPos[start]
+		if h.OldLines > 0 {
+			h.OldStart++
+		}
+		h.NewStart = newPos[start]
+		if h.NewLines > 0 {
+			h.NewStart++
+		}
+		hunks = append(hunks, h)
+		i = j + 1
+	}
+	return hunks
+}
+
+// Status 返回文件的变更状态，服务端未提供时根据内容推断
+func Status(d types.FileDiff) string {
+	switch d.Status {
+	case StatusAdded, StatusDeleted, StatusModified:
+		return d.Status
+	}
+	switch {
+	case d.Before == "" && d.After != "":
+		return StatusAdded
+	case d.Before != "" && d.After == "":
+		return StatusDeleted
+	}
+	return StatusModified
+}
+
+// FileHunks 计算单个文件的补丁段
+func FileHunks(d types.FileDiff, context int) []Hunk {
+	return Hunks(Compute(Lines(d.Before), Lines(d.After)), context)
+}
+
+// Stat 统计新增和删除的行数
+func Stat(hunks []Hunk) (added, deleted int) {
+	for _, h := range hunks {
+		for _, e := range h.Edits {
+			switch e.Op {
+			case Insert:
+				added++
+			case Delete:
+				deleted++
+			}
+		}
+	}
+	return added, deleted
+}
+
+// Unified 生成可被 git apply 使用的统一格式补丁，内容无变化时返回空字符串
+func Unified(d types.FileDiff, context int) string {
+	hunks := FileHunks(d, context)
+	status := Status(d)
+	if len(hunks) == 0 && status == StatusModified {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n", d.Path, d.Path)
+	oldName, newName := "a/"+d.Path, "b/"+d.Path
+	switch status {
+	case StatusAdded:
+		b.WriteString("new file mode 100644\n")
+		oldName = "/dev/null"
+	case StatusDeleted:
+		b.WriteString("deleted file mode 100644\n")
+		newName = "/dev/null"
+	}
+	if len(hunks) == 0 {
+		return b.String()
+	}
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	writeHunks(&b, hunks)
+	return b.String()
+}
+
+// Patch 生成多个文件的补丁
+func Patch(diffs []types.FileDiff, context int) string {
+	var b strings.Builder
+	for _, d := range diffs {
+		b.WriteString(Unified(d, context))
+	}
+	return b.String()
+}
+
+func writeHunks(b *strings.Builder, hunks []Hunk) {
+	for _, h := range hunks {
+		b.WriteString(h.Header())
+		b.WriteByte('\n')
+		for _, e := range h.Edits {
+			switch e.Op {
+			case Equal:
+				b.WriteByte(' ')
+			case Delete:
+				b.WriteByte('-')
+			case Insert:
+				b.WriteByte('+')
+			}
+			b.WriteString(e.Text)
+			if !strings.HasSuffix(e.Text, "\n") {
+				b.WriteString("\n" + noNewline + "\n")
+			}
+		}
+	}
+}
+
+// ANSI 颜色
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// Colorize 为统一格式补丁着色
+func Colorize(patch string) string {
+	lines := strings.SplitAfter(patch, "\n")
+	var b strings.Builder
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		text := strings.TrimSuffix(line, "\n")
+		color := ""
+		switch {
+		case strings.HasPrefix(text, "diff --git"),
+			strings.HasPrefix(text, "new file mode"),
+			strings.HasPrefix(text, "deleted file mode"),
+			strings.HasPrefix(text, "--- "),
+			strings.HasPrefix(text, "+++ "):
+			color = ansiBold
+		case strings.HasPrefix(text, "@@"):
+			color = ansiCyan
+		case strings.HasPrefix(text, "+"):
+			color = ansiGreen
+		case strings.HasPrefix(text, "-"):
+			color = ansiRed
+		case strings.HasPrefix(text, "\\"):
+			color = ansiDim
+		}
+		if color == "" {
+			b.WriteString(line)
+			continue
+		}
+		b.WriteString(color + text + ansiReset + line[len(text):])
+	}
+	return b.String()
+}
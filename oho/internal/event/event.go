@@ -0,0 +1,195 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// OpenCode Server 事件类型
+const (
+	TypeServerConnected    = "server.connected"
+	TypeSessionCreated     = "session.created"
+	TypeSessionUpdated     = "session.updated"
+	TypeSessionDeleted     = "session.deleted"
+	TypeSessionStatus      = "session.status"
+	TypeSessionIdle        = "session.idle"
+	TypeSessionError       = "session.error"
+	TypeMessageUpdated     = "message.updated"
+	TypeMessagePartUpdated = "message.part.updated"
+	TypePermissionUpdated  = "permission.updated"
+	TypePermissionAsked    = "permission.asked"
+	TypePermissionReplied  = "permission.replied"
+)
+
+// DefaultPath 当前实例的事件流端点
+const DefaultPath = "/event"
+
+// envelope 同时兼容 /event 与 /global/event（{directory, payload}）两种格式
+type envelope struct {
+	Type       string          `json:"type"`
+	Properties json.RawMessage `json:"properties"`
+	Payload    *types.Event    `json:"payload"`
+}
+
+// Parser 将 SSE 字节流拆分为事件
+// SSEStream 返回的是原始数据块，一个块可能包含半个或多个事件
+type Parser struct {
+	buf  []byte
+	data []string
+}
+
+// Feed 写入一个数据块，返回其中已完整的事件
+func (p *Parser) Feed(chunk []byte) []types.Event {
+	p.buf = append(p.buf, chunk...)
+
+	var events []types.Event
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimRight(string(p.buf[:i]), "\r")
+		p.buf = p.buf[i+1:]
+
+		switch {
+		case line == "":
+			// 空行表示一个事件结束
+			if len(p.data) > 0 {
+				if e, ok := Decode([]byte(strings.Join(p.data, "\n"))); ok {
+					events = append(events, e)
+				}
+				p.data = nil
+			}
+		case strings.HasPrefix(line, ":"):
+			// 注释行（心跳）
+		case strings.HasPrefix(line, "data:"):
+			p.data = append(p.data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	return events
+}
+
+// Decode 解析单个事件的 JSON 数据
+func Decode(data []byte) (types.Event, bool) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return types.Event{}, false
+	}
+	if env.Type == "" && env.Payload != nil {
+		return *env.Payload, env.Payload.Type != ""
+	}
+	if env.Type == "" {
+		return types.Event{}, false
+	}
+	return types.Event{Type: env.Type, Properties: env.Properties}, true
+}
+
+// Subscribe 订阅事件流，返回解析后的事件通道
+// 事件通道在连接断开或 ctx 取消时关闭，错误通道最多返回一个错误
+func Subscribe(ctx context.Context, c client.ClientInterface, path string) (<-chan types.Event, <-chan error, error) {
+	chunks, errs, err := c.SSEStream(ctx, path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events := make(chan types.Event)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errChan)
+
+		var parser Parser
+		for chunk := range chunks {
+			for _, e := range parser.Feed(chunk) {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+		if err, ok := <-errs; ok && err != nil {
+			errChan <- err
+		}
+	}()
+
+	return events, errChan, nil
+}
+
+// Watch 持续订阅事件流，连接失败或断开后等待 retry 再重连，直到 ctx 取消
+// onError 非空时接收每次连接错误
+func Watch(ctx context.Context, c client.ClientInterface, path string, retry time.Duration, onError func(error)) <-chan types.Event {
+	out := make(chan types.Event)
+
+	go func() {
+		defer close(out)
+		for {
+			events, errs, err := Subscribe(ctx, c, path)
+			if err == nil {
+				for e := range events {
+					select {
+					case out <- e:
+					case <-ctx.Done():
+						return
+					}
+				}
+				err = <-errs
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil && onError != nil {
+				onError(err)
+			}
+
+			select {
+			case <-time.After(retry):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// sessionRef 各类事件中会话 ID 可能出现的位置
+type sessionRef struct {
+	SessionID string `json:"sessionID"`
+	Info      *struct {
+		ID        string `json:"id"`
+		SessionID string `json:"sessionID"`
+	} `json:"info"`
+	Part *struct {
+		SessionID string `json:"sessionID"`
+	} `json:"part"`
+}
+
+// SessionID 提取事件关联的会话 ID，无关联时返回空字符串
+func SessionID(e types.Event) string {
+	if len(e.Properties) == 0 {
+		return ""
+	}
+	var ref sessionRef
+	if err := json.Unmarshal(e.Properties, &ref); err != nil {
+		return ""
+	}
+	switch {
+	case ref.SessionID != "":
+		return ref.SessionID
+	case ref.Part != nil && ref.Part.SessionID != "":
+		return ref.Part.SessionID
+	case ref.Info != nil && ref.Info.SessionID != "":
+		return ref.Info.SessionID
+	case ref.Info != nil && strings.HasPrefix(e.Type, "session."):
+		return ref.Info.ID
+	}
+	return ""
+}
@@ -0,0 +1,148 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+func TestParserFeed(t *testing.T) {
+	var p Parser
+
+	// 一个事件被拆成两个数据块
+	events := p.Feed([]byte(`data: {"type":"session.idle","prop`))
+	if len(events) != 0 {
+		t.Fatalf("Expected no events from partial chunk, got %d", len(events))
+	}
+	events = p.Feed([]byte("erties\":{\"sessionID\":\"s1\"}}\n\n: heartbeat\n\ndata: {\"type\":\"server.connected\"}\n\n"))
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if events[0].Type != TypeSessionIdle || SessionID(events[0]) != "s1" {
+		t.Errorf("Unexpected first event: %+v", events[0])
+	}
+	if events[1].Type != TypeServerConnected {
+		t.Errorf("Unexpected second event: %+v", events[1])
+	}
+}
+
+func TestParserCRLFAndInvalid(t *testing.T) {
+	var p Parser
+	events := p.Feed([]byte("data: not json\r\n\r\ndata: {\"type\":\"session.idle\"}\r\n\r\n"))
+	if len(events) != 1 || events[0].Type != TypeSessionIdle {
+		t.Errorf("Expected one valid event, got %+v", events)
+	}
+}
+
+func TestDecodeGlobalEnvelope(t *testing.T) {
+	e, ok := Decode([]byte(`{"directory":"/repo","payload":{"type":"session.status","properties":{"sessionID":"s2","status":{"type":"busy"}}}}`))
+	if !ok {
+		t.Fatal("Expected global event to decode")
+	}
+	if e.Type != TypeSessionStatus || SessionID(e) != "s2" {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+}
+
+func TestSessionID(t *testing.T) {
+	tests := []struct {
+		name  string
+		event types.Event
+		want  string
+	}{
+		{"direct", types.Event{Type: TypeSessionIdle, Properties: []byte(`{"sessionID":"s1"}`)}, "s1"},
+		{"message info", types.Event{Type: TypeMessageUpdated, Properties: []byte(`{"info":{"id":"m1","sessionID":"s2"}}`)}, "s2"},
+		{"part", types.Event{Type: TypeMessagePartUpdated, Properties: []byte(`{"part":{"id":"p1","sessionID":"s3"}}`)}, "s3"},
+		{"session info", types.Event{Type: TypeSessionUpdated, Properties: []byte(`{"info":{"id":"s4"}}`)}, "s4"},
+		{"no session", types.Event{Type: TypeServerConnected}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SessionID(tt.event); got != tt.want {
+				t.Errorf("SessionID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	mock := &client.MockClient{
+		SSEStreamFunc: func(ctx context.Context, path string) (<-chan []byte, <-chan error, error) {
+			if path != DefaultPath {
+				t.Errorf("Unexpected path: %s", path)
+			}
+			chunks := make(chan []byte, 2)
+			errs := make(chan error)
+			chunks <- []byte("data: {\"type\":\"session.idle\",\"properties\":{\"sessionID\":\"s1\"}}\n")
+			chunks <- []byte("\n")
+			close(chunks)
+			close(errs)
+			return chunks, errs, nil
+		},
+	}
+
+	events, errs, err := Subscribe(context.Background(), mock, DefaultPath)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	var got []types.Event
+	for e := range events {
+		got = append(got, e)
+	}
+	if len(got) != 1 || got[0].Type != TypeSessionIdle {
+		t.Errorf("Unexpected events: %+v", got)
+	}
+	if err, ok := <-errs; ok && err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestWatchReconnects(t *testing.T) {
+	var attempts int32
+	mock := &client.MockClient{
+		SSEStreamFunc: func(ctx context.Context, path string) (<-chan []byte, <-chan error, error) {
+			n := atomic.AddInt32(&attempts, 1)
+			if n == 1 {
+				return nil, nil, errors.New("connection refused")
+			}
+			chunks := make(chan []byte, 1)
+			errs := make(chan error)
+			chunks <- []byte("data: {\"type\":\"session.idle\",\"properties\":{\"sessionID\":\"s1\"}}\n\n")
+			close(chunks)
+			close(errs)
+			return chunks, errs, nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var errCount int32
+	events := Watch(ctx, mock, DefaultPath, time.Millisecond, func(error) { atomic.AddInt32(&errCount, 1) })
+
+	// 第一次连接失败，之后每次重连都会收到一个事件
+	for i := 0; i < 2; i++ {
+		select {
+		case e := <-events:
+			if e.Type != TypeSessionIdle {
+				t.Errorf("Unexpected event: %+v", e)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for event")
+		}
+	}
+	if atomic.LoadInt32(&errCount) != 1 {
+		t.Errorf("Expected 1 connection error, got %d", errCount)
+	}
+
+	cancel()
+	for range events {
+	}
+}
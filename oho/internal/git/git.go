@@ -0,0 +1,271 @@
+// Package git 调用本地 git 命令操作仓库
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Repo 本地 git 仓库
+type Repo struct {
+	Dir string // 工作区根目录
+}
+
+// Trailer 提交信息末尾的 Key: Value 行
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// Open 打开 dir 所在的仓库
+func Open(dir string) (*Repo, error) {
+	out, err := run(dir, nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{Dir: out}, nil
+}
+
+// run 执行 git 命令，返回去掉首尾空白的输出，失败时错误中包含 stderr
+func run(dir string, stdin []byte, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func (r *Repo) run(args ...string) (string, error) {
+	return run(r.Dir, nil, args...)
+}
+
+// CurrentBranch 当前分支名
+func (r *Repo) CurrentBranch() (string, error) {
+	return r.run("rev-parse", "--abbrev-ref", "HEAD")
+}
+
+// ValidateBranch 检查分支名是否合法
+func ValidateBranch(name string) error {
+	if name == "" || strings.HasPrefix(name, "-") {
+		return fmt.Errorf("invalid branch name: %q", name)
+	}
+	if err := exec.Command("git", "check-ref-format", "--branch", name).Run(); err != nil {
+		return fmt.Errorf("invalid branch name: %q", name)
+	}
+	return nil
+}
+
+// BranchExists 判断本地分支是否存在
+func (r *Repo) BranchExists(name string) bool {
+	_, err := r.run("rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	return err == nil
+}
+
+// CreateBranch 基于当前 HEAD 创建并切换到新分支，工作区改动保持不变
+func (r *Repo) CreateBranch(name string) error {
+	_, err := r.run("checkout", "-b", name)
+	return err
+}
+
+// Checkout 切换到分支或提交，工作区改动保持不变
+func (r *Repo) Checkout(ref string) error {
+	_, err := r.run("checkout", "-q", ref)
+	return err
+}
+
+// Changed 返回 paths 中在工作区或暂存区有改动的路径，包括未跟踪文件
+func (r *Repo) Changed(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	seen := make(map[string]bool)
+	var changed []string
+	for _, args := range [][]string{
+		{"diff", "--name-only", "-z", "HEAD"},
+		{"diff", "--name-only", "-z", "--cached"},
+		{"ls-files", "--others", "-z"},
+	} {
+		out, err := r.run(append(append(args, "--"), paths...)...)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range strings.Split(out, "\x00") {
+			if p != "" && !seen[p] {
+				seen[p] = true
+				changed = append(changed, p)
+			}
+		}
+	}
+	return changed, nil
+}
+
+// Restore 将 paths 在工作区和暂存区恢复为 HEAD 的内容，HEAD 中不存在的文件被删除
+func (r *Repo) Restore(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	out, err := r.run(append([]string{"ls-tree", "-r", "-z", "--name-only", "HEAD", "--"}, paths...)...)
+	if err != nil {
+		return err
+	}
+	tracked := make(map[string]bool)
+	for _, p := range strings.Split(out, "\x00") {
+		if p != "" {
+			tracked[p] = true
+		}
+	}
+
+	var existing []string
+	for _, p := range paths {
+		if tracked[filepath.ToSlash(p)] {
+			existing = append(existing, p)
+			continue
+		}
+		if _, err := r.run("rm", "-q", "--cached", "--ignore-unmatch", "--", p); err != nil {
+			return err
+		}
+		if err := os.Remove(filepath.Join(r.Dir, p)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if len(existing) > 0 {
+		if _, err := r.run(append([]string{"checkout", "HEAD", "--"}, existing...)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasStaged 暂存区是否有改动
+func (r *Repo) HasStaged() (bool, error) {
+	cmd := exec.Command("git", "-C", r.Dir, "diff", "--cached", "--quiet")
+	err := cmd.Run()
+	if err == nil {
+		return false, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	return false, fmt.Errorf("git diff: %w", err)
+}
+
+// Add 暂存指定路径，包括删除的文件
+func (r *Repo) Add(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	_, err := r.run(append([]string{"add", "-A", "--"}, paths...)...)
+	return err
+}
+
+// Commit 提交暂存区，返回提交哈希
+func (r *Repo) Commit(message string) (string, error) {
+	if _, err := run(r.Dir, []byte(message), "commit", "--file=-", "--cleanup=verbatim"); err != nil {
+		return "", err
+	}
+	return r.Head()
+}
+
+// Head 当前提交的哈希
+func (r *Repo) Head() (string, error) {
+	return r.run("rev-parse", "HEAD")
+}
+
+// ShortHash 提交哈希的前 7 位，用于显示
+func ShortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// Dirty 工作区是否有未提交的改动（包括未跟踪文件）
+func (r *Repo) Dirty() (bool, error) {
+	out, err := r.run("status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	return out != "", nil
+}
+
+// CommitAll 暂存工作区的全部改动并提交，返回提交哈希
+func (r *Repo) CommitAll(message string) (string, error) {
+	if _, err := r.run("add", "-A"); err != nil {
+		return "", err
+	}
+	return r.Commit(message)
+}
+
+// AddWorktree 基于当前 HEAD 在 path 创建新分支的工作树
+func (r *Repo) AddWorktree(path, branch string) error {
+	_, err := r.run("worktree", "add", "-b", branch, path, "HEAD")
+	return err
+}
+
+// RemoveWorktree 删除工作树，force 时忽略未提交的改动
+func (r *Repo) RemoveWorktree(path string, force bool) error {
+	args := []string{"worktree", "remove"}
+	if force {
+		args = append(args, "--force")
+	}
+	_, err := r.run(append(args, path)...)
+	return err
+}
+
+// PruneWorktrees 清理目录已不存在的工作树记录
+func (r *Repo) PruneWorktrees() error {
+	_, err := r.run("worktree", "prune")
+	return err
+}
+
+// Merge 将分支合并到当前分支，冲突时中止合并并返回错误
+func (r *Repo) Merge(branch, message string) error {
+	if _, err := r.run("merge", "--no-ff", "-m", message, branch); err != nil {
+		_, _ = r.run("merge", "--abort")
+		return err
+	}
+	return nil
+}
+
+// DeleteBranch 删除本地分支，未合并的分支只有 force 时才删除
+func (r *Repo) DeleteBranch(name string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	_, err := r.run("branch", flag, name)
+	return err
+}
+
+// FormatMessage 组装提交信息：标题、正文和 trailer
+func FormatMessage(subject, body string, trailers []Trailer) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(subject))
+	b.WriteString("\n")
+	if body = strings.TrimSpace(body); body != "" {
+		b.WriteString("\n" + body + "\n")
+	}
+	if len(trailers) > 0 {
+		b.WriteString("\n")
+		for _, t := range trailers {
+			fmt.Fprintf(&b, "%s: %s\n", t.Key, t.Value)
+		}
+	}
+	return b.String()
+}
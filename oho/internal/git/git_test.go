@@ -0,0 +1,200 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initRepo 创建包含一次提交的临时仓库
+func initRepo(t *testing.T) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for key, value := range map[string]string{
+		"GIT_AUTHOR_NAME":     "test",
+		"GIT_AUTHOR_EMAIL":    "test@example.com",
+		"GIT_COMMITTER_NAME":  "test",
+		"GIT_COMMITTER_EMAIL": "test@example.com",
+		"GIT_CONFIG_GLOBAL":   os.DevNull,
+	} {
+		t.Setenv(key, value)
+	}
+
+	dir := t.TempDir()
+	if _, err := run(dir, nil, "init", "-q"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Add([]string{"README"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Commit("initial\n"); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestCommitOnBranch(t *testing.T) {
+	repo := initRepo(t)
+
+	if repo.BranchExists("feat/x") {
+		t.Fatal("Expected feat/x not to exist")
+	}
+	if err := repo.CreateBranch("feat/x"); err != nil {
+		t.Fatal(err)
+	}
+	if branch, _ := repo.CurrentBranch(); branch != "feat/x" {
+		t.Errorf("CurrentBranch() = %s", branch)
+	}
+
+	if err := os.WriteFile(filepath.Join(repo.Dir, "a.txt"), []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(repo.Dir, "README")); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Add([]string{"a.txt", "README"}); err != nil {
+		t.Fatal(err)
+	}
+	if staged, err := repo.HasStaged(); err != nil || !staged {
+		t.Fatalf("HasStaged() = %v, %v", staged, err)
+	}
+
+	message := FormatMessage("Add a", "# Heading\n\nbody", []Trailer{{Key: "Session-Id", Value: "ses_1"}})
+	hash, err := repo.Commit(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hash) != 40 {
+		t.Errorf("Commit() = %q, want a full hash", hash)
+	}
+
+	// 提交信息原样保留，包括 # 开头的行
+	got, _ := repo.run("log", "-1", "--format=%B")
+	if got != strings.TrimSpace(message) {
+		t.Errorf("commit message =\n%s\nwant\n%s", got, message)
+	}
+	trailer, _ := repo.run("log", "-1", "--format=%(trailers:key=Session-Id,valueonly)")
+	if trailer != "ses_1" {
+		t.Errorf("Session-Id trailer = %q", trailer)
+	}
+	if staged, _ := repo.HasStaged(); staged {
+		t.Error("Expected a clean index after commit")
+	}
+}
+
+func TestOpenNotRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if _, err := Open(t.TempDir()); err == nil {
+		t.Error("Expected an error outside a repository")
+	}
+}
+
+func TestValidateBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"feat/x", true},
+		{"fix-1", true},
+		{"", false},
+		{"-b", false},
+		{"a..b", false},
+		{"has space", false},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateBranch(tt.name); (err == nil) != tt.valid {
+			t.Errorf("ValidateBranch(%q) = %v, want valid=%v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestFormatMessage(t *testing.T) {
+	tests := []struct {
+		subject  string
+		body     string
+		trailers []Trailer
+		want     string
+	}{
+		{"Title", "", nil, "Title\n"},
+		{"Title", "Body\n", nil, "Title\n\nBody\n"},
+		{" Title ", "", []Trailer{{"Session-Id", "ses_1"}}, "Title\n\nSession-Id: ses_1\n"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatMessage(tt.subject, tt.body, tt.trailers); got != tt.want {
+			t.Errorf("FormatMessage() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestChangedAndRestore(t *testing.T) {
+	repo := initRepo(t)
+
+	if changed, err := repo.Changed([]string{"README", "new.txt"}); err != nil || len(changed) != 0 {
+		t.Fatalf("Changed() = %v, %v, want none", changed, err)
+	}
+
+	// 未暂存的修改和未跟踪的文件都算作改动
+	if err := os.WriteFile(filepath.Join(repo.Dir, "README"), []byte("edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo.Dir, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo.Dir, "other.txt"), []byte("other\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := repo.Changed([]string{"README", "new.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(changed, ",") != "README,new.txt" {
+		t.Errorf("Changed() = %v, want [README new.txt]", changed)
+	}
+
+	if err := repo.Add([]string{"README", "new.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Restore([]string{"README", "new.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo.Dir, "README")); string(data) != "hello\n" {
+		t.Errorf("README = %q, want the HEAD content", data)
+	}
+	if _, err := os.Stat(filepath.Join(repo.Dir, "new.txt")); !os.IsNotExist(err) {
+		t.Error("Expected new.txt to be removed")
+	}
+	if staged, _ := repo.HasStaged(); staged {
+		t.Error("Expected a clean index after Restore")
+	}
+	// 其他文件不受影响
+	if _, err := os.Stat(filepath.Join(repo.Dir, "other.txt")); err != nil {
+		t.Error("Expected other.txt to be kept")
+	}
+}
+
+func TestShortHash(t *testing.T) {
+	if got := ShortHash("0123456789abcdef"); got != "0123456" {
+		t.Errorf("ShortHash() = %q, want 0123456", got)
+	}
+	if got := ShortHash("abc"); got != "abc" {
+		t.Errorf("ShortHash() = %q, want abc", got)
+	}
+}
@@ -0,0 +1,362 @@
+// Package guard 根据事件流累计会话运行的费用、token 数和时长，超出预算上限时中止会话
+package guard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// 超出的上限
+const (
+	LimitCost     = "cost"
+	LimitTokens   = "tokens"
+	LimitDuration = "duration"
+)
+
+// checkInterval 检查运行时长的间隔
+const checkInterval = time.Second
+
+// Limits 预算上限，零值表示不限
+type Limits struct {
+	MaxCost     float64
+	MaxTokens   int64
+	MaxDuration time.Duration
+}
+
+// AddFlags 注册 --max-cost、--max-tokens 和 --max-duration
+func (l *Limits) AddFlags(flags *pflag.FlagSet) {
+	flags.Float64Var(&l.MaxCost, "max-cost", 0, "费用上限（美元），超出时中止会话")
+	flags.Int64Var(&l.MaxTokens, "max-tokens", 0, "token 数上限（包括缓存读写），超出时中止会话")
+	flags.DurationVar(&l.MaxDuration, "max-duration", 0, "运行时长上限，超出时中止会话")
+}
+
+// Empty 没有设置任何上限
+func (l Limits) Empty() bool {
+	return l.MaxCost <= 0 && l.MaxTokens <= 0 && l.MaxDuration <= 0
+}
+
+// RequireReply 上限只能在等待回复时执行，不等待回复的请求应使用 oho guard
+func (l Limits) RequireReply(noReply bool) error {
+	if noReply && !l.Empty() {
+		return i18n.Errorf("--max-cost、--max-tokens 和 --max-duration 需要等待回复，不能与 --no-reply 同时使用（可以使用 oho guard）")
+	}
+	return nil
+}
+
+// AbortError 运行超出上限被中止
+type AbortError struct {
+	Action types.GuardAction
+}
+
+func (e *AbortError) Error() string {
+	return i18n.Sprintf("已中止会话 %s：%s", e.Action.SessionID, e.Action.Reason)
+}
+
+// Run 会话从开始工作到空闲的一次运行，用量包括其子会话
+type Run struct {
+	SessionID string
+	Started   time.Time
+	Cost      float64
+	Tokens    int64
+	Exceeded  string // 超出的上限，未超出时为空
+}
+
+// exceeded 返回运行超出的上限，未超出时为空
+func (l Limits) exceeded(r *Run, now time.Time) string {
+	switch {
+	case l.MaxCost > 0 && r.Cost > l.MaxCost:
+		return LimitCost
+	case l.MaxTokens > 0 && r.Tokens > l.MaxTokens:
+		return LimitTokens
+	case l.MaxDuration > 0 && now.Sub(r.Started) > l.MaxDuration:
+		return LimitDuration
+	}
+	return ""
+}
+
+// Reason 描述运行超出的上限
+func (l Limits) Reason(r *Run, now time.Time) string {
+	switch r.Exceeded {
+	case LimitCost:
+		return i18n.Sprintf("费用 $%.4f 超过上限 $%.4f", r.Cost, l.MaxCost)
+	case LimitTokens:
+		return i18n.Sprintf("token 数 %d 超过上限 %d", r.Tokens, l.MaxTokens)
+	case LimitDuration:
+		return i18n.Sprintf("运行时长 %s 超过上限 %s", now.Sub(r.Started).Round(100*time.Millisecond), l.MaxDuration)
+	}
+	return ""
+}
+
+// Guard 跟踪会话的运行
+// Auto 为 true 时会话开始工作即开始跟踪（oho guard），否则只跟踪 Start 指定的会话
+type Guard struct {
+	Limits Limits
+	Auto   bool
+
+	parents map[string]string
+	runs    map[string]*Run
+	steps   map[string]bool
+}
+
+// New 创建 Guard
+func New(limits Limits, auto bool) *Guard {
+	return &Guard{
+		Limits:  limits,
+		Auto:    auto,
+		parents: make(map[string]string),
+		runs:    make(map[string]*Run),
+		steps:   make(map[string]bool),
+	}
+}
+
+// AddSessions 记录会话的父会话，子会话的用量计入根会话的运行
+func (g *Guard) AddSessions(sessions []types.Session) {
+	for _, s := range sessions {
+		if s.ParentID != "" {
+			g.parents[s.ID] = s.ParentID
+		}
+	}
+}
+
+// Start 开始跟踪根会话的一次运行，已在跟踪时返回已有的运行
+func (g *Guard) Start(id string, now time.Time) *Run {
+	id = g.root(id)
+	if r, ok := g.runs[id]; ok {
+		return r
+	}
+	r := &Run{SessionID: id, Started: now}
+	g.runs[id] = r
+	return r
+}
+
+// Runs 正在跟踪的运行，按会话 ID 排序
+func (g *Guard) Runs() []*Run {
+	runs := make([]*Run, 0, len(g.runs))
+	for _, r := range g.runs {
+		runs = append(runs, r)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].SessionID < runs[j].SessionID })
+	return runs
+}
+
+// root 沿父会话找到根会话
+func (g *Guard) root(id string) string {
+	seen := map[string]bool{}
+	for !seen[id] {
+		seen[id] = true
+		parent, ok := g.parents[id]
+		if !ok {
+			break
+		}
+		id = parent
+	}
+	return id
+}
+
+// descendants 根会话的所有子会话，按 ID 排序
+func (g *Guard) descendants(id string) []string {
+	var result []string
+	for child := range g.parents {
+		if child != id && g.root(child) == id {
+			result = append(result, child)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// eventProps 会话相关事件的数据
+type eventProps struct {
+	SessionID string         `json:"sessionID"`
+	Info      *types.Session `json:"info"`
+	Status    *struct {
+		Type string `json:"type"`
+	} `json:"status"`
+	Part *struct {
+		ID        string       `json:"id"`
+		SessionID string       `json:"sessionID"`
+		Type      string       `json:"type"`
+		Cost      float64      `json:"cost"`
+		Tokens    types.Tokens `json:"tokens"`
+	} `json:"part"`
+}
+
+// Handle 处理一个事件，返回因此超出上限的运行
+func (g *Guard) Handle(e types.Event, now time.Time) *Run {
+	if len(e.Properties) == 0 {
+		return nil
+	}
+	var props eventProps
+	if err := json.Unmarshal(e.Properties, &props); err != nil {
+		return nil
+	}
+
+	switch e.Type {
+	case event.TypeSessionCreated, event.TypeSessionUpdated:
+		if props.Info != nil {
+			g.AddSessions([]types.Session{*props.Info})
+		}
+	case event.TypeSessionDeleted:
+		if props.Info != nil {
+			delete(g.runs, props.Info.ID)
+		}
+	case event.TypeSessionStatus:
+		if props.Status == nil {
+			return nil
+		}
+		if props.Status.Type == "idle" {
+			g.end(props.SessionID)
+		} else if g.Auto {
+			g.Start(props.SessionID, now)
+		}
+	case event.TypeSessionIdle:
+		g.end(props.SessionID)
+	case event.TypeMessagePartUpdated:
+		part := props.Part
+		if part == nil || part.Type != "step-finish" || g.steps[part.ID] {
+			return nil
+		}
+		r, ok := g.runs[g.root(part.SessionID)]
+		if !ok {
+			if !g.Auto {
+				return nil
+			}
+			r = g.Start(part.SessionID, now)
+		}
+		g.steps[part.ID] = true
+		t := part.Tokens
+		r.Cost += part.Cost
+		r.Tokens += t.Input + t.Output + t.Reasoning + t.Cache.Read + t.Cache.Write
+		return g.check(r, now)
+	}
+	return nil
+}
+
+// end 根会话空闲时结束自动跟踪的运行；Start 指定的运行由调用方结束
+func (g *Guard) end(id string) {
+	if g.Auto && g.root(id) == id {
+		delete(g.runs, id)
+	}
+}
+
+// Check 检查运行时长，返回新超出上限的运行
+func (g *Guard) Check(now time.Time) []*Run {
+	var exceeded []*Run
+	for _, r := range g.Runs() {
+		if g.check(r, now) != nil {
+			exceeded = append(exceeded, r)
+		}
+	}
+	return exceeded
+}
+
+// check 运行第一次超出上限时记录并返回运行
+func (g *Guard) check(r *Run, now time.Time) *Run {
+	if r.Exceeded != "" {
+		return nil
+	}
+	r.Exceeded = g.Limits.exceeded(r, now)
+	if r.Exceeded == "" {
+		return nil
+	}
+	return r
+}
+
+// Abort 中止超出上限的运行，包括根会话和它的所有子会话；dryRun 时只记录
+func (g *Guard) Abort(ctx context.Context, c client.ClientInterface, r *Run, dryRun bool, now time.Time) types.GuardAction {
+	action := types.GuardAction{
+		Time:      now.UnixMilli(),
+		SessionID: r.SessionID,
+		Limit:     r.Exceeded,
+		Reason:    g.Limits.Reason(r, now),
+		Cost:      r.Cost,
+		Tokens:    r.Tokens,
+		Duration:  now.Sub(r.Started).Milliseconds(),
+		Aborted:   []string{},
+		DryRun:    dryRun,
+	}
+	if dryRun {
+		return action
+	}
+	for _, id := range append([]string{r.SessionID}, g.descendants(r.SessionID)...) {
+		if _, err := c.Post(ctx, fmt.Sprintf("/session/%s/abort", id), nil); err != nil {
+			if action.Error == "" {
+				action.Error = i18n.Sprintf("中止会话 %s 失败：%v", id, err)
+			}
+			continue
+		}
+		action.Aborted = append(action.Aborted, id)
+	}
+	return action
+}
+
+// Send 在预算监控下执行 send（发送消息并等待回复）
+// 发送前订阅事件流，运行超出上限时中止会话，send 返回后返回 *AbortError
+func Send(ctx context.Context, c client.ClientInterface, id string, limits Limits, send func() error) error {
+	if limits.Empty() {
+		return send()
+	}
+
+	// 中止会话使用外层的 ctx，send 返回后监控停止，但中止子会话的请求不应被取消
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events, _, err := event.Subscribe(ctx, c, event.DefaultPath)
+	if err != nil {
+		return i18n.Errorf("订阅事件流失败，无法监控预算：%w", err)
+	}
+
+	g := New(limits, false)
+	g.Start(id, time.Now())
+	var action *types.GuardAction
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			var exceeded []*Run
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-events:
+				if !ok {
+					if ctx.Err() != nil {
+						return
+					}
+					// 连接断开后继续重连，断开期间只能检查运行时长
+					events = event.Watch(ctx, c, event.DefaultPath, checkInterval, nil)
+					continue
+				}
+				if r := g.Handle(e, time.Now()); r != nil {
+					exceeded = append(exceeded, r)
+				}
+			case now := <-ticker.C:
+				exceeded = g.Check(now)
+			}
+			if len(exceeded) > 0 {
+				a := g.Abort(parent, c, exceeded[0], false, time.Now())
+				action = &a
+				return
+			}
+		}
+	}()
+
+	sendErr := send()
+	cancel()
+	<-done
+	if action != nil {
+		return &AbortError{Action: *action}
+	}
+	return sendErr
+}
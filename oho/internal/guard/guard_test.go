@@ -0,0 +1,221 @@
+package guard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// stepFinish 构造 step-finish 部分的更新事件
+func stepFinish(partID, sessionID string, cost float64, input, output int64) types.Event {
+	props, _ := json.Marshal(map[string]interface{}{
+		"part": map[string]interface{}{
+			"id": partID, "sessionID": sessionID, "type": "step-finish", "cost": cost,
+			"tokens": map[string]interface{}{"input": input, "output": output, "reasoning": 0, "cache": map[string]int64{"read": 10, "write": 0}},
+		},
+	})
+	return types.Event{Type: "message.part.updated", Properties: props}
+}
+
+func statusEvent(sessionID, status string) types.Event {
+	props, _ := json.Marshal(map[string]interface{}{"sessionID": sessionID, "status": map[string]string{"type": status}})
+	return types.Event{Type: "session.status", Properties: props}
+}
+
+func createdEvent(id, parentID string) types.Event {
+	props, _ := json.Marshal(map[string]interface{}{"info": map[string]string{"id": id, "parentID": parentID}})
+	return types.Event{Type: "session.created", Properties: props}
+}
+
+func TestLimits(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tests := []struct {
+		name   string
+		limits Limits
+		run    Run
+		want   string
+	}{
+		{"no limits", Limits{}, Run{Cost: 100, Tokens: 1e9, Started: now.Add(-time.Hour)}, ""},
+		{"cost", Limits{MaxCost: 1}, Run{Cost: 1.01, Started: now}, LimitCost},
+		{"cost at limit", Limits{MaxCost: 1}, Run{Cost: 1, Started: now}, ""},
+		{"tokens", Limits{MaxTokens: 100}, Run{Tokens: 101, Started: now}, LimitTokens},
+		{"duration", Limits{MaxDuration: time.Minute}, Run{Started: now.Add(-2 * time.Minute)}, LimitDuration},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.limits.exceeded(&tt.run, now); got != tt.want {
+				t.Errorf("exceeded() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if err := (Limits{MaxCost: 1}).RequireReply(true); err == nil {
+		t.Error("Expected error for limits with --no-reply")
+	}
+	if err := (Limits{}).RequireReply(true); err != nil {
+		t.Errorf("RequireReply without limits = %v", err)
+	}
+}
+
+func TestHandle(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	// 只跟踪 Start 指定的会话，子会话的用量计入根会话，同一步骤只计一次
+	g := New(Limits{MaxCost: 1}, false)
+	g.Start("ses_root", now)
+	g.Handle(createdEvent("ses_child", "ses_root"), now)
+	if r := g.Handle(stepFinish("prt_1", "ses_root", 0.4, 100, 20), now); r != nil {
+		t.Fatalf("Unexpected exceeded run after first step: %+v", r)
+	}
+	g.Handle(stepFinish("prt_1", "ses_root", 0.4, 100, 20), now)
+	g.Handle(stepFinish("prt_x", "ses_other", 5, 1, 1), now)
+	r := g.Handle(stepFinish("prt_2", "ses_child", 0.7, 50, 5), now)
+	if r == nil || r.SessionID != "ses_root" || r.Exceeded != LimitCost {
+		t.Fatalf("Expected root run to exceed cost, got %+v", r)
+	}
+	if r.Tokens != 100+20+10+50+5+10 || r.Cost != 1.1 {
+		t.Errorf("run usage = %d tokens, $%v", r.Tokens, r.Cost)
+	}
+	if len(g.Runs()) != 1 {
+		t.Errorf("Expected only the started session to be tracked, got %d runs", len(g.Runs()))
+	}
+	// 超出后不再重复报告
+	if r := g.Handle(stepFinish("prt_3", "ses_root", 1, 1, 1), now); r != nil {
+		t.Error("Expected exceeded run to be reported once")
+	}
+	// Start 指定的运行不会因空闲而结束
+	g.Handle(statusEvent("ses_root", "idle"), now)
+	if len(g.Runs()) != 1 {
+		t.Error("Expected started run to survive idle")
+	}
+
+	// Auto 时会话开始工作即跟踪，根会话空闲时结束
+	g = New(Limits{MaxTokens: 1000}, true)
+	g.AddSessions([]types.Session{{ID: "ses_b"}, {ID: "ses_c", ParentID: "ses_b"}})
+	g.Handle(statusEvent("ses_c", "busy"), now)
+	if runs := g.Runs(); len(runs) != 1 || runs[0].SessionID != "ses_b" {
+		t.Fatalf("runs after busy child = %+v", runs)
+	}
+	g.Handle(stepFinish("prt_4", "ses_d", 0, 10, 1), now)
+	if len(g.Runs()) != 2 {
+		t.Errorf("Expected step of untracked session to start a run, got %d runs", len(g.Runs()))
+	}
+	g.Handle(statusEvent("ses_c", "idle"), now)
+	g.Handle(statusEvent("ses_b", "idle"), now)
+	if runs := g.Runs(); len(runs) != 1 || runs[0].SessionID != "ses_d" {
+		t.Errorf("runs after idle = %+v", runs)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	start := time.Unix(1000, 0)
+	g := New(Limits{MaxDuration: time.Minute}, false)
+	g.Start("ses_a", start)
+	if exceeded := g.Check(start.Add(30 * time.Second)); len(exceeded) != 0 {
+		t.Errorf("Unexpected exceeded runs: %v", exceeded)
+	}
+	exceeded := g.Check(start.Add(90 * time.Second))
+	if len(exceeded) != 1 || exceeded[0].Exceeded != LimitDuration {
+		t.Fatalf("Check() = %v", exceeded)
+	}
+	if reason := g.Limits.Reason(exceeded[0], start.Add(90*time.Second)); !strings.Contains(reason, "1m30s") {
+		t.Errorf("Reason() = %q", reason)
+	}
+	if exceeded := g.Check(start.Add(2 * time.Minute)); len(exceeded) != 0 {
+		t.Error("Expected exceeded run to be reported once")
+	}
+}
+
+func TestAbort(t *testing.T) {
+	var aborted []string
+	mock := &client.MockClient{
+		PostFunc: func(ctx context.Context, path string, body interface{}) ([]byte, error) {
+			if strings.Contains(path, "ses_bad") {
+				return nil, errors.New("API 错误 [500]")
+			}
+			aborted = append(aborted, path)
+			return []byte("true"), nil
+		},
+	}
+	now := time.Unix(1000, 0)
+	g := New(Limits{MaxCost: 1}, true)
+	g.AddSessions([]types.Session{{ID: "ses_child", ParentID: "ses_root"}, {ID: "ses_bad", ParentID: "ses_child"}, {ID: "ses_x", ParentID: "ses_other"}})
+	r := g.Start("ses_root", now)
+	r.Cost, r.Exceeded = 2, LimitCost
+
+	action := g.Abort(context.Background(), mock, r, false, now.Add(time.Minute))
+	if strings.Join(aborted, ",") != "/session/ses_root/abort,/session/ses_child/abort" {
+		t.Errorf("aborted = %v", aborted)
+	}
+	if action.Limit != LimitCost || action.Duration != 60000 || len(action.Aborted) != 2 || !strings.Contains(action.Error, "ses_bad") {
+		t.Errorf("action = %+v", action)
+	}
+
+	aborted = nil
+	if action := g.Abort(context.Background(), mock, r, true, now); len(aborted) != 0 || !action.DryRun {
+		t.Errorf("dry-run aborted %v", aborted)
+	}
+}
+
+// sseFrame 将事件编码为 SSE 数据
+func sseFrame(e types.Event) []byte {
+	data, _ := json.Marshal(map[string]interface{}{"type": e.Type, "properties": e.Properties})
+	return []byte(fmt.Sprintf("data: %s\n\n", data))
+}
+
+func TestSend(t *testing.T) {
+	var mu sync.Mutex
+	var aborted []string
+	abortedCh := make(chan struct{})
+	chunks := make(chan []byte, 4)
+	mock := &client.MockClient{
+		SSEStreamFunc: func(ctx context.Context, path string) (<-chan []byte, <-chan error, error) {
+			return chunks, make(chan error), nil
+		},
+		PostFunc: func(ctx context.Context, path string, body interface{}) ([]byte, error) {
+			mu.Lock()
+			aborted = append(aborted, path)
+			mu.Unlock()
+			close(abortedCh)
+			return []byte("true"), nil
+		},
+	}
+
+	// 发送期间超出费用上限，中止会话后 send 返回
+	err := Send(context.Background(), mock, "ses_a", Limits{MaxCost: 1}, func() error {
+		chunks <- sseFrame(stepFinish("prt_1", "ses_a", 0.6, 1, 1))
+		chunks <- sseFrame(stepFinish("prt_2", "ses_a", 0.6, 1, 1))
+		select {
+		case <-abortedCh:
+		case <-time.After(5 * time.Second):
+			t.Error("Timed out waiting for abort")
+		}
+		return errors.New("aborted")
+	})
+	var abortErr *AbortError
+	if !errors.As(err, &abortErr) || abortErr.Action.Limit != LimitCost {
+		t.Fatalf("Send() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(aborted) != 1 || aborted[0] != "/session/ses_a/abort" {
+		t.Errorf("aborted = %v", aborted)
+	}
+
+	// 没有上限时不订阅事件流
+	mock.SSEStreamFunc = func(ctx context.Context, path string) (<-chan []byte, <-chan error, error) {
+		t.Error("Unexpected subscription without limits")
+		return nil, nil, errors.New("unexpected")
+	}
+	if err := Send(context.Background(), mock, "ses_a", Limits{}, func() error { return nil }); err != nil {
+		t.Errorf("Send() without limits = %v", err)
+	}
+}
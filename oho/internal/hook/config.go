@@ -0,0 +1,43 @@
+package hook
+
+import (
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// Config oho notify 的配置
+type Config struct {
+	Hooks       `yaml:",inline"`
+	MinDuration util.Duration `yaml:"min_duration"` // 运行时长短于该值的完成不通知
+}
+
+// LoadConfig 从 YAML 文件加载配置
+func LoadConfig(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, i18n.Errorf("读取配置文件失败：%w", err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig 解析并校验配置
+func ParseConfig(data []byte) (*Config, error) {
+	var c Config
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, i18n.Errorf("解析配置失败：%w", err)
+	}
+	if c.Empty() {
+		return nil, i18n.Errorf("配置至少需要 on_complete、on_error 或 on_permission 中的一个")
+	}
+	return &c, nil
+}
+
+// Skip 运行时长短于 min_duration 的完成事件不通知，出错和权限请求总是通知
+func (c *Config) Skip(event string, duration time.Duration) bool {
+	return event == EventComplete && duration < time.Duration(c.MinDuration)
+}
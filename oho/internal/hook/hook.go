@@ -0,0 +1,206 @@
+// Package hook 在会话完成、出错或请求权限时执行本地命令或调用 webhook
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// 触发钩子的事件
+const (
+	EventComplete   = "complete"
+	EventError      = "error"
+	EventPermission = "permission"
+)
+
+// timeout 单个钩子的超时
+var timeout = time.Minute
+
+// Output 命令钩子的输出，默认为标准错误，避免混入命令的 JSON 输出
+var Output io.Writer = os.Stderr
+
+// Commands 一个事件的钩子列表，YAML 中可以写成单个字符串或列表
+type Commands []string
+
+// UnmarshalYAML 解析字符串或字符串列表
+func (c *Commands) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*c = Commands{value.Value}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*c = list
+	return nil
+}
+
+// Hooks 各事件的钩子，以 http:// 或 https:// 开头的为 webhook，其余为本地命令
+type Hooks struct {
+	OnComplete   Commands `yaml:"on_complete"`
+	OnError      Commands `yaml:"on_error"`
+	OnPermission Commands `yaml:"on_permission"`
+}
+
+// AddFlags 注册 --on-complete、--on-error 和 --on-permission
+// 钩子在本机执行命令，标志带有 mcp: skip 注解，不作为 MCP 工具的参数
+func (h *Hooks) AddFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVar((*[]string)(&h.OnComplete), "on-complete", nil, "会话完成时执行的命令或 webhook URL（可重复）")
+	flags.StringArrayVar((*[]string)(&h.OnError), "on-error", nil, "会话出错时执行的命令或 webhook URL（可重复）")
+	flags.StringArrayVar((*[]string)(&h.OnPermission), "on-permission", nil, "会话请求权限时执行的命令或 webhook URL（可重复）")
+	for _, name := range []string{"on-complete", "on-error", "on-permission"} {
+		_ = flags.SetAnnotation(name, "mcp", []string{"skip"})
+	}
+}
+
+// Empty 没有设置任何钩子
+func (h Hooks) Empty() bool {
+	return len(h.OnComplete) == 0 && len(h.OnError) == 0 && len(h.OnPermission) == 0
+}
+
+// RequireReply 钩子只能在等待回复时执行，不等待回复的请求应使用 oho session wait 或 oho notify
+func (h Hooks) RequireReply(noReply bool) error {
+	if noReply && !h.Empty() {
+		return i18n.Errorf("--on-complete、--on-error 和 --on-permission 需要等待回复，不能与 --no-reply 同时使用（可以使用 oho session wait 或 oho notify）")
+	}
+	return nil
+}
+
+// For 返回事件对应的钩子
+func (h Hooks) For(event string) Commands {
+	switch event {
+	case EventComplete:
+		return h.OnComplete
+	case EventError:
+		return h.OnError
+	case EventPermission:
+		return h.OnPermission
+	}
+	return nil
+}
+
+// Fire 依次执行事件对应的所有钩子，返回所有失败合并后的错误
+func (h Hooks) Fire(ctx context.Context, p types.HookPayload) error {
+	if p.Time == 0 {
+		p.Time = time.Now().UnixMilli()
+	}
+	var errs []error
+	for _, hook := range h.For(p.Event) {
+		if err := Run(ctx, hook, p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run 执行一个钩子：URL 以 POST 发送 JSON，其他作为 shell 命令执行
+func Run(ctx context.Context, hook string, p types.HookPayload) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if IsURL(hook) {
+		return post(ctx, hook, data)
+	}
+	return command(ctx, hook, p, data)
+}
+
+// IsURL 钩子是否为 webhook
+func IsURL(hook string) bool {
+	return strings.HasPrefix(hook, "http://") || strings.HasPrefix(hook, "https://")
+}
+
+// post 将事件发送到 webhook，非 2xx 响应视为失败
+func post(ctx context.Context, url string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return i18n.Errorf("调用 webhook %s 失败：%w", url, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return i18n.Errorf("调用 webhook %s 失败：%w", url, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return i18n.Errorf("调用 webhook %s 失败：HTTP %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// command 通过 shell 执行命令，事件通过环境变量和标准输入（JSON）传入
+func command(ctx context.Context, line string, p types.HookPayload, data []byte) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", line)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", line)
+	}
+	cmd.Env = append(os.Environ(), Env(p)...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = Output
+	cmd.Stderr = Output
+	if err := cmd.Run(); err != nil {
+		return i18n.Errorf("执行钩子 %q 失败：%w", line, err)
+	}
+	return nil
+}
+
+// Env 传给命令钩子的环境变量
+func Env(p types.HookPayload) []string {
+	env := []string{
+		"OHO_EVENT=" + p.Event,
+		"OHO_SESSION_ID=" + p.SessionID,
+		"OHO_SESSION_TITLE=" + p.Title,
+		"OHO_SESSION_DIRECTORY=" + p.Directory,
+		"OHO_MESSAGE_ID=" + p.MessageID,
+		fmt.Sprintf("OHO_DURATION_MS=%d", p.Duration),
+		"OHO_ERROR=" + p.Error,
+	}
+	if p.Permission != nil {
+		env = append(env,
+			"OHO_PERMISSION_ID="+p.Permission.ID,
+			"OHO_PERMISSION_SESSION_ID="+p.Permission.SessionID,
+			"OHO_PERMISSION_TOOL="+p.Permission.Tool,
+			"OHO_PERMISSION_SUMMARY="+p.Permission.Summary,
+		)
+	}
+	return env
+}
+
+// Payload 会话事件的基本信息
+func Payload(event string, s types.Session) types.HookPayload {
+	return types.HookPayload{
+		Event:     event,
+		SessionID: s.ID,
+		Title:     s.Title,
+		Directory: s.Directory,
+	}
+}
+
+// Report 将执行失败的钩子输出到标准错误，钩子失败不影响命令的结果
+func Report(err error) {
+	if err != nil {
+		i18n.Fprintf(os.Stderr, "警告：执行钩子失败：%v\n", err)
+	}
+}
@@ -0,0 +1,167 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+func TestParseConfig(t *testing.T) {
+	c, err := ParseConfig([]byte("on_complete: echo done\non_error:\n  - https://example.com/a\n  - echo failed\nmin_duration: 2m\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.OnComplete) != 1 || len(c.OnError) != 2 || len(c.OnPermission) != 0 || time.Duration(c.MinDuration) != 2*time.Minute {
+		t.Errorf("Unexpected config: %+v", c)
+	}
+	if !c.Skip(EventComplete, time.Minute) || c.Skip(EventError, time.Minute) || c.Skip(EventComplete, 3*time.Minute) {
+		t.Error("Skip() should only skip short completions")
+	}
+
+	for _, data := range []string{"", "min_duration: 1m\n", "on_complete: [\n"} {
+		if _, err := ParseConfig([]byte(data)); err == nil {
+			t.Errorf("Expected error for %q", data)
+		}
+	}
+}
+
+func TestRequireReply(t *testing.T) {
+	if err := (Hooks{OnComplete: Commands{"true"}}).RequireReply(true); err == nil {
+		t.Error("Expected error for hooks with --no-reply")
+	}
+	if err := (Hooks{}).RequireReply(true); err != nil {
+		t.Errorf("RequireReply without hooks = %v", err)
+	}
+}
+
+func TestFire(t *testing.T) {
+	var got types.HookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &got)
+	}))
+	defer server.Close()
+
+	Output = io.Discard
+	out := filepath.Join(t.TempDir(), "env")
+	hooks := Hooks{
+		OnComplete: Commands{
+			fmt.Sprintf(`echo "$OHO_EVENT $OHO_SESSION_ID $OHO_SESSION_TITLE $OHO_DURATION_MS" > %s; cat >> %s`, out, out),
+			server.URL + "/hook",
+		},
+		OnError: Commands{"exit 3", server.URL + "/fail"},
+	}
+	p := Payload(EventComplete, types.Session{ID: "ses_a", Title: "Fix bug"})
+	p.Duration = 1500
+	if err := hooks.Fire(context.Background(), p); err != nil {
+		t.Fatalf("Fire() = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitN(string(data), "\n", 2)
+	if lines[0] != "complete ses_a Fix bug 1500" || !strings.Contains(lines[1], `"sessionId":"ses_a"`) {
+		t.Errorf("command output = %q", data)
+	}
+	if got.SessionID != "ses_a" || got.Event != EventComplete || got.Time == 0 {
+		t.Errorf("webhook payload = %+v", got)
+	}
+
+	// 所有钩子都会执行，失败合并返回
+	p.Event = EventError
+	err = hooks.Fire(context.Background(), p)
+	if err == nil || !strings.Contains(err.Error(), "exit 3") || !strings.Contains(err.Error(), "HTTP 500") {
+		t.Errorf("Fire() error = %v", err)
+	}
+	if err := hooks.Fire(context.Background(), Payload(EventPermission, types.Session{ID: "ses_a"})); err != nil {
+		t.Errorf("Fire() without hooks = %v", err)
+	}
+}
+
+// sseFrame 将事件编码为 SSE 数据
+func sseFrame(e types.Event) []byte {
+	data, _ := json.Marshal(map[string]interface{}{"type": e.Type, "properties": e.Properties})
+	return []byte(fmt.Sprintf("data: %s\n\n", data))
+}
+
+func TestWatch(t *testing.T) {
+	chunks := make(chan []byte, 4)
+	subscribed := 0
+	mock := &client.MockClient{
+		SSEStreamFunc: func(ctx context.Context, path string) (<-chan []byte, <-chan error, error) {
+			subscribed++
+			return chunks, make(chan error), nil
+		},
+	}
+	out := filepath.Join(t.TempDir(), "fired")
+	hooks := Hooks{
+		OnComplete:   Commands{"echo complete >> " + out},
+		OnError:      Commands{`echo "error $OHO_ERROR" >> ` + out},
+		OnPermission: Commands{`echo "permission $OHO_PERMISSION_TOOL" >> ` + out},
+	}
+	read := func() []string {
+		data, _ := os.ReadFile(out)
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+
+	// 子会话的权限请求触发 on-permission，会话出错时 Finish 触发 on-error
+	w, err := Watch(context.Background(), mock, types.Session{ID: "ses_a"}, hooks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks <- sseFrame(newEvent("session.created", map[string]interface{}{"info": map[string]string{"id": "ses_b", "parentID": "ses_a"}}))
+	chunks <- sseFrame(newEvent("session.error", map[string]interface{}{"sessionID": "ses_a", "error": map[string]interface{}{"name": "MessageAbortedError"}}))
+	chunks <- sseFrame(newEvent("permission.asked", map[string]interface{}{"id": "per_1", "sessionID": "ses_b", "permission": "bash"}))
+	deadline := time.Now().Add(5 * time.Second)
+	for len(read()) < 1 || read()[0] == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for permission hook")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := w.Finish("msg_1", nil); err != nil {
+		t.Fatalf("Finish() = %v", err)
+	}
+	fired := read()
+	if strings.Join(fired, ",") != "permission bash,error MessageAbortedError" {
+		t.Errorf("fired = %v", fired)
+	}
+
+	// 发送失败时触发 on-error
+	os.Remove(out)
+	w, _ = Watch(context.Background(), mock, types.Session{ID: "ses_c"}, hooks)
+	if err := w.Finish("", errors.New("API 错误 [500]")); err != nil {
+		t.Fatal(err)
+	}
+	w, _ = Watch(context.Background(), mock, types.Session{ID: "ses_c"}, hooks)
+	if err := w.Finish("msg_2", nil); err != nil {
+		t.Fatal(err)
+	}
+	if fired = read(); strings.Join(fired, ",") != "error API 错误 [500],complete" {
+		t.Errorf("fired = %v", fired)
+	}
+
+	// 没有钩子时不订阅事件流
+	subscribed = 0
+	w, _ = Watch(context.Background(), mock, types.Session{ID: "ses_d"}, Hooks{})
+	if err := w.Finish("", nil); err != nil || subscribed != 0 {
+		t.Errorf("Finish() = %v, subscribed = %d", err, subscribed)
+	}
+}
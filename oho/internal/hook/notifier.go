@@ -0,0 +1,186 @@
+package hook
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/permission"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// Notifier 根据事件流判断根会话的一次运行何时完成或出错，以及何时请求权限
+// 子会话的权限请求计入根会话，子会话的完成不单独通知
+type Notifier struct {
+	Only string // 只通知该根会话，为空时通知所有根会话
+
+	sessions map[string]types.Session
+	started  map[string]time.Time // 正在运行的根会话 -> 开始时间
+	errors   map[string]string    // 根会话本次运行的错误
+	asked    map[string]bool      // 已通知的权限请求
+}
+
+// NewNotifier 创建 Notifier
+func NewNotifier() *Notifier {
+	return &Notifier{
+		sessions: make(map[string]types.Session),
+		started:  make(map[string]time.Time),
+		errors:   make(map[string]string),
+		asked:    make(map[string]bool),
+	}
+}
+
+// AddSessions 记录会话信息和父子关系
+func (n *Notifier) AddSessions(sessions []types.Session) {
+	for _, s := range sessions {
+		n.sessions[s.ID] = s
+	}
+}
+
+// Start 开始跟踪会话所属根会话的一次运行，已在运行时不变
+func (n *Notifier) Start(id string, now time.Time) {
+	root := n.root(id)
+	if _, ok := n.started[root]; !ok {
+		n.started[root] = now
+	}
+}
+
+// Running 正在运行的根会话，按 ID 排序
+func (n *Notifier) Running() []string {
+	ids := make([]string, 0, len(n.started))
+	for id := range n.started {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Error 根会话本次运行中出现的错误
+func (n *Notifier) Error(id string) string {
+	return n.errors[id]
+}
+
+// root 沿父会话找到根会话
+func (n *Notifier) root(id string) string {
+	seen := map[string]bool{}
+	for !seen[id] {
+		seen[id] = true
+		parent := n.sessions[id].ParentID
+		if parent == "" {
+			break
+		}
+		id = parent
+	}
+	return id
+}
+
+// session 返回会话信息，未知的会话只有 ID
+func (n *Notifier) session(id string) types.Session {
+	if s, ok := n.sessions[id]; ok {
+		return s
+	}
+	return types.Session{ID: id}
+}
+
+func (n *Notifier) wanted(root string) bool {
+	return n.Only == "" || n.Only == root
+}
+
+// eventProps 会话相关事件的数据
+type eventProps struct {
+	SessionID string         `json:"sessionID"`
+	Info      *types.Session `json:"info"`
+	Status    *struct {
+		Type string `json:"type"`
+	} `json:"status"`
+	Error *struct {
+		Name string `json:"name"`
+		Data struct {
+			Message string `json:"message"`
+		} `json:"data"`
+	} `json:"error"`
+}
+
+// Handle 处理一个事件，返回需要触发的钩子事件
+func (n *Notifier) Handle(e types.Event, now time.Time) []types.HookPayload {
+	var props eventProps
+	if len(e.Properties) > 0 {
+		_ = json.Unmarshal(e.Properties, &props)
+	}
+
+	switch e.Type {
+	case event.TypeSessionCreated, event.TypeSessionUpdated:
+		if props.Info != nil {
+			n.AddSessions([]types.Session{*props.Info})
+		}
+	case event.TypeSessionStatus:
+		if props.Status == nil || props.SessionID == "" {
+			return nil
+		}
+		if props.Status.Type == "idle" {
+			return n.finish(props.SessionID, now)
+		}
+		n.Start(props.SessionID, now)
+	case event.TypeSessionIdle:
+		return n.finish(props.SessionID, now)
+	case event.TypeSessionError:
+		// 子会话的错误由父会话处理，只记录根会话的错误
+		if props.SessionID == "" || props.Error == nil || n.root(props.SessionID) != props.SessionID {
+			return nil
+		}
+		msg := props.Error.Data.Message
+		if msg == "" {
+			msg = props.Error.Name
+		}
+		if msg == "" {
+			msg = "session error"
+		}
+		n.errors[props.SessionID] = msg
+	case event.TypePermissionAsked, event.TypePermissionUpdated:
+		if r, ok := permission.FromEvent(e); ok {
+			if p, ok := n.Permission(r, now); ok {
+				return []types.HookPayload{p}
+			}
+		}
+	}
+	return nil
+}
+
+// Permission 返回权限请求的钩子事件，每个请求只返回一次
+func (n *Notifier) Permission(r permission.Request, now time.Time) (types.HookPayload, bool) {
+	root := n.root(r.SessionID)
+	if n.asked[r.ID] || !n.wanted(root) {
+		return types.HookPayload{}, false
+	}
+	n.asked[r.ID] = true
+	p := Payload(EventPermission, n.session(root))
+	p.Time = now.UnixMilli()
+	p.Permission = &types.HookPermission{ID: r.ID, SessionID: r.SessionID, Tool: r.Tool, Summary: r.Summary()}
+	return p, true
+}
+
+// finish 根会话空闲时结束运行，返回完成或出错事件
+func (n *Notifier) finish(id string, now time.Time) []types.HookPayload {
+	if n.root(id) != id {
+		return nil
+	}
+	started, ok := n.started[id]
+	if !ok {
+		return nil
+	}
+	msg := n.errors[id]
+	delete(n.started, id)
+	delete(n.errors, id)
+	if !n.wanted(id) {
+		return nil
+	}
+
+	p := Payload(EventComplete, n.session(id))
+	p.Time = now.UnixMilli()
+	p.Duration = now.Sub(started).Milliseconds()
+	if msg != "" {
+		p.Event, p.Error = EventError, msg
+	}
+	return []types.HookPayload{p}
+}
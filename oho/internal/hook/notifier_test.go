@@ -0,0 +1,72 @@
+package hook
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/anomalyco/oho/internal/types"
+)
+
+func newEvent(typ string, props map[string]interface{}) types.Event {
+	data, _ := json.Marshal(props)
+	return types.Event{Type: typ, Properties: data}
+}
+
+func statusEvent(sessionID, status string) types.Event {
+	return newEvent("session.status", map[string]interface{}{"sessionID": sessionID, "status": map[string]string{"type": status}})
+}
+
+func TestNotifier(t *testing.T) {
+	start := time.Unix(1000, 0)
+	n := NewNotifier()
+	n.AddSessions([]types.Session{{ID: "ses_root", Title: "Root", Directory: "/work"}, {ID: "ses_child", ParentID: "ses_root"}})
+
+	n.Handle(statusEvent("ses_root", "busy"), start)
+	n.Handle(statusEvent("ses_child", "busy"), start.Add(time.Second))
+	if running := n.Running(); len(running) != 1 || running[0] != "ses_root" {
+		t.Fatalf("Running() = %v", running)
+	}
+
+	// 子会话的权限请求按根会话通知，每个请求只通知一次
+	asked := newEvent("permission.asked", map[string]interface{}{"id": "per_1", "sessionID": "ses_child", "permission": "bash", "metadata": map[string]string{"command": "go test"}})
+	payloads := n.Handle(asked, start.Add(2*time.Second))
+	if len(payloads) != 1 || payloads[0].Event != EventPermission || payloads[0].SessionID != "ses_root" || payloads[0].Permission.SessionID != "ses_child" || payloads[0].Permission.Tool != "bash" {
+		t.Fatalf("permission payloads = %+v", payloads)
+	}
+	if payloads := n.Handle(asked, start.Add(3*time.Second)); len(payloads) != 0 {
+		t.Errorf("Expected permission to be notified once, got %+v", payloads)
+	}
+
+	// 子会话空闲不通知，根会话空闲时通知完成
+	if payloads := n.Handle(statusEvent("ses_child", "idle"), start.Add(time.Minute)); len(payloads) != 0 {
+		t.Errorf("Unexpected payloads for child idle: %+v", payloads)
+	}
+	payloads = n.Handle(statusEvent("ses_root", "idle"), start.Add(2*time.Minute))
+	if len(payloads) != 1 || payloads[0].Event != EventComplete || payloads[0].Duration != 120000 || payloads[0].Title != "Root" || payloads[0].Directory != "/work" {
+		t.Fatalf("complete payloads = %+v", payloads)
+	}
+	if payloads := n.Handle(newEvent("session.idle", map[string]interface{}{"sessionID": "ses_root"}), start.Add(2*time.Minute)); len(payloads) != 0 {
+		t.Errorf("Expected run to be finished once, got %+v", payloads)
+	}
+
+	// 根会话出错后空闲时通知出错，子会话的错误不计入
+	n.Handle(statusEvent("ses_root", "busy"), start)
+	n.Handle(newEvent("session.error", map[string]interface{}{"sessionID": "ses_child", "error": map[string]interface{}{"name": "ChildError"}}), start)
+	n.Handle(newEvent("session.error", map[string]interface{}{"sessionID": "ses_root", "error": map[string]interface{}{"name": "APIError", "data": map[string]string{"message": "rate limited"}}}), start)
+	if n.Error("ses_root") != "rate limited" {
+		t.Errorf("Error() = %q", n.Error("ses_root"))
+	}
+	payloads = n.Handle(newEvent("session.idle", map[string]interface{}{"sessionID": "ses_root"}), start.Add(time.Second))
+	if len(payloads) != 1 || payloads[0].Event != EventError || payloads[0].Error != "rate limited" {
+		t.Fatalf("error payloads = %+v", payloads)
+	}
+
+	// Only 时只通知指定的根会话
+	n = NewNotifier()
+	n.Only = "ses_a"
+	n.Handle(statusEvent("ses_b", "busy"), start)
+	if payloads := n.Handle(statusEvent("ses_b", "idle"), start); len(payloads) != 0 {
+		t.Errorf("Unexpected payloads for other session: %+v", payloads)
+	}
+}
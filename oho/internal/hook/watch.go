@@ -0,0 +1,109 @@
+package hook
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// retryDelay 事件流断开后的重连间隔
+const retryDelay = time.Second
+
+// Watcher 在等待回复期间监听会话：会话及其子会话请求权限时触发 on-permission，
+// 并记录会话出现的错误；Finish 时触发 on-complete 或 on-error
+type Watcher struct {
+	hooks   Hooks
+	session types.Session
+	started time.Time
+	parent  context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	sessionErr string
+	errs       []error
+}
+
+// Watch 开始监听会话；订阅事件流失败时，只有设置了 on-permission 才返回错误，否则只根据 Finish 的结果触发钩子
+func Watch(ctx context.Context, c client.ClientInterface, s types.Session, hooks Hooks) (*Watcher, error) {
+	w := &Watcher{hooks: hooks, session: s, started: time.Now(), parent: ctx, done: make(chan struct{})}
+	if hooks.Empty() {
+		close(w.done)
+		return w, nil
+	}
+
+	// 钩子使用外层的 ctx，Finish 停止监听时不会中断正在执行的钩子
+	ctx, w.cancel = context.WithCancel(ctx)
+	events, _, err := event.Subscribe(ctx, c, event.DefaultPath)
+	if err != nil {
+		w.cancel()
+		if len(hooks.OnPermission) > 0 {
+			return nil, i18n.Errorf("订阅事件流失败，无法执行 --on-permission：%w", err)
+		}
+		close(w.done)
+		return w, nil
+	}
+
+	n := NewNotifier()
+	n.Only = s.ID
+	n.AddSessions([]types.Session{s})
+	n.Start(s.ID, w.started)
+	go w.loop(ctx, c, n, events)
+	return w, nil
+}
+
+func (w *Watcher) loop(ctx context.Context, c client.ClientInterface, n *Notifier, events <-chan types.Event) {
+	defer close(w.done)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				if ctx.Err() != nil {
+					return
+				}
+				events = event.Watch(ctx, c, event.DefaultPath, retryDelay, nil)
+				continue
+			}
+			for _, p := range n.Handle(e, time.Now()) {
+				switch p.Event {
+				case EventPermission:
+					w.errs = append(w.errs, w.hooks.Fire(w.parent, p))
+				case EventError:
+					w.sessionErr = p.Error
+				}
+			}
+			if msg := n.Error(w.session.ID); msg != "" {
+				w.sessionErr = msg
+			}
+		}
+	}
+}
+
+// Finish 停止监听，根据 err 和会话中出现的错误触发 on-error 或 on-complete，返回执行失败的钩子
+func (w *Watcher) Finish(messageID string, err error) error {
+	if w.hooks.Empty() {
+		return nil
+	}
+	if w.cancel != nil {
+		w.cancel()
+	}
+	<-w.done
+
+	p := Payload(EventComplete, w.session)
+	p.MessageID = messageID
+	p.Duration = time.Since(w.started).Milliseconds()
+	switch {
+	case err != nil:
+		p.Event, p.Error = EventError, err.Error()
+	case w.sessionErr != "":
+		p.Event, p.Error = EventError, w.sessionErr
+	}
+	w.errs = append(w.errs, w.hooks.Fire(w.parent, p))
+	return errors.Join(w.errs...)
+}
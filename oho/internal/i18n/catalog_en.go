@@ -0,0 +1,954 @@
+package i18n
+
+// en 英文目录，键为源码中的原文（大多是中文）
+var en = map[string]string{
+	"\n%d 个成功，%d 个失败\n":     "\n%d succeeded, %d failed\n",
+	"\n%d 个文件变更，+%d -%d\n":  "\n%d file(s) changed, +%d -%d\n",
+	"\n内容:\n":               "\nContent:\n",
+	"\n服务器命令:\n":            "\nServer commands:\n",
+	"\n请在浏览器中打开授权 URL 完成认证": "\nOpen the authorization URL in a browser to complete authentication",
+	"\n部分 (%d 个):\n":        "\nParts (%d):\n",
+	"\n默认模型:":               "\nDefault models:",
+	"   └─ 匹配位置：%d-%d\n":    "   └─ Match position: %d-%d\n",
+	"   位置：%s:%d:%d\n":      "   Location: %s:%d:%d\n",
+	"   容器：%s\n":            "   Container: %s\n",
+	"   工具：%s\n":            "   Tool: %s\n",
+	"   描述：%s\n":            "   Description: %s\n",
+	"   用法：%s\n":            "   Usage: %s\n",
+	"   错误：%s\n":            "   Error: %s\n",
+	"  %d. 类型：%s\n":         "  %d. Type: %s\n",
+	"  oho session wait ses_xxx\n  oho session wait @last --on-complete 'notify-send \"oho\" \"$OHO_SESSION_TITLE 已完成\"'\n  oho session wait ses_xxx --timeout 1h --on-error https://hooks.example.com/oho": "  oho session wait ses_xxx\n  oho session wait @last --on-complete 'notify-send \"oho\" \"$OHO_SESSION_TITLE finished\"'\n  oho session wait ses_xxx --timeout 1h --on-error https://hooks.example.com/oho",
+	"  …还有 %d 个":                    "  … and %d more",
+	"  └─ 部分类型：%s\n":                "  └─ Part type: %s\n",
+	"  主题：%s\n":                     "  Theme: %s\n",
+	"  会话：%s\n":                     "  Session: %s\n",
+	"  分支 %s 有未合并的提交，已保留\n":         "  Branch %s has unmerged commits and was kept\n",
+	"  授权 URL: %s\n":                "  Authorization URL: %s\n",
+	"  新 ID: %s\n":                  "  New ID: %s\n",
+	"  时间：%d\n":                     "  Time: %d\n",
+	"  最大 Token：%d\n":               "  Max tokens: %d\n",
+	"  未应用的补丁段已写入 %s\n":             "  Rejected hunks written to %s\n",
+	"  标题：%s\n":                     "  Title: %s\n",
+	"  模型：%s\n":                     "  Model: %s\n",
+	"  消息 ID: %s\n":                 "  Message ID: %s\n",
+	"  温度：%.2f\n":                   "  Temperature: %.2f\n",
+	"  自动批准：%s\n":                   "  Auto-approve: %s\n",
+	"  角色：%s\n":                     "  Role: %s\n",
+	"  语言：%s\n":                     "  Language: %s\n",
+	"  默认模型：%s\n":                   "  Default model: %s\n",
+	" (必需)":                         " (required)",
+	"%d 个会话操作失败":                    "%d session operations failed",
+	"%d 个文件存在冲突":                    "%d file(s) have conflicts",
+	"%d 个补丁段无法应用":                   "%d hunk(s) could not be applied",
+	"%s %s (状态：%s)\n":               "%s %s (status: %s)\n",
+	"%s %s (端口：%d, 状态：%s)\n":        "%s %s (port: %d, status: %s)\n",
+	"%s (dry-run) %s %s（沉默 %s）\n":   "%s (dry-run) %s %s (silent for %s)\n",
+	"%s ? %s 请求权限：[%s] %s\n":        "%s ? %s asks for permission: [%s] %s\n",
+	"%s ⚠ 会话 %s 已沉默 %s：%s\n":        "%s ⚠ session %s has been silent for %s: %s\n",
+	"%s ✓ %s 已完成（%s）\n":             "%s ✓ %s completed (%s)\n",
+	"%s ✓ 已中止 %s 并重发消息 %s（沉默 %s）\n": "%s ✓ aborted %s and re-sent message %s (silent for %s)\n",
+	"%s ✓ 已中止 %s（沉默 %s）\n":          "%s ✓ aborted %s (silent for %s)\n",
+	"%s ✓ 已中止 %s：%s\n":              "%s ✓ aborted %s: %s\n",
+	"%s ✗ %s %s（沉默 %s）：%s\n":        "%s ✗ %s %s (silent for %s): %s\n",
+	"%s ✗ %s 出错：%s\n":               "%s ✗ %s failed: %s\n",
+	"%s ✗ %s：%s（%s）\n":              "%s ✗ %s: %s (%s)\n",
+	"%s 匹配多个会话，请使用更长的 ID 前缀或更完整的标题：\n%s": "%s matches multiple sessions, use a longer ID prefix or a more complete title:\n%s",
+	"%s 更新，正在监听事件流（Ctrl+C 退出）\n":         "Updated at %s, watching the event stream (Ctrl+C to exit)\n",
+	"%s: %s (就绪：%v, 工作中：%v)\n":           "%s: %s (ready: %v, busy: %v)\n",
+	"%v；回滚失败：%w":                         "%v; rollback failed: %w",
+	"--max-cost、--max-tokens 和 --max-duration 需要等待回复，不能与 --no-reply 同时使用（可以使用 oho guard）":                       "--max-cost, --max-tokens and --max-duration wait for the reply and cannot be used with --no-reply (use oho guard instead)",
+	"--on-complete、--on-error 和 --on-permission 需要等待回复，不能与 --no-reply 同时使用（可以使用 oho session wait 或 oho notify）": "--on-complete, --on-error and --on-permission need the reply to be awaited and cannot be combined with --no-reply (use oho session wait or oho notify)",
+	"--where 不能与会话 ID 同时使用": "--where cannot be combined with session IDs",
+	"--where 至少需要一个条件":      "--where requires at least one condition",
+	"AGENTS.md 创建成功":        "AGENTS.md created successfully",
+	"API 错误 [%d]: %s":       "API error [%d]: %s",
+	`Create a new session in the current directory and send a message to it in one step.
+
+This command combines session creation and message sending into a single operation.
+By default, it uses the current working directory as the session directory and
+generates an automatic title.
+
+Examples:
+  oho add "帮我分析这个项目"
+  oho add "修复登录 bug" --title "Bug 修复"
+  oho add "测试功能" --no-reply
+  oho add "分析日志" --file /var/log/app.log`: `Create a new session in the current directory and send a message to it in one step.
+
+This command combines session creation and message sending into a single operation.
+By default, it uses the current working directory as the session directory and
+generates an automatic title.
+
+Examples:
+  oho add "Analyze this project"
+  oho add "Fix the login bug" --title "Bug fix"
+  oho add "Test the feature" --no-reply
+  oho add "Analyze the logs" --file /var/log/app.log`,
+	"Git 仓库：%v\n": "Git repository: %v\n",
+	"HTTP 传输的 Bearer Token (默认读取环境变量 OHO_MCP_TOKEN)": "Bearer token for the HTTP transport (defaults to the OHO_MCP_TOKEN environment variable)",
+	"HTTP 传输的监听地址":              "Listen address for the HTTP transport",
+	"HTTP 传输的端点路径":              "Endpoint path for the HTTP transport",
+	"ID:     %s\n":              "ID:        %s\n",
+	"LSP 服务器状态":                 "LSP server status",
+	"LSP 服务器状态:":                "LSP server status:",
+	"MCP 服务器 %s 已添加\n":          "MCP server %s added\n",
+	"MCP 服务器已启动：http://%s%s\n":  "MCP server started: http://%s%s\n",
+	"MCP 服务器状态:":                "MCP server status:",
+	"MCP 服务器管理":                 "MCP server management",
+	"MCP 服务器配置 (JSON 格式)":       "MCP server configuration (JSON)",
+	"OAuth 授权信息:":               "OAuth authorization:",
+	"OpenCode CLI - HTTP 客户端工具": "OpenCode CLI - HTTP client tool",
+	"SSE 错误 [%d]: %s":           "SSE error [%d]: %s",
+	"Shell 命令":                  "Shell command",
+	"Shell 命令已执行:\n":            "Shell command executed:\n",
+	"TUI 控制命令":                  "TUI control commands",
+	"VCS 类型：%s\n":               "VCS type: %s\n",
+	"[config] 尝试过的路径:\n":        "[config] Paths tried:\n",
+	"[config] 成功读取配置文件: %s\n":   "[config] Loaded config file: %s\n",
+	"[config] 配置文件不存在，请创建或设置环境变量\n":  "[config] Config file not found, create one or set environment variables\n",
+	"action 无效：%q（可选 %s）":            "invalid action: %q (one of %s)",
+	"default 只能是 escalate 或 deny：%s": "default must be escalate or deny: %s",
+	`oho 是 OpenCode Server 的命令行客户端工具。
+	
+它提供了对 OpenCode Server API 的完整访问，允许你通过命令行管理会话、消息、配置等。
+
+示例:
+  oho session create              # 创建新会话
+  oho message add -s session123   # 添加消息到会话
+  oho session list                # 列出所有会话
+  oho config get                  # 获取配置
+  oho provider list               # 列出所有提供商`: `oho is the command line client for OpenCode Server.
+
+It provides full access to the OpenCode Server API, letting you manage sessions, messages, configuration and more from the command line.
+
+Examples:
+  oho session create              # Create a new session
+  oho message add -s session123   # Add a message to a session
+  oho session list                # List all sessions
+  oho config get                  # Get the configuration
+  oho provider list               # List all providers`,
+	"keep_last 不能为负数：%d":           "keep_last cannot be negative: %d",
+	"max_resends 不能为负数：%d":         "max_resends cannot be negative: %d",
+	"silence 必须大于 0":               "silence must be greater than 0",
+	"token 数 %d 超过上限 %d":           "tokens %d exceed the limit of %d",
+	"token 数上限（包括缓存读写），超出时中止会话":    "Token limit, including cache reads and writes; the session is aborted when it is exceeded",
+	"⚠ 需要人工处理：%s [%s] %s（会话 %s）\n": "⚠ Needs manual review: %s [%s] %s (session %s)\n",
+	"✗ 响应 %s 失败：%v\n":              "✗ Failed to respond to %s: %v\n",
+	"不健康":                          "unhealthy",
+	"不支持的传输方式：%s (可选 stdio/http)":  "unsupported transport: %s (choose stdio/http)",
+	"不支持的分组方式：%s（可选 %s）":           "unsupported group: %s (choose from %s)",
+	"不支持的输出格式：%s（可选 %s）":           "unsupported output format: %s (choose %s)",
+	"不等待响应":                        "Don't wait for a response",
+	"与会话进行交互式对话":                   "Chat with a session interactively",
+	"中止会话 %s 失败：%v":                "failed to abort session %s: %v",
+	"中止正在运行的会话":                    "Abort a running session",
+	"中止正在运行的会话。\n\n指定多个 ID、使用 - 从 stdin 读取 ID 或使用 --where 按条件选择会话时进入批量模式。": "Abort running sessions.\n\nPassing several IDs, - to read IDs from stdin, or --where conditions switches to bulk mode.",
+	"为常用会话设置别名，之后可以在任何接受会话 ID 的位置使用 @<别名>。\n\n除别名外，会话 ID 还可以写作：\n  ID 前缀     至少 4 个字符，可省略 ses_，如 34dbffe\n  标题        不区分大小写，完全相同优先，其次是包含（至少 4 个字符）\n  @last       最近更新的顶层会话\n  @current    当前目录中最近更新的顶层会话\n匹配多个会话时列出候选并报错。\ndelete、abort、archive、unshare、revert 和 unrevert 只接受完整 ID、ID 前缀和别名。": "Give frequently used sessions an alias, then use @<alias> anywhere a session ID is accepted.\n\nBesides aliases, a session ID can be written as:\n  ID prefix   at least 4 characters, ses_ may be omitted, e.g. 34dbffe\n  title       case-insensitive; an exact title wins over a partial match (at least 4 characters)\n  @last       the most recently updated top-level session\n  @current    the most recently updated top-level session in the current directory\nWhen several sessions match, the candidates are listed and the command fails.\ndelete, abort, archive, unshare, revert and unrevert only accept full IDs, ID prefixes and aliases.",
+	"主目录：%s\n":                        "Home directory: %s\n",
+	"主题名称":                            "Theme name",
+	"主题选择器已打开":                        "Theme picker opened",
+	"事件流已断开":                          "event stream disconnected",
+	"事件流断开后的重连间隔":                     "Reconnect interval after the event stream drops",
+	"事件流断开：%v，%s 后重连\n":               "Event stream disconnected: %v, reconnecting in %s\n",
+	"从 stdin 读取会话 ID 时请使用 --yes 确认操作": "use --yes to confirm when reading session IDs from stdin",
+	"从服务器获取会话列表，更新用户缓存目录中的本地索引。\n\nsession list、get 和 search 在索引有效期内（默认 1 分钟，可用 OHO_CACHE_TTL 调整）\n直接使用索引，无法连接服务器时使用最后一次同步的快照。\n使用 --watch 时持续订阅事件流，增量更新索引并保持索引有效。": "Fetch the session list from the server and update the local index in the user cache directory.\n\nsession list, get and search use the index directly while it is fresh (1 minute by default,\nadjustable with OHO_CACHE_TTL), and fall back to the last synced snapshot when the server is unreachable.\nWith --watch, keep subscribing to the event stream to update the index incrementally and keep it fresh.",
+	"代理":          "Agent",
+	"代理 ID":       "Agent ID",
+	"代理 ID (必需)":  "Agent ID (required)",
+	"代理命令":        "Agent commands",
+	"代理：   %s\n":  "Agent:     %s\n",
+	"代理：%s\n":     "Agent: %s\n",
+	"以 JSON 格式输出": "Output in JSON format",
+	`以 MCP 协议启动服务器，允许外部 MCP 客户端调用 OpenCode API
+
+传输方式:
+  stdio  通过 stdin/stdout 交换换行分隔的 JSON-RPC 消息（默认，适用于本地客户端）
+  http   MCP Streamable HTTP 传输，POST /mcp 发送请求，支持 SSE 响应流和会话 ID
+
+示例:
+  oho mcpserver
+  oho mcpserver --transport http --listen :8765 --token secret
+  oho mcpserver --read-only
+  oho mcpserver --allow 'session_*' --deny session_delete`: `Start a server speaking the MCP protocol so external MCP clients can call the OpenCode API
+
+Transports:
+  stdio  Exchange newline-delimited JSON-RPC messages over stdin/stdout (default, for local clients)
+  http   MCP Streamable HTTP transport: POST /mcp to send requests, with SSE response streams and session IDs
+
+Examples:
+  oho mcpserver
+  oho mcpserver --transport http --listen :8765 --token secret
+  oho mcpserver --read-only
+  oho mcpserver --allow 'session_*' --deny session_delete`,
+	"以下 %d 个会话将被处理（--dry-run，未执行）:\n":      "%d sessions would be affected (--dry-run, nothing done):\n",
+	"以下文件与本地内容冲突：\n  %s":                   "the following files conflict with local content:\n  %s",
+	"以下文件有未提交的改动，请先提交或撤销：\n  %s":           "the following files have uncommitted changes; commit or discard them first:\n  %s",
+	"以树形显示会话及其子会话":                         "Show sessions and their children as a tree",
+	"会话":                                   "SESSION",
+	"会话 %s 出错：%s":                          "session %s failed: %s",
+	"会话 %s 在 %s 内没有停止，未重发":                 "session %s did not stop within %s; not re-sent",
+	"会话 %s 已中止\n":                          "Session %s aborted\n",
+	"会话 %s 已删除\n":                          "Session %s deleted\n",
+	"会话 %s 已归档\n":                          "Session %s archived\n",
+	"会话 %s 已结束（等待 %s）\n":                   "Session %s finished (waited %s)\n",
+	"会话 %s 没有可以重发的用户消息":                    "session %s has no user message to re-send",
+	"会话 %s 没有文件变更":                         "session %s has no file changes",
+	"会话 %s：%s\n":                           "Session %s: %s\n",
+	"会话 ID":                                "Session ID",
+	"会话不存在：%s":                             "session not found: %s",
+	"会话仍在运行":                               "session is still running",
+	"会话出错时执行的命令或 webhook URL（可重复）":         "Command or webhook URL to run when the session fails (repeatable)",
+	"会话分叉成功:\n":                            "Session forked:\n",
+	"会话创建成功:\n":                            "Session created:\n",
+	"会话卡住时的动作 (warn|abort|resend)":         "Action when a session is stuck (warn|abort|resend)",
+	"会话变更与分支 %s 的内容相同，没有可提交的改动":            "the session changes match branch %s; nothing to commit",
+	"会话完成、出错或请求权限时执行钩子":                    "Run hooks when sessions complete, fail or ask for permissions",
+	"会话完成时执行的命令或 webhook URL（可重复）":         "Command or webhook URL to run when the session completes (repeatable)",
+	"会话已中止\n":                              "Session aborted\n",
+	"会话已分享\n":                              "Session shared\n",
+	"会话已取消分享\n":                            "Session unshared\n",
+	"会话总结完成":                               "Session summarized",
+	"会话数":                                  "Sessions",
+	"会话标题":                                 "Session title",
+	"会话标题已更新为：%s\n":                        "Session title updated to: %s\n",
+	"会话管理命令":                               "Session management commands",
+	"会话请求权限时执行的命令或 webhook URL（可重复）":       "Command or webhook URL to run when the session asks for a permission (repeatable)",
+	"会话选择器已打开":                             "Session picker opened",
+	"会话：   %s\n":                           "Session:   %s\n",
+	"传输方式 (stdio/http)":                    "Transport (stdio/http)",
+	"位置参数":                                 "Positional arguments",
+	"位置参数 %s":                              "Positional argument %s",
+	"使用 --output 或 --json 时请使用 --yes 确认操作": "use --yes to confirm when using --output or --json",
+	"使用 OAuth 授权提供商":                       "Authorize a provider with OAuth",
+	"保存工作树记录失败：%w":                         "failed to save worktree records: %w",
+	"保留策略文件 (YAML)":                        "Retention policy file (YAML)",
+	"健康":                                   "healthy",
+	"允许的浏览器 Origin（可多次使用，本机 Origin 总是允许）": "Allowed browser origins (repeatable; local origins are always allowed)",
+	"全局命令": "Global commands",
+	"全局操作，包括健康检查和事件流":     "Global operations, including health checks and the event stream",
+	"共 %d 个代理:\n\n":       "%d agents:\n\n",
+	"共 %d 个会话:\n\n":       "%d sessions:\n\n",
+	"共 %d 个会话，%d 个正在运行\n": "%d sessions, %d running\n",
+	"共 %d 个命令:\n\n":       "%d commands:\n\n",
+	"共 %d 个工具:\n\n":       "%d tools:\n\n",
+	"共 %d 个已跟踪文件:\n\n":    "%d tracked files:\n\n",
+	"共 %d 个项目:\n\n":       "%d projects:\n\n",
+	"共 %d 条结果\n":          "%d results\n",
+	"内容":                  "CONTENT",
+	"写入别名文件失败：%w":         "failed to write alias file: %w",
+	"写入报告失败：%w":           "failed to write report: %w",
+	"写入本地索引失败：%v\n":       "Failed to write local index: %v\n",
+	"写入本地索引失败：%w":         "failed to write local index: %w",
+	"冲突：%s (%s)\n":        "Conflict: %s (%s)\n",
+	"分享会话":                "Share a session",
+	"分叉当前会话并切换到新会话":       "Fork the current session and switch to the fork",
+	"分支":                             "BRANCH",
+	"分支 %s 已存在":                      "branch %s already exists",
+	"分支：%s\n":                        "Branch: %s\n",
+	"分析应用并创建 AGENTS.md":              "Analyze the app and create AGENTS.md",
+	"分组方式 (model|agent|project|day)": "Group by (model|agent|project|day)",
+	"分页偏移量":                          "Pagination offset",
+	"列出 MCP 服务器状态":                   "List MCP server status",
+	"列出任务工作树":                        "List task worktrees",
+	"列出会话中的消息":                       "List messages in a session",
+	"列出会话别名":                         "List session aliases",
+	"列出和管理 AI 代理":                    "List and manage AI agents",
+	"列出和管理实验性工具":                     "List and manage experimental tools",
+	"列出和管理斜杠命令":                      "List and manage slash commands",
+	"列出待处理的权限请求":                     "List pending permission requests",
+	"列出所有 OpenCode 会话":               "List all OpenCode sessions",
+	"列出所有代理":                         "List all agents",
+	"列出所有会话":                         "List all sessions",
+	"列出所有可用的 AI 提供商":                 "List all available AI providers",
+	"列出所有命令":                         "List all commands",
+	"列出所有工具 ID":                      "List all tool IDs",
+	"列出所有提供商":                        "List all providers",
+	"列出所有项目":                         "List all projects",
+	"列出指定会话的所有消息":                    "List all messages in a session",
+	"列出指定模型的工具":                      "List tools for a model",
+	"列出指定目录的文件":                      "List files in a directory",
+	"列出提供商和默认模型":                     "List providers and default models",
+	"列出文件和目录":                        "List files and directories",
+	"创建一个新的 OpenCode 会话，可选择指定父会话和标题": "Create a new OpenCode session, optionally with a parent session and title",
+	"创建会话失败：%w":        "failed to create session: %w",
+	"创建工作树失败：%w":       "failed to create worktree: %w",
+	"创建新会话":            "Create a new session",
+	"创建新的 OpenCode 会话": "Create a new OpenCode session",
+	"创建请求失败：%w":        "failed to create request: %w",
+	"删除任务工作树和对应的分支，并从记录中移除。\n\n默认跳过有未提交改动的工作树和仍在运行的会话，无法获取会话状态时拒绝清理；\n分支有未合并的提交时只删除工作树，保留分支。\n使用 --merge 时先提交工作树中的改动，再将分支合并到创建工作树时的分支，合并失败时保留工作树。\n主仓库必须在该分支上且没有未提交的改动，否则拒绝合并。": "Remove task worktrees and their branches, and drop them from the records.\n\nWorktrees with uncommitted changes and sessions that are still running are skipped by default;\nnothing is cleaned if the session status cannot be fetched.\nIf a branch has unmerged commits, only the worktree is removed and the branch is kept.\nWith --merge, changes in the worktree are committed first and the branch is merged into the\nbranch the worktree was created from; the worktree is kept if the merge fails.\nThe main repository must be on that branch with no uncommitted changes, or the merge is refused.",
+	"删除会话": "Delete a session",
+	"删除会话。\n\n指定多个 ID、使用 - 从 stdin 读取 ID 或使用 --where 按条件选择会话时进入批量模式：\n先列出会话并确认，然后并发执行，最后输出每个会话的结果。": "Delete sessions.\n\nPassing several IDs, - to read IDs from stdin, or --where conditions switches to bulk mode:\nthe sessions are listed and confirmed, processed in parallel, and a result is reported for each one.",
+	"删除会话别名": "Remove session aliases",
+	"删除指定会话": "Delete a session",
+	"删除有未提交改动或仍在运行的工作树，并强制删除未合并的分支": "Remove worktrees with uncommitted changes or running sessions, and force-delete unmerged branches",
+	"删除本地索引和消息快照":         "Delete the local index and message snapshots",
+	"删除本地索引失败：%w":         "failed to delete local index: %w",
+	"别名":                  "Alias",
+	"别名 %s 是内置引用，请使用其他名称": "alias %s is a built-in reference, choose another name",
+	"别名不存在：%s":            "alias not found: %s",
+	"刷新失败：%v\n":           "Refresh failed: %v\n",
+	"动作":                  "Action",
+	"区分大小写":               "Match case",
+	"发现长时间没有进展的会话并告警、中止或重发":             "Find sessions that have stopped making progress and warn, abort or re-send",
+	"发送消息到会话并等待 AI 响应":                  "Send a message to a session and wait for the AI response",
+	"发送消息并等待响应":                         "Send a message and wait for the response",
+	"取消分享会话":                            "Unshare a session",
+	"只列出将要操作的会话":                        "Only list the sessions that would be affected",
+	"只处理指定会话的请求":                        "Only handle requests from this session",
+	"只报告超出上限的会话，不中止":                    "Only report sessions over a limit, do not abort them",
+	"只搜索指定目录的会话（支持模糊查询）":                "Only search sessions in the given directory (fuzzy)",
+	"只搜索指定项目的会话（支持模糊查询）":                "Only search sessions of the given project (fuzzy)",
+	"只显示将要提交的文件和提交信息":                   "Only show the files and commit message that would be committed",
+	"只显示指定消息产生的差异":                      "Only show changes made by the given message",
+	"只显示最后更新早于指定时长之前的会话（如 12h、7d）":      "Only show sessions last updated longer ago than the given duration (e.g. 12h, 7d)",
+	"只显示正在运行的会话":                        "Only show running sessions",
+	"只暴露匹配的工具（支持通配符，如 session_*，可多次使用）": "Only expose matching tools (wildcards such as session_* supported, repeatable)",
+	"只暴露只读工具":                           "Only expose read-only tools",
+	"只记录决定，不响应请求":                       "Only record decisions, don't respond to requests",
+	"只记录卡住的会话，不中止或重发":                   "Only log stuck sessions, do not abort or re-send",
+	"只输出报告，不修改会话":                       "Only print the report without changing sessions",
+	"可用提供商:":                            "Available providers:",
+	"合并失败：%v":                           "merge failed: %v",
+	"合并或删除已完成的任务工作树":                    "Merge or remove finished task worktrees",
+	"合计":              "Total",
+	"同时执行的工具调用数上限":    "Maximum number of concurrent tool calls",
+	"同时获取消息的会话数":      "Number of sessions whose messages are fetched at once",
+	"同步后订阅事件流，持续更新索引": "After syncing, subscribe to the event stream and keep the index updated",
+	"同步失败：%v\n":       "Sync failed: %v\n",
+	"同步本地会话索引":        "Sync the local session index",
+	"名称：%s\n":         "Name: %s\n",
+	"向指定会话发送消息":       "Send a message to a session",
+	"向提示词追加文本":        "Append text to the prompt",
+	"启动 MCP 服务器":      "Start the MCP server",
+	"命令 %q 没有登记输出 Schema，运行 oho schema 查看可用命令": "command %q has no registered output schema, run oho schema to list available commands",
+	"命令 %s 已执行\n":      "Command %s executed\n",
+	"命令:\n":            "Commands:\n",
+	"命令参数":             "Command arguments",
+	"命令参数 (key=value)": "Command arguments (key=value)",
+	"命令参数 (用法：%s)":     "Command arguments (usage: %s)",
+	"命令已执行:\n":         "Command executed:\n",
+	"命令管理":             "Command management",
+	"命令：   %s\n":       "Command:   %s\n",
+	"响应 [a]llow / al[w]ays / [d]eny / [s]kip / [q]uit: ": "Respond [a]llow / al[w]ays / [d]eny / [s]kip / [q]uit: ",
+	"响应体 (JSON 格式)":                                      "Response body (JSON)",
+	"响应控制请求":                                             "Respond to a control request",
+	"响应权限请求":                                             "Respond to a permission request",
+	"响应权限请求 %s 失败：%w":                                    "failed to respond to permission request %s: %w",
+	"回退最后一条用户消息及其回复":                                     "Revert the last user message and its reply",
+	"回退消息":                                               "Revert a message",
+	"在会话的消息文本、工具调用的输入输出和文件路径中搜索。\n\n默认将查询按空白分隔为多个词，不区分大小写，命中任意一个词即为匹配；\n命中的词越多、完整短语出现的结果排名越靠前。使用 -E 将查询作为正则表达式。\n匹配在压缩空白后的单行文本上进行。\n\n使用 -s 只搜索一个会话，使用 --project-id 或 --directory 限定会话范围。": "Search message text, tool inputs and outputs, and file paths in sessions.\n\nBy default the query is split on whitespace into words and matched case-insensitively; any word matches.\nHits with more of the words, or with the whole phrase, rank higher. Use -E to treat the query as a regular expression.\nMatching runs on single-line text with whitespace collapsed.\n\nUse -s to search one session, or --project-id and --directory to narrow the sessions.",
+	"在会话记录中全文搜索":                                 "Full-text search across session transcripts",
+	"在文件中搜索文本":                                   "Search for text in files",
+	"在某条消息处分叉会话":                                 "Fork a session at a message",
+	"在项目中搜索文本":                                   "Search for text in the project",
+	"在项目中查找文件、符号和文本内容":                           "Find files, symbols and text in the project",
+	"处理 OAuth 回调":                                "Handle the OAuth callback",
+	"实例已销毁":                                      "Instance disposed",
+	"审计日志文件 (默认 <配置目录>/permissions-audit.jsonl)": "Audit log file (default <config dir>/permissions-audit.jsonl)",
+	"对服务器上的所有会话执行预算上限":                           "Enforce budget limits on every session on the server",
+	"将会话变更提交到本地仓库的新分支":                           "Commit session changes to a new branch in a local repository",
+	"将会话的文件变更写入本地仓库，基于当前 HEAD 创建新分支并提交。\n\n提交信息由会话标题、总结和待办事项生成，末尾的 Session-Id trailer 指向会话。\n写入文件的规则与 oho session diff --apply 相同；任何文件存在冲突时不做任何修改。\n暂存区已有改动或涉及的文件有未提交的改动时拒绝提交，避免混入无关内容；\n提交失败时恢复文件、切回原分支并删除新分支。": "Write the session's file changes into a local repository, create a new branch from the current HEAD and commit them.\n\nThe commit message is generated from the session title, summary and todo list, and ends with a Session-Id trailer pointing to the session.\nFiles are written with the same rules as oho session diff --apply; nothing is changed if any file conflicts.\nThe command refuses to run when the index already has staged changes or the touched files have uncommitted changes, so unrelated work is not committed;\nif the commit fails, the files are restored, the original branch is checked out and the new branch is deleted.",
+	"将在 %s 上创建分支 %s 并提交 %d 个文件:\n":              "From %s, would create branch %s and commit %d file(s):\n",
+	"将差异应用到本地工作目录":                              "Apply the diff to a local checkout",
+	"将归档 %d 个，删除 %d 个，跳过 %d 个（--dry-run，未执行）\n": "Would archive %d, delete %d, skip %d (--dry-run, nothing done)\n",
+	"将报告以 JSON Lines 格式追加到文件":                   "Append the report to a file as JSON Lines",
+	"将查询作为正则表达式":                                "Treat the query as a regular expression",
+	"工作树目录不存在":                                  "worktree directory does not exist",
+	"工具":                                        "TOOL",
+	"工具列表":                                      "Tools list",
+	"工具命令":                                      "Tool commands",
+	"工具：   %s\n":                                "Tool:      %s\n",
+	"差异上下文行数":                                   "Number of context lines in the diff",
+	"已中止会话 %s：%s":                               "aborted session %s: %s",
+	"已切换到分叉的会话 %s\n":                            "Switched to forked session %s\n",
+	"已创建：%s\n":                                  "Created: %s\n",
+	"已删除 %d 个别名\n":                              "Removed %d aliases\n",
+	"已删除工作树 %s\n":                               "Removed worktree %s\n",
+	"已删除本地索引 %s\n":                              "Deleted local index %s\n",
+	"已删除：%s\n":                                  "Deleted: %s\n",
+	"已取消":                                       "Cancelled",
+	"已合并 %s (%s) 并删除工作树\n":                      "Merged %s (%s) and removed the worktree\n",
+	"已同步 %d 个会话到 %s\n":                          "Synced %d sessions to %s\n",
+	"已同步 %d 个会话，正在监听事件流（Ctrl+C 退出）\n": "Synced %d sessions, watching the event stream (Ctrl+C to exit)\n",
+	"已响应：%s\n":                   "Responded: %s\n",
+	"已回退消息 %s 及之后的回复\n":          "Reverted message %s and the replies after it\n",
+	"已在分支 %s 上提交 %s (%d 个文件):\n": "On branch %s, committed %s (%d file(s)):\n",
+	"已应用：%s\n":                   "Applied: %s\n",
+	"已归档 %d 个，已删除 %d 个，跳过 %d 个，失败 %d 个\n": "Archived %d, deleted %d, skipped %d, failed %d\n",
+	"已恢复所有回退的消息":                          "All reverted messages restored",
+	"已设置别名 @%s -> %s\n":                   "Alias @%s -> %s set\n",
+	"已跳过":                                 "Skipped",
+	"已重发 %d 次，改为中止":                       "already re-sent %d times, aborting instead",
+	"已附加 %s，将随下一条消息发送\n":                  "Attached %s; it will be sent with the next message\n",
+	"帮助对话框已打开":                            "Help dialog opened",
+	"并发执行的数量":                             "Number of sessions to process in parallel",
+	"并排显示差异":                              "Show the diff side by side",
+	"序列化请求体失败：%w":                         "failed to encode request body: %w",
+	"应用差异失败：%w":                           "failed to apply diff: %w",
+	"应用差异的本地目录":                           "Local directory to apply the diff to",
+	"异步发送消息（不等待响应）":                       "Send a message asynchronously (don't wait for the response)",
+	"归档会话":                                "Archive a session",
+	"归档会话。\n\n指定多个 ID、使用 - 从 stdin 读取 ID 或使用 --where 按条件选择会话时进入批量模式；\n批量模式下优先使用会话自身的目录。": "Archive sessions.\n\nPassing several IDs, - to read IDs from stdin, or --where conditions switches to bulk mode;\nin bulk mode each session's own directory is used when known.",
+	"当前目录没有会话：%s":     "no session in the current directory: %s",
+	"当前路径：%s\n":       "Current path: %s\n",
+	"当前配置:":           "Current configuration:",
+	"忽略本地索引，从服务器重新获取": "Ignore the local index and fetch from the server",
+	"总结会话":            "Summarize a session",
+	"恢复所有已回退的消息":      "Restore all reverted messages",
+	"所有提供商:":          "All providers:",
+	"打开 git 仓库失败：%w":  "failed to open git repository: %w",
+	"打开主题选择器":         "Open the theme picker",
+	"打开会话选择器":         "Open the session picker",
+	"打开审计日志失败：%w":     "failed to open audit log: %w",
+	"打开已有会话或创建新会话，逐行读取输入并流式输出回复。\n\n支持行编辑和历史记录（上下方向键），以 \\ 结尾的行与下一行合并发送。\n等待回复时按 Ctrl+C 中止会话，在输入提示符处按 Ctrl+D 或输入 /exit 退出。\n\n对话中可以使用以下命令:\n  /model [provider:model]  查看或切换模型\n  /agent [name]            查看或切换代理\n  /attach <file>           附加文件，随下一条消息发送\n  /abort                   中止正在运行的会话\n  /diff                    显示会话的文件变更\n  /fork                    分叉会话并切换到新会话\n  /undo                    回退最后一条消息\n  /<command> [参数]        执行服务器定义的斜杠命令（见 oho command list）": "Open an existing session or create a new one, read prompts line by line and stream the replies.\n\nLine editing and history (up/down arrows) are supported. A line ending with \\ is joined with the next one.\nPress Ctrl+C while waiting for a reply to abort the session. Press Ctrl+D at the prompt or type /exit to quit.\n\nCommands available in the chat:\n  /model [provider:model]  Show or switch the model\n  /agent [name]            Show or switch the agent\n  /attach <file>           Attach a file to the next message\n  /abort                   Abort the running session\n  /diff                    Show the session's file changes\n  /fork                    Fork the session and switch to the fork\n  /undo                    Revert the last message\n  /<command> [args]        Run a slash command defined by the server (see oho command list)",
+	"打开帮助对话框":        "Open the help dialog",
+	"打开日志文件失败：%w":    "failed to open log file: %w",
+	"打开模型选择器":        "Open the model picker",
+	"执行命令":           "Execute a command",
+	"执行斜杠命令":         "Execute a slash command",
+	"执行模板失败：%w":      "failed to execute template: %w",
+	"执行钩子 %q 失败：%w":  "hook %q failed: %w",
+	"找到 %d 个匹配:\n\n": "Found %d matches:\n\n",
+	"找到 %d 个文件:\n\n": "Found %d files:\n\n",
+	"找到 %d 个符号:\n\n": "Found %d symbols:\n\n",
+	"持续监听事件流，实时显示新的子会话和状态": "Keep listening to the event stream and show new child sessions and status live",
+	"按 ID 过滤（支持模糊查询）":      "Filter by ID (fuzzy)",
+	"按保留策略归档和删除旧会话":        "Archive and delete old sessions by retention policy",
+	"按保留策略归档空闲的会话，并删除归档已久的会话。\n\n策略字段:\n  archive_after  空闲（最后更新）超过该时长的会话归档\n  delete_after   归档超过该时长的会话删除\n  keep_last      每个目录保留最近更新的会话数，这些会话不会被处理\n时长支持 90m、12h、7d、2w 等格式。\n\n已分享的会话、正在运行的会话以及有正在运行的子会话的会话不会被处理，\n它们在报告中标记为 skipped。\n\n策略示例:\n  archive_after: 14d\n  delete_after: 30d\n  keep_last: 5": "Archive idle sessions and delete sessions that have been archived for a long time, according to a retention policy.\n\nPolicy fields:\n  archive_after  archive sessions idle (last updated) longer than this\n  delete_after   delete sessions archived longer than this\n  keep_last      number of most recently updated sessions kept per directory; these are never touched\nDurations accept formats such as 90m, 12h, 7d and 2w.\n\nShared sessions, running sessions and sessions with running children are never touched;\nthey are marked as skipped in the report.\n\nPolicy example:\n  archive_after: 14d\n  delete_after: 30d\n  keep_last: 5",
+	"按创建时间过滤（时间戳，精确匹配）":                                                   "Filter by creation time (timestamp, exact match)",
+	"按名称查找文件":                                                             "Find files by name",
+	"按更新时间过滤（时间戳，精确匹配）":                                                   "Filter by update time (timestamp, exact match)",
+	"按条件选择会话 (key=value：id、title、status、directory、project-id、older-than)": "Select sessions by condition (key=value: id, title, status, directory, project-id, older-than)",
+	"按标题过滤（支持模糊查询）":                                                       "Filter by title (fuzzy)",
+	"按模型、代理、项目或日期汇总 token 用量和费用":                                          "Summarize token usage and cost by model, agent, project or day",
+	"按状态过滤 (running/completed/error/aborted/idle)":                        "Filter by status (running/completed/error/aborted/idle)",
+	"按目录过滤（支持模糊查询）":                                                       "Filter by directory (fuzzy)",
+	"按项目 ID 过滤（支持模糊查询）":                                                   "Filter by project ID (fuzzy)",
+	"排序字段 (created/updated)":                                              "Sort field (created/updated)",
+	"排序顺序 (asc/desc)":                                                     "Sort order (asc/desc)",
+	"控制 TUI 界面行为":                                                         "Control the TUI",
+	"控制请求已响应":                                                             "Control request answered",
+	"控制请求：%s\n":                                                           "Control request: %s\n",
+	"推理":                                                                  "Reasoning",
+	"提交工作树中的改动并将分支合并到创建工作树时的分支":                                           "Commit changes in the worktree and merge its branch into the branch it was created from",
+	"提交当前提示词":                                                             "Submit the current prompt",
+	"提交：%s\n":                                                             "Commit: %s\n",
+	"提供商 %s 的 OAuth 回调处理成功\n":                                             "OAuth callback for provider %s handled\n",
+	"提供商 %s 的认证凭据已设置\n":                                                   "Credentials for provider %s set\n",
+	"提供商 ID":                                                              "Provider ID",
+	"提供商管理命令":                                                             "Provider commands",
+	"提示消息已显示":                                                             "Toast shown",
+	"提示词已提交":                                                              "Prompt submitted",
+	"提示词已清除":                                                              "Prompt cleared",
+	"提示词已追加":                                                              "Prompt appended",
+	"搜索内容不能为空":                                                            "search query cannot be empty",
+	"搜索目录":                                                                "Directory to search",
+	"搜索范围 (text、tool、file)":                                               "Where to search (text, tool, file)",
+	"文件不存在：%s":                                                            "file not found: %s",
+	"文件管理命令":                                                              "File commands",
+	"文件类型限制 (file/directory)":                                             "Restrict results by type (file/directory)",
+	"文件：%s\n":                                                             "File: %s\n",
+	"文件：%s (状态：%s)\n":                                                     "File: %s (status: %s)\n",
+	"新会话的工作目录（默认当前目录）":                                                    "Working directory for a new session (default: current directory)",
+	"新会话的标题":                                                              "Title for a new session",
+	"无效的列定义：%q（格式为 NAME:.field）":                                          "invalid column definition: %q (expected NAME:.field)",
+	"无效的别名：%s（只能包含字母、数字、- 和 _）":                                           "invalid alias: %s (only letters, digits, - and _ are allowed)",
+	"无效的响应：%s（可选 allow/always/deny）":                                      "invalid response: %s (choose allow/always/deny)",
+	"无效的工具匹配模式：%s":                                                        "invalid tool pattern: %s",
+	"无效的搜索范围：%s（可用：text、tool、file）":                                       "invalid search scope: %s (available: text, tool, file)",
+	"无效的时长：%s（示例：90m、12h、7d、2w）":                                          "invalid duration: %s (examples: 90m, 12h, 7d, 2w)",
+	"无效的条件：%s（格式为 key=value）":                                             "invalid condition: %s (format is key=value)",
+	"无效的正则表达式：%w":                                                         "invalid regular expression: %w",
+	"无效的状态：%s（可用：running、completed、idle、error、aborted）": "invalid status: %s (available: running, completed, idle, error, aborted)",
+	"无效的起始时间：%s（示例：7d、12h、2026-10-01）":                  "invalid start: %s (examples: 7d, 12h, 2026-10-01)",
+	"无法获取会话状态，拒绝清理（使用 --force 强制清理）：%w":                 "cannot fetch session status, refusing to clean (use --force to clean anyway): %w",
+	"无需变更：%s\n": "Unchanged: %s\n",
+	"日志文件 (默认 <配置目录>/watchdog.jsonl)": "Log file (default <config dir>/watchdog.jsonl)",
+	"日期":         "Date",
+	"时长":         "Age",
+	"时间：   %s\n": "Time:      %s\n",
+	"显示会话的文件变更":  "Show the session's file changes",
+	"显示会话的父子层级，包括子代理创建的子会话，以及每个会话的状态、代理、模型和最后更新至今的时长。\n\n指定 ID 时只显示该会话及其所有后代，否则显示全部会话。\n使用 --watch 持续监听事件流，运行中的会话创建子会话或状态变化时重新显示。": "Show the parent/child hierarchy of sessions, including child sessions created by subagents,\nwith each session's status, agent, model and time since its last update.\n\nWith an ID, only that session and all its descendants are shown; otherwise all sessions are shown.\nWith --watch, keep listening to the event stream and redraw when a running session spawns children or a status changes.",
+	"显示前 %d 条，共 %d 条结果（使用 --limit 0 显示全部）\n": "Showing the first %d of %d results (use --limit 0 to show all)\n",
+	"显示帮助":   "Show help",
+	"显示提示消息": "Show a toast message",
+	`更新 OpenCode 配置。
+
+注意：默认模型（--model）无法通过此命令设置，因为 OpenCode Server 的 
+/config PATCH 端点不支持 defaultModel 字段。
+
+如需设置默认模型，请编辑 OpenCode 配置文件：
+  - 全局配置：~/.config/opencode/opencode.json
+  - 项目配置：项目目录下的 opencode.json
+
+配置格式：
+{
+  "model": "provider/model-id",
+  "provider": "provider-id"
+}
+
+或者使用环境变量：
+  export OPENCODE_MODEL="provider/model-id"`: `Update the OpenCode configuration.
+
+Note: the default model (--model) can't be set with this command, because the OpenCode Server
+/config PATCH endpoint doesn't support the defaultModel field.
+
+To set the default model, edit the OpenCode config file:
+  - Global config: ~/.config/opencode/opencode.json
+  - Project config: opencode.json in the project directory
+
+Format:
+{
+  "model": "provider/model-id",
+  "provider": "provider-id"
+}
+
+Or use an environment variable:
+  export OPENCODE_MODEL="provider/model-id"`,
+	"暂存区有未提交的改动，请先提交或取消暂存": "the index has staged changes; commit or unstage them first",
+	"更新":     "Updated",
+	"更新会话属性": "Update session properties",
+	"更新配置（注意：部分配置项可能不被服务器 API 支持）": "Update the configuration (note: some settings may not be supported by the server API)",
+	"最多显示的结果数 (0 表示不限)":            "Maximum number of results (0 for no limit)",
+	"最大 Token 数":      "Maximum number of tokens",
+	"最大结果数":           "Maximum number of results",
+	"最长等待时间，0 表示一直等待": "Maximum time to wait; 0 waits forever",
+	"有未提交更改：%v\n":     "Uncommitted changes: %v\n",
+	"工作树没有记录创建时的分支，无法确定合并目标":        "the worktree has no recorded base branch, so the merge target is unknown",
+	"主仓库当前在分支 %s，请先切换到创建工作树时的分支 %s": "the main repository is on branch %s; check out %s, the branch the worktree was created from, first",
+	"主仓库有未提交的改动，请先提交或暂存":            "the main repository has uncommitted changes; commit or stash them first",
+	"有未提交的改动":                  "has uncommitted changes",
+	"服务器主机地址":                  "Server host",
+	"服务器密码 (覆盖环境变量)":           "Server password (overrides the environment variable)",
+	"服务器拒绝了权限响应 %s":            "server rejected the permission response %s",
+	"服务器未中止会话":                 "server did not abort the session",
+	"服务器未删除会话":                 "server did not delete the session",
+	"服务器状态：%s\n":               "Server status: %s\n",
+	"服务器端口":                    "Server port",
+	"未找到匹配":                    "No matches found",
+	"未找到待处理的权限请求：%s":           "no pending permission request found: %s",
+	"未找到文件":                    "No files found",
+	"未找到符号":                    "No symbols found",
+	"未知命令：/%s，输入 /help 查看可用命令": "unknown command: /%s, type /help to list commands",
+	"未知的会话别名：%s（使用 oho session alias list 查看）":                     "unknown session alias: %s (see oho session alias list)",
+	"未知的条件：%s（可用：id、title、status、directory、project-id、older-than）": "unknown condition: %s (available: id, title, status, directory, project-id, older-than)",
+	"本地仓库目录": "Local repository directory",
+	"本地提示模板目录 (默认: <配置目录>/prompts)": "Local prompt template directory (default: <config dir>/prompts)",
+	"本地文件不存在":          "local file not found",
+	"本地文件已存在":          "local file already exists",
+	"本地文件有改动":          "local file has changes",
+	"权限请求 %s 已响应：%s\n": "Permission request %s answered: %s\n",
+	"权限请求收件箱":          "Permission request inbox",
+	"查找命令":             "Find commands",
+	"查找工作区符号":          "Find workspace symbols",
+	`查看和响应代理发起的权限请求。
+
+待处理的请求来自服务器的权限列表以及事件流中的 permission 事件，
+命令会在 --wait 指定的时间内监听事件流以收集新的请求。
+
+示例:
+  oho permissions list
+  oho permissions list -s ses_123 --wait 10s
+  oho permissions show per_456
+  oho permissions review`: `View and respond to permission requests raised by agents.
+
+Pending requests come from the server's permission list and from permission events on the event stream;
+commands listen to the event stream for the duration given by --wait to collect new requests.
+
+Examples:
+  oho permissions list
+  oho permissions list -s ses_123 --wait 10s
+  oho permissions show per_456
+  oho permissions review`,
+	"查看或切换后续消息使用的代理":           "Show or switch the agent for the following messages",
+	"查看或切换后续消息使用的模型":           "Show or switch the model for the following messages",
+	"查看权限请求详情":                 "Show permission request details",
+	"标题":                       "Title",
+	"标题：   %s\n":               "Title:     %s\n",
+	"根据 CLI 命令树自动生成额外的 MCP 工具": "Generate additional MCP tools from the CLI command tree",
+	"根据文件名搜索文件":                "Find files by name",
+	"根据策略自动响应权限请求":             "Respond to permission requests automatically based on a policy",
+	"格式化器状态":                   "Formatter status",
+	"格式化器状态:":                  "Formatter status:",
+	"检查 OpenCode Server 健康状态":  "Check OpenCode Server health",
+	"检查服务器健康状态":                "Check server health",
+	"模型":                       "Model",
+	"模型 ID":                    "Model ID",
+	"模型选择器已打开":                 "Model picker opened",
+	"模型：   %s\n":               "Model:     %s\n",
+	"模型：%s\n":                  "Model: %s\n",
+	"模式：   %s\n":               "Mode:      %s\n",
+	"正在工作的会话超过该时长没有事件视为卡住":        "A working session with no events for this long is considered stuck",
+	"正在监听全局事件... (Ctrl+C 停止)":     "Listening for global events... (Ctrl+C to stop)",
+	"此操作不接受 %s，请使用完整 ID、ID 前缀或别名": "this command does not accept %s; use a full ID, an ID prefix or an alias",
+	"每个会话最多重发的次数，用完后改为中止":         "Maximum re-sends per session; abort once exhausted",
+	"汇总统计范围内所有会话（包括子代理的子会话）中助手消息的输入、输出、推理、\n缓存读取和缓存写入 token 数以及费用。\n\n--group-by 的分组方式：\n  model     提供商/模型\n  agent     代理\n  project   项目根目录\n  day       消息创建的日期（本地时间）\n\n-o json 和 -o yaml 输出包含合计的完整报告，-o csv 只输出各分组，便于导入表格。\n会话在上次统计后没有更新时使用本地的消息快照。": "Summarize input, output, reasoning, cache read and cache write tokens and cost\nof assistant messages in all sessions in range, including subagent sessions.\n\n--group-by values:\n  model     provider/model\n  agent     agent\n  project   project root directory\n  day       date the message was created (local time)\n\n-o json and -o yaml print the full report with totals; -o csv prints only the groups, for spreadsheets.\nMessage snapshots are reused for sessions not updated since the last report.",
+	"没有 LSP 服务器":                        "No LSP servers",
+	"没有 MCP 服务器":                        "No MCP servers",
+	"没有 token 用量":                       "No token usage",
+	"没有任务工作树":                           "No task worktrees",
+	"没有匹配 %s 的会话":                       "no session matches %s",
+	"没有会话":                              "No sessions",
+	"没有会话别名":                            "No session aliases",
+	"没有匹配的会话":                           "No matching sessions",
+	"没有可以回退的消息":                         "no message to revert",
+	"没有可用代理":                            "No agents available",
+	"没有可用命令":                            "No commands available",
+	"没有可用工具":                            "No tools available",
+	"没有已跟踪的文件":                          "No tracked files",
+	"没有待处理的权限请求":                        "No pending permission requests",
+	"没有找到匹配的内容":                         "No matches found",
+	"没有找到工作树：%s":                        "worktree not found: %s",
+	"没有文件变更\n":                          "No file changes\n",
+	"没有格式化器":                            "No formatters",
+	"没有附加文件\n":                          "No attached files\n",
+	"没有需要处理的会话":                         "No sessions to process",
+	"没有项目":                              "No projects",
+	"消息 ID":                             "Message ID",
+	"消息使用的代理":                           "Agent for messages",
+	"消息使用的模型 (provider:model)":          "Model for messages (provider:model)",
+	"消息内容":                              "Message content",
+	"消息已发送":                             "Message sent",
+	"消息已发送:\n":                          "Message sent:\n",
+	"消息已回退":                             "Message reverted",
+	"消息已异步发送":                           "Message sent asynchronously",
+	"消息数":                               "Messages",
+	"消息标题":                              "Toast title",
+	"消息管理命令":                            "Message commands",
+	"消息类型 (info/warning/error/success)": "Toast variant (info/warning/error/success)",
+	"消息详情:\n":                           "Message details:\n",
+	"添加 MCP 服务器":                        "Add an MCP server",
+	"清理所有未在运行的工作树":                      "Clean all worktrees whose session is not running",
+	"清除提示词":                             "Clear the prompt",
+	"温度参数":                              "Temperature",
+	"父会话 ID（用于创建子会话）":                   "Parent session ID (for creating a child session)",
+	"版本：%s\n":                           "Version: %s\n",
+	"状态":                                "STATE",
+	"界面语言 (zh|en，默认根据 LANG 环境变量)": "Interface language (zh|en, defaults to the LANG environment variable)",
+	"监听事件流收集请求的时间":                "How long to listen to the event stream for requests",
+	"监听全局事件流 (SSE)":               "Listen to the global event stream (SSE)",
+	"目录":                          "Directory",
+	"目录：   %s\n":                  "Directory: %s\n",
+	"看门狗已启动（%d 个会话正在运行，日志：%s）\n": "Watchdog started (%d sessions running, log: %s)\n",
+	"确定中止以上 %d 个会话？":             "Abort the %d sessions above?",
+	"确定删除以上 %d 个会话？":             "Delete the %d sessions above?",
+	"确定处理以上 %d 个会话？":             "Process the %d sessions above?",
+	"确定归档以上 %d 个会话？":             "Archive the %d sessions above?",
+	"空目录":                        "Empty directory",
+	"等待下一个控制请求":                  "Wait for the next control request",
+	"等待会话 %s 超时（%s）":             "timed out waiting for session %s (%s)",
+	"等待会话运行结束":                   "Wait for a session's run to end",
+	"等待会话运行结束，子代理创建的子会话请求权限时同样触发 --on-permission。\n\n会话已经空闲时立即返回。会话出错或等待超时时以非零状态退出。\n\n钩子可以是本地命令或 webhook URL（以 http:// 或 https:// 开头）：\n命令通过 shell 执行，会话信息通过 OHO_EVENT、OHO_SESSION_ID、OHO_SESSION_TITLE、\nOHO_SESSION_DIRECTORY、OHO_DURATION_MS、OHO_ERROR 和 OHO_PERMISSION_* 环境变量传入，\n标准输入为 JSON 格式的事件；webhook 以 POST 请求发送同样的 JSON。": "Wait until a session's run ends. Permission requests from child sessions created by subagents also trigger --on-permission.\n\nReturns immediately if the session is already idle. Exits with a non-zero status if the session fails or the wait times out.\n\nA hook is either a local command or a webhook URL (starting with http:// or https://):\ncommands run through the shell and receive session details in the OHO_EVENT, OHO_SESSION_ID, OHO_SESSION_TITLE,\nOHO_SESSION_DIRECTORY, OHO_DURATION_MS, OHO_ERROR and OHO_PERMISSION_* environment variables,\nwith the event as JSON on standard input; webhooks receive the same JSON in a POST request.",
+	"等待超过 %s":     "waited longer than %s",
+	"策略文件 (YAML)": "Policy file (YAML)",
+	"策略至少需要 archive_after 或 delete_after": "policy needs archive_after or delete_after",
+	"管理 AI 提供商，包括列表、认证和 OAuth":            "Manage AI providers, including listing, authentication and OAuth",
+	"管理 MCP 服务器":                          "Manage MCP servers",
+	"管理 OpenCode 会话消息，包括发送、列表、命令执行等":      "Manage OpenCode session messages: send, list, run commands and more",
+	"管理 OpenCode 会话，包括创建、删除、更新等操作":        "Manage OpenCode sessions: create, delete, update and more",
+	"管理 OpenCode 项目":                      "Manage OpenCode projects",
+	"管理 oho add --worktree 和 oho session submit --worktree 创建的 git 工作树。\n\n每个任务在独立的工作树和 oho/<任务名> 分支中执行，工作树与会话的对应关系\n记录在配置目录的 worktrees.json 中。任务完成后使用 clean 合并或删除工作树。\n\n示例:\n  oho worktree list\n  oho worktree clean ses_123 --merge\n  oho worktree clean --all": "Manage the git worktrees created by oho add --worktree and oho session submit --worktree.\n\nEach task runs in its own worktree on an oho/<task> branch. The mapping between\nworktrees and sessions is recorded in worktrees.json in the config directory.\nUse clean to merge or remove a worktree once its task is finished.\n\nExamples:\n  oho worktree list\n  oho worktree clean ses_123 --merge\n  oho worktree clean --all",
+	"管理任务工作树":           "Manage task worktrees",
+	"管理会话别名":            "Manage session aliases",
+	"管理文件，包括列出、读取内容和状态": "Manage files: list, read content and status",
+	"管理认证凭据":            "Manage authentication credentials",
+	"系统提示":              "System prompt",
+	"结果":                "Result",
+	"统计 token 用量和费用":    "Report token usage and cost",
+	"统计助手消息的 token 用量和费用。\n\n示例:\n  oho usage report\n  oho usage report --since 30d --group-by project -o csv": "Report token usage and cost of assistant messages.\n\nExamples:\n  oho usage report\n  oho usage report --since 30d --group-by project -o csv",
+	"统计范围：%s 至 %s\n\n":                   "Range: %s to %s\n\n",
+	"统计起点，时长（如 7d、12h）或日期（如 2026-10-01）": "Start of the range, a duration (e.g. 7d, 12h) or a date (e.g. 2026-10-01)",
+	"缓存写入":      "Cache Write",
+	"缓存读取":      "Cache Read",
+	"编码：%s\n\n": "Encoding: %s\n\n",
+	"自动审批已启动（%d 条规则，审计日志：%s）\n": "Autopilot started (%d rules, audit log: %s)\n",
+	"自动批准的工具列表（注意：可能不被服务器支持）":   "Tools to auto-approve (note: may not be supported by the server)",
+	"获取 LSP 服务器状态":              "Get LSP server status",
+	"获取 OpenCode 配置":            "Get the OpenCode configuration",
+	"获取 VCS 信息":                 "Get VCS information",
+	"获取会话中文件的变更，以统一格式显示差异。\n\n服务端工作目录在远程时，可以用 --patch 导出补丁，或用 --apply 直接应用到本地检出的代码：\n本地文件与变更前一致时直接写入，已有改动时按上下文逐段应用。\n无法应用的补丁段写入 <文件>.rej，该文件保持不变。": "Show the files changed in a session as unified diffs.\n\nWhen the server workspace is remote, export a patch with --patch, or apply it to a local checkout with --apply:\nfiles that still match the original are written directly; files with local changes are patched hunk by hunk using the context.\nHunks that cannot be applied are written to <file>.rej and the file is left unchanged.",
+	"获取会话差异":                    "Get the session diff",
+	"获取会话待办事项":                  "Get session todos",
+	"获取会话详情":                    "Get session details",
+	"获取和更新 OpenCode 配置":         "Get and update the OpenCode configuration",
+	"获取子会话":                     "Get child sessions",
+	"获取已跟踪文件的状态":                "Get the status of tracked files",
+	"获取当前目录失败：%w":               "failed to get current directory: %w",
+	"获取当前路径":                    "Get the current path",
+	"获取当前项目":                    "Get the current project",
+	"获取所有会话状态":                  "Get the status of all sessions",
+	"获取所有会话的状态":                 "Get the status of all sessions",
+	"获取指定会话的详细信息":               "Get details of a session",
+	"获取提供商认证方式":                 "Get provider authentication methods",
+	"获取格式化器状态":                  "Get formatter status",
+	"获取消息详情":                    "Get message details",
+	"获取配置":                      "Get the configuration",
+	"要创建的分支":                    "Branch to create",
+	"要执行的命令":                    "Command to execute",
+	"要继续的会话 ID（默认创建新会话）":        "Session ID to continue (default: create a new session)",
+	"规则 %s 的 action 无效：%q":      "rule %s has an invalid action: %q",
+	"规则 %s 的 command 正则无效：%w":   "rule %s has an invalid command regexp: %w",
+	"规则 %s 的 directory 模式无效：%s": "rule %s has an invalid directory pattern: %s",
+	"规则 %s 的 tool 模式无效：%s":      "rule %s has an invalid tool pattern: %s",
+	"规则 %s 的路径模式无效：%s":          "rule %s has an invalid path pattern: %s",
+	"解析 OAuth 响应失败：%w":          "failed to parse OAuth response: %w",
+	"解析 body 失败：%w":             "failed to parse body: %w",
+	"解析会话列表失败：%w":               "failed to parse session list: %w",
+	"解析会话失败：%w":                 "failed to parse session: %w",
+	"解析会话状态失败：%w":               "failed to parse session status: %w",
+	"解析别名文件失败：%w":               "failed to parse alias file: %w",
+	"解析命令列表失败：%w":               "failed to parse command list: %w",
+	"解析响应失败：%w":                 "failed to parse response: %w",
+	"解析回调响应失败：%w":               "failed to parse callback response: %w",
+	"解析差异失败：%w":                 "failed to parse diff: %w",
+	"解析待办事项失败：%w":               "failed to parse todos: %w",
+	"解析提供商列表失败：%w":              "failed to parse provider list: %w",
+	"解析提示模板失败：%s: %w":           "failed to parse prompt template: %s: %w",
+	"解析权限请求失败：%w":               "failed to parse permission requests: %w",
+	"解析模板失败：%w":                 "failed to parse template: %w",
+	"解析消息列表失败：%w":               "failed to parse message list: %w",
+	"解析策略失败：%w":                 "failed to parse policy: %w",
+	"解析认证方式失败：%w":               "failed to parse authentication methods: %w",
+	"解析配置失败：%w":                 "failed to parse configuration: %w",
+	"解析配置文件失败：%w":               "failed to parse config file: %w",
+	"警告：中止会话 %s 失败：%v\n":        "Warning: failed to abort session %s: %v\n",
+	"警告：写入审计日志失败：%v\n":          "Warning: failed to write audit log: %v\n",
+	"警告：写入日志失败：%v\n":            "Warning: failed to write log: %v\n",
+	"警告：执行钩子失败：%v\n":            "Warning: hook failed: %v\n",
+	"警告：无法连接服务器（%v），使用 %s 同步的本地快照\n":                                              "Warning: cannot reach the server (%v), using the local snapshot synced at %s\n",
+	"监听非本机地址 %s 时必须设置 --token 或 OHO_MCP_TOKEN；确实不需要认证时使用 --allow-unauthenticated": "--token or OHO_MCP_TOKEN is required when listening on non-local address %s; use --allow-unauthenticated if no authentication is really wanted",
+	"允许在非本机地址上不设置 --token 监听（任何能访问端口的人都可以调用 OpenCode API）":                        "Allow listening on a non-local address without --token (anyone who can reach the port can call the OpenCode API)",
+	"HTTP 会话空闲多久后过期": "How long an idle HTTP session lives before it expires",
+	"警告：监听非本机地址 %s 且未设置 --token，任何人都可以调用 OpenCode API\n": "Warning: listening on non-local address %s without --token, anyone can call the OpenCode API\n",
+	"警告：获取会话 %s 的消息失败：%s\n":                              "Warning: failed to fetch messages of session %s: %s\n",
+	"警告：获取服务器命令失败：%v\n":                                  "Warning: failed to fetch server commands: %v\n",
+	"警告：订阅事件流失败：%v\n":                                    "Warning: failed to subscribe to the event stream: %v\n",
+	"警告：配置初始化失败：%v\n":                                    "Warning: failed to initialize config: %v\n",
+	`订阅事件流，根据策略文件自动响应权限请求。
+
+规则按顺序匹配，第一条命中的规则生效。规则的所有条件都满足才算命中：
+  tool       工具名（通配符）
+  paths      文件路径 glob 列表，请求中的每个路径都必须匹配（** 匹配任意层级）；
+             路径先按会话目录规范化，用 .. 越出根目录的路径不匹配任何规则
+  command    shell 命令正则，应匹配整条命令并排除 ; & | $ 和反引号，避免命令拼接绕过
+  directory  会话目录 glob
+action 可以是 allow、always、deny 或 escalate。
+没有规则命中时使用 default（escalate 或 deny，默认 escalate）。
+escalate 的请求保持待处理，交给人工通过 oho permissions review 处理。
+
+每个决定都会以 JSON Lines 格式写入审计日志。
+
+策略示例:
+  default: escalate
+  rules:
+    - name: read-repo
+      tool: read
+      paths: ["/work/repo/**"]
+      action: allow
+    - name: safe-shell
+      tool: bash
+      command: '^(go (test|build|vet)|git (status|diff))( [^;&|$\x60<>\n]*)?$'
+      action: allow
+    - name: no-push
+      tool: bash
+      command: 'git push'
+      action: deny
+
+示例:
+  oho permissions autopilot --policy policy.yaml
+  oho permissions autopilot --policy policy.yaml -s ses_123 --dry-run`: `Subscribe to the event stream and respond to permission requests automatically based on a policy file.
+
+Rules are matched in order and the first matching rule wins. A rule matches only when all of its conditions hold:
+  tool       Tool name (wildcards)
+  paths      File path globs; every path in the request must match (** matches any depth);
+             paths are cleaned against the session directory first, and paths that escape the root with .. match no rule
+  command    Shell command regexp; it should match the whole command and exclude ; & | $ and backticks so chained commands cannot slip through
+  directory  Session directory glob
+action can be allow, always, deny or escalate.
+When no rule matches, default applies (escalate or deny, escalate by default).
+Escalated requests stay pending for a human to handle with oho permissions review.
+
+Every decision is written to the audit log as JSON Lines.
+
+Example policy:
+  default: escalate
+  rules:
+    - name: read-repo
+      tool: read
+      paths: ["/work/repo/**"]
+      action: allow
+    - name: safe-shell
+      tool: bash
+      command: '^(go (test|build|vet)|git (status|diff))( [^;&|$\x60<>\n]*)?$'
+      action: allow
+    - name: no-push
+      tool: bash
+      command: 'git push'
+      action: deny
+
+Examples:
+  oho permissions autopilot --policy policy.yaml
+  oho permissions autopilot --policy policy.yaml -s ses_123 --dry-run`,
+	"订阅事件流失败，无法执行 --on-permission：%w": "failed to subscribe to the event stream, cannot run --on-permission: %w",
+	"订阅事件流失败，无法监控预算：%w":               "cannot enforce budget limits, failed to subscribe to the event stream: %w",
+	"订阅事件流失败：%w":                      "failed to subscribe to the event stream: %w",
+	"订阅事件流，对服务器上所有会话的每次运行执行预算上限，超出时中止会话及其子会话。\n\n一次运行从会话开始工作到空闲为止，子代理创建的子会话的用量计入根会话。\n费用和 token 数来自每个步骤结束（step-finish）时的用量，token 数包括缓存读写。\n启动时已在运行的会话从启动时开始计算。\n\n每次中止输出一行记录，-o json 或 -o jsonl 时输出 JSON。\n\n示例:\n  oho guard --max-cost 5 --max-duration 1h\n  oho guard --max-tokens 2000000 --dry-run -o jsonl >> guard.jsonl":                                                                                                                                                                                                                                                                                                                                                                                                                               "Subscribe to the event stream and enforce budget limits on every run of every session\non the server. Sessions that exceed a limit are aborted along with their child sessions.\n\nA run lasts from when a session starts working until it is idle. Usage of child sessions\ncreated by subagents counts toward the root session.\nCost and tokens come from the usage reported when each step finishes (step-finish); tokens include cache reads and writes.\nSessions already running at startup are measured from startup.\n\nEach abort prints one line, or JSON with -o json or -o jsonl.\n\nExamples:\n  oho guard --max-cost 5 --max-duration 1h\n  oho guard --max-tokens 2000000 --dry-run -o jsonl >> guard.jsonl",
+	"订阅事件流，服务器上任意会话的一次运行完成、出错或请求权限时，执行配置文件中的钩子。\n\n一次运行从会话开始工作到空闲为止。子代理创建的子会话的权限请求计入根会话，子会话的完成不单独通知。\n启动时已在运行的会话从启动时开始计算，已在等待响应的权限请求在启动时通知。\n\n钩子可以是本地命令或 webhook URL（以 http:// 或 https:// 开头）：\n命令通过 shell 执行，会话信息通过 OHO_EVENT、OHO_SESSION_ID、OHO_SESSION_TITLE、\nOHO_SESSION_DIRECTORY、OHO_DURATION_MS、OHO_ERROR 和 OHO_PERMISSION_* 环境变量传入，\n标准输入为 JSON 格式的事件；webhook 以 POST 请求发送同样的 JSON。\n\n每个事件输出一行记录，-o json 或 -o jsonl 时输出 JSON。\n\n配置示例:\n  on_complete: notify-send \"oho\" \"$OHO_SESSION_TITLE 已完成\"\n  on_error:\n    - https://hooks.example.com/oho\n  on_permission: https://hooks.example.com/oho\n  min_duration: 2m   # 运行时长短于该值的完成不通知\n\n示例:\n  oho notify --config notify.yaml\n  oho notify --config notify.yaml -o jsonl >> notify.jsonl": "Subscribe to the event stream and run the hooks from the config file whenever a run of any session on the server completes, fails or asks for a permission.\n\nA run lasts from when a session starts working until it goes idle. Permission requests from child sessions created by subagents count toward the root session; child sessions finishing are not notified separately.\nSessions already running at startup are timed from startup, and permission requests already pending are notified at startup.\n\nA hook is either a local command or a webhook URL (starting with http:// or https://):\ncommands run through the shell and receive session details in the OHO_EVENT, OHO_SESSION_ID, OHO_SESSION_TITLE,\nOHO_SESSION_DIRECTORY, OHO_DURATION_MS, OHO_ERROR and OHO_PERMISSION_* environment variables,\nwith the event as JSON on standard input; webhooks receive the same JSON in a POST request.\n\nEach event prints one line, or JSON with -o json or -o jsonl.\n\nConfig example:\n  on_complete: notify-send \"oho\" \"$OHO_SESSION_TITLE finished\"\n  on_error:\n    - https://hooks.example.com/oho\n  on_permission: https://hooks.example.com/oho\n  min_duration: 2m   # completions of shorter runs are not notified\n\nExamples:\n  oho notify --config notify.yaml\n  oho notify --config notify.yaml -o jsonl >> notify.jsonl",
+	"订阅事件流，跟踪每个正在工作的会话最后一次事件的时间，会话沉默超过阈值时执行动作：\n  warn    只记录\n  abort   中止会话及其子会话\n  resend  中止会话，等待停止后以相同的代理和模型重新发送最后一条用户消息；\n          每个会话最多重发 max_resends 次，之后改为中止\n\n子代理创建的子会话的事件计为根会话的活动。等待人工响应权限请求期间不计沉默。\n启动时已在运行的会话从启动时开始计算。每次沉默只处理一次，直到会话再次有活动。\n\n每个动作以 JSON Lines 格式写入日志文件。\n\n配置示例（规则按顺序匹配，第一条命中的规则覆盖默认的 silence 和 action）:\n  silence: 10m\n  action: warn\n  max_resends: 1\n  rules:\n    - name: ci\n      directory: /work/ci/**\n      silence: 20m\n      action: resend\n    - name: build\n      agent: build\n      action: abort\n\n示例:\n  oho watchdog --silence 15m --action abort\n  oho watchdog --config watchdog.yaml --log /var/log/oho-watchdog.jsonl":                                                                         "Subscribe to the event stream and track when each working session last produced an event. When a session stays silent past the threshold, take an action:\n  warn    log only\n  abort   abort the session and its child sessions\n  resend  abort the session, wait for it to stop, then re-send the last user message with the same agent and model;\n          each session is re-sent at most max_resends times, after which it is aborted instead\n\nEvents from child sessions created by subagents count as activity of the root session. Time spent waiting for a permission reply does not count as silence.\nSessions already running at startup are timed from startup. Each silence is handled once, until the session is active again.\n\nEvery action is written to the log file as JSON Lines.\n\nConfig example (rules are matched in order; the first matching rule overrides the default silence and action):\n  silence: 10m\n  action: warn\n  max_resends: 1\n  rules:\n    - name: ci\n      directory: /work/ci/**\n      silence: 20m\n      action: resend\n    - name: build\n      agent: build\n      action: abort\n\nExamples:\n  oho watchdog --silence 15m --action abort\n  oho watchdog --config watchdog.yaml --log /var/log/oho-watchdog.jsonl",
+	"认证凭据 (key=value 格式)": "Credentials (key=value)",
+	`认证失败 [401]: 用户名或密码错误
+
+请配置认证信息，选择以下任一方式:
+  1. 环境变量 (推荐):
+     export OPENCODE_SERVER_HOST=127.0.0.1
+     export OPENCODE_SERVER_PORT=4096
+     export OPENCODE_SERVER_USERNAME=opencode
+     export OPENCODE_SERVER_PASSWORD=your-password
+
+  2. 命令行标志:
+     oho --password your-password <command>
+
+  3. 配置文件 (~/.config/oho/config.json):
+     {"password": "your-password"}`: `authentication failed [401]: wrong username or password
+
+Configure credentials in one of the following ways:
+  1. Environment variables (recommended):
+     export OPENCODE_SERVER_HOST=127.0.0.1
+     export OPENCODE_SERVER_PORT=4096
+     export OPENCODE_SERVER_USERNAME=opencode
+     export OPENCODE_SERVER_PASSWORD=your-password
+
+  2. Command line flag:
+     oho --password your-password <command>
+
+  3. Config file (~/.config/oho/config.json):
+     {"password": "your-password"}`,
+	"认证管理":              "Authentication management",
+	"记录工作树失败：%w":        "failed to record worktree: %w",
+	"设置会话别名":            "Set a session alias",
+	"设置认证凭据":            "Set authentication credentials",
+	"语言设置":              "Language settings",
+	"说明":                "Note",
+	"请使用 --title 指定新标题": "please use --title to set the new title",
+	"请指定会话 ID 或分支，或使用 --all":         "specify a session ID or branch, or use --all",
+	"请提供 --agent 参数":                 "please provide --agent",
+	"请提供 --body 参数":                  "please provide --body",
+	"请提供 --command 参数":               "please provide --command",
+	"请提供 --config 参数 (JSON 格式)":      "please provide --config (JSON)",
+	"请提供 --message 参数":               "please provide --message",
+	"请提供 --provider 和 --model 参数":    "please provide --provider and --model",
+	"请提供 --response 参数 (allow/deny)": "please provide --response (allow/deny)",
+	"请提供会话 ID 或使用 -s 标志":             "please provide a session ID or use the -s flag",
+	"请提供会话 ID、- 或 --where 条件":        "provide session IDs, - or --where conditions",
+	"请提供权限 ID":                       "please provide a permission ID",
+	"请提供消息内容":                        "please provide the message content",
+	"请提供消息内容或文件，例如：oho message add -s <session> \"你好\" 或 oho message add -s <session> --file image.jpg": "please provide message content or files, e.g. oho message add -s <session> \"hello\" or oho message add -s <session> --file image.jpg",
+	"请提供至少一个凭据 (--credentials key=value)":                                                               "please provide at least one credential (--credentials key=value)",
+	"请提供至少一个要更新的配置项":                                                                                    "please provide at least one setting to update",
+	"请提供要执行的 shell 命令":                                                                                  "please provide the shell command to run",
+	"请求失败：%w":                                                                                           "request failed: %w",
+	`请求超时（%d 秒）：%w
+
+建议:
+  1. 使用 --no-reply 参数避免等待
+  2. 设置环境变量增加超时：export OPENCODE_CLIENT_TIMEOUT=600
+  3. 使用异步命令：oho message prompt-async -s <session-id> "任务"`: `request timed out (%d seconds): %w
+
+Suggestions:
+  1. Use --no-reply to avoid waiting
+  2. Increase the timeout with an environment variable: export OPENCODE_CLIENT_TIMEOUT=600
+  3. Use the async command: oho message prompt-async -s <session-id> "task"`,
+	"请至少指定 --max-cost、--max-tokens 或 --max-duration 中的一个": "specify at least one of --max-cost, --max-tokens or --max-duration",
+	"读取 stdin 失败：%w":           "failed to read stdin: %w",
+	"读取别名文件失败：%w":              "failed to read alias file: %w",
+	"读取响应失败：%w":                "failed to read response: %w",
+	"读取工作树记录失败：%w":             "failed to read worktree records: %w",
+	"读取指定文件的内容":                "Read the content of a file",
+	"读取提示模板失败：%s: %w":          "failed to read prompt template: %s: %w",
+	"读取提示模板目录失败：%w":            "failed to read prompt template directory: %w",
+	"读取文件内容":                   "Read file content",
+	"读取文件失败：%s: %w":            "failed to read file: %s: %w",
+	"读取本地索引失败：%w":              "failed to read local index: %w",
+	"读取策略文件失败：%w":              "failed to read policy file: %w",
+	"读取配置文件失败：%w":              "failed to read config file: %w",
+	"读取错误: %v\n":               "Read error: %v\n",
+	"调用 webhook %s 失败：%w":      "webhook %s failed: %w",
+	"调用 webhook %s 失败：HTTP %d": "webhook %s failed: HTTP %d",
+	"费用":                       "Cost",
+	"费用 $%.4f 超过上限 $%.4f":      "cost $%.4f exceeds the limit of $%.4f",
+	"费用上限（美元），超出时中止会话":         "Cost limit in USD; the session is aborted when it is exceeded",
+	"路径":         "PATH",
+	"路径：   %s\n": "Path:      %s\n",
+	"路径：%s\n":    "Path: %s\n",
+	"跳过 %s：%s\n": "Skipped %s: %s\n",
+	"跳过确认":       "Skip confirmation",
+	"输入":         "Input",
+	"输入 /help 查看命令，Ctrl+D 退出\n": "Type /help for commands, Ctrl+D to quit\n",
+	"输出":                   "Output",
+	"输出可用于 git apply 的补丁":  "Print a patch usable with git apply",
+	"输出命令 JSON 输出的 Schema": "Print the JSON Schema of a command's JSON output",
+	`输出命令在 --json 模式下的 JSON Schema。
+
+所有命令的 JSON 输出都使用同一个信封：
+  成功：{"version": 1, "ok": true, "data": ...}
+  失败：{"version": 1, "ok": false, "error": {"code": "...", "message": "...", "status": 404}}
+
+示例:
+  oho schema                  # 列出有 Schema 的命令
+  oho schema session list     # 输出 session list 的 Schema
+  oho schema --all            # 输出所有命令的 Schema`: `Print the JSON Schema of a command's output in --json mode.
+
+The JSON output of every command uses the same envelope:
+  Success: {"version": 1, "ok": true, "data": ...}
+  Failure: {"version": 1, "ok": false, "error": {"code": "...", "message": "...", "status": 404}}
+
+Examples:
+  oho schema                  # List commands with a schema
+  oho schema session list     # Print the schema of session list
+  oho schema --all            # Print the schemas of all commands`,
+	"输出所有命令的 Schema":                                                        "Print the schemas of all commands",
+	"输出格式 %s 不接受参数":                                                         "output format %s takes no argument",
+	"输出格式 %s 需要参数，如 %s=...":                                                 "output format %s requires an argument, e.g. %s=...",
+	"输出格式 (table|wide|yaml|json|jsonl|csv|template=...|custom-columns=...)": "Output format (table|wide|yaml|json|jsonl|csv|template=...|custom-columns=...)",
+	"运行 shell 命令":                                                           "Run a shell command",
+	"运行时长 %s 超过上限 %s":                                                       "run time %s exceeds the limit of %s",
+	"运行时长上限，超出时中止会话":                                                        "Run duration limit; the session is aborted when it is exceeded",
+	"远程：%s\n":      "Remote: %s\n",
+	"退出":           "Quit",
+	"逐个审核待处理的权限请求": "Review pending permission requests one by one",
+	`逐个显示待处理的权限请求并提示响应：
+
+  a / allow   允许本次
+  w / always  始终允许
+  d / deny    拒绝
+  s / 回车     跳过
+  q           退出`: `Show pending permission requests one by one and prompt for a response:
+
+  a / allow   Allow once
+  w / always  Always allow
+  d / deny    Deny
+  s / Enter   Skip
+  q           Quit`,
+	"通知已启动（%d 个会话正在运行）\n": "Notifier started (%d sessions running)\n",
+	"配置已更新":       "Configuration updated",
+	"配置文件 (YAML)": "Config file (YAML)",
+	"配置文件 (YAML)，其中的设置优先于命令行参数": "Config file (YAML); its settings take precedence over flags",
+	"配置管理命令": "Configuration commands",
+	"配置至少需要 on_complete、on_error 或 on_permission 中的一个": "config needs at least one of on_complete, on_error or on_permission",
+	"重发消息失败：%w":      "failed to re-send message: %w",
+	"销毁当前实例":         "Dispose the current instance",
+	"锁定工作树记录失败：%w":   "failed to lock worktree records: %w",
+	"错误":             "Error",
+	"错误：%v\n":        "Error: %v\n",
+	"附件文件路径 (可多次使用)": "File attachment paths (repeatable)",
+	"附加文件，随下一条消息发送":  "Attach a file to the next message",
+	"限制消息数量":         "Limit the number of messages",
+	"限制结果数量":         "Limit the number of results",
+	"隐藏匹配的工具（优先于 --allow，可多次使用）": "Hide matching tools (takes precedence over --allow, repeatable)",
+	"项目":         "Project",
+	"项目管理命令":     "Project commands",
+	"项目：   %s\n": "Project:   %s\n",
+	"预算守护已启动（%d 个会话正在运行）\n": "Budget guard started (%d sessions running)\n",
+	"默认": "default",
+	"默认模型（当前不支持，请使用配置文件设置）": "Default model (not supported yet, set it in the config file)",
+	"📄 %s (行 %d)\n": "📄 %s (line %d)\n",
+}
@@ -0,0 +1,64 @@
+package i18n
+
+// zh 中文目录，键为源码中的英文原文（如 add、session submit 命令）
+var zh = map[string]string{
+	"API request failed: %w":                                 "API 请求失败：%w",
+	"Agent ID for message":                                   "消息使用的代理 ID",
+	"Create a new session and send a message in one command": "创建新会话并发送消息（一条命令完成）",
+	"Create a new session in current directory, optionally initialize it with AGENTS.md, and send a message in one command.": "在当前目录创建新会话，可选用 AGENTS.md 初始化，并在一条命令中发送消息。",
+	`Create a new session in the current directory and send a message to it in one step.
+
+This command combines session creation and message sending into a single operation.
+By default, it uses the current working directory as the session directory and
+generates an automatic title.
+
+Examples:
+  oho add "帮我分析这个项目"
+  oho add "修复登录 bug" --title "Bug 修复"
+  oho add "测试功能" --no-reply
+  oho add "分析日志" --file /var/log/app.log`: `在当前目录创建新会话并发送消息，一步完成。
+
+此命令将创建会话和发送消息合并为一个操作。
+默认使用当前工作目录作为会话目录，并自动生成标题。
+
+示例:
+  oho add "帮我分析这个项目"
+  oho add "修复登录 bug" --title "Bug 修复"
+  oho add "测试功能" --no-reply
+  oho add "分析日志" --file /var/log/app.log`,
+	"Don't wait for AI response":                                            "不等待 AI 响应",
+	"Don't wait for response":                                               "不等待响应",
+	"File attachments (can be specified multiple times)":                    "附件文件（可多次使用）",
+	"Initialize project with AGENTS.md":                                     "使用 AGENTS.md 初始化项目",
+	"Message sent (async): %s\n":                                            "消息已发送（异步）：%s\n",
+	"Message sent successfully":                                             "消息发送成功",
+	"Message sent successfully: %s\n":                                       "消息发送成功：%s\n",
+	"Message sent: %s\n":                                                    "消息已发送：%s\n",
+	"Model ID for initialization":                                           "初始化使用的模型 ID",
+	"Model ID for message":                                                  "消息使用的模型 ID",
+	"New session - %s":                                                      "新会话 - %s",
+	"Parent session ID (for creating sub-session)":                          "父会话 ID（用于创建子会话）",
+	"Provider ID for initialization":                                        "初始化使用的提供商 ID",
+	"Request timeout in seconds (0 uses default 300s)":                      "请求超时秒数（0 表示使用默认的 300 秒）",
+	"Run the task in a new git worktree on its own branch":                  "在新的 git 工作树和分支中执行任务",
+	"Session created: %s\n":                                                 "会话已创建：%s\n",
+	"Session initialized successfully":                                      "会话初始化成功",
+	"Session title":                                                         "会话标题",
+	"Session title (auto-generated if not provided)":                        "会话标题（未提供时自动生成）",
+	"Submit a task by creating a session and sending a message in one step": "提交任务，一步完成创建会话和发送消息",
+	"System prompt":                                                         "系统提示",
+	"Tools list (can be specified multiple times)":                          "工具列表（可多次使用）",
+	"Working directory for the session":                                     "会话的工作目录",
+	"Working directory for the session (default: current directory)":        "会话的工作目录（默认为当前目录）",
+	"Worktree: %s (branch %s)\n":                                            "工作树：%s（分支 %s）\n",
+	"failed to create session: %w":                                          "创建会话失败：%w",
+	"failed to get current directory: %w":                                   "获取当前目录失败：%w",
+	"failed to initialize session: %w":                                      "初始化会话失败：%w",
+	"failed to parse response: %w":                                          "解析响应失败：%w",
+	"failed to read file %s: %w":                                            "读取文件 %s 失败：%w",
+	"failed to read file: %s: %w":                                           "读取文件失败：%s: %w",
+	"failed to send message: %w":                                            "发送消息失败：%w",
+	"file not found: %s":                                                    "文件不存在：%s",
+	"session %s was created, but sending the message failed: %w":            "会话 %s 已创建，但发送消息失败：%w",
+	"when using --init-project, --provider and --model are required":        "使用 --init-project 时必须提供 --provider 和 --model",
+}
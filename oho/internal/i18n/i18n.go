@@ -0,0 +1,120 @@
+package i18n
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// 支持的界面语言
+const (
+	Chinese = "zh"
+	English = "en"
+)
+
+// Languages --lang 支持的取值，用于帮助信息
+const Languages = "zh|en"
+
+// catalogs 各语言的消息目录，键为源码中的原文
+// 源码中的原文大多是中文，少数命令（如 add）是英文，因此两个方向都需要翻译
+var catalogs = map[string]map[string]string{
+	English: en,
+	Chinese: zh,
+}
+
+// language 当前语言，未检测时与源码保持一致使用中文
+var language = Chinese
+
+// Normalize 将 zh_CN.UTF-8、en-US 等语言标识归一化为支持的语言
+// 未设置或为 C/POSIX 时返回空字符串，其他语言回退到英文
+func Normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, ".@"); i >= 0 {
+		lang = lang[:i]
+	}
+	switch {
+	case lang == "" || lang == "c" || lang == "posix":
+		return ""
+	case strings.HasPrefix(lang, "zh"):
+		return Chinese
+	}
+	return English
+}
+
+// Detect 确定界面语言
+// 优先级：--lang > 配置文件 > LC_ALL > LC_MESSAGES > LANG > 中文
+func Detect(flag, configured string) string {
+	for _, lang := range []string{flag, configured, os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")} {
+		if l := Normalize(lang); l != "" {
+			return l
+		}
+	}
+	return Chinese
+}
+
+// SetLanguage 设置当前语言
+func SetLanguage(lang string) {
+	if l := Normalize(lang); l != "" {
+		language = l
+	}
+}
+
+// Language 返回当前语言
+func Language() string {
+	return language
+}
+
+// T 翻译消息，目录中没有对应条目时返回原文
+func T(msgid string) string {
+	if s, ok := catalogs[language][msgid]; ok {
+		return s
+	}
+	return msgid
+}
+
+// Sprintf 翻译格式字符串后格式化
+func Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Printf 翻译格式字符串后输出到 stdout
+func Printf(format string, args ...interface{}) (int, error) {
+	return fmt.Printf(T(format), args...)
+}
+
+// Fprintf 翻译格式字符串后写入 w
+func Fprintf(w io.Writer, format string, args ...interface{}) (int, error) {
+	return fmt.Fprintf(w, T(format), args...)
+}
+
+// Errorf 翻译格式字符串后创建错误，支持 %w
+func Errorf(format string, args ...interface{}) error {
+	return fmt.Errorf(T(format), args...)
+}
+
+// Localize 翻译命令树中的帮助文本和标志说明
+// 命令在包初始化时定义，此时语言尚未确定，因此在执行前统一翻译
+func Localize(cmd *cobra.Command) {
+	cmd.Short = T(cmd.Short)
+	cmd.Long = T(cmd.Long)
+	cmd.Example = T(cmd.Example)
+
+	// 持久标志可能同时出现在两个标志集中，只翻译一次
+	seen := make(map[*pflag.Flag]bool)
+	localizeFlag := func(f *pflag.Flag) {
+		if !seen[f] {
+			seen[f] = true
+			f.Usage = T(f.Usage)
+		}
+	}
+	cmd.Flags().VisitAll(localizeFlag)
+	cmd.PersistentFlags().VisitAll(localizeFlag)
+
+	for _, sub := range cmd.Commands() {
+		Localize(sub)
+	}
+}
@@ -0,0 +1,248 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{"C", ""},
+		{"POSIX", ""},
+		{"C.UTF-8", ""},
+		{"zh_CN.UTF-8", Chinese},
+		{"zh-TW", Chinese},
+		{"en_US.UTF-8", English},
+		{"en", English},
+		{"de_DE@euro", English},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.input); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name       string
+		flag       string
+		configured string
+		env        map[string]string
+		want       string
+	}{
+		{name: "default", want: Chinese},
+		{name: "LANG", env: map[string]string{"LANG": "en_US.UTF-8"}, want: English},
+		{name: "LC_ALL over LANG", env: map[string]string{"LC_ALL": "zh_CN.UTF-8", "LANG": "en_US.UTF-8"}, want: Chinese},
+		{name: "C locale is ignored", env: map[string]string{"LC_ALL": "C", "LANG": "en_US.UTF-8"}, want: English},
+		{name: "config over environment", configured: "zh", env: map[string]string{"LANG": "en_US.UTF-8"}, want: Chinese},
+		{name: "flag over config", flag: "en", configured: "zh", want: English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+				t.Setenv(key, tt.env[key])
+			}
+			if got := Detect(tt.flag, tt.configured); got != tt.want {
+				t.Errorf("Detect(%q, %q) = %q, want %q", tt.flag, tt.configured, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer SetLanguage(Language())
+
+	SetLanguage(English)
+	if got := T("请提供会话 ID 或使用 -s 标志"); got != "please provide a session ID or use the -s flag" {
+		t.Errorf("T() = %q", got)
+	}
+	if got := T("no translation"); got != "no translation" {
+		t.Errorf("Expected untranslated text to be returned as is, got %q", got)
+	}
+	if got := Sprintf("会话 %s 已删除\n", "ses_1"); got != "Session ses_1 deleted\n" {
+		t.Errorf("Sprintf() = %q", got)
+	}
+
+	SetLanguage(Chinese)
+	if got := Sprintf("Session created: %s\n", "ses_1"); got != "会话已创建：ses_1\n" {
+		t.Errorf("Sprintf() = %q", got)
+	}
+
+	// 无法识别的取值不改变当前语言
+	SetLanguage("C")
+	if Language() != Chinese {
+		t.Errorf("Expected language to stay %s, got %s", Chinese, Language())
+	}
+}
+
+func TestErrorfWraps(t *testing.T) {
+	defer SetLanguage(Language())
+	SetLanguage(English)
+
+	inner := os.ErrNotExist
+	err := Errorf("请求失败：%w", inner)
+	if err.Error() != "request failed: "+inner.Error() {
+		t.Errorf("Errorf() = %q", err.Error())
+	}
+	if !strings.Contains(err.Error(), inner.Error()) || unwrap(err) != inner {
+		t.Error("Expected %w to keep wrapping the error")
+	}
+}
+
+func unwrap(err error) error {
+	if u, ok := err.(interface{ Unwrap() error }); ok {
+		return u.Unwrap()
+	}
+	return nil
+}
+
+var verbPattern = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]+)?[a-zA-Z%]`)
+
+// TestCatalogVerbs 翻译必须保留原文中的格式化动词及其顺序
+func TestCatalogVerbs(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for msgid, translation := range catalog {
+			want := verbPattern.FindAllString(msgid, -1)
+			got := verbPattern.FindAllString(translation, -1)
+			if strings.Join(want, " ") != strings.Join(got, " ") {
+				t.Errorf("[%s] %q: verbs %v, translation has %v", lang, msgid, want, got)
+			}
+		}
+	}
+}
+
+// TestCatalogCoverage 源码中传给 i18n 的中文消息都必须有英文翻译
+func TestCatalogCoverage(t *testing.T) {
+	var missing []string
+	for _, msgid := range sourceMessages(t) {
+		if hasHan(msgid) {
+			if _, ok := en[msgid]; !ok {
+				missing = append(missing, msgid)
+			}
+		}
+	}
+
+	sort.Strings(missing)
+	for _, msgid := range missing {
+		t.Errorf("Missing English translation for %q", msgid)
+	}
+}
+
+func hasHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceMessages 收集 cmd 和 internal 中 i18n.T/Sprintf/Printf/Fprintf/Errorf 的消息参数
+func sourceMessages(t *testing.T) []string {
+	t.Helper()
+
+	var messages []string
+	for _, dir := range []string{"../../cmd", ".."} {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+			if filepath.Base(filepath.Dir(path)) == "i18n" {
+				return nil
+			}
+
+			file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+			if err != nil {
+				return err
+			}
+			consts := stringConsts(file)
+
+			ast.Inspect(file, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "i18n" {
+					return true
+				}
+
+				arg := 0
+				switch sel.Sel.Name {
+				case "T", "Sprintf", "Printf", "Errorf":
+				case "Fprintf":
+					arg = 1
+				default:
+					return true
+				}
+				if len(call.Args) <= arg {
+					return true
+				}
+
+				switch v := call.Args[arg].(type) {
+				case *ast.BasicLit:
+					if s, err := strconv.Unquote(v.Value); err == nil {
+						messages = append(messages, s)
+					}
+				case *ast.Ident:
+					if s, ok := consts[v.Name]; ok {
+						messages = append(messages, s)
+					}
+				}
+				return true
+			})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to scan %s: %v", dir, err)
+		}
+	}
+	return messages
+}
+
+// stringConsts 文件中的字符串常量
+func stringConsts(file *ast.File) map[string]string {
+	consts := make(map[string]string)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, name := range vs.Names {
+				if i >= len(vs.Values) {
+					continue
+				}
+				if lit, ok := vs.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					if s, err := strconv.Unquote(lit.Value); err == nil {
+						consts[name.Name] = s
+					}
+				}
+			}
+		}
+	}
+	return consts
+}
@@ -0,0 +1,220 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+)
+
+// language 代码块语法高亮规则
+type language struct {
+	keywords map[string]bool
+	comment  []string // 行注释前缀
+	block    [2]string
+	quotes   string
+}
+
+func words(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+var (
+	cLike = [2]string{"/*", "*/"}
+
+	goLang = &language{
+		keywords: words("break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var nil true false iota"),
+		comment:  []string{"//"},
+		block:    cLike,
+		quotes:   "\"'`",
+	}
+	jsLang = &language{
+		keywords: words("async await break case catch class const continue default delete do else export extends finally for from function if import in instanceof interface let new null of return static super switch this throw true false try type typeof undefined var void while yield"),
+		comment:  []string{"//"},
+		block:    cLike,
+		quotes:   "\"'`",
+	}
+	pyLang = &language{
+		keywords: words("and as assert async await break class continue def del elif else except False finally for from global if import in is lambda None nonlocal not or pass raise return True try while with yield self"),
+		comment:  []string{"#"},
+		quotes:   "\"'",
+	}
+	shLang = &language{
+		keywords: words("if then else elif fi for while until do done case esac in function return export local readonly set unset echo exit source"),
+		comment:  []string{"#"},
+		quotes:   "\"'",
+	}
+	rustLang = &language{
+		keywords: words("as async await break const continue crate else enum extern false fn for if impl in let loop match mod move mut pub ref return self Self static struct super trait true type unsafe use where while"),
+		comment:  []string{"//"},
+		block:    cLike,
+		quotes:   "\"",
+	}
+	cLang = &language{
+		keywords: words("auto break case char class const continue default delete do double else enum extern false final float for if import int long namespace new null nullptr private protected public return short signed sizeof static struct switch template this throw true try typedef union unsigned using var virtual void volatile while"),
+		comment:  []string{"//"},
+		block:    cLike,
+		quotes:   "\"'",
+	}
+	sqlLang = &language{
+		keywords: words("select from where and or not insert into values update set delete create table drop alter index join left right inner outer on group by order having limit as distinct null is in like SELECT FROM WHERE AND OR NOT INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE DROP ALTER INDEX JOIN LEFT RIGHT INNER OUTER ON GROUP BY ORDER HAVING LIMIT AS DISTINCT NULL IS IN LIKE"),
+		comment:  []string{"--"},
+		quotes:   "'\"",
+	}
+	yamlLang = &language{
+		keywords: words("true false null yes no"),
+		comment:  []string{"#"},
+		quotes:   "\"'",
+	}
+	jsonLang = &language{
+		keywords: words("true false null"),
+		quotes:   "\"",
+	}
+)
+
+// languages 代码块信息字符串到高亮规则的映射
+var languages = map[string]*language{
+	"go":         goLang,
+	"golang":     goLang,
+	"js":         jsLang,
+	"javascript": jsLang,
+	"jsx":        jsLang,
+	"ts":         jsLang,
+	"typescript": jsLang,
+	"tsx":        jsLang,
+	"py":         pyLang,
+	"python":     pyLang,
+	"sh":         shLang,
+	"bash":       shLang,
+	"shell":      shLang,
+	"zsh":        shLang,
+	"console":    shLang,
+	"rust":       rustLang,
+	"rs":         rustLang,
+	"c":          cLang,
+	"h":          cLang,
+	"cpp":        cLang,
+	"c++":        cLang,
+	"java":       cLang,
+	"cs":         cLang,
+	"csharp":     cLang,
+	"sql":        sqlLang,
+	"yaml":       yamlLang,
+	"yml":        yamlLang,
+	"json":       jsonLang,
+}
+
+var (
+	keywordStyle = style{color: colorMagenta}
+	stringStyle  = style{color: colorGreen}
+	commentStyle = style{color: colorGray}
+	numberStyle  = style{color: colorYellow}
+)
+
+// highlighter 逐行高亮代码，记录跨行的块注释状态
+type highlighter struct {
+	lang    *language
+	inBlock bool
+}
+
+func newHighlighter(info string) *highlighter {
+	return &highlighter{lang: languages[strings.ToLower(info)]}
+}
+
+// line 返回高亮后的行
+func (h *highlighter) line(s string) string {
+	if h.lang == nil {
+		return s
+	}
+	lang := h.lang
+
+	var out strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		rest := string(runes[i:])
+
+		if h.inBlock {
+			end := strings.Index(rest, lang.block[1])
+			if end < 0 {
+				out.WriteString(paint(commentStyle, rest))
+				return out.String()
+			}
+			end += len(lang.block[1])
+			out.WriteString(paint(commentStyle, rest[:end]))
+			i += len([]rune(rest[:end]))
+			h.inBlock = false
+			continue
+		}
+
+		if lang.block[0] != "" && strings.HasPrefix(rest, lang.block[0]) {
+			h.inBlock = true
+			out.WriteString(paint(commentStyle, lang.block[0]))
+			i += len([]rune(lang.block[0]))
+			continue
+		}
+
+		if lineComment(lang, rest) {
+			out.WriteString(paint(commentStyle, rest))
+			return out.String()
+		}
+
+		r := runes[i]
+		switch {
+		case strings.ContainsRune(lang.quotes, r):
+			j := i + 1
+			for j < len(runes) && runes[j] != r {
+				if runes[j] == '\\' && r != '`' {
+					j++
+				}
+				j++
+			}
+			if j >= len(runes) {
+				j = len(runes) - 1
+			}
+			out.WriteString(paint(stringStyle, string(runes[i:j+1])))
+			i = j + 1
+
+		case unicode.IsDigit(r) && (i == 0 || !isIdentRune(runes[i-1])):
+			j := i
+			for j < len(runes) && (isIdentRune(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			out.WriteString(paint(numberStyle, string(runes[i:j])))
+			i = j
+
+		case isIdentRune(r):
+			j := i
+			for j < len(runes) && isIdentRune(runes[j]) {
+				j++
+			}
+			word := string(runes[i:j])
+			if lang.keywords[word] {
+				out.WriteString(paint(keywordStyle, word))
+			} else {
+				out.WriteString(word)
+			}
+			i = j
+
+		default:
+			out.WriteRune(r)
+			i++
+		}
+	}
+	return out.String()
+}
+
+// lineComment 判断是否为行注释的开始
+func lineComment(lang *language, rest string) bool {
+	for _, prefix := range lang.comment {
+		if strings.HasPrefix(rest, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
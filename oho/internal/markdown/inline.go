@@ -0,0 +1,239 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+)
+
+// span 一段样式相同的行内文本
+type span struct {
+	text  string
+	style style
+}
+
+var (
+	codeStyle = style{color: colorCyan}
+	linkStyle = style{underline: true, color: colorBlue}
+	urlStyle  = style{dim: true}
+)
+
+// parseInline 解析行内标记：**粗体**、*斜体*、~~删除线~~、`代码`、[链接](url)
+func parseInline(s string) []span {
+	var spans []span
+	var buf strings.Builder
+	var cur style
+
+	flush := func() {
+		if buf.Len() > 0 {
+			spans = append(spans, span{text: buf.String(), style: cur})
+			buf.Reset()
+		}
+	}
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		rest := string(runes[i:])
+
+		switch {
+		case r == '\\' && i+1 < len(runes) && strings.ContainsRune("\\`*_~[]()<>#+-.!|", runes[i+1]):
+			buf.WriteRune(runes[i+1])
+			i++
+
+		case r == '`':
+			// 行内代码，内部不再解析
+			n := 1
+			for i+n < len(runes) && runes[i+n] == '`' {
+				n++
+			}
+			fence := strings.Repeat("`", n)
+			end := strings.Index(string(runes[i+n:]), fence)
+			if end < 0 {
+				buf.WriteString(fence)
+				i += n - 1
+				continue
+			}
+			code := []rune(string(runes[i+n:])[:end])
+			flush()
+			spans = append(spans, span{text: strings.TrimSpace(string(code)), style: cur.merge(codeStyle)})
+			i += n + len(code) + n - 1
+
+		case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__"):
+			if !cur.bold && !opens(runes, i, 2) {
+				buf.WriteString(string(runes[i : i+2]))
+				i++
+				continue
+			}
+			flush()
+			cur.bold = !cur.bold
+			i++
+
+		case strings.HasPrefix(rest, "~~"):
+			flush()
+			cur.strike = !cur.strike
+			i++
+
+		case r == '*' || (r == '_' && wordBoundary(runes, i)):
+			if !cur.italic && !opens(runes, i, 1) {
+				buf.WriteRune(r)
+				continue
+			}
+			flush()
+			cur.italic = !cur.italic
+
+		case r == '[':
+			text, url, n, ok := parseLink(runes[i:])
+			if !ok {
+				buf.WriteRune(r)
+				continue
+			}
+			flush()
+			for _, sp := range parseInline(text) {
+				spans = append(spans, span{text: sp.text, style: cur.merge(sp.style).merge(linkStyle)})
+			}
+			if url != text {
+				spans = append(spans, span{text: " (" + url + ")", style: cur.merge(urlStyle)})
+			}
+			i += n - 1
+
+		case r == '<' && (strings.HasPrefix(rest, "<http://") || strings.HasPrefix(rest, "<https://")):
+			end := strings.IndexRune(rest, '>')
+			if end < 0 {
+				buf.WriteRune(r)
+				continue
+			}
+			flush()
+			spans = append(spans, span{text: rest[1:end], style: cur.merge(linkStyle)})
+			i += len([]rune(rest[:end]))
+
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	flush()
+	return spans
+}
+
+// opens 判断 runes[i] 处长度为 n 的标记后是否紧跟非空白字符，可以作为起始标记
+func opens(runes []rune, i, n int) bool {
+	return i+n < len(runes) && !unicode.IsSpace(runes[i+n])
+}
+
+// wordBoundary 下划线只在单词边界处作为强调标记，避免误伤 snake_case
+func wordBoundary(runes []rune, i int) bool {
+	before := i == 0 || !isWordRune(runes[i-1])
+	after := i+1 >= len(runes) || !isWordRune(runes[i+1])
+	return before || after
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// parseLink 解析 [text](url)，返回消耗的字符数
+func parseLink(runes []rune) (string, string, int, bool) {
+	depth := 0
+	for i, r := range runes {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if i+1 >= len(runes) || runes[i+1] != '(' {
+				return "", "", 0, false
+			}
+			end := strings.IndexRune(string(runes[i+2:]), ')')
+			if end < 0 {
+				return "", "", 0, false
+			}
+			url := string(runes[i+2:])[:end]
+			return string(runes[1:i]), strings.TrimSpace(url), i + 2 + len([]rune(url)) + 1, true
+		}
+	}
+	return "", "", 0, false
+}
+
+// plainText 去掉行内标记后的纯文本
+func plainText(spans []span) string {
+	var b strings.Builder
+	for _, sp := range spans {
+		b.WriteString(sp.text)
+	}
+	return b.String()
+}
+
+// token 折行的最小单位
+type token struct {
+	text  string
+	style style
+	space bool // 前面是否有空格
+}
+
+// tokenize 按空白切分，宽字符各自成为一个单位，以便中文可以在任意字符间折行
+func tokenize(spans []span) []token {
+	var tokens []token
+	space := false
+	for _, sp := range spans {
+		var word strings.Builder
+		emit := func() {
+			if word.Len() > 0 {
+				tokens = append(tokens, token{text: word.String(), style: sp.style, space: space})
+				word.Reset()
+				space = false
+			}
+		}
+		for _, r := range sp.text {
+			switch {
+			case unicode.IsSpace(r):
+				emit()
+				space = len(tokens) > 0
+			case runeWidth(r) == 2:
+				emit()
+				tokens = append(tokens, token{text: string(r), style: sp.style, space: space})
+				space = false
+			default:
+				word.WriteRune(r)
+			}
+		}
+		emit()
+	}
+	return tokens
+}
+
+// wrap 将行内文本按宽度折行
+// first 为首行前缀，indent 为后续行前缀，两者的宽度按 prefixWidth 计算
+func wrap(spans []span, base style, width int, first, indent string, prefixWidth int) []string {
+	tokens := tokenize(spans)
+	avail := width - prefixWidth
+	if width <= 0 || avail < 10 {
+		avail = 0
+	}
+
+	var lines []string
+	var line strings.Builder
+	line.WriteString(first)
+	lineWidth := 0
+
+	for _, tok := range tokens {
+		w := Width(tok.text)
+		sep := ""
+		if tok.space && lineWidth > 0 {
+			sep = " "
+		}
+		if avail > 0 && lineWidth > 0 && lineWidth+len(sep)+w > avail {
+			lines = append(lines, line.String())
+			line.Reset()
+			line.WriteString(indent)
+			lineWidth = 0
+			sep = ""
+		}
+		line.WriteString(sep)
+		line.WriteString(paint(base.merge(tok.style), tok.text))
+		lineWidth += len(sep) + w
+	}
+	lines = append(lines, line.String())
+	return lines
+}
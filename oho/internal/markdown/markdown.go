@@ -0,0 +1,412 @@
+// Package markdown 将 Markdown 文本渲染为带 ANSI 样式的终端输出
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// defaultRuleWidth 未指定宽度时分隔线的长度
+const defaultRuleWidth = 40
+
+var (
+	h1Style    = style{bold: true, underline: true, color: colorMagenta}
+	h2Style    = style{bold: true, color: colorCyan}
+	hStyle     = style{bold: true}
+	frameStyle = style{dim: true}
+	quoteStyle = style{italic: true}
+	headStyle  = style{bold: true}
+	markStyle  = style{color: colorYellow}
+)
+
+var (
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	rulePattern    = regexp.MustCompile(`^ {0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	bulletPattern  = regexp.MustCompile(`^(\s*)([-*+])\s+(.*)$`)
+	orderedPattern = regexp.MustCompile(`^(\s*)(\d{1,9})[.)]\s+(.*)$`)
+	fencePattern   = regexp.MustCompile("^\\s*(```+|~~~+)\\s*([^`\\s]*)")
+	taskPattern    = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	tableDelimiter = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+// Render 渲染 Markdown，width 为折行宽度，不大于 0 时不折行
+func Render(src string, width int) string {
+	r := &renderer{width: width}
+	r.render(strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n"))
+	return strings.Join(r.out, "\n")
+}
+
+type renderer struct {
+	width int
+	base  style // 引用块等容器叠加的样式
+	out   []string
+}
+
+// blank 输出块之间的空行，连续空行合并为一行
+func (r *renderer) blank() {
+	if len(r.out) > 0 && r.out[len(r.out)-1] != "" {
+		r.out = append(r.out, "")
+	}
+}
+
+func (r *renderer) emit(lines ...string) {
+	r.out = append(r.out, lines...)
+}
+
+func (r *renderer) render(lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case fencePattern.MatchString(line):
+			i = r.code(lines, i)
+
+		case headingPattern.MatchString(trimmed):
+			r.heading(trimmed)
+			i++
+
+		case rulePattern.MatchString(line):
+			r.blank()
+			width := r.width
+			if width <= 0 {
+				width = defaultRuleWidth
+			}
+			r.emit(paint(frameStyle, strings.Repeat("─", width)))
+			r.blank()
+			i++
+
+		case strings.HasPrefix(trimmed, ">"):
+			i = r.quote(lines, i)
+
+		case isTable(lines, i):
+			i = r.table(lines, i)
+
+		case isListItem(line):
+			i = r.list(lines, i)
+
+		default:
+			i = r.paragraph(lines, i)
+		}
+	}
+	if n := len(r.out); n > 0 && r.out[n-1] == "" {
+		r.out = r.out[:n-1]
+	}
+}
+
+func (r *renderer) heading(line string) {
+	m := headingPattern.FindStringSubmatch(line)
+	s := hStyle
+	switch len(m[1]) {
+	case 1:
+		s = h1Style
+	case 2:
+		s = h2Style
+	}
+	r.blank()
+	r.emit(wrap(parseInline(m[2]), r.base.merge(s), r.width, "", "", 0)...)
+	r.blank()
+}
+
+// code 渲染围栏代码块，代码不折行，外加边框
+func (r *renderer) code(lines []string, start int) int {
+	m := fencePattern.FindStringSubmatch(lines[start])
+	fence, info := m[1], m[2]
+
+	var body []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+			i++
+			break
+		}
+		body = append(body, strings.ReplaceAll(lines[i], "\t", "    "))
+	}
+
+	inner := 0
+	for _, line := range body {
+		if w := Width(line); w > inner {
+			inner = w
+		}
+	}
+	if w := Width(info) + 2; w > inner {
+		inner = w
+	}
+
+	top := "┌" + strings.Repeat("─", inner+2) + "┐"
+	if info != "" {
+		top = "┌─ " + info + " " + strings.Repeat("─", inner-Width(info)-1) + "┐"
+	}
+
+	r.blank()
+	r.emit(paint(frameStyle, top))
+	h := newHighlighter(info)
+	for _, line := range body {
+		pad := strings.Repeat(" ", inner-Width(line))
+		r.emit(paint(frameStyle, "│ ") + h.line(line) + pad + paint(frameStyle, " │"))
+	}
+	r.emit(paint(frameStyle, "└"+strings.Repeat("─", inner+2)+"┘"))
+	r.blank()
+	return i
+}
+
+// quote 渲染引用块，内容按 Markdown 递归渲染
+func (r *renderer) quote(lines []string, start int) int {
+	var body []string
+	i := start
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(trimmed, ">") {
+			break
+		}
+		trimmed = strings.TrimPrefix(trimmed, ">")
+		body = append(body, strings.TrimPrefix(trimmed, " "))
+	}
+
+	width := r.width - 2
+	if r.width <= 0 {
+		width = 0
+	}
+	inner := &renderer{width: width, base: r.base.merge(quoteStyle)}
+	inner.render(body)
+
+	r.blank()
+	for _, line := range inner.out {
+		r.emit(paint(frameStyle, "│ ") + line)
+	}
+	r.blank()
+	return i
+}
+
+func isListItem(line string) bool {
+	return bulletPattern.MatchString(line) || orderedPattern.MatchString(line)
+}
+
+// list 渲染列表，缩进每两个空格算作一级，续行与条目文字对齐
+func (r *renderer) list(lines []string, start int) int {
+	type item struct {
+		level  int
+		marker string
+		text   []string
+	}
+
+	var items []item
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			// 空行后如果仍是列表项则继续
+			if i+1 < len(lines) && isListItem(lines[i+1]) {
+				continue
+			}
+			break
+		}
+
+		var indent, marker, text string
+		if m := bulletPattern.FindStringSubmatch(line); m != nil {
+			indent, marker, text = m[1], "•", m[3]
+		} else if m := orderedPattern.FindStringSubmatch(line); m != nil {
+			indent, marker, text = m[1], m[2]+".", m[3]
+		} else if len(items) > 0 && !fencePattern.MatchString(line) && !headingPattern.MatchString(strings.TrimSpace(line)) {
+			// 续行并入上一条目
+			last := &items[len(items)-1]
+			last.text = append(last.text, strings.TrimSpace(line))
+			continue
+		} else {
+			break
+		}
+
+		if m := taskPattern.FindStringSubmatch(text); m != nil {
+			marker = "☐"
+			if m[1] != " " {
+				marker = "☑"
+			}
+			text = m[2]
+		}
+		level := len(strings.ReplaceAll(indent, "\t", "    ")) / 2
+		items = append(items, item{level: level, marker: marker, text: []string{text}})
+	}
+
+	r.blank()
+	for _, it := range items {
+		pad := strings.Repeat("  ", it.level)
+		first := pad + paint(markStyle, it.marker) + " "
+		prefixWidth := Width(pad) + Width(it.marker) + 1
+		indent := strings.Repeat(" ", prefixWidth)
+		r.emit(wrap(parseInline(strings.Join(it.text, " ")), r.base, r.width, first, indent, prefixWidth)...)
+	}
+	r.blank()
+	return i
+}
+
+// paragraph 合并连续的文本行并折行
+func (r *renderer) paragraph(lines []string, start int) int {
+	var text []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || (i > start && startsBlock(lines, i)) {
+			break
+		}
+		text = append(text, trimmed)
+	}
+
+	r.blank()
+	r.emit(wrap(parseInline(strings.Join(text, " ")), r.base, r.width, "", "", 0)...)
+	r.blank()
+	return i
+}
+
+// startsBlock 判断该行是否开始一个新的块，用于结束段落
+func startsBlock(lines []string, i int) bool {
+	line := lines[i]
+	trimmed := strings.TrimSpace(line)
+	return fencePattern.MatchString(line) ||
+		headingPattern.MatchString(trimmed) ||
+		rulePattern.MatchString(line) ||
+		strings.HasPrefix(trimmed, ">") ||
+		isListItem(line) ||
+		isTable(lines, i)
+}
+
+// isTable 表格需要表头行和分隔行
+func isTable(lines []string, i int) bool {
+	return strings.Contains(lines[i], "|") &&
+		i+1 < len(lines) &&
+		strings.Contains(lines[i+1], "-") &&
+		tableDelimiter.MatchString(lines[i+1])
+}
+
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// table 渲染管道表格，按分隔行的冒号对齐
+func (r *renderer) table(lines []string, start int) int {
+	header := splitRow(lines[start])
+	var aligns []string
+	for _, cell := range splitRow(lines[start+1]) {
+		left := strings.HasPrefix(cell, ":")
+		right := strings.HasSuffix(cell, ":")
+		switch {
+		case left && right:
+			aligns = append(aligns, "center")
+		case right:
+			aligns = append(aligns, "right")
+		default:
+			aligns = append(aligns, "left")
+		}
+	}
+
+	rows := [][]string{header}
+	i := start + 2
+	for ; i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != ""; i++ {
+		rows = append(rows, splitRow(lines[i]))
+	}
+
+	cols := len(header)
+	widths := make([]int, cols)
+	cells := make([][][]span, len(rows))
+	for ri, row := range rows {
+		cells[ri] = make([][]span, cols)
+		for c := 0; c < cols; c++ {
+			if c < len(row) {
+				cells[ri][c] = parseInline(row[c])
+			}
+			if w := Width(plainText(cells[ri][c])); w > widths[c] {
+				widths[c] = w
+			}
+		}
+	}
+
+	border := func(left, mid, right string) string {
+		parts := make([]string, cols)
+		for c, w := range widths {
+			parts[c] = strings.Repeat("─", w+2)
+		}
+		return paint(frameStyle, left+strings.Join(parts, mid)+right)
+	}
+
+	r.blank()
+	r.emit(border("┌", "┬", "┐"))
+	for ri, row := range cells {
+		var b strings.Builder
+		b.WriteString(paint(frameStyle, "│"))
+		for c, spans := range row {
+			base := style{}
+			if ri == 0 {
+				base = headStyle
+			}
+			var text strings.Builder
+			for _, sp := range spans {
+				text.WriteString(paint(base.merge(sp.style), sp.text))
+			}
+			align := "left"
+			if c < len(aligns) {
+				align = aligns[c]
+			}
+			b.WriteString(" " + pad(text.String(), widths[c]-Width(plainText(spans)), align) + " ")
+			b.WriteString(paint(frameStyle, "│"))
+		}
+		r.emit(b.String())
+		if ri == 0 {
+			r.emit(border("├", "┼", "┤"))
+		}
+	}
+	r.emit(border("└", "┴", "┘"))
+	r.blank()
+	return i
+}
+
+// pad 按对齐方式填充 n 个空格
+func pad(s string, n int, align string) string {
+	if n <= 0 {
+		return s
+	}
+	switch align {
+	case "right":
+		return strings.Repeat(" ", n) + s
+	case "center":
+		return strings.Repeat(" ", n/2) + s + strings.Repeat(" ", n-n/2)
+	}
+	return s + strings.Repeat(" ", n)
+}
+
+// Strip 去除文本中的 ANSI 转义序列
+func Strip(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0x1b && i+1 < len(s) && s[i+1] == '[' {
+			j := i + 2
+			for j < len(s) && (s[j] == ';' || (s[j] >= '0' && s[j] <= '9')) {
+				j++
+			}
+			i = j
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
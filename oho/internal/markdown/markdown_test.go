@@ -0,0 +1,158 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseInline(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []span
+	}{
+		{"plain", []span{{text: "plain"}}},
+		{"a **b** c", []span{{text: "a "}, {text: "b", style: style{bold: true}}, {text: " c"}}},
+		{"*i*", []span{{text: "i", style: style{italic: true}}}},
+		{"~~s~~", []span{{text: "s", style: style{strike: true}}}},
+		{"`x * y`", []span{{text: "x * y", style: codeStyle}}},
+		{"snake_case_name", []span{{text: "snake_case_name"}}},
+		{"2 * 3", []span{{text: "2 * 3"}}},
+		{`\*lit\*`, []span{{text: "*lit*"}}},
+		{"[doc](http://x)", []span{{text: "doc", style: linkStyle}, {text: " (http://x)", style: urlStyle}}},
+		{"<https://x>", []span{{text: "https://x", style: linkStyle}}},
+	}
+
+	for _, tt := range tests {
+		got := parseInline(tt.input)
+		if len(got) != len(tt.want) {
+			t.Errorf("parseInline(%q) = %+v, want %+v", tt.input, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("parseInline(%q)[%d] = %+v, want %+v", tt.input, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestWidth(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"abc", 3},
+		{"中文", 4},
+		{"a中b", 4},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := Width(tt.input); got != tt.want {
+			t.Errorf("Width(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  string
+	}{
+		{
+			name:  "paragraph joins lines",
+			input: "hello\nworld",
+			want:  "hello world",
+		},
+		{
+			name:  "paragraph wraps",
+			input: "one two three four",
+			width: 13,
+			want:  "one two three\nfour",
+		},
+		{
+			name:  "CJK wraps between characters",
+			input: "中文字符中文字符",
+			width: 10,
+			want:  "中文字符中\n文字符",
+		},
+		{
+			name:  "heading",
+			input: "# Title\ntext",
+			want:  "Title\n\ntext",
+		},
+		{
+			name:  "list with hanging indent",
+			input: "- alpha beta gamma\n  - nested\n1. first\n- [x] done",
+			width: 14,
+			want:  "• alpha beta\n  gamma\n  • nested\n1. first\n☑ done",
+		},
+		{
+			name:  "code block",
+			input: "```go\nx := 1\n```",
+			want:  "┌─ go ───┐\n│ x := 1 │\n└────────┘",
+		},
+		{
+			name:  "table",
+			input: "| a | b |\n|---|--:|\n| 1 | 中文 |",
+			want:  "┌───┬──────┐\n│ a │    b │\n├───┼──────┤\n│ 1 │ 中文 │\n└───┴──────┘",
+		},
+		{
+			name:  "quote",
+			input: "> quoted\n> text",
+			want:  "│ quoted text",
+		},
+		{
+			name:  "rule",
+			input: "a\n\n---\n\nb",
+			width: 5,
+			want:  "a\n\n─────\n\nb",
+		},
+		{
+			name:  "blank lines collapse",
+			input: "a\n\n\n\nb\n",
+			want:  "a\n\nb",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Strip(Render(tt.input, tt.width))
+			if got != tt.want {
+				t.Errorf("Render(%q) =\n%s\nwant\n%s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderStyles(t *testing.T) {
+	out := Render("# Title\n\n**bold**", 0)
+	if !strings.Contains(out, h1Style.sgr()+"Title"+reset) {
+		t.Errorf("Expected styled heading, got %q", out)
+	}
+	if !strings.Contains(out, "\x1b[1mbold"+reset) {
+		t.Errorf("Expected bold text, got %q", out)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	h := newHighlighter("go")
+	got := h.line(`return "s" // done`)
+	want := paint(keywordStyle, "return") + " " + paint(stringStyle, `"s"`) + " " + paint(commentStyle, "// done")
+	if got != want {
+		t.Errorf("line() = %q, want %q", got, want)
+	}
+
+	// 块注释跨行
+	h.line("/* start")
+	if got := h.line("end */ x"); got != paint(commentStyle, "end */")+" x" {
+		t.Errorf("line() = %q", got)
+	}
+
+	// 未知语言不高亮
+	if got := newHighlighter("brainfuck").line("return 1"); got != "return 1" {
+		t.Errorf("Expected no highlighting, got %q", got)
+	}
+}
@@ -0,0 +1,117 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ANSI 前景色
+const (
+	colorNone    = 0
+	colorRed     = 31
+	colorGreen   = 32
+	colorYellow  = 33
+	colorBlue    = 34
+	colorMagenta = 35
+	colorCyan    = 36
+	colorGray    = 90
+)
+
+const reset = "\x1b[0m"
+
+// style 文本样式
+type style struct {
+	bold      bool
+	italic    bool
+	underline bool
+	strike    bool
+	dim       bool
+	color     int
+}
+
+// merge 叠加样式，s 中设置的颜色优先
+func (base style) merge(s style) style {
+	out := style{
+		bold:      base.bold || s.bold,
+		italic:    base.italic || s.italic,
+		underline: base.underline || s.underline,
+		strike:    base.strike || s.strike,
+		dim:       base.dim || s.dim,
+		color:     base.color,
+	}
+	if s.color != colorNone {
+		out.color = s.color
+	}
+	return out
+}
+
+func (s style) plain() bool {
+	return s == style{}
+}
+
+// sgr 返回样式对应的 ANSI 转义序列
+func (s style) sgr() string {
+	if s.plain() {
+		return ""
+	}
+	var codes []string
+	if s.bold {
+		codes = append(codes, "1")
+	}
+	if s.dim {
+		codes = append(codes, "2")
+	}
+	if s.italic {
+		codes = append(codes, "3")
+	}
+	if s.underline {
+		codes = append(codes, "4")
+	}
+	if s.strike {
+		codes = append(codes, "9")
+	}
+	if s.color != colorNone {
+		codes = append(codes, strconv.Itoa(s.color))
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
+
+// paint 以指定样式输出文本
+func paint(s style, text string) string {
+	if s.plain() || text == "" {
+		return text
+	}
+	return s.sgr() + text + reset
+}
+
+// runeWidth 字符在终端中占用的列数，东亚宽字符和 emoji 占两列
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || r < 32 || (r >= 0x7f && r < 0xa0):
+		return 0
+	case r >= 0x300 && r <= 0x36f, r >= 0x200b && r <= 0x200f, r == 0xfe0f:
+		// 组合字符和零宽字符
+		return 0
+	case r >= 0x1100 && r <= 0x115f,
+		r >= 0x2e80 && r <= 0xa4cf && r != 0x303f,
+		r >= 0xac00 && r <= 0xd7a3,
+		r >= 0xf900 && r <= 0xfaff,
+		r >= 0xfe30 && r <= 0xfe4f,
+		r >= 0xff00 && r <= 0xff60,
+		r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x1f300 && r <= 0x1f64f,
+		r >= 0x1f900 && r <= 0x1f9ff,
+		r >= 0x20000 && r <= 0x3fffd:
+		return 2
+	}
+	return 1
+}
+
+// Width 字符串在终端中占用的列数
+func Width(s string) int {
+	w := 0
+	for _, r := range s {
+		w += runeWidth(r)
+	}
+	return w
+}
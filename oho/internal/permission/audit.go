@@ -0,0 +1,72 @@
+package permission
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AuditEntry 审计日志中的一条记录
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"requestID"`
+	SessionID string    `json:"sessionID"`
+	Tool      string    `json:"tool"`
+	Command   string    `json:"command,omitempty"`
+	Paths     []string  `json:"paths,omitempty"`
+	Directory string    `json:"directory,omitempty"`
+	Decision
+	DryRun bool   `json:"dryRun,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AuditLog 以 JSON Lines 格式追加写入审计记录
+type AuditLog struct {
+	mu sync.Mutex
+	w  io.Writer
+	f  *os.File
+}
+
+// NewAuditLog 写入到 w
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{w: w}
+}
+
+// OpenAuditLog 以追加方式打开审计日志文件
+func OpenAuditLog(file string) (*AuditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLog{w: f, f: f}, nil
+}
+
+// Record 写入一条记录
+func (l *AuditLog) Record(entry AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(append(data, '\n'))
+	return err
+}
+
+// Close 关闭日志文件
+func (l *AuditLog) Close() error {
+	if l.f != nil {
+		return l.f.Close()
+	}
+	return nil
+}
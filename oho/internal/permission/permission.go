@@ -0,0 +1,268 @@
+package permission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// OpenCode Server 接受的权限响应
+const (
+	ResponseOnce   = "once"
+	ResponseAlways = "always"
+	ResponseReject = "reject"
+)
+
+// Request 待处理的权限请求
+type Request struct {
+	ID        string                 `json:"id"`
+	SessionID string                 `json:"sessionID"`
+	Tool      string                 `json:"tool"`
+	Title     string                 `json:"title,omitempty"`
+	Patterns  []string               `json:"patterns,omitempty"`
+	Command   string                 `json:"command,omitempty"`
+	Paths     []string               `json:"paths,omitempty"`
+	MessageID string                 `json:"messageID,omitempty"`
+	CallID    string                 `json:"callID,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Created   int64                  `json:"created,omitempty"`
+}
+
+// wireRequest 兼容 permission.updated（旧版）与 permission.asked（新版）两种格式
+type wireRequest struct {
+	ID         string                 `json:"id"`
+	SessionID  string                 `json:"sessionID"`
+	Type       string                 `json:"type"`
+	Permission string                 `json:"permission"`
+	Pattern    json.RawMessage        `json:"pattern"`
+	Patterns   []string               `json:"patterns"`
+	Title      string                 `json:"title"`
+	MessageID  string                 `json:"messageID"`
+	CallID     string                 `json:"callID"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	Tool       *struct {
+		MessageID string `json:"messageID"`
+		CallID    string `json:"callID"`
+	} `json:"tool"`
+	Time struct {
+		Created int64 `json:"created"`
+	} `json:"time"`
+}
+
+// pathKeys metadata 中表示文件路径的键
+var pathKeys = []string{"filePath", "filepath", "path"}
+
+func (w wireRequest) request() Request {
+	r := Request{
+		ID:        w.ID,
+		SessionID: w.SessionID,
+		Tool:      w.Permission,
+		Title:     w.Title,
+		Patterns:  w.Patterns,
+		MessageID: w.MessageID,
+		CallID:    w.CallID,
+		Metadata:  w.Metadata,
+		Created:   w.Time.Created,
+	}
+	if r.Tool == "" {
+		r.Tool = w.Type
+	}
+	if w.Tool != nil {
+		r.MessageID = w.Tool.MessageID
+		r.CallID = w.Tool.CallID
+	}
+
+	// pattern 可能是字符串或字符串数组
+	if len(w.Pattern) > 0 && len(r.Patterns) == 0 {
+		var single string
+		if err := json.Unmarshal(w.Pattern, &single); err == nil && single != "" {
+			r.Patterns = []string{single}
+		} else {
+			_ = json.Unmarshal(w.Pattern, &r.Patterns)
+		}
+	}
+
+	if cmd, ok := w.Metadata["command"].(string); ok {
+		r.Command = cmd
+	}
+	for _, key := range pathKeys {
+		if p, ok := w.Metadata[key].(string); ok && p != "" {
+			r.Paths = append(r.Paths, p)
+		}
+	}
+	// 多个 pattern 是互相独立的命令，不能拼接成一条命令，由策略逐个匹配
+	if r.Command == "" && r.Tool == "bash" && len(r.Patterns) == 1 {
+		r.Command = r.Patterns[0]
+	}
+	if len(r.Paths) == 0 && r.Tool != "bash" {
+		r.Paths = append(r.Paths, r.Patterns...)
+	}
+	return r
+}
+
+// Summary 返回请求的简短描述：命令、路径或标题
+func (r Request) Summary() string {
+	switch {
+	case r.Command != "":
+		return r.Command
+	case len(r.Paths) > 0:
+		return strings.Join(r.Paths, ", ")
+	case r.Title != "":
+		return r.Title
+	}
+	return strings.Join(r.Patterns, ", ")
+}
+
+// FromEvent 解析权限请求事件，非请求事件返回 false
+func FromEvent(e types.Event) (Request, bool) {
+	if e.Type != event.TypePermissionUpdated && e.Type != event.TypePermissionAsked {
+		return Request{}, false
+	}
+	var w wireRequest
+	if err := json.Unmarshal(e.Properties, &w); err != nil || w.ID == "" {
+		return Request{}, false
+	}
+	return w.request(), true
+}
+
+// RepliedID 解析权限已响应事件，返回被响应的请求 ID
+func RepliedID(e types.Event) (string, bool) {
+	if e.Type != event.TypePermissionReplied {
+		return "", false
+	}
+	var replied struct {
+		PermissionID string `json:"permissionID"`
+		RequestID    string `json:"requestID"`
+	}
+	if err := json.Unmarshal(e.Properties, &replied); err != nil {
+		return "", false
+	}
+	if replied.PermissionID != "" {
+		return replied.PermissionID, true
+	}
+	return replied.RequestID, replied.RequestID != ""
+}
+
+// Inbox 根据事件维护待处理的权限请求
+type Inbox struct {
+	mu      sync.Mutex
+	pending map[string]Request
+}
+
+// NewInbox 创建空的收件箱
+func NewInbox() *Inbox {
+	return &Inbox{pending: make(map[string]Request)}
+}
+
+// Add 添加请求
+func (b *Inbox) Add(r Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending[r.ID] = r
+}
+
+// Remove 移除请求
+func (b *Inbox) Remove(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.pending, id)
+}
+
+// Apply 应用一个事件；新请求返回该请求和 true
+func (b *Inbox) Apply(e types.Event) (Request, bool) {
+	if r, ok := FromEvent(e); ok {
+		b.Add(r)
+		return r, true
+	}
+	if id, ok := RepliedID(e); ok {
+		b.Remove(id)
+	}
+	return Request{}, false
+}
+
+// Get 按 ID 获取请求
+func (b *Inbox) Get(id string) (Request, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r, ok := b.pending[id]
+	return r, ok
+}
+
+// Pending 返回待处理请求，sessionID 非空时只返回该会话的请求，按创建时间排序
+func (b *Inbox) Pending(sessionID string) []Request {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var result []Request
+	for _, r := range b.pending {
+		if sessionID == "" || r.SessionID == sessionID {
+			result = append(result, r)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Created != result[j].Created {
+			return result[i].Created < result[j].Created
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Fetch 从服务器获取当前待处理的请求（需要服务器支持 GET /permission）
+func Fetch(ctx context.Context, c client.ClientInterface) ([]Request, error) {
+	resp, err := c.Get(ctx, "/permission")
+	if err != nil {
+		return nil, err
+	}
+
+	var wire []wireRequest
+	if err := json.Unmarshal(resp, &wire); err != nil {
+		return nil, i18n.Errorf("解析权限请求失败：%w", err)
+	}
+
+	requests := make([]Request, 0, len(wire))
+	for _, w := range wire {
+		requests = append(requests, w.request())
+	}
+	return requests, nil
+}
+
+// Respond 响应权限请求
+func Respond(ctx context.Context, c client.ClientInterface, r Request, response string) error {
+	resp, err := c.Post(ctx, fmt.Sprintf("/session/%s/permissions/%s", r.SessionID, r.ID), map[string]interface{}{
+		"response": response,
+	})
+	if err != nil {
+		return err
+	}
+
+	var success bool
+	if err := json.Unmarshal(resp, &success); err != nil {
+		return i18n.Errorf("解析响应失败：%w", err)
+	}
+	if !success {
+		return i18n.Errorf("服务器拒绝了权限响应 %s", r.ID)
+	}
+	return nil
+}
+
+// ParseResponse 将用户输入转换为服务器接受的响应值
+func ParseResponse(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "a", "allow", "once", "y", "yes":
+		return ResponseOnce, nil
+	case "w", "always":
+		return ResponseAlways, nil
+	case "d", "deny", "reject", "n", "no":
+		return ResponseReject, nil
+	}
+	return "", i18n.Errorf("无效的响应：%s（可选 allow/always/deny）", s)
+}
@@ -0,0 +1,171 @@
+package permission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+func TestFromEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		event types.Event
+		want  Request
+		ok    bool
+	}{
+		{
+			name: "permission.updated bash",
+			event: types.Event{Type: "permission.updated", Properties: json.RawMessage(`{
+				"id":"per_1","type":"bash","pattern":"git push","sessionID":"ses_1","messageID":"msg_1","callID":"call_1",
+				"title":"git push","metadata":{"command":"git push origin main"},"time":{"created":100}}`)},
+			want: Request{
+				ID: "per_1", SessionID: "ses_1", Tool: "bash", Title: "git push", Patterns: []string{"git push"},
+				Command: "git push origin main", MessageID: "msg_1", CallID: "call_1",
+				Metadata: map[string]interface{}{"command": "git push origin main"}, Created: 100,
+			},
+			ok: true,
+		},
+		{
+			name: "permission.asked edit",
+			event: types.Event{Type: "permission.asked", Properties: json.RawMessage(`{
+				"id":"per_2","sessionID":"ses_1","permission":"edit","patterns":["src/main.go"],
+				"metadata":{"filepath":"/repo/src/main.go"},"tool":{"messageID":"msg_2","callID":"call_2"}}`)},
+			want: Request{
+				ID: "per_2", SessionID: "ses_1", Tool: "edit", Patterns: []string{"src/main.go"},
+				Paths: []string{"/repo/src/main.go"}, MessageID: "msg_2", CallID: "call_2",
+				Metadata: map[string]interface{}{"filepath": "/repo/src/main.go"},
+			},
+			ok: true,
+		},
+		{
+			name:  "pattern array without metadata",
+			event: types.Event{Type: "permission.updated", Properties: json.RawMessage(`{"id":"per_3","type":"external_directory","pattern":["/tmp/*"],"sessionID":"ses_2"}`)},
+			want:  Request{ID: "per_3", SessionID: "ses_2", Tool: "external_directory", Patterns: []string{"/tmp/*"}, Paths: []string{"/tmp/*"}},
+			ok:    true,
+		},
+		{
+			name:  "other event",
+			event: types.Event{Type: "session.idle", Properties: json.RawMessage(`{"sessionID":"ses_1"}`)},
+			ok:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FromEvent(tt.event)
+			if ok != tt.ok {
+				t.Fatalf("FromEvent() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromEvent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInboxApply(t *testing.T) {
+	inbox := NewInbox()
+
+	inbox.Apply(types.Event{Type: "permission.updated", Properties: json.RawMessage(`{"id":"per_2","type":"bash","sessionID":"ses_1","time":{"created":200}}`)})
+	inbox.Apply(types.Event{Type: "permission.asked", Properties: json.RawMessage(`{"id":"per_1","permission":"edit","sessionID":"ses_2","time":{"created":100}}`)})
+	inbox.Apply(types.Event{Type: "permission.asked", Properties: json.RawMessage(`{"id":"per_3","permission":"read","sessionID":"ses_1","time":{"created":300}}`)})
+
+	pending := inbox.Pending("")
+	if len(pending) != 3 || pending[0].ID != "per_1" || pending[2].ID != "per_3" {
+		t.Fatalf("Unexpected pending order: %+v", pending)
+	}
+	if got := inbox.Pending("ses_1"); len(got) != 2 {
+		t.Errorf("Expected 2 requests for ses_1, got %d", len(got))
+	}
+
+	inbox.Apply(types.Event{Type: "permission.replied", Properties: json.RawMessage(`{"sessionID":"ses_1","permissionID":"per_2","response":"once"}`)})
+	inbox.Apply(types.Event{Type: "permission.replied", Properties: json.RawMessage(`{"sessionID":"ses_1","requestID":"per_3","reply":"reject"}`)})
+
+	if _, ok := inbox.Get("per_2"); ok {
+		t.Error("Expected per_2 to be removed after reply")
+	}
+	if got := inbox.Pending(""); len(got) != 1 || got[0].ID != "per_1" {
+		t.Errorf("Expected only per_1 pending, got %+v", got)
+	}
+}
+
+func TestRespond(t *testing.T) {
+	var gotPath string
+	var gotBody interface{}
+	mock := &client.MockClient{
+		PostFunc: func(ctx context.Context, path string, body interface{}) ([]byte, error) {
+			gotPath, gotBody = path, body
+			return []byte("true"), nil
+		},
+	}
+
+	err := Respond(context.Background(), mock, Request{ID: "per_1", SessionID: "ses_1"}, ResponseAlways)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotPath != "/session/ses_1/permissions/per_1" {
+		t.Errorf("Unexpected path: %s", gotPath)
+	}
+	if body := gotBody.(map[string]interface{}); body["response"] != "always" {
+		t.Errorf("Unexpected body: %v", body)
+	}
+
+	mock.PostFunc = func(ctx context.Context, path string, body interface{}) ([]byte, error) {
+		return []byte("false"), nil
+	}
+	if err := Respond(context.Background(), mock, Request{ID: "per_1", SessionID: "ses_1"}, ResponseOnce); err == nil {
+		t.Error("Expected error when server returns false")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			if path != "/permission" {
+				return nil, errors.New("unexpected path")
+			}
+			return []byte(`[{"id":"per_1","sessionID":"ses_1","permission":"bash","patterns":["ls"]}]`), nil
+		},
+	}
+
+	requests, err := Fetch(context.Background(), mock)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(requests) != 1 || requests[0].Command != "ls" {
+		t.Errorf("Unexpected requests: %+v", requests)
+	}
+}
+
+func TestParseResponse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "allow", want: ResponseOnce},
+		{input: "A", want: ResponseOnce},
+		{input: "always", want: ResponseAlways},
+		{input: "w", want: ResponseAlways},
+		{input: "deny", want: ResponseReject},
+		{input: "reject", want: ResponseReject},
+		{input: "maybe", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseResponse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseResponse(%q) error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseResponse(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
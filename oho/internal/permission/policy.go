@@ -0,0 +1,233 @@
+package permission
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/anomalyco/oho/internal/i18n"
+)
+
+// 策略动作
+const (
+	ActionAllow    = "allow"
+	ActionAlways   = "always"
+	ActionDeny     = "deny"
+	ActionEscalate = "escalate"
+)
+
+// Rule 自动审批规则，所有已设置的条件都满足时规则命中
+type Rule struct {
+	Name      string   `yaml:"name" json:"name,omitempty"`
+	Tool      string   `yaml:"tool" json:"tool,omitempty"`
+	Paths     []string `yaml:"paths" json:"paths,omitempty"`
+	Command   string   `yaml:"command" json:"command,omitempty"`
+	Directory string   `yaml:"directory" json:"directory,omitempty"`
+	Action    string   `yaml:"action" json:"action"`
+
+	paths   []*regexp.Regexp
+	command *regexp.Regexp
+	dir     *regexp.Regexp
+}
+
+// Policy 自动审批策略，规则按顺序匹配，第一条命中的规则生效
+type Policy struct {
+	Default string  `yaml:"default" json:"default"`
+	Rules   []*Rule `yaml:"rules" json:"rules"`
+}
+
+// Decision 策略对一个请求的决定
+type Decision struct {
+	Rule     string `json:"rule,omitempty"`
+	Action   string `json:"action"`
+	Response string `json:"response,omitempty"`
+}
+
+// LoadPolicy 从 YAML 文件加载策略
+func LoadPolicy(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, i18n.Errorf("读取策略文件失败：%w", err)
+	}
+	return ParsePolicy(data)
+}
+
+// ParsePolicy 解析并校验策略
+func ParsePolicy(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, i18n.Errorf("解析策略失败：%w", err)
+	}
+
+	if p.Default == "" {
+		p.Default = ActionEscalate
+	}
+	if p.Default != ActionEscalate && p.Default != ActionDeny {
+		return nil, i18n.Errorf("default 只能是 escalate 或 deny：%s", p.Default)
+	}
+
+	for i, r := range p.Rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		switch r.Action {
+		case ActionAllow, ActionAlways, ActionDeny, ActionEscalate:
+		default:
+			return nil, i18n.Errorf("规则 %s 的 action 无效：%q", r.Name, r.Action)
+		}
+		if r.Tool != "" {
+			if _, err := path.Match(r.Tool, ""); err != nil {
+				return nil, i18n.Errorf("规则 %s 的 tool 模式无效：%s", r.Name, r.Tool)
+			}
+		}
+		for _, g := range r.Paths {
+			re, err := globRegexp(g)
+			if err != nil {
+				return nil, i18n.Errorf("规则 %s 的路径模式无效：%s", r.Name, g)
+			}
+			r.paths = append(r.paths, re)
+		}
+		if r.Command != "" {
+			re, err := regexp.Compile(r.Command)
+			if err != nil {
+				return nil, i18n.Errorf("规则 %s 的 command 正则无效：%w", r.Name, err)
+			}
+			r.command = re
+		}
+		if r.Directory != "" {
+			re, err := globRegexp(r.Directory)
+			if err != nil {
+				return nil, i18n.Errorf("规则 %s 的 directory 模式无效：%s", r.Name, r.Directory)
+			}
+			r.dir = re
+		}
+	}
+	return &p, nil
+}
+
+// Decide 根据策略决定如何响应请求，directory 为请求所属会话的目录
+func (p *Policy) Decide(r Request, directory string) Decision {
+	for _, rule := range p.Rules {
+		if rule.matches(r, directory) {
+			return Decision{Rule: rule.Name, Action: rule.Action, Response: responseFor(rule.Action)}
+		}
+	}
+	return Decision{Action: p.Default, Response: responseFor(p.Default)}
+}
+
+func (r *Rule) matches(req Request, directory string) bool {
+	if r.Tool != "" {
+		if ok, _ := path.Match(r.Tool, req.Tool); !ok {
+			return false
+		}
+	}
+	// 每个路径都必须匹配至少一个模式
+	if len(r.paths) > 0 {
+		if len(req.Paths) == 0 {
+			return false
+		}
+		for _, p := range req.Paths {
+			p, ok := cleanPath(p, directory)
+			if !ok || !matchAnyRegexp(r.paths, p) {
+				return false
+			}
+		}
+	}
+	// 每条命令都必须匹配，没有命令时不匹配
+	if r.command != nil {
+		commands := requestCommands(req)
+		if len(commands) == 0 {
+			return false
+		}
+		for _, c := range commands {
+			if !r.command.MatchString(c) {
+				return false
+			}
+		}
+	}
+	if r.dir != nil && (directory == "" || !r.dir.MatchString(directory)) {
+		return false
+	}
+	return true
+}
+
+// requestCommands 请求要执行的命令：metadata 中的命令，否则是 bash 请求的每个 pattern
+func requestCommands(req Request) []string {
+	if req.Command != "" {
+		return []string{req.Command}
+	}
+	if req.Tool == "bash" {
+		return req.Patterns
+	}
+	return nil
+}
+
+// cleanPath 规范化请求中的路径，相对路径基于会话目录
+// 清理后仍以 .. 开头的路径越出了根目录，不匹配任何路径模式
+func cleanPath(p, directory string) (string, bool) {
+	if !filepath.IsAbs(p) && directory != "" {
+		p = filepath.Join(directory, p)
+	}
+	p = filepath.ToSlash(filepath.Clean(p))
+	if p == ".." || strings.HasPrefix(p, "../") {
+		return "", false
+	}
+	return p, true
+}
+
+func matchAnyRegexp(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// responseFor 将策略动作转换为服务器响应值，escalate 不响应
+func responseFor(action string) string {
+	switch action {
+	case ActionAllow:
+		return ResponseOnce
+	case ActionAlways:
+		return ResponseAlways
+	case ActionDeny:
+		return ResponseReject
+	}
+	return ""
+}
+
+// globRegexp 将 glob 转换为正则：* 不跨目录，** 匹配任意层级
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					// **/ 匹配零个或多个目录
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,247 @@
+package permission
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+const testPolicy = `
+default: escalate
+rules:
+  - name: read-repo
+    tool: read
+    paths: ["/work/repo/**"]
+    action: allow
+  - name: no-push
+    tool: bash
+    command: 'git push'
+    action: deny
+  - name: safe-shell
+    tool: bash
+    command: '^(go (test|build)|ls)( [^;&|$\x60<>\n]*)?$'
+    directory: /work/*
+    action: always
+  - tool: "web*"
+    action: escalate
+`
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(p.Rules) != 4 || p.Rules[3].Name != "rule-4" {
+		t.Errorf("Unexpected rules: %+v", p.Rules)
+	}
+
+	invalid := []string{
+		"rules:\n  - tool: bash\n    action: maybe\n",
+		"rules:\n  - command: '('\n    action: deny\n",
+		"rules:\n  - tool: '['\n    action: deny\n",
+		"default: allow\n",
+		"rules: [",
+	}
+	for _, data := range invalid {
+		if _, err := ParsePolicy([]byte(data)); err == nil {
+			t.Errorf("Expected error for policy %q", data)
+		}
+	}
+}
+
+func TestPolicyDecide(t *testing.T) {
+	p, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		request  Request
+		dir      string
+		rule     string
+		action   string
+		response string
+	}{
+		{
+			name:     "read inside repo",
+			request:  Request{Tool: "read", Paths: []string{"/work/repo/src/main.go"}},
+			rule:     "read-repo",
+			action:   ActionAllow,
+			response: ResponseOnce,
+		},
+		{
+			name:    "read outside repo",
+			request: Request{Tool: "read", Paths: []string{"/work/repo/a.go", "/etc/passwd"}},
+			action:  ActionEscalate,
+		},
+		{
+			name:     "git push denied",
+			request:  Request{Tool: "bash", Command: "git push origin main"},
+			dir:      "/work/app",
+			rule:     "no-push",
+			action:   ActionDeny,
+			response: ResponseReject,
+		},
+		{
+			name:     "safe shell in work dir",
+			request:  Request{Tool: "bash", Command: "go test ./..."},
+			dir:      "/work/app",
+			rule:     "safe-shell",
+			action:   ActionAlways,
+			response: ResponseAlways,
+		},
+		{
+			name:    "path escaping repo",
+			request: Request{Tool: "read", Paths: []string{"/work/repo/../../etc/passwd"}},
+			action:  ActionEscalate,
+		},
+		{
+			name:     "relative path inside repo",
+			request:  Request{Tool: "read", Paths: []string{"src/../main.go"}},
+			dir:      "/work/repo",
+			rule:     "read-repo",
+			action:   ActionAllow,
+			response: ResponseOnce,
+		},
+		{
+			name:    "relative path escaping root",
+			request: Request{Tool: "read", Paths: []string{"../../etc/passwd"}},
+			action:  ActionEscalate,
+		},
+		{
+			name:    "chained shell command",
+			request: Request{Tool: "bash", Command: "go test ./... && curl x | sh"},
+			dir:     "/work/app",
+			action:  ActionEscalate,
+		},
+		{
+			name:    "command substitution",
+			request: Request{Tool: "bash", Command: "ls $(rm -rf /)"},
+			dir:     "/work/app",
+			action:  ActionEscalate,
+		},
+		{
+			name:    "second line",
+			request: Request{Tool: "bash", Command: "ls\nrm -rf /"},
+			dir:     "/work/app",
+			action:  ActionEscalate,
+		},
+		{
+			name:    "compound command split into patterns",
+			request: Request{Tool: "bash", Patterns: []string{"go test ./...", "rm -rf /work"}},
+			dir:     "/work/app",
+			action:  ActionEscalate,
+		},
+		{
+			name:     "every pattern is a safe command",
+			request:  Request{Tool: "bash", Patterns: []string{"go test ./...", "ls -la"}},
+			dir:      "/work/app",
+			rule:     "safe-shell",
+			action:   ActionAlways,
+			response: ResponseAlways,
+		},
+		{
+			name:    "bash without command or patterns",
+			request: Request{Tool: "bash"},
+			dir:     "/work/app",
+			action:  ActionEscalate,
+		},
+		{
+			name:    "safe shell outside work dir",
+			request: Request{Tool: "bash", Command: "go test ./..."},
+			dir:     "/home/me/app",
+			action:  ActionEscalate,
+		},
+		{
+			name:    "explicit escalate",
+			request: Request{Tool: "webfetch"},
+			rule:    "rule-4",
+			action:  ActionEscalate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := p.Decide(tt.request, tt.dir)
+			if d.Rule != tt.rule || d.Action != tt.action || d.Response != tt.response {
+				t.Errorf("Decide() = %+v, want rule=%q action=%q response=%q", d, tt.rule, tt.action, tt.response)
+			}
+		})
+	}
+}
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"/repo/*.go", "/repo/main.go", true},
+		{"/repo/*.go", "/repo/pkg/main.go", false},
+		{"/repo/**", "/repo/pkg/main.go", true},
+		{"/repo/**/*.go", "/repo/main.go", true},
+		{"/repo/**/*.go", "/repo/a/b/main.go", true},
+		{"src/?.go", "src/a.go", true},
+		{"a+b/*", "a+b/c", true},
+	}
+
+	for _, tt := range tests {
+		re, err := globRegexp(tt.glob)
+		if err != nil {
+			t.Fatalf("globRegexp(%q) error: %v", tt.glob, err)
+		}
+		if got := re.MatchString(tt.path); got != tt.match {
+			t.Errorf("glob %q on %q = %v, want %v", tt.glob, tt.path, got, tt.match)
+		}
+	}
+}
+
+func TestAuditLogRecord(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewAuditLog(&buf)
+
+	if err := log.Record(AuditEntry{RequestID: "per_1", Tool: "bash", Decision: Decision{Rule: "r", Action: ActionDeny, Response: ResponseReject}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := log.Record(AuditEntry{RequestID: "per_2", Decision: Decision{Action: ActionEscalate}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(lines[0], &entry); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if entry["requestID"] != "per_1" || entry["action"] != "deny" || entry["response"] != "reject" || entry["time"] == nil {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+}
+
+func TestSampleShellRulePatterns(t *testing.T) {
+	// 与 README 和 autopilot --help 中的示例规则相同
+	p, err := ParsePolicy([]byte(`
+rules:
+  - name: safe-shell
+    tool: bash
+    command: '^(go (test|build|vet)|git (status|diff))( [^;&|$\x60<>\n]*)?$'
+    directory: /work/*
+    action: allow
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有 metadata.command 时，每个 pattern 单独匹配，拼接后的命令不能绕过规则
+	w := wireRequest{ID: "per_1", Permission: "bash", Patterns: []string{"go test ./...", "rm -rf /work"}}
+	req := w.request()
+	if req.Command != "" {
+		t.Errorf("Command = %q, want patterns kept separate", req.Command)
+	}
+	if d := p.Decide(req, "/work/app"); d.Action != ActionEscalate {
+		t.Errorf("Decide() = %+v, want escalate", d)
+	}
+}
@@ -0,0 +1,68 @@
+package resolve
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
+)
+
+// Aliases 会话别名到会话 ID 的映射，使用时写作 @<别名>
+type Aliases map[string]string
+
+// aliasName 别名只能包含字母、数字、- 和 _
+var aliasName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// AliasFile 别名文件的路径：OHO_ALIAS_FILE，或配置目录下的 aliases.json
+func AliasFile() string {
+	if file := os.Getenv("OHO_ALIAS_FILE"); file != "" {
+		return file
+	}
+	return filepath.Join(config.Dir(), "aliases.json")
+}
+
+// LoadAliases 读取别名文件，文件不存在时返回空映射
+func LoadAliases() (Aliases, error) {
+	aliases := Aliases{}
+	data, err := os.ReadFile(AliasFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return aliases, nil
+		}
+		return nil, i18n.Errorf("读取别名文件失败：%w", err)
+	}
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, i18n.Errorf("解析别名文件失败：%w", err)
+	}
+	return aliases, nil
+}
+
+// Save 写入别名文件
+func (a Aliases) Save() error {
+	file := AliasFile()
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return i18n.Errorf("写入别名文件失败：%w", err)
+	}
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, append(data, '\n'), 0600); err != nil {
+		return i18n.Errorf("写入别名文件失败：%w", err)
+	}
+	return nil
+}
+
+// ValidateAlias 检查别名是否可用，last 和 current 是内置引用
+func ValidateAlias(name string) error {
+	if !aliasName.MatchString(name) {
+		return i18n.Errorf("无效的别名：%s（只能包含字母、数字、- 和 _）", name)
+	}
+	if "@"+name == Last || "@"+name == Current {
+		return i18n.Errorf("别名 %s 是内置引用，请使用其他名称", name)
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+// Package resolve 将命令行中的会话引用（ID 前缀、标题、@last、@current、别名）解析为完整的会话 ID
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/anomalyco/oho/internal/cache"
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// 内置的引用
+const (
+	Last    = "@last"    // 最近更新的会话
+	Current = "@current" // 当前目录中最近更新的会话
+)
+
+// idPrefix 会话 ID 的固定前缀，匹配前缀时可以省略
+const idPrefix = "ses_"
+
+// minPrefix 按 ID 前缀匹配时至少需要的字符数（不含 ses_），避免短词被当作前缀
+const minPrefix = 4
+
+// minTitle 按标题包含匹配时至少需要的字符数，更短的引用只匹配完全相同的标题
+const minTitle = 4
+
+// maxCandidates 匹配多个会话时错误信息中最多列出的候选数
+const maxCandidates = 10
+
+// Session 将引用解析为完整的会话 ID
+// 先使用有效期内的本地索引，找不到时请求服务器；引用不匹配任何会话时原样返回，由服务器报告错误
+// @last 和 @current 总是请求服务器，避免过期的索引指向其他会话
+func Session(ctx context.Context, c client.ClientInterface, ref string) (string, error) {
+	return lookup(ctx, c, ref, modeLoose)
+}
+
+// Strict 与 Session 相同，但只接受完整 ID、唯一的 ID 前缀和别名，用于删除、中止、归档等破坏性操作
+// 总是请求服务器的会话列表，不使用本地索引
+func Strict(ctx context.Context, c client.ClientInterface, ref string) (string, error) {
+	return lookup(ctx, c, ref, modeStrict)
+}
+
+// Existing 与 Session 相同，但引用必须匹配服务器上的会话，用于设置别名等会保存解析结果的操作
+// 总是请求服务器的会话列表，获取失败或没有匹配时返回错误
+func Existing(ctx context.Context, c client.ClientInterface, ref string) (string, error) {
+	return lookup(ctx, c, ref, modeExisting)
+}
+
+// mode 引用的解析方式
+type mode int
+
+const (
+	modeLoose    mode = iota // 接受所有引用，没有匹配时原样返回
+	modeStrict               // 只接受完整 ID、ID 前缀和别名
+	modeExisting             // 接受所有引用，但必须匹配服务器上的会话
+)
+
+func lookup(ctx context.Context, c client.ClientInterface, ref string, m mode) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", nil
+	}
+	latestRef := ref == Last || ref == Current
+	if strings.HasPrefix(ref, "@") && !latestRef {
+		aliases, err := LoadAliases()
+		if err != nil {
+			return "", err
+		}
+		id, ok := aliases[strings.TrimPrefix(ref, "@")]
+		if !ok {
+			return "", i18n.Errorf("未知的会话别名：%s（使用 oho session alias list 查看）", ref)
+		}
+		return id, nil
+	}
+	if latestRef && m == modeStrict {
+		return "", i18n.Errorf("此操作不接受 %s，请使用完整 ID、ID 前缀或别名", ref)
+	}
+
+	match := Match
+	if m == modeStrict {
+		match = func(sessions []types.Session, ref, _ string) (string, error) {
+			return matchID(sessions, ref)
+		}
+	}
+
+	// 索引可能缺少其他客户端新建的会话或包含已删除的会话，破坏性操作和保存解析结果的操作必须按服务器的列表判断
+	serverOnly := latestRef || m != modeLoose
+
+	cwd, _ := os.Getwd()
+	idx, idxErr := cache.OpenDefault()
+	if idxErr == nil && !serverOnly && idx.Fresh(time.Now(), cache.TTL()) {
+		// 索引中没有时可能是新创建的会话，再请求服务器
+		if id, err := match(idx.Sessions, ref, cwd); err != nil || id != "" {
+			return id, err
+		}
+	}
+
+	// 获取列表失败时，离线使用索引中的快照，否则 ID 原样交给服务器处理
+	sessions, err := cache.Fetch(ctx, c)
+	if err != nil {
+		switch {
+		case latestRef || m == modeExisting:
+			return "", err
+		case m == modeLoose && idxErr == nil && idx.Synced != 0 && client.IsUnavailable(err):
+			sessions = idx.Sessions
+		default:
+			return ref, nil
+		}
+	} else if idxErr == nil {
+		idx.Replace(sessions, time.Now())
+		_ = idx.Save()
+	}
+
+	id, err := match(sessions, ref, cwd)
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		switch {
+		case latestRef:
+			return "", noSession(ref, cwd)
+		case m == modeExisting:
+			return "", i18n.Errorf("没有匹配 %s 的会话", ref)
+		}
+		return ref, nil
+	}
+	return id, nil
+}
+
+// Match 在会话列表中查找引用，按以下顺序匹配，没有匹配时返回空字符串：
+//
+//	@last / @current  最近更新的顶层会话（@current 只看 dir 目录中的会话）
+//	完整 ID
+//	ID 前缀（可省略 ses_）
+//	标题（不区分大小写，完全相同优先，其次是包含；包含至少需要 4 个字符）
+//
+// 前缀或标题匹配多个会话时返回列出候选的错误
+func Match(sessions []types.Session, ref, dir string) (string, error) {
+	switch ref {
+	case Last:
+		return latest(sessions, ""), nil
+	case Current:
+		return latest(sessions, dir), nil
+	}
+
+	if id, err := matchID(sessions, ref); err != nil || id != "" {
+		return id, err
+	}
+
+	var exact, contains []types.Session
+	lower := strings.ToLower(ref)
+	partial := utf8.RuneCountInString(ref) >= minTitle
+	for _, s := range sessions {
+		title := strings.ToLower(s.Title)
+		switch {
+		case title == lower:
+			exact = append(exact, s)
+		case partial && strings.Contains(title, lower):
+			contains = append(contains, s)
+		}
+	}
+	if len(exact) > 0 {
+		return unique(ref, exact)
+	}
+	return unique(ref, contains)
+}
+
+// matchID 按完整 ID 或 ID 前缀查找会话
+func matchID(sessions []types.Session, ref string) (string, error) {
+	for _, s := range sessions {
+		if s.ID == ref {
+			return s.ID, nil
+		}
+	}
+
+	if len(strings.TrimPrefix(ref, idPrefix)) < minPrefix {
+		return "", nil
+	}
+	var matched []types.Session
+	for _, s := range sessions {
+		if strings.HasPrefix(s.ID, ref) || strings.HasPrefix(s.ID, idPrefix+ref) {
+			matched = append(matched, s)
+		}
+	}
+	return unique(ref, matched)
+}
+
+// latest 最近更新的未归档顶层会话，dir 不为空时只看该目录中的会话
+func latest(sessions []types.Session, dir string) string {
+	var best *types.Session
+	for i := range sessions {
+		s := &sessions[i]
+		if s.ParentID != "" || s.Time.Archived != 0 {
+			continue
+		}
+		if dir != "" && (s.Directory == "" || filepath.Clean(s.Directory) != filepath.Clean(dir)) {
+			continue
+		}
+		if best == nil || s.Time.Updated > best.Time.Updated {
+			best = s
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.ID
+}
+
+// unique 只有一个会话时返回其 ID，多个时返回列出候选的错误
+func unique(ref string, matched []types.Session) (string, error) {
+	switch len(matched) {
+	case 0:
+		return "", nil
+	case 1:
+		return matched[0].ID, nil
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Time.Updated > matched[j].Time.Updated
+	})
+	var lines []string
+	for i, s := range matched {
+		if i == maxCandidates {
+			lines = append(lines, i18n.Sprintf("  …还有 %d 个", len(matched)-maxCandidates))
+			break
+		}
+		line := "  " + s.ID
+		if s.Title != "" {
+			line += "  " + s.Title
+		}
+		if s.Directory != "" {
+			line += fmt.Sprintf("  (%s)", s.Directory)
+		}
+		lines = append(lines, line)
+	}
+	return "", i18n.Errorf("%s 匹配多个会话，请使用更长的 ID 前缀或更完整的标题：\n%s", ref, strings.Join(lines, "\n"))
+}
+
+// noSession @last 或 @current 没有匹配的会话
+func noSession(ref, dir string) error {
+	if ref == Current {
+		return i18n.Errorf("当前目录没有会话：%s", dir)
+	}
+	return i18n.Errorf("没有会话")
+}
@@ -0,0 +1,265 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+func TestMain(m *testing.M) {
+	os.Setenv("OPENCODE_SERVER_HOST", "127.0.0.1")
+	os.Setenv("OPENCODE_SERVER_PORT", "4096")
+	_ = config.Init()
+	m.Run()
+}
+
+var testSessions = []types.Session{
+	{ID: "ses_34dbffe0dffe8SfdMTbL53MWFP", Title: "Fix login bug", Directory: "/src/api", Time: types.SessionTime{Updated: 30}},
+	{ID: "ses_34dbaa11aaaa1AbcDefGhiJkLm", Title: "Login page redesign", Directory: "/src/web", Time: types.SessionTime{Updated: 50}},
+	{ID: "ses_77c0ffee0000aXyzXyzXyzXyzX", Title: "Refactor", Directory: "/src/api", Time: types.SessionTime{Updated: 20}},
+	{ID: "ses_99child000000aChildChildCh", Title: "explore", ParentID: "ses_77c0ffee0000aXyzXyzXyzXyzX", Directory: "/src/api", Time: types.SessionTime{Updated: 90}},
+	{ID: "ses_88archived0000ArchivedArch", Title: "old", Directory: "/src/api", Time: types.SessionTime{Updated: 80, Archived: 85}},
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     string
+		dir     string
+		want    string
+		wantErr string
+	}{
+		{"full id", "ses_77c0ffee0000aXyzXyzXyzXyzX", "", "ses_77c0ffee0000aXyzXyzXyzXyzX", ""},
+		{"prefix", "ses_34dbf", "", "ses_34dbffe0dffe8SfdMTbL53MWFP", ""},
+		{"prefix without ses_", "77c0ff", "", "ses_77c0ffee0000aXyzXyzXyzXyzX", ""},
+		{"ambiguous prefix", "34db", "", "", "ses_34dbaa11aaaa1AbcDefGhiJkLm"},
+		{"short prefix is not a prefix", "ses_", "", "", ""},
+		{"exact title", "refactor", "", "ses_77c0ffee0000aXyzXyzXyzXyzX", ""},
+		{"title substring", "redesign", "", "ses_34dbaa11aaaa1AbcDefGhiJkLm", ""},
+		{"ambiguous title", "login", "", "", "Fix login bug"},
+		{"short title substring", "fix", "", "", ""},
+		{"short exact title", "old", "", "ses_88archived0000ArchivedArch", ""},
+		{"no match", "nothing", "", "", ""},
+		{"last skips children and archived", "@last", "", "ses_34dbaa11aaaa1AbcDefGhiJkLm", ""},
+		{"current", "@current", "/src/api/", "ses_34dbffe0dffe8SfdMTbL53MWFP", ""},
+		{"current without sessions", "@current", "/tmp", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Match(testSessions, tt.ref, tt.dir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Match(%q) error = %v, want candidates containing %q", tt.ref, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Match(%q) = %q, %v, want %q", tt.ref, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession(t *testing.T) {
+	t.Setenv("OHO_CACHE_DIR", t.TempDir())
+	t.Setenv("OHO_ALIAS_FILE", filepath.Join(t.TempDir(), "aliases.json"))
+	if err := (Aliases{"api": "ses_77c0ffee0000aXyzXyzXyzXyzX"}).Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	var fetchErr error
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			calls++
+			if fetchErr != nil {
+				return nil, fetchErr
+			}
+			return []byte(`[
+				{"id":"ses_34dbffe0dffe8SfdMTbL53MWFP","title":"Fix login bug","time":{"updated":30}},
+				{"id":"ses_77c0ffee0000aXyzXyzXyzXyzX","title":"Refactor","time":{"updated":20}}
+			]`), nil
+		},
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"empty", "", "", false},
+		{"alias", "@api", "ses_77c0ffee0000aXyzXyzXyzXyzX", false},
+		{"unknown alias", "@web", "", true},
+		{"prefix", "34dbffe", "ses_34dbffe0dffe8SfdMTbL53MWFP", false},
+		{"last", "@last", "ses_34dbffe0dffe8SfdMTbL53MWFP", false},
+		{"unknown id passes through", "ses_other", "ses_other", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Session(ctx, mock, tt.ref)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Session(%q) = %q, %v, want %q (error %v)", tt.ref, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	// 获取列表后写入索引，有效期内的匹配不再请求服务器
+	calls = 0
+	if got, _ := Session(ctx, mock, "refactor"); got != "ses_77c0ffee0000aXyzXyzXyzXyzX" || calls != 0 {
+		t.Errorf("Session from index = %q, calls = %d", got, calls)
+	}
+
+	// @last 不使用索引
+	calls = 0
+	if got, _ := Session(ctx, mock, "@last"); got != "ses_34dbffe0dffe8SfdMTbL53MWFP" || calls != 1 {
+		t.Errorf("Session(@last) = %q, calls = %d, want a server request", got, calls)
+	}
+
+	// 服务器返回错误时 ID 原样返回，@last 报告错误
+	t.Setenv("OHO_CACHE_DIR", t.TempDir())
+	fetchErr = errors.New("API 错误 [500]")
+	if got, err := Session(ctx, mock, "34dbffe"); err != nil || got != "34dbffe" {
+		t.Errorf("Session on error = %q, %v", got, err)
+	}
+	if _, err := Session(ctx, mock, "@last"); err == nil {
+		t.Error("Expected error for @last when the list is unavailable")
+	}
+	fetchErr = &url.Error{Op: "Get", URL: "/session", Err: errors.New("connection refused")}
+	if got, err := Session(ctx, mock, "34dbffe"); err != nil || got != "34dbffe" {
+		t.Errorf("Session offline without index = %q, %v", got, err)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	t.Setenv("OHO_CACHE_DIR", t.TempDir())
+	t.Setenv("OHO_ALIAS_FILE", filepath.Join(t.TempDir(), "aliases.json"))
+	if err := (Aliases{"api": "ses_77c0ffee0000aXyzXyzXyzXyzX"}).Save(); err != nil {
+		t.Fatal(err)
+	}
+	list := `[
+		{"id":"ses_34dbffe0dffe8SfdMTbL53MWFP","title":"Fix login bug","time":{"updated":30}},
+		{"id":"ses_77c0ffee0000aXyzXyzXyzXyzX","title":"Refactor","time":{"updated":20}}
+	]`
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			return []byte(list), nil
+		},
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"full id", "ses_77c0ffee0000aXyzXyzXyzXyzX", "ses_77c0ffee0000aXyzXyzXyzXyzX", false},
+		{"prefix", "34dbffe", "ses_34dbffe0dffe8SfdMTbL53MWFP", false},
+		{"alias", "@api", "ses_77c0ffee0000aXyzXyzXyzXyzX", false},
+		// 标题不参与匹配，原样交给服务器
+		{"title", "Refactor", "Refactor", false},
+		{"last", "@last", "", true},
+		{"current", "@current", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Strict(ctx, mock, tt.ref)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Strict(%q) = %q, %v, want %q (error %v)", tt.ref, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	// 有效期内的索引中前缀唯一，但服务器上已有另一个同前缀的会话
+	if _, err := Session(ctx, mock, "34dbffe"); err != nil {
+		t.Fatal(err)
+	}
+	list = `[
+		{"id":"ses_34dbffe0dffe8SfdMTbL53MWFP","title":"Fix login bug","time":{"updated":30}},
+		{"id":"ses_34dbffe9new00NewNewNewNewNe","title":"New","time":{"updated":40}}
+	]`
+	if _, err := Strict(ctx, mock, "34dbffe"); err == nil || !strings.Contains(err.Error(), "ses_34dbffe9new") {
+		t.Errorf("Strict() with a stale index error = %v, want ambiguity from the server list", err)
+	}
+}
+
+func TestExisting(t *testing.T) {
+	t.Setenv("OHO_CACHE_DIR", t.TempDir())
+	t.Setenv("OHO_ALIAS_FILE", filepath.Join(t.TempDir(), "aliases.json"))
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			return []byte(`[{"id":"ses_34dbffe0dffe8SfdMTbL53MWFP","title":"Fix login bug","time":{"updated":30}}]`), nil
+		},
+	}
+	ctx := context.Background()
+
+	if id, err := Existing(ctx, mock, "login"); err != nil || id != "ses_34dbffe0dffe8SfdMTbL53MWFP" {
+		t.Errorf("Existing(login) = %q, %v", id, err)
+	}
+	// 没有匹配时不原样返回，而是报错
+	if id, err := Existing(ctx, mock, "typo"); err == nil || id != "" {
+		t.Errorf("Existing(typo) = %q, %v, want an error", id, err)
+	}
+
+	// 无法获取会话列表时不能确认会话存在
+	mock.GetFunc = func(ctx context.Context, path string) ([]byte, error) {
+		return nil, errors.New("connection refused")
+	}
+	if _, err := Existing(ctx, mock, "ses_34dbffe0dffe8SfdMTbL53MWFP"); err == nil {
+		t.Error("Expected Existing() to fail when the session list is unavailable")
+	}
+}
+
+func TestAliases(t *testing.T) {
+	t.Setenv("OHO_ALIAS_FILE", filepath.Join(t.TempDir(), "sub", "aliases.json"))
+
+	aliases, err := LoadAliases()
+	if err != nil || len(aliases) != 0 {
+		t.Fatalf("LoadAliases() = %v, %v", aliases, err)
+	}
+	aliases["work"] = "ses_1"
+	if err := aliases.Save(); err != nil {
+		t.Fatal(err)
+	}
+	aliases, err = LoadAliases()
+	if err != nil || aliases["work"] != "ses_1" {
+		t.Errorf("reloaded aliases = %v, %v", aliases, err)
+	}
+
+	if err := os.WriteFile(AliasFile(), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadAliases(); err == nil {
+		t.Error("Expected error for invalid alias file")
+	}
+}
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"work", false},
+		{"api-2_x", false},
+		{"last", true},
+		{"current", true},
+		{"-x", true},
+		{"a b", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		if err := ValidateAlias(tt.name); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateAlias(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
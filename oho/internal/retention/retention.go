@@ -0,0 +1,167 @@
+package retention
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// 清理动作
+const (
+	ActionArchive = "archive"
+	ActionDelete  = "delete"
+)
+
+// 计划中会话的状态，执行后由调用方改为 done 或 failed
+const (
+	StatusPlanned = "planned"
+	StatusSkipped = "skipped"
+)
+
+// 跳过会话的原因
+const (
+	SkipWorking         = "working"          // 会话正在运行
+	SkipShared          = "shared"           // 会话已分享
+	SkipWorkingChildren = "working-children" // 子会话正在运行
+	SkipKeepLast        = "keep-last"        // 属于目录下最近的 keep_last 个会话
+)
+
+// Policy 会话保留策略
+type Policy struct {
+	ArchiveAfter util.Duration `yaml:"archive_after"` // 空闲超过该时长的会话归档
+	DeleteAfter  util.Duration `yaml:"delete_after"`  // 归档超过该时长的会话删除
+	KeepLast     int           `yaml:"keep_last"`     // 每个目录保留最近更新的会话数
+}
+
+// LoadPolicy 从 YAML 文件加载策略
+func LoadPolicy(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, i18n.Errorf("读取策略文件失败：%w", err)
+	}
+	return ParsePolicy(data)
+}
+
+// ParsePolicy 解析并校验策略
+func ParsePolicy(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, i18n.Errorf("解析策略失败：%w", err)
+	}
+	if p.ArchiveAfter == 0 && p.DeleteAfter == 0 {
+		return nil, i18n.Errorf("策略至少需要 archive_after 或 delete_after")
+	}
+	if p.KeepLast < 0 {
+		return nil, i18n.Errorf("keep_last 不能为负数：%d", p.KeepLast)
+	}
+	return &p, nil
+}
+
+// Plan 根据策略计算每个会话的清理动作
+// 返回的结果只包含命中规则的会话：可以执行的为 planned，受保护的为 skipped 并注明原因
+func (p *Policy) Plan(sessions []types.Session, statuses map[string]types.SessionStatus, now time.Time) []types.GCResult {
+	working := func(id string) bool {
+		status, ok := statuses[id]
+		return ok && status.Working()
+	}
+	children := map[string][]string{}
+	for _, s := range sessions {
+		if s.ParentID != "" {
+			children[s.ParentID] = append(children[s.ParentID], s.ID)
+		}
+	}
+	// 任意层级的子会话正在运行时保护父会话
+	var busyChild func(id string, seen map[string]bool) bool
+	busyChild = func(id string, seen map[string]bool) bool {
+		for _, child := range children[id] {
+			if seen[child] {
+				continue
+			}
+			seen[child] = true
+			if working(child) || busyChild(child, seen) {
+				return true
+			}
+		}
+		return false
+	}
+	kept := p.keepLast(sessions)
+
+	var results []types.GCResult
+	for _, s := range sessions {
+		action, age := p.action(s, now)
+		if action == "" {
+			continue
+		}
+		result := types.GCResult{
+			ID:        s.ID,
+			Title:     s.Title,
+			Directory: s.Directory,
+			Action:    action,
+			Age:       util.FormatAge(age),
+			Status:    StatusPlanned,
+		}
+		switch {
+		case working(s.ID):
+			result.Status, result.Reason = StatusSkipped, SkipWorking
+		case s.Share != nil && s.Share.URL != "":
+			result.Status, result.Reason = StatusSkipped, SkipShared
+		case busyChild(s.ID, map[string]bool{s.ID: true}):
+			result.Status, result.Reason = StatusSkipped, SkipWorkingChildren
+		case kept[s.ID]:
+			result.Status, result.Reason = StatusSkipped, SkipKeepLast
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// action 返回会话命中的动作及对应的时长：已归档的会话按归档时间判断删除，其他会话按最后更新时间判断归档
+func (p *Policy) action(s types.Session, now time.Time) (string, time.Duration) {
+	if s.Time.Archived != 0 {
+		age := now.Sub(time.UnixMilli(s.Time.Archived))
+		if p.DeleteAfter > 0 && age >= time.Duration(p.DeleteAfter) {
+			return ActionDelete, age
+		}
+		return "", 0
+	}
+	last := s.Time.Updated
+	if last == 0 {
+		last = s.Time.Created
+	}
+	// 没有时间信息的会话无法判断空闲时长，不处理
+	if last == 0 {
+		return "", 0
+	}
+	age := now.Sub(time.UnixMilli(last))
+	if p.ArchiveAfter > 0 && age >= time.Duration(p.ArchiveAfter) {
+		return ActionArchive, age
+	}
+	return "", 0
+}
+
+// keepLast 每个目录中最近更新的 keep_last 个会话
+func (p *Policy) keepLast(sessions []types.Session) map[string]bool {
+	kept := map[string]bool{}
+	if p.KeepLast == 0 {
+		return kept
+	}
+	byDir := map[string][]types.Session{}
+	for _, s := range sessions {
+		byDir[s.Directory] = append(byDir[s.Directory], s)
+	}
+	for _, list := range byDir {
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Time.Updated > list[j].Time.Updated
+		})
+		for i := 0; i < len(list) && i < p.KeepLast; i++ {
+			kept[list[i].ID] = true
+		}
+	}
+	return kept
+}
@@ -0,0 +1,75 @@
+package retention
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy([]byte("archive_after: 14d\ndelete_after: 1w\nkeep_last: 3\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if time.Duration(p.ArchiveAfter) != 14*24*time.Hour || time.Duration(p.DeleteAfter) != 7*24*time.Hour || p.KeepLast != 3 {
+		t.Errorf("Unexpected policy: %+v", p)
+	}
+
+	invalid := []string{
+		"keep_last: 3\n",
+		"archive_after: soon\n",
+		"archive_after: 1d\nkeep_last: -1\n",
+		"archive_after: [",
+	}
+	for _, data := range invalid {
+		if _, err := ParsePolicy([]byte(data)); err == nil {
+			t.Errorf("Expected error for policy %q", data)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	day := int64(24 * time.Hour / time.Millisecond)
+	now := time.UnixMilli(100 * day)
+	ago := func(days int64) int64 { return now.UnixMilli() - days*day }
+
+	sessions := []types.Session{
+		{ID: "idle", Directory: "/a", Time: types.SessionTime{Updated: ago(20)}},
+		{ID: "fresh", Directory: "/a", Time: types.SessionTime{Updated: ago(2)}},
+		{ID: "shared", Directory: "/a", Time: types.SessionTime{Updated: ago(40)}, Share: &types.SessionShare{URL: "https://opncd.ai/s/x"}},
+		{ID: "running", Directory: "/a", Time: types.SessionTime{Updated: ago(30)}},
+		{ID: "parent", Directory: "/a", Time: types.SessionTime{Updated: ago(50)}},
+		{ID: "child", ParentID: "parent", Directory: "/a", Time: types.SessionTime{Updated: ago(3)}},
+		{ID: "grandchild", ParentID: "child", Directory: "/a", Time: types.SessionTime{Updated: ago(60)}},
+		{ID: "old-archived", Directory: "/a", Time: types.SessionTime{Updated: ago(90), Archived: ago(45)}},
+		{ID: "new-archived", Directory: "/a", Time: types.SessionTime{Updated: ago(90), Archived: ago(5)}},
+		{ID: "only-b", Directory: "/b", Time: types.SessionTime{Updated: ago(25)}},
+		{ID: "no-time", Directory: "/c"},
+	}
+	statuses := map[string]types.SessionStatus{
+		"running":    {Type: "busy"},
+		"grandchild": {Type: "busy"},
+		"idle":       {Type: "idle"},
+	}
+	p := &Policy{ArchiveAfter: util.Duration(14 * 24 * time.Hour), DeleteAfter: util.Duration(30 * 24 * time.Hour), KeepLast: 1}
+
+	var got []string
+	for _, r := range p.Plan(sessions, statuses, now) {
+		got = append(got, r.ID+":"+r.Action+":"+r.Status+":"+r.Reason+":"+r.Age)
+	}
+	want := []string{
+		"idle:archive:planned::20d",
+		"shared:archive:skipped:shared:40d",
+		"running:archive:skipped:working:30d",
+		"parent:archive:skipped:working-children:50d",
+		"grandchild:archive:skipped:working:60d",
+		"old-archived:delete:planned::45d",
+		"only-b:archive:skipped:keep-last:25d",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Plan() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,196 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Draft 生成的 JSON Schema 版本
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	mu       sync.Mutex
+	registry = make(map[string]reflect.Type)
+)
+
+// Register 登记命令输出（信封中 data 字段）的类型，path 为命令路径，如 "session list"
+func Register(path string, v interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[path] = reflect.TypeOf(v)
+}
+
+// Paths 返回已登记的命令路径
+func Paths() []string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	paths := make([]string, 0, len(registry))
+	for p := range registry {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Lookup 返回命令 JSON 输出信封的完整 Schema
+func Lookup(path string) (map[string]interface{}, bool) {
+	mu.Lock()
+	t, ok := registry[path]
+	mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return Envelope("oho "+path, t), true
+}
+
+// Envelope 生成信封 Schema，data 字段的类型为 t
+func Envelope(title string, t reflect.Type) map[string]interface{} {
+	g := &generator{defs: make(map[string]interface{})}
+	data := g.schema(t)
+
+	s := map[string]interface{}{
+		"$schema": Draft,
+		"title":   title,
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"version": map[string]interface{}{"type": "integer", "const": 1},
+			"ok":      map[string]interface{}{"type": "boolean"},
+			"data":    data,
+			"error": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"code":    map[string]interface{}{"type": "string"},
+					"message": map[string]interface{}{"type": "string"},
+					"status":  map[string]interface{}{"type": "integer"},
+				},
+				"required": []string{"code", "message"},
+			},
+		},
+		"required": []string{"version", "ok"},
+	}
+	if len(g.defs) > 0 {
+		s["$defs"] = g.defs
+	}
+	return s
+}
+
+// Generate 生成单个类型的 Schema
+func Generate(v interface{}) map[string]interface{} {
+	g := &generator{defs: make(map[string]interface{})}
+	s := g.schema(reflect.TypeOf(v))
+	if len(g.defs) > 0 {
+		s["$defs"] = g.defs
+	}
+	return s
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// generator 命名结构体放入 $defs 并以 $ref 引用，以支持递归类型
+type generator struct {
+	defs map[string]interface{}
+}
+
+func (g *generator) schema(t reflect.Type) map[string]interface{} {
+	if t == nil {
+		return map[string]interface{}{}
+	}
+
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case rawJSONType:
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schema(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := t.Name()
+		if _, ok := g.defs[name]; !ok {
+			// 先占位，防止递归类型无限展开
+			g.defs[name] = map[string]interface{}{}
+			g.defs[name] = g.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + name}
+	}
+	// interface{} 等任意值
+	return map[string]interface{}{}
+}
+
+// object 根据 json 标签生成对象 Schema，非 omitempty 字段为必需字段
+func (g *generator) object(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// 匿名嵌入且无名称的结构体，字段提升到外层
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded := g.object(ft)
+				for k, v := range embedded["properties"].(map[string]interface{}) {
+					properties[k] = v
+				}
+				required = append(required, embedded["required"].([]string)...)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = g.schema(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Ptr && f.Type.Kind() != reflect.Interface {
+			required = append(required, name)
+		}
+	}
+
+	sort.Strings(required)
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
@@ -0,0 +1,119 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testNode struct {
+	ID       string     `json:"id"`
+	Title    string     `json:"title,omitempty"`
+	Created  time.Time  `json:"created"`
+	Parent   *testNode  `json:"parent"`
+	Children []testNode `json:"children"`
+	Labels   map[string]int
+	secret   string
+}
+
+type testEmbedded struct {
+	testBase
+	Name string `json:"name"`
+	Skip string `json:"-"`
+}
+
+type testBase struct {
+	Kind string `json:"kind"`
+}
+
+func TestGenerate(t *testing.T) {
+	s := Generate(testNode{})
+
+	if s["$ref"] != "#/$defs/testNode" {
+		t.Fatalf("Expected named struct to be referenced, got %v", s)
+	}
+	defs := s["$defs"].(map[string]interface{})
+	node := defs["testNode"].(map[string]interface{})
+	props := node["properties"].(map[string]interface{})
+
+	tests := []struct {
+		field string
+		want  map[string]interface{}
+	}{
+		{"id", map[string]interface{}{"type": "string"}},
+		{"created", map[string]interface{}{"type": "string", "format": "date-time"}},
+		{"parent", map[string]interface{}{"$ref": "#/$defs/testNode"}},
+		{"children", map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/$defs/testNode"}}},
+		{"Labels", map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "integer"}}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(props[tt.field], tt.want) {
+			t.Errorf("Property %s = %v, want %v", tt.field, props[tt.field], tt.want)
+		}
+	}
+	if _, ok := props["secret"]; ok {
+		t.Error("Expected unexported field to be skipped")
+	}
+
+	wantRequired := []string{"Labels", "children", "created", "id"}
+	if !reflect.DeepEqual(node["required"], wantRequired) {
+		t.Errorf("required = %v, want %v", node["required"], wantRequired)
+	}
+}
+
+func TestGenerateEmbedded(t *testing.T) {
+	s := Generate(testEmbedded{})
+	obj := s["$defs"].(map[string]interface{})["testEmbedded"].(map[string]interface{})
+	props := obj["properties"].(map[string]interface{})
+
+	if _, ok := props["kind"]; !ok {
+		t.Error("Expected embedded struct fields to be promoted")
+	}
+	if _, ok := props["Skip"]; ok {
+		t.Error("Expected json:\"-\" field to be skipped")
+	}
+	if !reflect.DeepEqual(obj["required"], []string{"kind", "name"}) {
+		t.Errorf("required = %v", obj["required"])
+	}
+}
+
+func TestLookup(t *testing.T) {
+	Register("test nodes", []testNode{})
+
+	if _, ok := Lookup("test missing"); ok {
+		t.Error("Expected unregistered path to be missing")
+	}
+
+	s, ok := Lookup("test nodes")
+	if !ok {
+		t.Fatal("Expected registered path to be found")
+	}
+	if s["$schema"] != Draft || s["title"] != "oho test nodes" {
+		t.Errorf("Unexpected schema header: %v, %v", s["$schema"], s["title"])
+	}
+
+	props := s["properties"].(map[string]interface{})
+	data := props["data"].(map[string]interface{})
+	if data["type"] != "array" {
+		t.Errorf("Expected data to be an array, got %v", data)
+	}
+	if _, ok := s["$defs"].(map[string]interface{})["testNode"]; !ok {
+		t.Error("Expected $defs to include referenced types")
+	}
+
+	// Schema 必须能序列化为 JSON
+	if _, err := json.Marshal(s); err != nil {
+		t.Errorf("Marshal failed: %v", err)
+	}
+
+	found := false
+	for _, p := range Paths() {
+		if p == "test nodes" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Paths() = %v, want to include test nodes", Paths())
+	}
+}
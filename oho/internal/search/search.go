@@ -0,0 +1,178 @@
+package search
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/anomalyco/oho/internal/i18n"
+)
+
+// 评分：命中的不同词越多越靠前，其次是完整短语和出现次数
+const (
+	termScore   = 100
+	phraseScore = 50
+	maxCount    = 20 // 出现次数最多计入的分数
+)
+
+// Matcher 在文本中查找关键词或正则表达式
+type Matcher struct {
+	terms  []*regexp.Regexp
+	phrase *regexp.Regexp // 多个词时作为完整短语出现的加分
+}
+
+// New 创建匹配器
+// regex 为 false 时查询按空白分隔为多个词，命中任意一个词即为匹配；默认不区分大小写
+func New(query string, regex, caseSensitive bool) (*Matcher, error) {
+	flags := "(?i)"
+	if caseSensitive {
+		flags = ""
+	}
+
+	m := &Matcher{}
+	if regex {
+		re, err := regexp.Compile(flags + query)
+		if err != nil {
+			return nil, i18n.Errorf("无效的正则表达式：%w", err)
+		}
+		m.terms = []*regexp.Regexp{re}
+		return m, nil
+	}
+
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return nil, i18n.Errorf("搜索内容不能为空")
+	}
+	for _, w := range words {
+		m.terms = append(m.terms, regexp.MustCompile(flags+regexp.QuoteMeta(w)))
+	}
+	if len(words) > 1 {
+		quoted := make([]string, len(words))
+		for i, w := range words {
+			quoted[i] = regexp.QuoteMeta(w)
+		}
+		m.phrase = regexp.MustCompile(flags + strings.Join(quoted, `\s+`))
+	}
+	return m, nil
+}
+
+// Match 返回文本的得分和匹配的字节范围（已排序合并），没有匹配时得分为 0
+func (m *Matcher) Match(text string) (int, [][2]int) {
+	score, count := 0, 0
+	var ranges [][2]int
+	for _, re := range m.terms {
+		locs := re.FindAllStringIndex(text, -1)
+		found := false
+		for _, loc := range locs {
+			if loc[1] > loc[0] {
+				ranges = append(ranges, [2]int{loc[0], loc[1]})
+				found = true
+				count++
+			}
+		}
+		if found {
+			score += termScore
+		}
+	}
+	if score == 0 {
+		return 0, nil
+	}
+	if m.phrase != nil && m.phrase.MatchString(text) {
+		score += phraseScore
+	}
+	if count > maxCount {
+		count = maxCount
+	}
+	return score + count, merge(ranges)
+}
+
+// merge 排序并合并重叠的范围
+func merge(ranges [][2]int) [][2]int {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	var merged [][2]int
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r[0] <= merged[n-1][1] {
+			if r[1] > merged[n-1][1] {
+				merged[n-1][1] = r[1]
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// Normalize 将连续的空白压缩为一个空格，便于在单行中显示片段
+func Normalize(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// ellipsis 片段被截断时的标记
+const ellipsis = "…"
+
+// Snippet 截取第一个匹配附近的片段，before 和 after 为匹配前后保留的字符数
+// 返回片段以及片段中的匹配范围
+func Snippet(text string, ranges [][2]int, before, after int) (string, [][2]int) {
+	if len(ranges) == 0 {
+		return text, nil
+	}
+	start := back(text, ranges[0][0], before)
+	end := forward(text, ranges[0][1], after)
+
+	var b strings.Builder
+	offset := -start
+	if start > 0 {
+		b.WriteString(ellipsis)
+		offset += len(ellipsis)
+	}
+	b.WriteString(text[start:end])
+	if end < len(text) {
+		b.WriteString(ellipsis)
+	}
+
+	var highlights [][2]int
+	for _, r := range ranges {
+		if r[0] >= end {
+			break
+		}
+		if r[1] > end {
+			r[1] = end
+		}
+		highlights = append(highlights, [2]int{r[0] + offset, r[1] + offset})
+	}
+	return b.String(), highlights
+}
+
+// back 从 i 向前移动 n 个字符
+func back(s string, i, n int) int {
+	for ; n > 0 && i > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(s[:i])
+		i -= size
+	}
+	return i
+}
+
+// forward 从 i 向后移动 n 个字符
+func forward(s string, i, n int) int {
+	for ; n > 0 && i < len(s); n-- {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return i
+}
+
+// Highlight 用 open 和 close 包围片段中的匹配内容
+func Highlight(snippet string, highlights [][2]int, open, close string) string {
+	var b strings.Builder
+	last := 0
+	for _, h := range highlights {
+		b.WriteString(snippet[last:h[0]])
+		b.WriteString(open)
+		b.WriteString(snippet[h[0]:h[1]])
+		b.WriteString(close)
+		last = h[1]
+	}
+	b.WriteString(snippet[last:])
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		regex     bool
+		sensitive bool
+		text      string
+		wantScore int
+		want      [][2]int
+	}{
+		{"single word", "limit", false, false, "Rate LIMIT hit", termScore + 1, [][2]int{{5, 10}}},
+		{"all words and phrase", "rate limit", false, false, "rate limit, rate", 2*termScore + phraseScore + 3, [][2]int{{0, 4}, {5, 10}, {12, 16}}},
+		{"one of two words", "rate timeout", false, false, "rate limit", termScore + 1, [][2]int{{0, 4}}},
+		{"case sensitive", "Limit", false, true, "limit", 0, nil},
+		{"regex", `err(or)?\d`, true, false, "error1 err2", termScore + 2, [][2]int{{0, 6}, {7, 11}}},
+		{"overlapping words", "abc bcd", false, false, "abcd", 2*termScore + 2, [][2]int{{0, 4}}},
+		{"no match", "zzz", false, false, "abc", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := New(tt.query, tt.regex, tt.sensitive)
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			score, ranges := m.Match(tt.text)
+			if score != tt.wantScore || !reflect.DeepEqual(ranges, tt.want) {
+				t.Errorf("Match() = %d, %v, want %d, %v", score, ranges, tt.wantScore, tt.want)
+			}
+		})
+	}
+
+	if _, err := New("  ", false, false); err == nil {
+		t.Error("Expected error for empty query")
+	}
+	if _, err := New("(", true, false); err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	text := "0123456789 needle 0123456789 needle 0123456789"
+	m, _ := New("needle", false, false)
+	_, ranges := m.Match(text)
+
+	snippet, highlights := Snippet(text, ranges, 5, 20)
+	if want := "…6789 needle 0123456789 needle 0…"; snippet != want {
+		t.Errorf("Snippet() = %q, want %q", snippet, want)
+	}
+	if got := Highlight(snippet, highlights, "[", "]"); got != "…6789 [needle] 0123456789 [needle] 0…" {
+		t.Errorf("Highlight() = %q", got)
+	}
+
+	// 片段末尾截断的匹配只高亮片段内的部分
+	snippet, highlights = Snippet(text, ranges, 0, 16)
+	if got := Highlight(snippet, highlights, "[", "]"); got != "…[needle] 0123456789 [need]…" {
+		t.Errorf("Highlight() = %q", got)
+	}
+
+	// 按字符而不是字节截取
+	snippet, highlights = Snippet("你好世界，搜索结果", [][2]int{{15, 21}}, 2, 1)
+	if got := Highlight(snippet, highlights, "[", "]"); got != "…界，[搜索]结…" {
+		t.Errorf("Highlight() = %q", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize("  a\n\tb  c \n"); got != "a b c" {
+		t.Errorf("Normalize() = %q", got)
+	}
+}
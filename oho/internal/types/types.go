@@ -1,5 +1,7 @@
 package types
 
+import "encoding/json"
+
 // Model represents the model as an object with provider and model IDs
 type Model struct {
 	ProviderID string `json:"providerID"`
@@ -8,20 +10,27 @@ type Model struct {
 
 // Session 会话类型
 type Session struct {
-	ID        string      `json:"id"`
-	Title     string      `json:"title"`
-	ParentID  string      `json:"parentId,omitempty"`
-	ProjectID string      `json:"projectId,omitempty"`
-	Directory string      `json:"directory,omitempty"`
-	Time      SessionTime `json:"time"`
-	Model     interface{} `json:"model"`  // Can be string or Model object
-	Agent     string      `json:"agent"`
+	ID        string        `json:"id"`
+	Title     string        `json:"title"`
+	ParentID  string        `json:"parentId,omitempty"`
+	ProjectID string        `json:"projectId,omitempty"`
+	Directory string        `json:"directory,omitempty"`
+	Time      SessionTime   `json:"time"`
+	Model     interface{}   `json:"model"`  // Can be string or Model object
+	Agent     string        `json:"agent"`
+	Share     *SessionShare `json:"share,omitempty"`
 }
 
 // SessionTime 会话时间戳
 type SessionTime struct {
-	Created int64 `json:"created"`
-	Updated int64 `json:"updated"`
+	Created  int64 `json:"created"`
+	Updated  int64 `json:"updated"`
+	Archived int64 `json:"archived,omitempty"`
+}
+
+// SessionShare 会话的分享信息
+type SessionShare struct {
+	URL string `json:"url"`
 }
 
 // SessionStatus 会话状态
@@ -30,6 +39,12 @@ type SessionStatus struct {
 	IsReady   bool   `json:"isReady"`
 	IsWorking bool   `json:"isWorking"`
 	MessageID string `json:"messageId,omitempty"`
+	Type      string `json:"type,omitempty"` // 新版服务器返回 idle/busy/retry
+}
+
+// Working 判断会话是否正在工作，兼容 isWorking 和 type 两种表示
+func (s SessionStatus) Working() bool {
+	return s.IsWorking || s.Type == "busy" || s.Type == "retry"
 }
 
 // Message 消息类型
@@ -39,15 +54,65 @@ type Message struct {
 	Role      string `json:"role"`
 	CreatedAt int64  `json:"createdAt"`
 	Content   string `json:"content,omitempty"`
+	Summary   bool   `json:"summary,omitempty"` // 由 session summarize 生成的总结消息
+
+	// 以下字段只有助手消息才有
+	Time       *MessageTime `json:"time,omitempty"`
+	ProviderID string       `json:"providerId,omitempty"`
+	ModelID    string       `json:"modelId,omitempty"`
+	Mode       string       `json:"mode,omitempty"` // 旧版本服务器的代理名称
+	Agent      string       `json:"agent,omitempty"`
+	Path       *MessagePath `json:"path,omitempty"`
+	Cost       float64      `json:"cost,omitempty"` // 费用（美元）
+	Tokens     *Tokens      `json:"tokens,omitempty"`
+}
+
+// MessageTime 消息时间戳（毫秒）
+type MessageTime struct {
+	Created   int64 `json:"created"`
+	Completed int64 `json:"completed,omitempty"`
+}
+
+// MessagePath 生成消息时的工作目录
+type MessagePath struct {
+	Cwd  string `json:"cwd"`
+	Root string `json:"root"`
 }
 
-// Part 消息部分 (对应 OpenCode API 的 TextPart | FilePart)
+// Tokens 助手消息的 token 用量
+type Tokens struct {
+	Input     int64       `json:"input"`
+	Output    int64       `json:"output"`
+	Reasoning int64       `json:"reasoning"`
+	Cache     TokensCache `json:"cache"`
+}
+
+// TokensCache 缓存读写的 token 数
+type TokensCache struct {
+	Read  int64 `json:"read"`
+	Write int64 `json:"write"`
+}
+
+// Part 消息部分 (对应 OpenCode API 的 TextPart | FilePart，以及响应中的 ToolPart、PatchPart)
 type Part struct {
-	Type   string      `json:"type"`
-	Text   *string     `json:"text,omitempty"`   // 使用指针，nil 时会被 omit，符合 TextPart 规范
-	URL    string      `json:"url,omitempty"`    // FilePart 的 url 字段 (base64 data URL 或文件路径)
-	Mime   string      `json:"mime,omitempty"`   // FilePart 的 mime 字段
-	Source *FileSource `json:"source,omitempty"` // FilePart 的 source 字段 (可选)
+	Type     string      `json:"type"`
+	Text     *string     `json:"text,omitempty"`     // 使用指针，nil 时会被 omit，符合 TextPart 规范
+	URL      string      `json:"url,omitempty"`      // FilePart 的 url 字段 (base64 data URL 或文件路径)
+	Mime     string      `json:"mime,omitempty"`     // FilePart 的 mime 字段
+	Filename string      `json:"filename,omitempty"` // FilePart 的文件名
+	Source   *FileSource `json:"source,omitempty"`   // FilePart 的 source 字段 (可选)
+	Tool     string      `json:"tool,omitempty"`     // ToolPart 的工具名
+	State    *ToolState  `json:"state,omitempty"`    // ToolPart 的执行状态
+	Files    []string    `json:"files,omitempty"`    // PatchPart 修改的文件
+}
+
+// ToolState 工具调用的状态
+type ToolState struct {
+	Status string          `json:"status"`
+	Title  string          `json:"title,omitempty"`
+	Input  json.RawMessage `json:"input,omitempty"`
+	Output string          `json:"output,omitempty"`
+	Error  string          `json:"error,omitempty"`
 }
 
 // FileSource 文件来源
@@ -204,6 +269,7 @@ type Command struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Usage       string `json:"usage"`
+	Template    string `json:"template,omitempty"` // 命令模板，可包含 $ARGUMENTS、$1 等占位符
 }
 
 // ToolIDs 工具 ID 列表
@@ -306,8 +372,205 @@ type Submatch struct {
 	End   int `json:"end"`
 }
 
+// ActionResult 操作类命令的结果
+type ActionResult struct {
+	ID      string `json:"id,omitempty"`
+	Success bool   `json:"success"`
+}
+
+// SubmitResult 创建会话并发送消息的结果
+type SubmitResult struct {
+	SessionID string `json:"sessionId"`
+	MessageID string `json:"messageId,omitempty"`
+	Directory string `json:"directory,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Branch    string `json:"branch,omitempty"` // --worktree 时任务所在的分支
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// CommitResult 将会话变更提交到本地仓库的结果
+type CommitResult struct {
+	SessionID string   `json:"sessionId"`
+	Branch    string   `json:"branch"`
+	Base      string   `json:"base,omitempty"`
+	Commit    string   `json:"commit,omitempty"` // dry-run 时为空
+	Files     []string `json:"files"`
+	Message   string   `json:"message"`
+	DryRun    bool     `json:"dryRun,omitempty"`
+}
+
+// WorktreeInfo 任务工作树及其状态
+type WorktreeInfo struct {
+	SessionID string `json:"sessionId"`
+	Branch    string `json:"branch"`
+	Path      string `json:"path"`
+	Repo      string `json:"repo"`
+	Title     string `json:"title,omitempty"`
+	State     string `json:"state"` // missing、dirty、clean 或 busy
+	Created   int64  `json:"created"`
+}
+
+// WorktreeCleanResult 清理单个工作树的结果
+type WorktreeCleanResult struct {
+	SessionID  string `json:"sessionId"`
+	Branch     string `json:"branch"`
+	Path       string `json:"path"`
+	Status     string `json:"status"`           // merged、removed 或 skipped
+	Commit     string `json:"commit,omitempty"` // --merge 时的合并提交
+	BranchKept bool   `json:"branchKept,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// BulkResult 批量会话操作中单个会话的结果
+type BulkResult struct {
+	ID     string `json:"id"`
+	Title  string `json:"title,omitempty"`
+	Action string `json:"action"`
+	Status string `json:"status"` // done、failed 或 planned
+	Error  string `json:"error,omitempty"`
+}
+
+// GCResult session gc 中单个会话的结果
+type GCResult struct {
+	ID        string `json:"id"`
+	Title     string `json:"title,omitempty"`
+	Directory string `json:"directory,omitempty"`
+	Action    string `json:"action"`           // archive 或 delete
+	Age       string `json:"age"`              // 空闲或归档的时长
+	Status    string `json:"status"`           // done、failed、planned 或 skipped
+	Reason    string `json:"reason,omitempty"` // 跳过的原因
+	Error     string `json:"error,omitempty"`
+}
+
+// SessionNode session tree 中的一个会话及其子会话
+type SessionNode struct {
+	ID        string        `json:"id"`
+	Title     string        `json:"title"`
+	ParentID  string        `json:"parentId,omitempty"`
+	Directory string        `json:"directory,omitempty"`
+	Agent     string        `json:"agent,omitempty"`
+	Model     string        `json:"model,omitempty"`
+	Status    string        `json:"status"` // idle、busy、retry、error 等
+	Created   int64         `json:"created"`
+	Updated   int64         `json:"updated"`
+	Children  []SessionNode `json:"children,omitempty"`
+}
+
+// SessionAlias 会话别名
+type SessionAlias struct {
+	Name string `json:"name"`
+	ID   string `json:"id"`
+}
+
+// CacheInfo 本地会话索引的信息
+type CacheInfo struct {
+	Dir      string `json:"dir"`
+	Server   string `json:"server"`
+	Sessions int    `json:"sessions"`
+	Synced   int64  `json:"synced"` // 最后一次完整同步的时间（毫秒）
+}
+
+// SearchHit session search 的一条结果
+type SearchHit struct {
+	SessionID    string   `json:"sessionId"`
+	SessionTitle string   `json:"sessionTitle,omitempty"`
+	MessageID    string   `json:"messageId"`
+	Role         string   `json:"role"`
+	Field        string   `json:"field"`          // text、tool-input、tool-output 或 file
+	Tool         string   `json:"tool,omitempty"` // 工具调用的工具名
+	Score        int      `json:"score"`
+	Snippet      string   `json:"snippet"`
+	Highlights   [][2]int `json:"highlights"` // 匹配内容在 snippet 中的字节范围
+}
+
+// GCReport session gc 的报告
+type GCReport struct {
+	Time     int64      `json:"time"`
+	Policy   string     `json:"policy"`
+	DryRun   bool       `json:"dryRun"`
+	Archived int        `json:"archived"`
+	Deleted  int        `json:"deleted"`
+	Skipped  int        `json:"skipped"`
+	Failed   int        `json:"failed"`
+	Results  []GCResult `json:"results"`
+}
+
+// UsageGroup usage report 中一个分组的 token 用量和费用
+type UsageGroup struct {
+	Key        string  `json:"key"` // 模型、代理、项目根目录或日期，合计行为 total
+	Messages   int     `json:"messages"`
+	Sessions   int     `json:"sessions"`
+	Input      int64   `json:"input"`
+	Output     int64   `json:"output"`
+	Reasoning  int64   `json:"reasoning"`
+	CacheRead  int64   `json:"cacheRead"`
+	CacheWrite int64   `json:"cacheWrite"`
+	Cost       float64 `json:"cost"`
+}
+
+// UsageReport usage report 的报告，CSV 等表格类格式只输出各分组
+type UsageReport struct {
+	Since   int64        `json:"since"`
+	Until   int64        `json:"until"`
+	GroupBy string       `json:"groupBy"`
+	Groups  []UsageGroup `json:"groups"`
+	Total   UsageGroup   `json:"total"`
+}
+
+// OutputRows 表格类格式输出的行
+func (r UsageReport) OutputRows() interface{} {
+	return r.Groups
+}
+
+// GuardAction 运行超出预算上限时的中止记录
+type GuardAction struct {
+	Time      int64    `json:"time"`
+	SessionID string   `json:"sessionId"`
+	Limit     string   `json:"limit"` // cost、tokens 或 duration
+	Reason    string   `json:"reason"`
+	Cost      float64  `json:"cost"`
+	Tokens    int64    `json:"tokens"`
+	Duration  int64    `json:"duration"` // 运行时长（毫秒）
+	Aborted   []string `json:"aborted"`  // 已中止的会话，包括子会话
+	DryRun    bool     `json:"dryRun,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// HookPayload 钩子收到的事件，webhook 以 JSON 请求体发送，命令从标准输入读取
+type HookPayload struct {
+	Event      string          `json:"event"` // complete、error 或 permission
+	Time       int64           `json:"time"`
+	SessionID  string          `json:"sessionId"`
+	Title      string          `json:"title,omitempty"`
+	Directory  string          `json:"directory,omitempty"`
+	MessageID  string          `json:"messageId,omitempty"`
+	Duration   int64           `json:"duration,omitempty"` // 运行时长（毫秒）
+	Error      string          `json:"error,omitempty"`
+	Permission *HookPermission `json:"permission,omitempty"`
+}
+
+// HookPermission 触发 on-permission 的权限请求
+type HookPermission struct {
+	ID        string `json:"id"`
+	SessionID string `json:"sessionId"` // 请求所属的会话，可能是子会话
+	Tool      string `json:"tool"`
+	Summary   string `json:"summary,omitempty"`
+}
+
+// WaitResult session wait 的结果
+type WaitResult struct {
+	SessionID string `json:"sessionId"`
+	Title     string `json:"title,omitempty"`
+	Directory string `json:"directory,omitempty"`
+	Status    string `json:"status"` // idle、error 或 timeout
+	Error     string `json:"error,omitempty"`
+	Duration  int64  `json:"duration"` // 等待时长（毫秒）
+}
+
 // Event 事件类型
 type Event struct {
-	Type string      `json:"type"`
-	Text interface{} `json:"text"`
+	Type       string          `json:"type"`
+	Text       interface{}     `json:"text"`
+	Properties json.RawMessage `json:"properties,omitempty"` // 事件数据，结构随 Type 变化
 }
@@ -0,0 +1,170 @@
+// Package usage 汇总助手消息的 token 用量和费用
+package usage
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// 分组方式
+const (
+	ByModel   = "model"
+	ByAgent   = "agent"
+	ByProject = "project"
+	ByDay     = "day"
+)
+
+// GroupBys 支持的分组方式，用于帮助信息
+const GroupBys = "model|agent|project|day"
+
+// totalKey 合计行的 Key
+const totalKey = "total"
+
+// dayLayout 按天分组时的日期格式
+const dayLayout = "2006-01-02"
+
+// ValidGroupBy 检查分组方式
+func ValidGroupBy(groupBy string) error {
+	switch groupBy {
+	case ByModel, ByAgent, ByProject, ByDay:
+		return nil
+	}
+	return i18n.Errorf("不支持的分组方式：%s（可选 %s）", groupBy, GroupBys)
+}
+
+// ParseSince 解析统计起点：时长（如 7d、12h）表示 now 之前，日期（如 2026-10-01）表示当天零点（本地时间）
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.ParseInLocation(dayLayout, s, now.Location()); err == nil {
+		return t, nil
+	}
+	d, err := util.ParseAge(s)
+	if err != nil {
+		return time.Time{}, i18n.Errorf("无效的起始时间：%s（示例：7d、12h、2026-10-01）", s)
+	}
+	return now.Add(-d), nil
+}
+
+// Active 最后更新时间不早于 since 的会话，只有这些会话可能包含统计范围内的消息
+func Active(sessions []types.Session, since time.Time) []types.Session {
+	var active []types.Session
+	for _, s := range sessions {
+		if s.Time.Updated >= since.UnixMilli() {
+			active = append(active, s)
+		}
+	}
+	return active
+}
+
+// Report 按 groupBy 汇总 [since, until] 之间创建的助手消息
+// 按天分组时按日期升序排列，其他分组按费用降序排列
+func Report(sessions []types.Session, messages map[string][]types.MessageWithParts, groupBy string, since, until time.Time) types.UsageReport {
+	report := types.UsageReport{
+		Since:   since.UnixMilli(),
+		Until:   until.UnixMilli(),
+		GroupBy: groupBy,
+		Groups:  []types.UsageGroup{},
+		Total:   types.UsageGroup{Key: totalKey},
+	}
+
+	groups := map[string]*types.UsageGroup{}
+	groupSessions := map[string]map[string]bool{}
+	allSessions := map[string]bool{}
+	for _, s := range sessions {
+		for _, m := range messages[s.ID] {
+			info := m.Info
+			if info.Role != "assistant" || (info.Tokens == nil && info.Cost == 0) {
+				continue
+			}
+			created := Created(info)
+			if created < report.Since || created > report.Until {
+				continue
+			}
+
+			key := groupKey(s, info, groupBy, until.Location())
+			g, ok := groups[key]
+			if !ok {
+				g = &types.UsageGroup{Key: key}
+				groups[key] = g
+				groupSessions[key] = map[string]bool{}
+			}
+			add(g, info)
+			add(&report.Total, info)
+			groupSessions[key][s.ID] = true
+			allSessions[s.ID] = true
+		}
+	}
+
+	for key, g := range groups {
+		g.Sessions = len(groupSessions[key])
+		report.Groups = append(report.Groups, *g)
+	}
+	report.Total.Sessions = len(allSessions)
+
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if groupBy != ByDay && a.Cost != b.Cost {
+			return a.Cost > b.Cost
+		}
+		return a.Key < b.Key
+	})
+	return report
+}
+
+// Created 消息的创建时间（毫秒）
+func Created(m types.Message) int64 {
+	if m.Time != nil && m.Time.Created != 0 {
+		return m.Time.Created
+	}
+	return m.CreatedAt
+}
+
+// add 将一条消息的用量累加到分组
+func add(g *types.UsageGroup, m types.Message) {
+	g.Messages++
+	g.Cost += m.Cost
+	if t := m.Tokens; t != nil {
+		g.Input += t.Input
+		g.Output += t.Output
+		g.Reasoning += t.Reasoning
+		g.CacheRead += t.Cache.Read
+		g.CacheWrite += t.Cache.Write
+	}
+}
+
+// groupKey 消息所属的分组，缺少信息时为 -
+func groupKey(s types.Session, m types.Message, groupBy string, loc *time.Location) string {
+	var key string
+	switch groupBy {
+	case ByModel:
+		key = m.ModelID
+		if m.ProviderID != "" && m.ModelID != "" {
+			key = m.ProviderID + "/" + m.ModelID
+		}
+	case ByAgent:
+		key = firstOf(m.Agent, m.Mode, s.Agent)
+	case ByProject:
+		if m.Path != nil {
+			key = m.Path.Root
+		}
+		key = firstOf(key, s.Directory, s.ProjectID)
+	case ByDay:
+		key = time.UnixMilli(Created(m)).In(loc).Format(dayLayout)
+	}
+	return firstOf(key, "-")
+}
+
+// firstOf 第一个非空字符串
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anomalyco/oho/internal/types"
+)
+
+func TestValidGroupBy(t *testing.T) {
+	for _, g := range []string{ByModel, ByAgent, ByProject, ByDay} {
+		if err := ValidGroupBy(g); err != nil {
+			t.Errorf("ValidGroupBy(%q) = %v", g, err)
+		}
+	}
+	if err := ValidGroupBy("session"); err == nil {
+		t.Error("Expected error for unsupported group")
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{input: "7d", want: now.Add(-7 * 24 * time.Hour)},
+		{input: "12h", want: now.Add(-12 * time.Hour)},
+		{input: "2026-10-01", want: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{input: "yesterday", wantErr: true},
+		{input: "-1d", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.input, now)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %v, %v, want %v (error %v)", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// assistant 构造带用量的助手消息
+func assistant(created int64, provider, model, agent string, cost float64, input, output int64) types.MessageWithParts {
+	return types.MessageWithParts{Info: types.Message{
+		Role:       "assistant",
+		Time:       &types.MessageTime{Created: created},
+		ProviderID: provider,
+		ModelID:    model,
+		Agent:      agent,
+		Cost:       cost,
+		Tokens:     &types.Tokens{Input: input, Output: output, Reasoning: 1, Cache: types.TokensCache{Read: 10, Write: 2}},
+	}}
+}
+
+func TestReport(t *testing.T) {
+	day := func(d, h int) int64 { return time.Date(2026, 10, d, h, 0, 0, 0, time.UTC).UnixMilli() }
+	since := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	sessions := []types.Session{
+		{ID: "a", Agent: "build", Directory: "/src/api"},
+		{ID: "b", Directory: "/src/web"},
+	}
+	old := assistant(day(1, 9), "anthropic", "claude", "build", 5, 1000, 1000)
+	withPath := assistant(day(12, 9), "openai", "gpt", "", 0.5, 50, 5)
+	withPath.Info.Path = &types.MessagePath{Cwd: "/src/web/ui", Root: "/src/web"}
+	messages := map[string][]types.MessageWithParts{
+		"a": {
+			{Info: types.Message{Role: "user", Time: &types.MessageTime{Created: day(12, 8)}}},
+			assistant(day(12, 9), "anthropic", "claude", "", 1.25, 100, 20),
+			assistant(day(13, 9), "anthropic", "claude", "plan", 0.25, 10, 2),
+			old,
+		},
+		"b": {
+			withPath,
+			{Info: types.Message{Role: "assistant", CreatedAt: day(14, 9), ModelID: "local", Mode: "general", Tokens: &types.Tokens{Input: 7}}},
+		},
+	}
+
+	tests := []struct {
+		groupBy string
+		want    []string
+	}{
+		{ByModel, []string{"anthropic/claude", "openai/gpt", "local"}},
+		{ByAgent, []string{"build", "-", "plan", "general"}},
+		{ByProject, []string{"/src/api", "/src/web"}},
+		{ByDay, []string{"2026-10-12", "2026-10-13", "2026-10-14"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.groupBy, func(t *testing.T) {
+			report := Report(sessions, messages, tt.groupBy, since, until)
+			var keys []string
+			for _, g := range report.Groups {
+				keys = append(keys, g.Key)
+			}
+			if len(keys) != len(tt.want) {
+				t.Fatalf("groups = %v, want %v", keys, tt.want)
+			}
+			for i := range keys {
+				if keys[i] != tt.want[i] {
+					t.Fatalf("groups = %v, want %v", keys, tt.want)
+				}
+			}
+
+			total := report.Total
+			if total.Key != "total" || total.Messages != 4 || total.Sessions != 2 || total.Input != 167 || total.Output != 27 ||
+				total.Reasoning != 3 || total.CacheRead != 30 || total.CacheWrite != 6 || total.Cost != 2 {
+				t.Errorf("total = %+v", total)
+			}
+		})
+	}
+
+	report := Report(sessions, messages, ByProject, since, until)
+	if g := report.Groups[1]; g.Messages != 2 || g.Sessions != 1 || g.Input != 57 {
+		t.Errorf("/src/web group = %+v", g)
+	}
+}
+
+func TestActive(t *testing.T) {
+	since := time.UnixMilli(100)
+	sessions := []types.Session{
+		{ID: "old", Time: types.SessionTime{Updated: 99}},
+		{ID: "new", Time: types.SessionTime{Updated: 100}},
+	}
+	if active := Active(sessions, since); len(active) != 1 || active[0].ID != "new" {
+		t.Errorf("Active() = %v", active)
+	}
+}
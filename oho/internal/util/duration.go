@@ -0,0 +1,55 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/anomalyco/oho/internal/i18n"
+)
+
+// Duration 配置文件中的时长，支持 time.ParseDuration 的格式以及 d（天）和 w（周）
+type Duration time.Duration
+
+// UnmarshalYAML 解析 30d、12h 等格式的时长
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	age, err := ParseAge(value.Value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(age)
+	return nil
+}
+
+// ParseAge 解析时长，在 time.ParseDuration 的基础上支持 d（天）和 w（周）
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil || v < 0 {
+				break
+			}
+			return time.Duration(v * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, i18n.Errorf("无效的时长：%s（示例：90m、12h、7d、2w）", s)
+	}
+	return d, nil
+}
+
+// FormatAge 以最大的整数单位显示时长，如 45d、5h、30m
+func FormatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dm", int(d/time.Minute))
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"90m", 90 * time.Minute, false},
+		{"12h", 12 * time.Hour, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"1.5d", 36 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"xd", 0, true},
+		{"-1h", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseAge(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseAge(%q) = %v, %v", tt.in, got, err)
+			}
+		})
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{45 * 24 * time.Hour, "45d"},
+		{5*time.Hour + 30*time.Minute, "5h"},
+		{30 * time.Minute, "30m"},
+	}
+	for _, tt := range tests {
+		if got := FormatAge(tt.in); got != tt.want {
+			t.Errorf("FormatAge(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/anomalyco/oho/internal/client"
+)
+
+// EnvelopeVersion JSON 输出信封的版本，字段有不兼容变化时递增
+const EnvelopeVersion = 1
+
+// 错误码
+const (
+	ErrorCodeAPI     = "api_error"
+	ErrorCodeGeneric = "error"
+)
+
+// Envelope JSON 输出信封：成功时为 {version, ok: true, data}，失败时为 {version, ok: false, error}
+type Envelope struct {
+	Version int
+	OK      bool
+	Data    interface{}
+	Error   *EnvelopeError
+}
+
+// EnvelopeError 信封中的错误信息
+type EnvelopeError struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Status  int         `json:"status,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// DetailError 带有结构化详情的错误，详情输出到错误信封的 details 中
+type DetailError struct {
+	Err     error
+	Details interface{}
+}
+
+func (e *DetailError) Error() string { return e.Err.Error() }
+
+func (e *DetailError) Unwrap() error { return e.Err }
+
+// WithDetails 为错误附加详情，如已经创建的会话
+func WithDetails(err error, details interface{}) error {
+	return &DetailError{Err: err, Details: details}
+}
+
+// MarshalJSON 成功时只输出 data，失败时只输出 error
+func (e Envelope) MarshalJSON() ([]byte, error) {
+	if e.OK {
+		return json.Marshal(struct {
+			Version int         `json:"version"`
+			OK      bool        `json:"ok"`
+			Data    interface{} `json:"data"`
+		}{e.Version, true, e.Data})
+	}
+	return json.Marshal(struct {
+		Version int            `json:"version"`
+		OK      bool           `json:"ok"`
+		Error   *EnvelopeError `json:"error"`
+	}{e.Version, false, e.Error})
+}
+
+// NewEnvelope 包装成功结果，nil 切片输出为空数组
+func NewEnvelope(data interface{}) Envelope {
+	if v := reflect.ValueOf(data); v.Kind() == reflect.Slice && v.IsNil() {
+		data = reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+	return Envelope{Version: EnvelopeVersion, OK: true, Data: data}
+}
+
+// NewErrorEnvelope 包装错误
+func NewErrorEnvelope(err error) Envelope {
+	e := &EnvelopeError{Code: ErrorCodeGeneric, Message: err.Error()}
+
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		e.Code = ErrorCodeAPI
+		e.Status = apiErr.StatusCode
+	}
+	var detailErr *DetailError
+	if errors.As(err, &detailErr) {
+		e.Details = detailErr.Details
+	}
+	return Envelope{Version: EnvelopeVersion, OK: false, Error: e}
+}
+
+// RenderError JSON 模式下将错误以信封形式写入标准输出并返回 true，其他模式返回 false
+func RenderError(ctx context.Context, err error) bool {
+	if outputFormat(ctx) != FormatJSON {
+		return false
+	}
+	encoder := json.NewEncoder(outputOf(ctx).w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(NewErrorEnvelope(err))
+	return true
+}
@@ -0,0 +1,99 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/config"
+)
+
+func TestEnvelopeJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		envelope Envelope
+		want     string
+	}{
+		{
+			name:     "success",
+			envelope: NewEnvelope(map[string]string{"id": "ses_1"}),
+			want:     `{"version":1,"ok":true,"data":{"id":"ses_1"}}`,
+		},
+		{
+			name:     "nil slice becomes empty array",
+			envelope: NewEnvelope([]string(nil)),
+			want:     `{"version":1,"ok":true,"data":[]}`,
+		},
+		{
+			name:     "nil data",
+			envelope: NewEnvelope(nil),
+			want:     `{"version":1,"ok":true,"data":null}`,
+		},
+		{
+			name:     "generic error",
+			envelope: NewErrorEnvelope(fmt.Errorf("请提供会话 ID")),
+			want:     `{"version":1,"ok":false,"error":{"code":"error","message":"请提供会话 ID"}}`,
+		},
+		{
+			name:     "wrapped API error",
+			envelope: NewErrorEnvelope(fmt.Errorf("获取会话失败：%w", &client.APIError{StatusCode: 404, Message: "not found"})),
+			want:     `{"version":1,"ok":false,"error":{"code":"api_error","message":"获取会话失败：API 错误 [404]: not found","status":404}}`,
+		},
+		{
+			name:     "error with details",
+			envelope: NewErrorEnvelope(WithDetails(fmt.Errorf("发送消息失败"), map[string]string{"sessionId": "ses_1"})),
+			want:     `{"version":1,"ok":false,"error":{"code":"error","message":"发送消息失败","details":{"sessionId":"ses_1"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.envelope)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderToJSONEnvelope(t *testing.T) {
+	var buf bytes.Buffer
+	ok, err := RenderTo(&buf, "json", renderItems()[1])
+	if err != nil || !ok {
+		t.Fatalf("RenderTo failed: ok=%v err=%v", ok, err)
+	}
+
+	var got struct {
+		Version int                    `json:"version"`
+		OK      bool                   `json:"ok"`
+		Data    map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if got.Version != EnvelopeVersion || !got.OK || got.Data["id"] != "ses_2" {
+		t.Errorf("Unexpected envelope: %+v", got)
+	}
+}
+
+func TestRenderErrorOnlyInJSONMode(t *testing.T) {
+	cfg := config.Get()
+	origJSON, origOutput := cfg.JSON, cfg.Output
+	defer func() { cfg.JSON, cfg.Output = origJSON, origOutput }()
+
+	cfg.JSON, cfg.Output = false, ""
+	if RenderError(context.Background(), fmt.Errorf("boom")) {
+		t.Error("Expected errors to be left to stderr in table mode")
+	}
+
+	cfg.Output = "yaml"
+	if RenderError(context.Background(), fmt.Errorf("boom")) {
+		t.Error("Expected errors to be left to stderr in yaml mode")
+	}
+}
@@ -0,0 +1,87 @@
+package util
+
+import (
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// mimeTypes 常用扩展名的 MIME 类型，优先于系统的 MIME 表，保证各平台结果一致
+var mimeTypes = map[string]string{
+	// 图片
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".bmp":  "image/bmp",
+	".svg":  "image/svg+xml",
+
+	// 文档
+	".pdf":  "application/pdf",
+	".doc":  "application/msword",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xls":  "application/vnd.ms-excel",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".ppt":  "application/vnd.ms-powerpoint",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+
+	// 文本
+	".txt":  "text/plain",
+	".md":   "text/markdown",
+	".html": "text/html",
+	".css":  "text/css",
+	".js":   "application/javascript",
+	".json": "application/json",
+	".xml":  "application/xml",
+	".yaml": "application/x-yaml",
+	".yml":  "application/x-yaml",
+
+	// 代码
+	".py":   "text/x-python",
+	".go":   "text/x-go",
+	".java": "text/x-java",
+	".c":    "text/x-c",
+	".cpp":  "text/x-c++",
+	".h":    "text/x-c",
+	".rs":   "text/x-rust",
+	".ts":   "text/x-typescript",
+	".tsx":  "text/x-typescript",
+
+	// 其他
+	".zip": "application/zip",
+	".tar": "application/x-tar",
+	".gz":  "application/gzip",
+	".mp3": "audio/mpeg",
+	".mp4": "video/mp4",
+	".wav": "audio/wav",
+}
+
+// DetectMimeType 根据文件扩展名检测 MIME 类型，未知或没有扩展名时返回 application/octet-stream
+func DetectMimeType(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return "application/octet-stream"
+	}
+	if mimeType, ok := mimeTypes[ext]; ok {
+		return mimeType
+	}
+	if mimeType := mime.TypeByExtension(ext); mimeType != "" {
+		mimeType, _, _ = strings.Cut(mimeType, ";")
+		return mimeType
+	}
+	return "application/octet-stream"
+}
+
+// ConvertModel 将模型参数转换为请求格式：provider:model 转为对象，其他保持字符串，空字符串返回 nil
+func ConvertModel(model string) interface{} {
+	if model == "" {
+		return nil
+	}
+	if providerID, modelID, ok := strings.Cut(model, ":"); ok {
+		return types.Model{ProviderID: providerID, ModelID: modelID}
+	}
+	return model
+}
@@ -0,0 +1,111 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/anomalyco/oho/internal/types"
+)
+
+func TestConvertModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		wantType string
+		wantStr  string
+		wantObj  types.Model
+	}{
+		{
+			name:     "empty model returns nil",
+			model:    "",
+			wantType: "nil",
+		},
+		{
+			name:     "simple model returns string",
+			model:    "gpt-4",
+			wantType: "string",
+			wantStr:  "gpt-4",
+		},
+		{
+			name:     "provider:model format returns Model object",
+			model:    "openai:gpt-4",
+			wantType: "Model",
+			wantObj:  types.Model{ProviderID: "openai", ModelID: "gpt-4"},
+		},
+		{
+			name:     "provider with colon in model name",
+			model:    "anthropic:claude-3-opus",
+			wantType: "Model",
+			wantObj:  types.Model{ProviderID: "anthropic", ModelID: "claude-3-opus"},
+		},
+		{
+			name:     "model without provider stays string",
+			model:    "claude-3",
+			wantType: "string",
+			wantStr:  "claude-3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ConvertModel(tt.model)
+
+			switch tt.wantType {
+			case "nil":
+				if result != nil {
+					t.Errorf("ConvertModel(%q) = %v, want nil", tt.model, result)
+				}
+			case "string":
+				str, ok := result.(string)
+				if !ok {
+					t.Errorf("ConvertModel(%q) returned %T, want string", tt.model, result)
+				} else if str != tt.wantStr {
+					t.Errorf("ConvertModel(%q) = %q, want %q", tt.model, str, tt.wantStr)
+				}
+			case "Model":
+				obj, ok := result.(types.Model)
+				if !ok {
+					t.Errorf("ConvertModel(%q) returned %T, want types.Model", tt.model, result)
+				} else if obj.ProviderID != tt.wantObj.ProviderID || obj.ModelID != tt.wantObj.ModelID {
+					t.Errorf("ConvertModel(%q) = %+v, want %+v", tt.model, obj, tt.wantObj)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectMimeType(t *testing.T) {
+	tests := []struct {
+		name     string
+		filePath string
+		want     string
+	}{
+		{"text file", "test.txt", "text/plain"},
+		{"markdown file", "README.md", "text/markdown"},
+		{"Go source", "main.go", "text/x-go"},
+		{"Python source", "script.py", "text/x-python"},
+		{"JavaScript", "app.js", "application/javascript"},
+		{"TypeScript", "index.ts", "text/x-typescript"},
+		{"TSX file", "component.tsx", "text/x-typescript"},
+		{"JSON", "config.json", "application/json"},
+		{"YAML", "config.yaml", "application/x-yaml"},
+		{"YML", "config.yml", "application/x-yaml"},
+		{"PNG image", "image.png", "image/png"},
+		{"JPEG image", "photo.jpg", "image/jpeg"},
+		{"GIF image", "anim.gif", "image/gif"},
+		{"PDF document", "doc.pdf", "application/pdf"},
+		{"ZIP archive", "archive.zip", "application/zip"},
+		{"unknown extension", "file.unknownext", "application/octet-stream"},
+		{"no extension", "Makefile", "application/octet-stream"},
+		{"dot in directory", "build.d/Makefile", "application/octet-stream"},
+		{"uppercase extension", "IMAGE.PNG", "image/png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectMimeType(tt.filePath)
+			if got != tt.want {
+				t.Errorf("DetectMimeType(%q) = %q, want %q", tt.filePath, got, tt.want)
+			}
+		})
+	}
+}
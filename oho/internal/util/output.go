@@ -1,46 +1,72 @@
 package util
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
-
-	"github.com/anomalyco/oho/internal/config"
 )
 
-// Output 输出结果
-func Output(data interface{}) error {
-	if config.Get().JSON {
-		return OutputJSON(data)
+// outputKey context 中输出设置的键，见 WithOutput
+type outputKey struct{}
+
+// output 命令输出的目标，format 不为空时代替 --output 的格式
+type output struct {
+	w      io.Writer
+	format string
+}
+
+// WithOutput 返回将命令的输出写入 w 并固定输出格式的 context
+// mcpserver 在进程内执行命令时用于捕获输出，不修改 os.Stdout 和配置，多个命令可以同时执行
+func WithOutput(ctx context.Context, w io.Writer, format string) context.Context {
+	return context.WithValue(ctx, outputKey{}, output{w: w, format: format})
+}
+
+// outputOf ctx 中的输出设置，没有时写入 os.Stdout
+func outputOf(ctx context.Context) output {
+	if o, ok := ctx.Value(outputKey{}).(output); ok {
+		return o
 	}
-	return nil
+	return output{w: os.Stdout}
+}
+
+// Output 按 --output 格式输出结果，table 格式不输出
+func Output(ctx context.Context, data interface{}) error {
+	_, err := Render(ctx, data)
+	return err
 }
 
 // OutputJSON 以 JSON 格式输出
-func OutputJSON(data interface{}) error {
-	encoder := json.NewEncoder(os.Stdout)
+func OutputJSON(ctx context.Context, data interface{}) error {
+	encoder := json.NewEncoder(outputOf(ctx).w)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(data)
 }
 
-// OutputText 以文本格式输出
-func OutputText(format string, args ...interface{}) {
-	if !config.Get().JSON {
-		fmt.Printf(format, args...)
+// TextOutput 是否使用默认的 table 文本输出
+func TextOutput(ctx context.Context) bool {
+	return outputFormat(ctx) == FormatTable
+}
+
+// OutputText 以文本格式输出，仅在默认的 table 格式下输出
+func OutputText(ctx context.Context, format string, args ...interface{}) {
+	if outputFormat(ctx) == FormatTable {
+		fmt.Fprintf(outputOf(ctx).w, format, args...)
 	}
 }
 
-// OutputLine 输出一行文本
-func OutputLine(line string) {
-	if !config.Get().JSON {
-		fmt.Println(line)
+// OutputLine 输出一行文本，仅在默认的 table 格式下输出
+func OutputLine(ctx context.Context, line string) {
+	if outputFormat(ctx) == FormatTable {
+		fmt.Fprintln(outputOf(ctx).w, line)
 	}
 }
 
 // OutputTable 输出表格
-func OutputTable(headers []string, rows [][]string) {
-	if config.Get().JSON {
+func OutputTable(ctx context.Context, headers []string, rows [][]string) {
+	if outputFormat(ctx) == FormatJSON {
 		// 转换为 map 数组输出
 		data := make([]map[string]string, len(rows))
 		for i, row := range rows {
@@ -52,9 +78,10 @@ func OutputTable(headers []string, rows [][]string) {
 			}
 			data[i] = rowMap
 		}
-		_ = OutputJSON(data)
+		_ = OutputJSON(ctx, data)
 		return
 	}
+	stdout := outputOf(ctx).w
 
 	// 计算列宽
 	colWidths := make([]int, len(headers))
@@ -74,8 +101,8 @@ func OutputTable(headers []string, rows [][]string) {
 	for i, h := range headers {
 		headerLine += fmt.Sprintf("%-*s ", colWidths[i], h)
 	}
-	fmt.Println(headerLine)
-	fmt.Println(strings.Repeat("-", len(headerLine)))
+	fmt.Fprintln(stdout, headerLine)
+	fmt.Fprintln(stdout, strings.Repeat("-", len(headerLine)))
 
 	// 输出数据行
 	for _, row := range rows {
@@ -85,17 +112,17 @@ func OutputTable(headers []string, rows [][]string) {
 				rowLine += fmt.Sprintf("%-*s ", colWidths[i], cell)
 			}
 		}
-		fmt.Println(rowLine)
+		fmt.Fprintln(stdout, rowLine)
 	}
 }
 
-// Confirm 确认操作
-func Confirm(prompt string) bool {
-	if config.Get().JSON {
+// Confirm 确认操作，提示与其他输出写入同一目标
+func Confirm(ctx context.Context, prompt string) bool {
+	if outputFormat(ctx) == FormatJSON {
 		return true
 	}
 
-	fmt.Printf("%s [y/N]: ", prompt)
+	fmt.Fprintf(outputOf(ctx).w, "%s [y/N]: ", prompt)
 	var response string
 	_, _ = fmt.Scanln(&response)
 	return strings.ToLower(response) == "y" || strings.ToLower(response) == "yes"
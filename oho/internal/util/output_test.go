@@ -1,6 +1,7 @@
 package util
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -28,7 +29,7 @@ func TestOutputJSON(t *testing.T) {
 
 	// Test JSON output
 	data := map[string]string{"key": "value"}
-	err := OutputJSON(data)
+	err := OutputJSON(context.Background(), data)
 	if err != nil {
 		t.Errorf("OutputJSON failed: %v", err)
 	}
@@ -43,7 +44,7 @@ func TestOutputText(t *testing.T) {
 	config.Get().JSON = false
 
 	// Test text output - should not panic
-	OutputText("test %s", "value")
+	OutputText(context.Background(), "test %s", "value")
 }
 
 func TestOutputLine(t *testing.T) {
@@ -55,7 +56,7 @@ func TestOutputLine(t *testing.T) {
 	config.Get().JSON = false
 
 	// Test line output - should not panic
-	OutputLine("test line")
+	OutputLine(context.Background(), "test line")
 }
 
 func TestOutputTable(t *testing.T) {
@@ -73,7 +74,7 @@ func TestOutputTable(t *testing.T) {
 	}
 
 	// Should not panic
-	OutputTable(headers, rows)
+	OutputTable(context.Background(), headers, rows)
 }
 
 func TestOutputTableJSON(t *testing.T) {
@@ -91,7 +92,5 @@ func TestOutputTableJSON(t *testing.T) {
 	}
 
 	// Should not panic
-	OutputTable(headers, rows)
+	OutputTable(context.Background(), headers, rows)
 }
-
-
@@ -0,0 +1,519 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/i18n"
+)
+
+// 输出格式
+const (
+	FormatTable         = "table"
+	FormatWide          = "wide"
+	FormatYAML          = "yaml"
+	FormatJSON          = "json"
+	FormatJSONL         = "jsonl"
+	FormatCSV           = "csv"
+	FormatTemplate      = "template"
+	FormatCustomColumns = "custom-columns"
+)
+
+// OutputFormats --output 支持的格式，用于帮助信息
+const OutputFormats = "table|wide|yaml|json|jsonl|csv|template=...|custom-columns=..."
+
+// ParseOutputFormat 解析 --output 的值，返回格式和参数（template 和 custom-columns 的 = 之后的部分）
+func ParseOutputFormat(s string) (string, string, error) {
+	name, arg, hasArg := strings.Cut(s, "=")
+	switch name {
+	case "", FormatTable, FormatWide, FormatYAML, FormatJSON, FormatJSONL, FormatCSV:
+		if hasArg {
+			return "", "", i18n.Errorf("输出格式 %s 不接受参数", name)
+		}
+		if name == "" {
+			name = FormatTable
+		}
+		return name, "", nil
+	case FormatTemplate, FormatCustomColumns:
+		if arg == "" {
+			return "", "", i18n.Errorf("输出格式 %s 需要参数，如 %s=...", name, name)
+		}
+		if name == FormatCustomColumns {
+			if _, err := parseColumns(arg); err != nil {
+				return "", "", err
+			}
+		}
+		return name, arg, nil
+	}
+	return "", "", i18n.Errorf("不支持的输出格式：%s（可选 %s）", s, OutputFormats)
+}
+
+// outputFormat 当前生效的输出格式，--json 等同于 -o json
+func outputFormat(ctx context.Context) string {
+	if o := outputOf(ctx); o.format != "" {
+		return o.format
+	}
+	cfg := config.Get()
+	if cfg.Output != "" {
+		return cfg.Output
+	}
+	if cfg.JSON {
+		return FormatJSON
+	}
+	return FormatTable
+}
+
+// Render 按 --output 指定的格式输出数据
+// 格式为 table 时返回 false，由命令使用自己的默认布局输出
+func Render(ctx context.Context, data interface{}) (bool, error) {
+	return RenderTo(outputOf(ctx).w, outputFormat(ctx), data)
+}
+
+// Rows 由报告等包含汇总信息的结构体实现，表格类格式（wide、custom-columns、csv）只输出其中的行
+type Rows interface {
+	OutputRows() interface{}
+}
+
+// RenderTo 按指定格式将数据写入 w
+func RenderTo(w io.Writer, output string, data interface{}) (bool, error) {
+	format, arg, err := ParseOutputFormat(output)
+	if err != nil {
+		return false, err
+	}
+	if r, ok := data.(Rows); ok {
+		switch format {
+		case FormatWide, FormatCustomColumns, FormatCSV:
+			data = r.OutputRows()
+		}
+	}
+
+	switch format {
+	case FormatTable:
+		return false, nil
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return true, encoder.Encode(NewEnvelope(data))
+	case FormatJSONL:
+		return true, renderJSONL(w, data)
+	case FormatYAML:
+		return true, renderYAML(w, data)
+	case FormatTemplate:
+		return true, renderTemplate(w, arg, data)
+	case FormatCustomColumns:
+		columns, _ := parseColumns(arg)
+		return true, renderColumns(w, columns, data)
+	case FormatWide:
+		return true, renderWide(w, data)
+	case FormatCSV:
+		return true, renderCSV(w, data)
+	}
+	return false, nil
+}
+
+// items 将切片展开为元素列表，其他值视为单个元素
+func items(data interface{}) []interface{} {
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		result := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			result[i] = v.Index(i).Interface()
+		}
+		return result
+	}
+	return []interface{}{data}
+}
+
+// generic 通过 JSON 将值转换为 map/slice 形式，使字段名与 JSON 输出一致
+func generic(data interface{}) (interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func renderJSONL(w io.Writer, data interface{}) error {
+	encoder := json.NewEncoder(w)
+	for _, item := range items(data) {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderYAML(w io.Writer, data interface{}) error {
+	v, err := generic(data)
+	if err != nil {
+		return err
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(yamlValue(v)); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// yamlValue 将 json.Number 转换为数字，避免 YAML 中输出为字符串
+func yamlValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = yamlValue(item)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = yamlValue(item)
+		}
+		return val
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+		return val.String()
+	}
+	return v
+}
+
+// templateFuncs 模板可用的函数
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// renderTemplate 对每个元素执行 Go 模板，每个元素输出一行
+func renderTemplate(w io.Writer, text string, data interface{}) error {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return i18n.Errorf("解析模板失败：%w", err)
+	}
+
+	for _, item := range items(data) {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, item); err != nil {
+			return i18n.Errorf("执行模板失败：%w", err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// column custom-columns 中的一列
+type column struct {
+	header string
+	path   []string
+}
+
+// parseColumns 解析 NAME:.path,NAME:.path 形式的列定义
+func parseColumns(spec string) ([]column, error) {
+	var columns []column
+	for _, part := range strings.Split(spec, ",") {
+		header, path, ok := strings.Cut(part, ":")
+		if !ok || header == "" || path == "" {
+			return nil, i18n.Errorf("无效的列定义：%q（格式为 NAME:.field）", part)
+		}
+		columns = append(columns, column{header: header, path: splitPath(path)})
+	}
+	return columns, nil
+}
+
+// splitPath 将 .a.b[0].c 拆分为 [a b 0 c]
+func splitPath(path string) []string {
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	var segments []string
+	for _, s := range strings.Split(path, ".") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+// lookup 在通用值中按路径取值
+func lookup(v interface{}, path []string) (interface{}, bool) {
+	for _, key := range path {
+		switch val := v.(type) {
+		case map[string]interface{}:
+			next, ok := val[key]
+			if !ok {
+				return nil, false
+			}
+			v = next
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(val) {
+				return nil, false
+			}
+			v = val[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// formatCell 将值格式化为表格单元格
+func formatCell(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		return strconv.FormatBool(val)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func renderColumns(w io.Writer, columns []column, data interface{}) error {
+	v, err := generic(data)
+	if err != nil {
+		return err
+	}
+
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.header
+	}
+
+	var rows [][]string
+	for _, item := range items(v) {
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			value, ok := lookup(item, c.path)
+			if !ok {
+				value = nil
+			}
+			row[i] = formatCell(value)
+		}
+		rows = append(rows, row)
+	}
+	return writeTable(w, headers, rows)
+}
+
+// renderWide 以表格输出每个元素的全部字段，嵌套对象展开为 a.b 形式的列
+func renderWide(w io.Writer, data interface{}) error {
+	keys, rows, err := flatRows(data, 60)
+	if err != nil {
+		return err
+	}
+	headers := make([]string, len(keys))
+	for i, k := range keys {
+		headers[i] = strings.ToUpper(k)
+	}
+	return writeTable(w, headers, rows)
+}
+
+// renderCSV 以 CSV 输出每个元素的全部字段，表头为 JSON 字段名，嵌套对象展开为 a.b 形式的列
+// 列的顺序与 JSON 输出中字段的顺序一致
+func renderCSV(w io.Writer, data interface{}) error {
+	keys, rows, err := flatRows(data, 0)
+	if err != nil {
+		return err
+	}
+	order, err := fieldOrder(data)
+	if err != nil {
+		return err
+	}
+	keys, rows = reorder(keys, rows, order)
+	cw := csv.NewWriter(w)
+	if err := cw.Write(keys); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// fieldOrder 按字段在 JSON 中首次出现的顺序返回展开后的列名
+func fieldOrder(data interface{}) ([]string, error) {
+	seen := make(map[string]bool)
+	var order []string
+	for _, item := range items(data) {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		err = walkFields(json.NewDecoder(bytes.NewReader(raw)), "", func(key string) {
+			if !seen[key] {
+				seen[key] = true
+				order = append(order, key)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// walkFields 读取一个 JSON 值，对象逐层展开，其他值以 prefix 作为列名
+func walkFields(decoder *json.Decoder, prefix string, add func(string)) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case json.Delim('{'):
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			key := keyToken.(string)
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if err := walkFields(decoder, key, add); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+		return err
+	case json.Delim('['):
+		// 数组作为一个单元格，跳过其中的元素
+		for depth := 1; depth > 0; {
+			token, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			switch token {
+			case json.Delim('['), json.Delim('{'):
+				depth++
+			case json.Delim(']'), json.Delim('}'):
+				depth--
+			}
+		}
+	}
+	if prefix == "" {
+		prefix = "value"
+	}
+	add(prefix)
+	return nil
+}
+
+// reorder 按 order 重新排列列
+func reorder(keys []string, rows [][]string, order []string) ([]string, [][]string) {
+	index := make(map[string]int, len(keys))
+	for i, k := range keys {
+		index[k] = i
+	}
+	result := make([][]string, len(rows))
+	for i, row := range rows {
+		result[i] = make([]string, len(order))
+		for j, k := range order {
+			result[i][j] = row[index[k]]
+		}
+	}
+	return order, result
+}
+
+// flatRows 将每个元素展开为一行，返回列名和各行的值；width 大于 0 时截断过长的值
+func flatRows(data interface{}, width int) ([]string, [][]string, error) {
+	v, err := generic(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var flat []map[string]string
+	seen := make(map[string]bool)
+	var keys []string
+	for _, item := range items(v) {
+		fields := make(map[string]string)
+		flatten("", item, fields, width)
+		for k := range fields {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+		flat = append(flat, fields)
+	}
+
+	// id 列放在最前，其余按字母排序
+	sort.Slice(keys, func(i, j int) bool {
+		if (keys[i] == "id") != (keys[j] == "id") {
+			return keys[i] == "id"
+		}
+		return keys[i] < keys[j]
+	})
+
+	rows := make([][]string, len(flat))
+	for i, fields := range flat {
+		row := make([]string, len(keys))
+		for j, k := range keys {
+			row[j] = fields[k]
+		}
+		rows[i] = row
+	}
+	return keys, rows, nil
+}
+
+func flatten(prefix string, v interface{}, out map[string]string, width int) {
+	if m, ok := v.(map[string]interface{}); ok {
+		for k, item := range m {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			flatten(key, item, out, width)
+		}
+		return
+	}
+	if prefix == "" {
+		prefix = "value"
+	}
+	cell := formatCell(v)
+	if width > 0 {
+		cell = Truncate(cell, width)
+	}
+	out[prefix] = cell
+}
+
+func writeTable(w io.Writer, headers []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
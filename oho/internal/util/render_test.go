@@ -0,0 +1,183 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/anomalyco/oho/internal/config"
+)
+
+type renderItem struct {
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+	Time  struct {
+		Created int64 `json:"created"`
+	} `json:"time"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// renderReport 带汇总的报告，表格类格式只输出 Items
+type renderReport struct {
+	Total int          `json:"total"`
+	Items []renderItem `json:"items"`
+}
+
+func (r renderReport) OutputRows() interface{} { return r.Items }
+
+func renderItems() []renderItem {
+	a := renderItem{ID: "ses_1", Title: "first", Tags: []string{"x", "y"}}
+	a.Time.Created = 1700000000000
+	b := renderItem{ID: "ses_2"}
+	b.Time.Created = 1700000001000
+	return []renderItem{a, b}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		format  string
+		arg     string
+		wantErr bool
+	}{
+		{input: "", format: FormatTable},
+		{input: "yaml", format: FormatYAML},
+		{input: "jsonl", format: FormatJSONL},
+		{input: "csv", format: FormatCSV},
+		{input: "template={{.ID}}", format: FormatTemplate, arg: "{{.ID}}"},
+		{input: "custom-columns=ID:.id,TITLE:.title", format: FormatCustomColumns, arg: "ID:.id,TITLE:.title"},
+		{input: "template=", wantErr: true},
+		{input: "custom-columns=ID", wantErr: true},
+		{input: "json=1", wantErr: true},
+		{input: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			format, arg, err := ParseOutputFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOutputFormat(%q) error = %v", tt.input, err)
+			}
+			if format != tt.format || arg != tt.arg {
+				t.Errorf("ParseOutputFormat(%q) = %q, %q; want %q, %q", tt.input, format, arg, tt.format, tt.arg)
+			}
+		})
+	}
+}
+
+func TestRenderTo(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		data   interface{}
+		want   string
+	}{
+		{
+			name:   "template per item",
+			output: "template={{.ID}}",
+			data:   renderItems(),
+			want:   "ses_1\nses_2\n",
+		},
+		{
+			name:   "template single value",
+			output: "template={{.ID}} {{join .Tags \",\"}}",
+			data:   renderItems()[0],
+			want:   "ses_1 x,y\n",
+		},
+		{
+			name:   "custom columns",
+			output: "custom-columns=ID:.id,TITLE:.title,CREATED:.time.created,TAG:.tags[1]",
+			data:   renderItems(),
+			want:   "ID     TITLE   CREATED        TAG\nses_1  first   1700000000000  y\nses_2  <none>  1700000001000  <none>\n",
+		},
+		{
+			name:   "jsonl",
+			output: "jsonl",
+			data:   renderItems(),
+			want:   `{"id":"ses_1","title":"first","time":{"created":1700000000000},"tags":["x","y"]}` + "\n" + `{"id":"ses_2","time":{"created":1700000001000}}` + "\n",
+		},
+		{
+			name:   "yaml",
+			output: "yaml",
+			data:   renderItems()[1],
+			want:   "id: ses_2\ntime:\n  created: 1700000001000\n",
+		},
+		{
+			name:   "wide",
+			output: "wide",
+			data:   renderItems(),
+			want:   "ID     TAGS       TIME.CREATED   TITLE\nses_1  [\"x\",\"y\"]  1700000000000  first\nses_2             1700000001000  \n",
+		},
+		{
+			name:   "csv",
+			output: "csv",
+			data:   renderItems(),
+			want:   "id,title,time.created,tags\nses_1,first,1700000000000,\"[\"\"x\"\",\"\"y\"\"]\"\nses_2,,1700000001000,\n",
+		},
+		{
+			name:   "csv rows of a report",
+			output: "csv",
+			data:   renderReport{Total: 2, Items: renderItems()},
+			want:   "id,title,time.created,tags\nses_1,first,1700000000000,\"[\"\"x\"\",\"\"y\"\"]\"\nses_2,,1700000001000,\n",
+		},
+		{
+			name:   "json of a report",
+			output: "jsonl",
+			data:   renderReport{Total: 2},
+			want:   `{"total":2,"items":null}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			ok, err := RenderTo(&buf, tt.output, tt.data)
+			if err != nil {
+				t.Fatalf("RenderTo failed: %v", err)
+			}
+			if !ok {
+				t.Fatal("Expected data to be rendered")
+			}
+			if buf.String() != tt.want {
+				t.Errorf("RenderTo(%q) =\n%q\nwant\n%q", tt.output, buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderTableFallsThrough(t *testing.T) {
+	var buf bytes.Buffer
+	ok, err := RenderTo(&buf, "table", renderItems())
+	if ok || err != nil || buf.Len() != 0 {
+		t.Errorf("Expected table format to be left to the command, got ok=%v err=%v out=%q", ok, err, buf.String())
+	}
+}
+
+func TestRenderTemplateError(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := RenderTo(&buf, "template={{.Missing}}", renderItems()); err == nil || !strings.Contains(err.Error(), "模板") {
+		t.Errorf("Expected template execution error, got %v", err)
+	}
+}
+
+func TestOutputFormatFromConfig(t *testing.T) {
+	cfg := config.Get()
+	origJSON, origOutput := cfg.JSON, cfg.Output
+	defer func() { cfg.JSON, cfg.Output = origJSON, origOutput }()
+
+	cfg.JSON, cfg.Output = false, ""
+	if got := outputFormat(context.Background()); got != FormatTable {
+		t.Errorf("Expected table by default, got %s", got)
+	}
+
+	cfg.JSON = true
+	if got := outputFormat(context.Background()); got != FormatJSON {
+		t.Errorf("Expected --json to select json, got %s", got)
+	}
+
+	cfg.Output = "yaml"
+	if got := outputFormat(context.Background()); got != FormatYAML {
+		t.Errorf("Expected --output to win over --json, got %s", got)
+	}
+}
@@ -0,0 +1,65 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/anomalyco/oho/internal/markdown"
+)
+
+// defaultTerminalWidth 无法获取终端宽度时的默认值
+const defaultTerminalWidth = 80
+
+// IsTerminal 判断文件是否为终端
+func IsTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return (stat.Mode() & os.ModeCharDevice) != 0
+}
+
+// ColorEnabled 判断是否向 stdout 输出 ANSI 样式
+// stdout 不是终端、设置了 NO_COLOR 或 TERM=dumb 时关闭
+func ColorEnabled() bool {
+	return colorEnabled(IsTerminal(os.Stdout), os.Getenv("NO_COLOR"), os.Getenv("TERM"))
+}
+
+func colorEnabled(tty bool, noColor, term string) bool {
+	return tty && noColor == "" && term != "dumb"
+}
+
+// TerminalWidth 终端宽度，COLUMNS 环境变量优先，其次是 stdout 所在终端的宽度
+func TerminalWidth() int {
+	return terminalWidth(os.Getenv("COLUMNS"), int(os.Stdout.Fd()))
+}
+
+func terminalWidth(columns string, fd int) int {
+	if n, err := strconv.Atoi(strings.TrimSpace(columns)); err == nil && n > 0 {
+		return n
+	}
+	if width, _, err := term.GetSize(fd); err == nil && width > 0 {
+		return width
+	}
+	return defaultTerminalWidth
+}
+
+// FormatMarkdown 在支持样式的终端中渲染 Markdown，否则原样返回
+func FormatMarkdown(text string) string {
+	if !ColorEnabled() {
+		return text
+	}
+	return markdown.Render(text, TerminalWidth())
+}
+
+// OutputMarkdown 输出 Markdown 文本，仅在默认的 table 格式下输出
+func OutputMarkdown(ctx context.Context, text string) {
+	if outputFormat(ctx) == FormatTable {
+		fmt.Fprintln(outputOf(ctx).w, FormatMarkdown(text))
+	}
+}
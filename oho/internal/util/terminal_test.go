@@ -0,0 +1,64 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		tty     bool
+		noColor string
+		term    string
+		want    bool
+	}{
+		{"terminal", true, "", "xterm-256color", true},
+		{"not a terminal", false, "", "xterm", false},
+		{"NO_COLOR", true, "1", "xterm", false},
+		{"dumb terminal", true, "", "dumb", false},
+	}
+
+	for _, tt := range tests {
+		if got := colorEnabled(tt.tty, tt.noColor, tt.term); got != tt.want {
+			t.Errorf("%s: colorEnabled() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTerminalWidth(t *testing.T) {
+	tests := []struct {
+		columns string
+		want    int
+	}{
+		{"120", 120},
+		{"", defaultTerminalWidth},
+		{"abc", defaultTerminalWidth},
+		{"-5", defaultTerminalWidth},
+	}
+
+	// 无效的文件描述符无法获取终端宽度，使用 COLUMNS 或默认值
+	for _, tt := range tests {
+		if got := terminalWidth(tt.columns, -1); got != tt.want {
+			t.Errorf("COLUMNS=%q: terminalWidth() = %d, want %d", tt.columns, got, tt.want)
+		}
+	}
+}
+
+func TestFormatMarkdownPlain(t *testing.T) {
+	// 测试中 stdout 不是终端，原样输出
+	text := "# Title\n\n**bold**"
+	if got := FormatMarkdown(text); got != text {
+		t.Errorf("FormatMarkdown() = %q, want %q", got, text)
+	}
+}
+
+func TestOutputMarkdownWriter(t *testing.T) {
+	var out bytes.Buffer
+	OutputMarkdown(WithOutput(context.Background(), &out, FormatTable), "**bold**")
+
+	if out.String() != "**bold**\n" {
+		t.Errorf("OutputMarkdown() wrote %q", out.String())
+	}
+}
@@ -1,6 +1,9 @@
 package util
 
 import (
+	"bytes"
+	"context"
+	"os"
 	"testing"
 )
 
@@ -48,8 +51,24 @@ func TestPluralize(t *testing.T) {
 }
 
 func TestConfirm(t *testing.T) {
-	// Confirm 函数需要交互式输入，这里只测试不 panic
-	// 在 CI 环境中会被跳过
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	origStdin := os.Stdin
+	defer func() { os.Stdin = origStdin }()
+	os.Stdin = r
+	_, _ = w.WriteString("y\n")
+	w.Close()
+
+	// 提示写入 context 中的输出目标，不写入 os.Stdout
+	var out bytes.Buffer
+	if !Confirm(WithOutput(context.Background(), &out, FormatTable), "Delete?") {
+		t.Error("Expected Confirm() to accept y")
+	}
+	if out.String() != "Delete? [y/N]: " {
+		t.Errorf("Confirm() wrote %q", out.String())
+	}
 }
 
 func TestReadStdin(t *testing.T) {
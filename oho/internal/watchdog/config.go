@@ -0,0 +1,159 @@
+package watchdog
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+// 会话沉默时的动作
+const (
+	ActionWarn   = "warn"
+	ActionAbort  = "abort"
+	ActionResend = "resend"
+)
+
+// Actions 可用的动作，用于帮助信息
+const Actions = "warn|abort|resend"
+
+// Rule 按会话目录和代理覆盖默认的沉默时长和动作，所有已设置的条件都满足时规则命中
+type Rule struct {
+	Name      string        `yaml:"name"`
+	Directory string        `yaml:"directory"`
+	Agent     string        `yaml:"agent"`
+	Silence   util.Duration `yaml:"silence"`
+	Action    string        `yaml:"action"`
+}
+
+// Config 看门狗配置，规则按顺序匹配，第一条命中的规则生效
+type Config struct {
+	Silence    util.Duration `yaml:"silence"`     // 正在工作的会话超过该时长没有事件视为卡住
+	Action     string        `yaml:"action"`      // 卡住时的动作
+	MaxResends int           `yaml:"max_resends"` // 每个会话最多重发的次数，用完后改为中止
+	Rules      []*Rule       `yaml:"rules"`
+}
+
+// Policy 对一个会话生效的沉默时长和动作
+type Policy struct {
+	Rule    string
+	Silence time.Duration
+	Action  string
+}
+
+// LoadConfig 从 YAML 文件加载配置，文件中没有设置的字段使用 defaults；file 为空时只使用 defaults
+func LoadConfig(file string, defaults Config) (*Config, error) {
+	if file == "" {
+		return ParseConfig(nil, defaults)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, i18n.Errorf("读取配置文件失败：%w", err)
+	}
+	return ParseConfig(data, defaults)
+}
+
+// ParseConfig 解析并校验配置
+func ParseConfig(data []byte, defaults Config) (*Config, error) {
+	c := defaults
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, i18n.Errorf("解析配置失败：%w", err)
+	}
+	if c.Silence <= 0 {
+		return nil, i18n.Errorf("silence 必须大于 0")
+	}
+	if !validAction(c.Action) {
+		return nil, i18n.Errorf("action 无效：%q（可选 %s）", c.Action, Actions)
+	}
+	if c.MaxResends < 0 {
+		return nil, i18n.Errorf("max_resends 不能为负数：%d", c.MaxResends)
+	}
+	for i, r := range c.Rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if r.Action != "" && !validAction(r.Action) {
+			return nil, i18n.Errorf("规则 %s 的 action 无效：%q", r.Name, r.Action)
+		}
+		if r.Directory != "" {
+			if _, err := path.Match(strings.TrimSuffix(r.Directory, "/**"), ""); err != nil {
+				return nil, i18n.Errorf("规则 %s 的 directory 模式无效：%s", r.Name, r.Directory)
+			}
+		}
+	}
+	return &c, nil
+}
+
+func validAction(action string) bool {
+	switch action {
+	case ActionWarn, ActionAbort, ActionResend:
+		return true
+	}
+	return false
+}
+
+// For 返回对会话生效的策略，规则没有设置的字段使用默认值
+func (c *Config) For(s types.Session) Policy {
+	p := Policy{Silence: time.Duration(c.Silence), Action: c.Action}
+	for _, r := range c.Rules {
+		if !r.matches(s) {
+			continue
+		}
+		p.Rule = r.Name
+		if r.Silence > 0 {
+			p.Silence = time.Duration(r.Silence)
+		}
+		if r.Action != "" {
+			p.Action = r.Action
+		}
+		break
+	}
+	return p
+}
+
+// MinSilence 所有规则中最短的沉默时长
+func (c *Config) MinSilence() time.Duration {
+	min := time.Duration(c.Silence)
+	for _, r := range c.Rules {
+		if r.Silence > 0 && time.Duration(r.Silence) < min {
+			min = time.Duration(r.Silence)
+		}
+	}
+	return min
+}
+
+func (r *Rule) matches(s types.Session) bool {
+	if r.Agent != "" && r.Agent != s.Agent {
+		return false
+	}
+	if r.Directory != "" && !matchDir(r.Directory, strings.TrimRight(s.Directory, "/")) {
+		return false
+	}
+	return true
+}
+
+// matchDir 按通配符匹配目录，以 /** 结尾时匹配该目录及其所有子目录
+func matchDir(pattern, dir string) bool {
+	if dir == "" {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		for d := dir; ; d = path.Dir(d) {
+			if ok, _ := path.Match(prefix, d); ok {
+				return true
+			}
+			if d == "/" || d == "." {
+				return false
+			}
+		}
+	}
+	ok, _ := path.Match(pattern, dir)
+	return ok
+}
@@ -0,0 +1,82 @@
+package watchdog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+var defaults = Config{Silence: util.Duration(10 * time.Minute), Action: ActionWarn, MaxResends: 1}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"defaults only", "", false},
+		{"rules", "silence: 5m\naction: abort\nrules:\n  - directory: /work/**\n    action: resend\n", false},
+		{"bad action", "action: restart\n", true},
+		{"bad rule action", "rules:\n  - agent: build\n    action: kill\n", true},
+		{"bad silence", "silence: soon\n", true},
+		{"zero silence", "silence: 0s\n", true},
+		{"negative resends", "max_resends: -1\n", true},
+		{"bad directory", "rules:\n  - directory: '/work/[**'\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.data), defaults)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	c, err := ParseConfig([]byte("action: abort\nrules:\n  - agent: build\n"), defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Duration(c.Silence) != 10*time.Minute || c.Action != ActionAbort || c.MaxResends != 1 || c.Rules[0].Name != "rule-1" {
+		t.Errorf("Unexpected config: %+v", c)
+	}
+}
+
+func TestFor(t *testing.T) {
+	c, err := ParseConfig([]byte(`
+rules:
+  - name: ci
+    directory: /work/ci/**
+    silence: 30m
+    action: resend
+  - name: build
+    agent: build
+    action: abort
+  - name: exact
+    directory: /tmp/*
+    silence: 1m
+`), defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		session types.Session
+		want    Policy
+	}{
+		{types.Session{Directory: "/work/ci"}, Policy{"ci", 30 * time.Minute, ActionResend}},
+		{types.Session{Directory: "/work/ci/repo/", Agent: "build"}, Policy{"ci", 30 * time.Minute, ActionResend}},
+		{types.Session{Directory: "/work/cid", Agent: "build"}, Policy{"build", 10 * time.Minute, ActionAbort}},
+		{types.Session{Directory: "/tmp/x"}, Policy{"exact", time.Minute, ActionWarn}},
+		{types.Session{Directory: "/tmp/x/y"}, Policy{"", 10 * time.Minute, ActionWarn}},
+		{types.Session{}, Policy{"", 10 * time.Minute, ActionWarn}},
+	}
+	for _, tt := range tests {
+		if got := c.For(tt.session); got != tt.want {
+			t.Errorf("For(%+v) = %+v, want %+v", tt.session, got, tt.want)
+		}
+	}
+	if got := c.MinSilence(); got != time.Minute {
+		t.Errorf("MinSilence() = %v", got)
+	}
+}
@@ -0,0 +1,74 @@
+package watchdog
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry 看门狗日志中的一条记录
+type Entry struct {
+	Time      time.Time `json:"time"`
+	SessionID string    `json:"sessionID"`
+	Title     string    `json:"title,omitempty"`
+	Directory string    `json:"directory,omitempty"`
+	Rule      string    `json:"rule,omitempty"`
+	Action    string    `json:"action"`
+	Silence   string    `json:"silence"`           // 沉默时长
+	Aborted   []string  `json:"aborted,omitempty"` // 已中止的会话，包括子会话
+	Resent    string    `json:"resent,omitempty"`  // 被重发的用户消息 ID
+	Note      string    `json:"note,omitempty"`
+	DryRun    bool      `json:"dryRun,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Log 以 JSON Lines 格式追加写入看门狗记录
+type Log struct {
+	mu sync.Mutex
+	w  io.Writer
+	f  *os.File
+}
+
+// NewLog 写入到 w
+func NewLog(w io.Writer) *Log {
+	return &Log{w: w}
+}
+
+// OpenLog 以追加方式打开日志文件
+func OpenLog(file string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{w: f, f: f}, nil
+}
+
+// Record 写入一条记录
+func (l *Log) Record(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(append(data, '\n'))
+	return err
+}
+
+// Close 关闭日志文件
+func (l *Log) Close() error {
+	if l.f != nil {
+		return l.f.Close()
+	}
+	return nil
+}
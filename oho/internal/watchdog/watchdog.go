@@ -0,0 +1,369 @@
+// Package watchdog 根据事件流跟踪正在工作的会话最后一次活动的时间，会话沉默过久时告警、中止或中止后重发最后一条提示
+package watchdog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/event"
+	"github.com/anomalyco/oho/internal/i18n"
+	"github.com/anomalyco/oho/internal/permission"
+	"github.com/anomalyco/oho/internal/types"
+)
+
+// 重发前等待会话中止的时长和轮询间隔
+var (
+	idleTimeout  = 30 * time.Second
+	pollInterval = 500 * time.Millisecond
+)
+
+// Stall 沉默超过阈值的根会话
+type Stall struct {
+	Session types.Session
+	Silence time.Duration
+	Policy
+}
+
+// Tracker 跟踪正在工作的会话
+// 子会话的事件计为根会话的活动，只对根会话执行动作
+type Tracker struct {
+	sessions    map[string]types.Session
+	working     map[string]bool      // 正在工作的会话，包括子会话
+	last        map[string]time.Time // 根会话最后一次活动的时间
+	permissions map[string]string    // 待处理的权限请求 ID -> 会话 ID
+	stalled     map[string]bool      // 本次沉默已经处理过的根会话
+	resends     map[string]int
+}
+
+// New 创建 Tracker
+func New() *Tracker {
+	return &Tracker{
+		sessions:    make(map[string]types.Session),
+		working:     make(map[string]bool),
+		last:        make(map[string]time.Time),
+		permissions: make(map[string]string),
+		stalled:     make(map[string]bool),
+		resends:     make(map[string]int),
+	}
+}
+
+// AddSessions 记录会话信息和父子关系
+func (t *Tracker) AddSessions(sessions []types.Session) {
+	for _, s := range sessions {
+		t.sessions[s.ID] = s
+	}
+}
+
+// AddPermission 记录待处理的权限请求，等待人工响应期间不计沉默
+func (t *Tracker) AddPermission(id, sessionID string) {
+	t.permissions[id] = sessionID
+}
+
+// Start 标记会话正在工作，根会话此前没有活动记录时从 now 开始计算
+func (t *Tracker) Start(id string, now time.Time) {
+	t.working[id] = true
+	if root := t.root(id); t.last[root].IsZero() {
+		t.last[root] = now
+	}
+}
+
+// Working 正在工作的根会话，按 ID 排序
+func (t *Tracker) Working() []string {
+	seen := map[string]bool{}
+	var roots []string
+	for id := range t.working {
+		if root := t.root(id); !seen[root] {
+			seen[root] = true
+			roots = append(roots, root)
+		}
+	}
+	sort.Strings(roots)
+	return roots
+}
+
+// root 沿父会话找到根会话
+func (t *Tracker) root(id string) string {
+	seen := map[string]bool{}
+	for !seen[id] {
+		seen[id] = true
+		parent := t.sessions[id].ParentID
+		if parent == "" {
+			break
+		}
+		id = parent
+	}
+	return id
+}
+
+// descendants 根会话的所有子会话，按 ID 排序
+func (t *Tracker) descendants(id string) []string {
+	var result []string
+	for child := range t.sessions {
+		if child != id && t.root(child) == id {
+			result = append(result, child)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// session 返回会话信息，未知的会话只有 ID
+func (t *Tracker) session(id string) types.Session {
+	if s, ok := t.sessions[id]; ok {
+		return s
+	}
+	return types.Session{ID: id}
+}
+
+// eventProps 会话相关事件的数据
+type eventProps struct {
+	SessionID string         `json:"sessionID"`
+	Info      *types.Session `json:"info"`
+	Status    *struct {
+		Type string `json:"type"`
+	} `json:"status"`
+}
+
+// Handle 处理一个事件：更新会话的工作状态，并把事件计为所属根会话的活动
+func (t *Tracker) Handle(e types.Event, now time.Time) {
+	var props eventProps
+	if len(e.Properties) > 0 {
+		_ = json.Unmarshal(e.Properties, &props)
+	}
+
+	switch e.Type {
+	case event.TypeSessionCreated, event.TypeSessionUpdated:
+		if props.Info != nil {
+			t.AddSessions([]types.Session{*props.Info})
+		}
+	case event.TypeSessionDeleted:
+		if props.Info != nil {
+			t.idle(props.Info.ID)
+			delete(t.sessions, props.Info.ID)
+		}
+		return
+	case event.TypeSessionStatus:
+		if props.Status != nil {
+			if props.Status.Type == "idle" {
+				t.idle(props.SessionID)
+			} else {
+				t.Start(props.SessionID, now)
+			}
+		}
+	case event.TypeSessionIdle:
+		t.idle(props.SessionID)
+	case event.TypePermissionAsked, event.TypePermissionUpdated:
+		if r, ok := permission.FromEvent(e); ok {
+			t.AddPermission(r.ID, r.SessionID)
+		}
+	case event.TypePermissionReplied:
+		if id, ok := permission.RepliedID(e); ok {
+			delete(t.permissions, id)
+		}
+	}
+
+	if id := event.SessionID(e); id != "" {
+		if root := t.root(id); t.busy(root) {
+			t.last[root] = now
+			delete(t.stalled, root)
+		}
+	}
+}
+
+// idle 会话空闲，整个会话树都空闲时清除根会话的活动记录
+func (t *Tracker) idle(id string) {
+	if id == "" {
+		return
+	}
+	delete(t.working, id)
+	if root := t.root(id); !t.busy(root) {
+		delete(t.last, root)
+		delete(t.stalled, root)
+	}
+}
+
+// busy 根会话或它的任意子会话正在工作
+func (t *Tracker) busy(root string) bool {
+	for id := range t.working {
+		if t.root(id) == root {
+			return true
+		}
+	}
+	return false
+}
+
+// waiting 根会话树中有等待人工响应的权限请求
+func (t *Tracker) waiting(root string) bool {
+	for _, sessionID := range t.permissions {
+		if t.root(sessionID) == root {
+			return true
+		}
+	}
+	return false
+}
+
+// Stalled 返回新超过沉默阈值的根会话，每次沉默只返回一次，直到会话再次有活动
+func (t *Tracker) Stalled(c *Config, now time.Time) []Stall {
+	var stalls []Stall
+	for _, root := range t.Working() {
+		if t.stalled[root] || t.waiting(root) {
+			continue
+		}
+		s := t.session(root)
+		p := c.For(s)
+		silence := now.Sub(t.last[root])
+		if silence < p.Silence {
+			continue
+		}
+		t.stalled[root] = true
+		stalls = append(stalls, Stall{Session: s, Silence: silence, Policy: p})
+	}
+	return stalls
+}
+
+// Task 对一个卡住的会话要执行的动作，由 Tracker.Act 在事件循环中创建
+// Run 只访问服务器，不访问 Tracker，因此可以在其他 goroutine 中执行
+type Task struct {
+	entry    Entry
+	sessions []string // 要中止的会话：根会话及其子会话
+}
+
+// Act 决定对卡住的会话执行的动作；重发次数用完后改为中止，重发次数在决定重发时计入
+func (t *Tracker) Act(st Stall, maxResends int, dryRun bool, now time.Time) *Task {
+	id := st.Session.ID
+	entry := Entry{
+		Time:      now,
+		SessionID: id,
+		Title:     st.Session.Title,
+		Directory: st.Session.Directory,
+		Rule:      st.Rule,
+		Action:    st.Action,
+		Silence:   st.Silence.Round(time.Second).String(),
+		DryRun:    dryRun,
+	}
+	if entry.Action == ActionResend && t.resends[id] >= maxResends {
+		entry.Action = ActionAbort
+		entry.Note = i18n.Sprintf("已重发 %d 次，改为中止", t.resends[id])
+	}
+	if entry.Action == ActionWarn || dryRun {
+		return &Task{entry: entry}
+	}
+	if entry.Action == ActionResend {
+		t.resends[id]++
+	}
+	return &Task{entry: entry, sessions: append([]string{id}, t.descendants(id)...)}
+}
+
+// Run 执行动作并返回日志记录；warn 和 dry-run 不发送请求
+// 重发时要等待会话停止，可能持续 idleTimeout
+func (k *Task) Run(ctx context.Context, c client.ClientInterface) Entry {
+	entry := k.entry
+	for _, sid := range k.sessions {
+		if _, err := c.Post(ctx, fmt.Sprintf("/session/%s/abort", sid), nil); err != nil {
+			if entry.Error == "" {
+				entry.Error = i18n.Sprintf("中止会话 %s 失败：%v", sid, err)
+			}
+			continue
+		}
+		entry.Aborted = append(entry.Aborted, sid)
+	}
+	if entry.Action != ActionResend || entry.Error != "" || len(k.sessions) == 0 {
+		return entry
+	}
+
+	messageID, err := resend(ctx, c, entry.SessionID)
+	if err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+	entry.Resent = messageID
+	return entry
+}
+
+// promptMessage 重发时读取的用户消息
+type promptMessage struct {
+	Info struct {
+		ID    string       `json:"id"`
+		Role  string       `json:"role"`
+		Agent string       `json:"agent"`
+		Model *types.Model `json:"model"`
+	} `json:"info"`
+	Parts []struct {
+		types.Part
+		Synthetic bool `json:"synthetic"`
+		Ignored   bool `json:"ignored"`
+	} `json:"parts"`
+}
+
+// resend 等待会话中止后，以相同的代理和模型重新发送最后一条用户消息的文本和文件，返回被重发的消息 ID
+func resend(ctx context.Context, c client.ClientInterface, id string) (string, error) {
+	if err := waitIdle(ctx, c, id); err != nil {
+		return "", err
+	}
+	resp, err := c.Get(ctx, fmt.Sprintf("/session/%s/message", id))
+	if err != nil {
+		return "", err
+	}
+	var messages []promptMessage
+	if err := json.Unmarshal(resp, &messages); err != nil {
+		return "", i18n.Errorf("解析消息列表失败：%w", err)
+	}
+
+	for i := len(messages) - 1; i >= 0; i-- {
+		m := messages[i]
+		if m.Info.Role != "user" {
+			continue
+		}
+		req := types.MessageRequest{Agent: m.Info.Agent, Parts: []types.Part{}}
+		if m.Info.Model != nil {
+			req.Model = m.Info.Model
+		}
+		for _, p := range m.Parts {
+			switch {
+			case p.Synthetic || p.Ignored:
+			case p.Type == "text" && p.Text != nil && *p.Text != "":
+				req.Parts = append(req.Parts, types.Part{Type: "text", Text: p.Text})
+			case p.Type == "file":
+				req.Parts = append(req.Parts, types.Part{Type: "file", URL: p.URL, Mime: p.Mime, Filename: p.Filename, Source: p.Source})
+			}
+		}
+		if len(req.Parts) == 0 {
+			continue
+		}
+		if _, err := c.Post(ctx, fmt.Sprintf("/session/%s/prompt_async", id), req); err != nil {
+			return "", i18n.Errorf("重发消息失败：%w", err)
+		}
+		return m.Info.ID, nil
+	}
+	return "", i18n.Errorf("会话 %s 没有可以重发的用户消息", id)
+}
+
+// waitIdle 轮询会话状态直到会话不再工作
+func waitIdle(ctx context.Context, c client.ClientInterface, id string) error {
+	deadline := time.Now().Add(idleTimeout)
+	for {
+		resp, err := c.Get(ctx, "/session/status")
+		if err != nil {
+			return err
+		}
+		var statuses map[string]types.SessionStatus
+		if err := json.Unmarshal(resp, &statuses); err != nil {
+			return i18n.Errorf("解析会话状态失败：%w", err)
+		}
+		if !statuses[id].Working() {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return i18n.Errorf("会话 %s 在 %s 内没有停止，未重发", id, idleTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package watchdog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anomalyco/oho/internal/client"
+	"github.com/anomalyco/oho/internal/types"
+	"github.com/anomalyco/oho/internal/util"
+)
+
+func newEvent(typ string, props map[string]interface{}) types.Event {
+	data, _ := json.Marshal(props)
+	return types.Event{Type: typ, Properties: data}
+}
+
+func statusEvent(sessionID, status string) types.Event {
+	return newEvent("session.status", map[string]interface{}{"sessionID": sessionID, "status": map[string]string{"type": status}})
+}
+
+func partEvent(sessionID string) types.Event {
+	return newEvent("message.part.updated", map[string]interface{}{"part": map[string]string{"id": "prt_1", "sessionID": sessionID, "type": "text"}})
+}
+
+func TestStalled(t *testing.T) {
+	c := &Config{Silence: util.Duration(time.Minute), Action: ActionWarn}
+	start := time.Unix(1000, 0)
+	tr := New()
+	tr.AddSessions([]types.Session{{ID: "ses_root", Title: "Root"}, {ID: "ses_child", ParentID: "ses_root"}})
+	tr.Start("ses_root", start)
+	tr.Handle(statusEvent("ses_other", "busy"), start)
+
+	// 子会话的事件计为根会话的活动
+	tr.Handle(partEvent("ses_child"), start.Add(50*time.Second))
+	stalls := tr.Stalled(c, start.Add(70*time.Second))
+	if len(stalls) != 1 || stalls[0].Session.ID != "ses_other" || stalls[0].Silence != 70*time.Second {
+		t.Fatalf("Stalled() = %+v", stalls)
+	}
+	// 每次沉默只报告一次
+	stalls = tr.Stalled(c, start.Add(2*time.Minute))
+	if len(stalls) != 1 || stalls[0].Session.ID != "ses_root" || stalls[0].Session.Title != "Root" {
+		t.Fatalf("Stalled() = %+v", stalls)
+	}
+	if stalls := tr.Stalled(c, start.Add(3*time.Minute)); len(stalls) != 0 {
+		t.Errorf("Expected stall to be reported once, got %+v", stalls)
+	}
+	// 有新活动后重新计算
+	tr.Handle(partEvent("ses_root"), start.Add(3*time.Minute))
+	if stalls := tr.Stalled(c, start.Add(4*time.Minute)); len(stalls) != 1 {
+		t.Errorf("Expected stall after new silence, got %+v", stalls)
+	}
+
+	// 等待权限响应期间不计沉默
+	tr.Handle(statusEvent("ses_root", "idle"), start.Add(4*time.Minute))
+	tr.Handle(statusEvent("ses_other", "idle"), start.Add(4*time.Minute))
+	if working := tr.Working(); len(working) != 0 {
+		t.Fatalf("Working() after idle = %v", working)
+	}
+	tr.Handle(statusEvent("ses_child", "busy"), start.Add(5*time.Minute))
+	tr.Handle(newEvent("permission.asked", map[string]interface{}{"id": "per_1", "sessionID": "ses_child", "permission": "bash"}), start.Add(5*time.Minute))
+	if stalls := tr.Stalled(c, start.Add(time.Hour)); len(stalls) != 0 {
+		t.Errorf("Expected no stall while waiting for permission, got %+v", stalls)
+	}
+	tr.Handle(newEvent("permission.replied", map[string]interface{}{"requestID": "per_1", "sessionID": "ses_child"}), start.Add(time.Hour))
+	if stalls := tr.Stalled(c, start.Add(time.Hour+time.Minute)); len(stalls) != 1 || stalls[0].Session.ID != "ses_root" {
+		t.Errorf("Stalled() after reply = %+v", stalls)
+	}
+}
+
+func TestAct(t *testing.T) {
+	idleTimeout, pollInterval = time.Second, time.Millisecond
+	text := "fix the build"
+	var posts []string
+	var prompt types.MessageRequest
+	mock := &client.MockClient{
+		GetFunc: func(ctx context.Context, path string) ([]byte, error) {
+			if path == "/session/status" {
+				return []byte(`{}`), nil
+			}
+			return []byte(`[
+				{"info":{"id":"msg_1","role":"user","agent":"build","model":{"providerID":"p","modelID":"m"}},"parts":[{"type":"text","text":"` + text + `"},{"type":"text","text":"file contents","synthetic":true},{"type":"file","url":"file:///a.go","mime":"text/plain","filename":"a.go"}]},
+				{"info":{"id":"msg_2","role":"assistant"},"parts":[{"type":"text","text":"working"}]}
+			]`), nil
+		},
+		PostFunc: func(ctx context.Context, path string, body interface{}) ([]byte, error) {
+			if strings.Contains(path, "ses_bad") {
+				return nil, errors.New("API 错误 [500]")
+			}
+			posts = append(posts, path)
+			if req, ok := body.(types.MessageRequest); ok {
+				prompt = req
+			}
+			return []byte("true"), nil
+		},
+	}
+	now := time.Unix(1000, 0)
+	tr := New()
+	tr.AddSessions([]types.Session{{ID: "ses_root"}, {ID: "ses_child", ParentID: "ses_root"}})
+	st := Stall{Session: types.Session{ID: "ses_root", Title: "Root"}, Silence: 90 * time.Second, Policy: Policy{Action: ActionResend}}
+
+	entry := tr.Act(st, 1, false, now).Run(context.Background(), mock)
+	if strings.Join(posts, ",") != "/session/ses_root/abort,/session/ses_child/abort,/session/ses_root/prompt_async" {
+		t.Errorf("posts = %v", posts)
+	}
+	if entry.Action != ActionResend || entry.Resent != "msg_1" || entry.Silence != "1m30s" || len(entry.Aborted) != 2 || entry.Error != "" {
+		t.Errorf("entry = %+v", entry)
+	}
+	if prompt.Agent != "build" || len(prompt.Parts) != 2 || *prompt.Parts[0].Text != text || prompt.Parts[1].Filename != "a.go" {
+		t.Errorf("prompt = %+v", prompt)
+	}
+
+	// 重发次数用完后改为中止
+	posts = nil
+	entry = tr.Act(st, 1, false, now).Run(context.Background(), mock)
+	if entry.Action != ActionAbort || entry.Note == "" || len(posts) != 2 {
+		t.Errorf("entry after max resends = %+v, posts = %v", entry, posts)
+	}
+
+	// warn 和 dry-run 不发送请求
+	posts = nil
+	st.Action = ActionWarn
+	if entry := tr.Act(st, 1, false, now).Run(context.Background(), mock); entry.Action != ActionWarn || len(posts) != 0 {
+		t.Errorf("warn posted %v", posts)
+	}
+	st.Action = ActionAbort
+	if entry := tr.Act(st, 1, true, now).Run(context.Background(), mock); !entry.DryRun || len(posts) != 0 {
+		t.Errorf("dry-run posted %v", posts)
+	}
+
+	// 中止失败时不重发
+	st = Stall{Session: types.Session{ID: "ses_bad"}, Policy: Policy{Action: ActionResend}}
+	if entry := tr.Act(st, 1, false, now).Run(context.Background(), mock); entry.Error == "" || entry.Resent != "" || len(posts) != 0 {
+		t.Errorf("entry = %+v, posts = %v", entry, posts)
+	}
+}
+
+func TestLog(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewLog(&buf)
+	if err := log.Record(Entry{SessionID: "ses_a", Action: ActionWarn, Silence: "10m0s"}); err != nil {
+		t.Fatal(err)
+	}
+	var entry Entry
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Invalid entry: %v", err)
+	}
+	if entry.SessionID != "ses_a" || entry.Time.IsZero() {
+		t.Errorf("entry = %+v", entry)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package worktree
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile 对文件加排他锁，阻塞直到获得锁
+func lockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,345 @@
+// Package worktree 为每个任务创建独立的 git 工作树，并记录工作树与会话的对应关系
+package worktree
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/anomalyco/oho/internal/config"
+	"github.com/anomalyco/oho/internal/git"
+	"github.com/anomalyco/oho/internal/i18n"
+)
+
+// BranchPrefix 任务分支名前缀
+const BranchPrefix = "oho/"
+
+// maxSlugLength 分支名中任务描述部分的最大长度
+const maxSlugLength = 40
+
+// Entry 一个任务工作树
+type Entry struct {
+	SessionID string    `json:"sessionId"`
+	Branch    string    `json:"branch"`
+	Path      string    `json:"path"`
+	Repo      string    `json:"repo"`           // 创建工作树的主仓库
+	Base      string    `json:"base,omitempty"` // 创建时主仓库所在的分支
+	Title     string    `json:"title,omitempty"`
+	Created   time.Time `json:"created"`
+}
+
+// Registry 工作树登记表，保存在 JSON 文件中
+type Registry struct {
+	file    string
+	Entries []Entry `json:"worktrees"`
+}
+
+// DefaultFile 默认的登记表文件
+func DefaultFile() string {
+	return filepath.Join(config.Dir(), "worktrees.json")
+}
+
+// Load 读取登记表，文件不存在时返回空表
+func Load(file string) (*Registry, error) {
+	r := &Registry{file: file}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Save 写入登记表，先写临时文件再替换，避免写到一半的文件
+func (r *Registry) Save() error {
+	dir := filepath.Dir(r.file)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(r.file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.file)
+}
+
+// Update 锁定登记表后读取、修改并写回，避免多个进程同时修改时丢失记录
+// fn 返回错误时不写回
+func Update(file string, fn func(*Registry) error) error {
+	unlock, err := lock(file)
+	if err != nil {
+		return i18n.Errorf("锁定工作树记录失败：%w", err)
+	}
+	defer unlock()
+
+	r, err := Load(file)
+	if err != nil {
+		return i18n.Errorf("读取工作树记录失败：%w", err)
+	}
+	if err := fn(r); err != nil {
+		return err
+	}
+	if err := r.Save(); err != nil {
+		return i18n.Errorf("保存工作树记录失败：%w", err)
+	}
+	return nil
+}
+
+// lock 对登记表旁的 .lock 文件加排他锁，返回解锁函数
+func lock(file string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(file+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// Add 添加记录
+func (r *Registry) Add(e Entry) {
+	r.Entries = append(r.Entries, e)
+}
+
+// Remove 按工作树路径删除记录
+func (r *Registry) Remove(path string) {
+	entries := r.Entries[:0]
+	for _, e := range r.Entries {
+		if e.Path != path {
+			entries = append(entries, e)
+		}
+	}
+	r.Entries = entries
+}
+
+// Find 按会话 ID、分支名或路径查找记录
+func (r *Registry) Find(key string) (Entry, bool) {
+	for _, e := range r.Entries {
+		if e.SessionID == key || e.Branch == key || e.Branch == BranchPrefix+key || e.Path == key {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Register 将记录追加到默认登记表
+func Register(e Entry) error {
+	return Update(DefaultFile(), func(r *Registry) error {
+		r.Add(e)
+		return nil
+	})
+}
+
+// Setup 创建任务工作树，再调用 create 在工作树中创建会话并返回会话 ID
+// 会话创建失败时删除工作树，成功时记录到默认登记表
+func Setup(dir, task string, create func(dir string) (string, error)) (Entry, error) {
+	e, err := Create(dir, task)
+	if err != nil {
+		return Entry{}, i18n.Errorf("创建工作树失败：%w", err)
+	}
+	e.SessionID, err = create(e.Path)
+	if err != nil {
+		_ = Discard(e)
+		return Entry{}, i18n.Errorf("创建会话失败：%w", err)
+	}
+	if err := Register(e); err != nil {
+		return e, i18n.Errorf("记录工作树失败：%w", err)
+	}
+	return e, nil
+}
+
+// Create 在 dir 所在仓库旁创建新分支的工作树
+// 工作树位于 <仓库父目录>/<仓库名>-worktrees/<任务名>，分支为 oho/<任务名>
+func Create(dir, task string) (Entry, error) {
+	repo, err := git.Open(dir)
+	if err != nil {
+		return Entry{}, err
+	}
+	base, _ := repo.CurrentBranch()
+
+	root := filepath.Join(filepath.Dir(repo.Dir), filepath.Base(repo.Dir)+"-worktrees")
+	name := uniqueName(Slug(task), func(name string) bool {
+		if repo.BranchExists(BranchPrefix + name) {
+			return true
+		}
+		_, err := os.Stat(filepath.Join(root, name))
+		return err == nil
+	})
+
+	entry := Entry{
+		Branch:  BranchPrefix + name,
+		Path:    filepath.Join(root, name),
+		Repo:    repo.Dir,
+		Base:    base,
+		Title:   task,
+		Created: time.Now(),
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return Entry{}, err
+	}
+	if err := repo.AddWorktree(entry.Path, entry.Branch); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// Discard 删除刚创建的工作树和分支，用于会话创建失败时回滚
+func Discard(e Entry) error {
+	repo := &git.Repo{Dir: e.Repo}
+	if err := repo.RemoveWorktree(e.Path, true); err != nil {
+		return err
+	}
+	return repo.DeleteBranch(e.Branch, true)
+}
+
+// Slug 由任务描述生成分支名：保留字母数字，其余字符替换为连字符
+func Slug(task string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(task) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= maxSlugLength {
+			break
+		}
+	}
+	slug := strings.Trim(b.String(), "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.Trim(slug[:maxSlugLength], "-")
+	}
+	if slug == "" {
+		slug = "task-" + time.Now().Format("20060102-150405")
+	}
+	return slug
+}
+
+// uniqueName 名称被占用时依次追加 -2、-3……
+func uniqueName(name string, taken func(string) bool) string {
+	if !taken(name) {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := name + "-" + strconv.Itoa(i)
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// 工作树状态
+const (
+	StateMissing = "missing" // 目录已不存在
+	StateDirty   = "dirty"   // 有未提交的改动
+	StateClean   = "clean"
+	StateBusy    = "busy" // 会话仍在运行
+)
+
+// State 检查工作树目录的状态，不包括会话是否在运行
+func State(e Entry) string {
+	if _, err := os.Stat(e.Path); err != nil {
+		return StateMissing
+	}
+	dirty, err := (&git.Repo{Dir: e.Path}).Dirty()
+	if err != nil || dirty {
+		return StateDirty
+	}
+	return StateClean
+}
+
+// Merge 提交工作树中未提交的改动，再将任务分支合并到主仓库，返回合并提交的哈希
+// 主仓库必须仍在创建工作树时的分支上且没有未提交的改动，否则合并到错误的分支，或冲突时中止合并会丢失本地改动
+func Merge(e Entry) (string, error) {
+	repo := &git.Repo{Dir: e.Repo}
+	if e.Base == "" {
+		return "", i18n.Errorf("工作树没有记录创建时的分支，无法确定合并目标")
+	}
+	current, err := repo.CurrentBranch()
+	if err != nil {
+		return "", err
+	}
+	if current != e.Base {
+		return "", i18n.Errorf("主仓库当前在分支 %s，请先切换到创建工作树时的分支 %s", current, e.Base)
+	}
+	dirty, err := repo.Dirty()
+	if err != nil {
+		return "", err
+	}
+	if dirty {
+		return "", i18n.Errorf("主仓库有未提交的改动，请先提交或暂存")
+	}
+
+	trailers := []git.Trailer{{Key: "Session-Id", Value: e.SessionID}}
+	if State(e) == StateDirty {
+		subject := strings.TrimSpace(strings.SplitN(e.Title, "\n", 2)[0])
+		if subject == "" {
+			subject = "Apply changes from session " + e.SessionID
+		}
+		if _, err := (&git.Repo{Dir: e.Path}).CommitAll(git.FormatMessage(subject, "", trailers)); err != nil {
+			return "", err
+		}
+	}
+
+	if err := repo.Merge(e.Branch, git.FormatMessage("Merge branch '"+e.Branch+"'", "", trailers)); err != nil {
+		return "", err
+	}
+	return repo.Head()
+}
+
+// Remove 删除工作树和任务分支
+// 分支有未合并的提交且未指定 force 时保留分支，返回 false
+func Remove(e Entry, force bool) (bool, error) {
+	repo := &git.Repo{Dir: e.Repo}
+	if _, err := os.Stat(e.Path); err != nil {
+		if err := repo.PruneWorktrees(); err != nil {
+			return false, err
+		}
+	} else if err := repo.RemoveWorktree(e.Path, force); err != nil {
+		return false, err
+	}
+	if !repo.BranchExists(e.Branch) {
+		return true, nil
+	}
+	if err := repo.DeleteBranch(e.Branch, force); err != nil {
+		if force {
+			return false, err
+		}
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,304 @@
+package worktree
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/anomalyco/oho/internal/git"
+)
+
+// initRepo 创建包含一次提交的临时仓库
+func initRepo(t *testing.T) *git.Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for key, value := range map[string]string{
+		"GIT_AUTHOR_NAME":     "test",
+		"GIT_AUTHOR_EMAIL":    "test@example.com",
+		"GIT_COMMITTER_NAME":  "test",
+		"GIT_COMMITTER_EMAIL": "test@example.com",
+		"GIT_CONFIG_GLOBAL":   os.DevNull,
+	} {
+		t.Setenv(key, value)
+	}
+
+	dir := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", dir, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := git.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CommitAll("initial\n"); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		task string
+		want string
+	}{
+		{"Fix login bug", "fix-login-bug"},
+		{"  修复 README 中的 typo!! ", "readme-typo"},
+		{"feat/add--api_v2", "feat-add-api-v2"},
+		{"a very long task description that keeps going past the limit", "a-very-long-task-description-that-keeps"},
+	}
+	for _, tt := range tests {
+		if got := Slug(tt.task); got != tt.want {
+			t.Errorf("Slug(%q) = %q, want %q", tt.task, got, tt.want)
+		}
+	}
+
+	if got := Slug("修复登录"); !strings.HasPrefix(got, "task-") {
+		t.Errorf("Slug() without ASCII = %q, want task- prefix", got)
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	taken := map[string]bool{"fix": true, "fix-2": true}
+	if got := uniqueName("fix", func(name string) bool { return taken[name] }); got != "fix-3" {
+		t.Errorf("uniqueName() = %q, want fix-3", got)
+	}
+	if got := uniqueName("new", func(name string) bool { return taken[name] }); got != "new" {
+		t.Errorf("uniqueName() = %q, want new", got)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "worktrees.json")
+
+	r, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load() of missing file: %v", err)
+	}
+	if len(r.Entries) != 0 {
+		t.Fatalf("Expected empty registry, got %d entries", len(r.Entries))
+	}
+
+	r.Add(Entry{SessionID: "ses_1", Branch: "oho/fix", Path: "/tmp/p-worktrees/fix"})
+	r.Add(Entry{SessionID: "ses_2", Branch: "oho/docs", Path: "/tmp/p-worktrees/docs"})
+	if err := r.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	r, err = Load(file)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	for _, key := range []string{"ses_1", "oho/fix", "fix", "/tmp/p-worktrees/fix"} {
+		if e, ok := r.Find(key); !ok || e.SessionID != "ses_1" {
+			t.Errorf("Find(%q) = %+v, %v", key, e, ok)
+		}
+	}
+	if _, ok := r.Find("ses_3"); ok {
+		t.Error("Find(ses_3) should not match")
+	}
+
+	r.Remove("/tmp/p-worktrees/fix")
+	if len(r.Entries) != 1 || r.Entries[0].SessionID != "ses_2" {
+		t.Errorf("Remove() left %+v", r.Entries)
+	}
+}
+
+func TestRegistryUpdateConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "worktrees.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := Update(file, func(r *Registry) error {
+				r.Add(Entry{SessionID: "ses_" + strconv.Itoa(i)})
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	r, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Entries) != 20 {
+		t.Errorf("Expected 20 entries, got %d", len(r.Entries))
+	}
+
+	// fn 返回错误时不写回
+	err = Update(file, func(r *Registry) error {
+		r.Entries = nil
+		return errors.New("boom")
+	})
+	if err == nil {
+		t.Fatal("Expected Update() to return the error")
+	}
+	if r, _ := Load(file); len(r.Entries) != 20 {
+		t.Errorf("Expected the registry to be unchanged, got %d entries", len(r.Entries))
+	}
+
+	// 不留下临时文件
+	tmps, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	if len(tmps) != 0 {
+		t.Errorf("Leftover temp files: %v", tmps)
+	}
+}
+
+func TestCreateMergeRemove(t *testing.T) {
+	repo := initRepo(t)
+
+	e, err := Create(repo.Dir, "Fix login bug")
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if e.Branch != "oho/fix-login-bug" {
+		t.Errorf("Branch = %s", e.Branch)
+	}
+	if want := filepath.Join(filepath.Dir(repo.Dir), "project-worktrees", "fix-login-bug"); e.Path != want {
+		t.Errorf("Path = %s, want %s", e.Path, want)
+	}
+	if State(e) != StateClean {
+		t.Errorf("State() = %s, want clean", State(e))
+	}
+
+	// 同名任务使用新的分支和目录
+	second, err := Create(repo.Dir, "Fix login bug")
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if second.Branch != "oho/fix-login-bug-2" {
+		t.Errorf("Branch = %s, want oho/fix-login-bug-2", second.Branch)
+	}
+	if err := Discard(second); err != nil {
+		t.Fatalf("Discard() error: %v", err)
+	}
+	if repo.BranchExists(second.Branch) {
+		t.Error("Expected discarded branch to be deleted")
+	}
+
+	e.SessionID = "ses_1"
+	if err := os.WriteFile(filepath.Join(e.Path, "login.go"), []byte("package login\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if State(e) != StateDirty {
+		t.Errorf("State() = %s, want dirty", State(e))
+	}
+
+	commit, err := Merge(e)
+	if err != nil {
+		t.Fatalf("Merge() error: %v", err)
+	}
+	if commit == "" {
+		t.Error("Expected merge commit hash")
+	}
+	if _, err := os.Stat(filepath.Join(repo.Dir, "login.go")); err != nil {
+		t.Errorf("Expected merged file in main repository: %v", err)
+	}
+
+	deleted, err := Remove(e, false)
+	if err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+	if !deleted || repo.BranchExists(e.Branch) {
+		t.Error("Expected merged branch to be deleted")
+	}
+	if State(e) != StateMissing {
+		t.Errorf("State() = %s, want missing", State(e))
+	}
+}
+
+func TestMergeRequiresCleanBase(t *testing.T) {
+	repo := initRepo(t)
+
+	e, err := Create(repo.Dir, "docs")
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(e.Path, "DOCS"), []byte("docs\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	head, _ := repo.Head()
+
+	// 主仓库切到了其他分支：不能合并到该分支
+	if err := repo.CreateBranch("other"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Merge(e); err == nil || !strings.Contains(err.Error(), e.Base) {
+		t.Fatalf("Merge() on another branch error = %v, want a refusal naming %s", err, e.Base)
+	}
+	if err := repo.Checkout(e.Base); err != nil {
+		t.Fatal(err)
+	}
+
+	// 主仓库有未提交的改动：冲突时 merge --abort 可能丢失这些改动
+	if err := os.WriteFile(filepath.Join(repo.Dir, "README"), []byte("local edit\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Merge(e); err == nil {
+		t.Fatal("Expected Merge() to refuse a dirty main repository")
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo.Dir, "README")); string(data) != "local edit\n" {
+		t.Errorf("Expected local changes to be kept, got %q", data)
+	}
+
+	// 拒绝时主仓库和工作树都不变
+	if after, _ := repo.Head(); after != head {
+		t.Errorf("HEAD moved from %s to %s", head, after)
+	}
+	if State(e) != StateDirty {
+		t.Errorf("Expected worktree changes to stay uncommitted, got %s", State(e))
+	}
+}
+
+func TestRemoveKeepsUnmergedBranch(t *testing.T) {
+	repo := initRepo(t)
+
+	e, err := Create(repo.Dir, "docs")
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(e.Path, "DOCS"), []byte("docs\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&git.Repo{Dir: e.Path}).CommitAll("docs\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := Remove(e, false)
+	if err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+	if deleted || !repo.BranchExists(e.Branch) {
+		t.Error("Expected unmerged branch to be kept")
+	}
+	if _, err := os.Stat(e.Path); !os.IsNotExist(err) {
+		t.Error("Expected worktree directory to be removed")
+	}
+
+	// 目录已不存在时仍可强制删除分支
+	if deleted, err := Remove(e, true); err != nil || !deleted {
+		t.Errorf("Remove(force) = %v, %v", deleted, err)
+	}
+	if repo.BranchExists(e.Branch) {
+		t.Error("Expected branch to be force-deleted")
+	}
+}